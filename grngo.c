@@ -2,8 +2,20 @@
 
 #include <string.h>
 
+#include "_cgo_export.h"
+
 #define GRNGO_MAX_DATA_TYPE_ID GRN_DB_WGS84_GEO_POINT
 
+// grngo_is_data_type() returns whether id is a supported built-in data type.
+static grn_bool grngo_is_data_type(grn_id id) {
+#ifdef GRNGO_HAVE_FLOAT32
+  if (id == GRN_DB_FLOAT32) {
+    return GRN_TRUE;
+  }
+#endif
+  return id <= GRNGO_MAX_DATA_TYPE_ID;
+}
+
 grn_obj *grngo_find_table(grn_ctx *ctx, const char *name, int name_len) {
   grn_obj *obj = grn_ctx_get(ctx, name, name_len);
   if (!obj) {
@@ -23,6 +35,19 @@ grn_obj *grngo_find_table(grn_ctx *ctx, const char *name, int name_len) {
   }
 }
 
+grn_obj *grngo_table_create_temporary(grn_ctx *ctx, unsigned int flags,
+                                      grn_obj *key_type, grn_obj *value_type) {
+  return grn_table_create(ctx, NULL, 0, NULL, flags, key_type, value_type);
+}
+
+void grngo_ctx_cancel(grn_ctx *ctx) {
+  // NOTE: Groonga checks ctx->rc in long-running loops and aborts the command
+  //       if it is set to GRN_CANCEL.
+  if (ctx->rc == GRN_SUCCESS) {
+    ctx->rc = GRN_CANCEL;
+  }
+}
+
 // grngo_init_type_info() initializes the members of type_info.
 // The initialized type info specifies a valid Void type.
 static void grngo_init_type_info(grngo_type_info *type_info) {
@@ -39,7 +64,7 @@ grn_bool grngo_table_get_key_info(grn_ctx *ctx, grn_obj *table,
       case GRN_TABLE_HASH_KEY:
       case GRN_TABLE_PAT_KEY:
       case GRN_TABLE_DAT_KEY: {
-        if (table->header.domain <= GRNGO_MAX_DATA_TYPE_ID) {
+        if (grngo_is_data_type(table->header.domain)) {
           key_info->data_type = table->header.domain;
           return GRN_TRUE;
         }
@@ -77,7 +102,7 @@ grn_bool grngo_table_get_value_info(grn_ctx *ctx, grn_obj *table,
     case GRN_TABLE_DAT_KEY:
     case GRN_TABLE_NO_KEY: {
       grn_id range = grn_obj_get_range(ctx, table);
-      if (range <= GRNGO_MAX_DATA_TYPE_ID) {
+      if (grngo_is_data_type(range)) {
         value_info->data_type = range;
         return GRN_TRUE;
       }
@@ -119,7 +144,7 @@ grn_bool grngo_column_get_value_info(grn_ctx *ctx, grn_obj *column,
     }
   }
   grn_id range = grn_obj_get_range(ctx, column);
-  if (range <= GRNGO_MAX_DATA_TYPE_ID) {
+  if (grngo_is_data_type(range)) {
     value_info->data_type = range;
     return GRN_TRUE;
   }
@@ -148,18 +173,193 @@ char *grngo_table_get_name(grn_ctx *ctx, grn_obj *table) {
       return NULL;
     }
   }
+  return grngo_obj_get_name(ctx, table);
+}
+
+char *grngo_obj_get_name(grn_ctx *ctx, grn_obj *obj) {
+  if (!obj) {
+    return NULL;
+  }
   char buf[GRN_TABLE_MAX_KEY_SIZE];
-  int len = grn_obj_name(ctx, table, buf, GRN_TABLE_MAX_KEY_SIZE);
+  int len = grn_obj_name(ctx, obj, buf, GRN_TABLE_MAX_KEY_SIZE);
   if (len <= 0) {
     return NULL;
   }
-  char *table_name = (char *)malloc(len + 1);
-  if (!table_name) {
+  char *name = (char *)malloc(len + 1);
+  if (!name) {
     return NULL;
   }
-  memcpy(table_name, buf, len);
-  table_name[len] = '\0';
-  return table_name;
+  memcpy(name, buf, len);
+  name[len] = '\0';
+  return name;
+}
+
+grn_bool grngo_db_get_table_ids(grn_ctx *ctx, grn_id *ids, size_t *n) {
+  grn_obj *db = grn_ctx_db(ctx);
+  if (!db) {
+    return GRN_FALSE;
+  }
+  grn_table_cursor *cursor = grn_table_cursor_open(ctx, db, NULL, 0, NULL, 0,
+                                                   0, -1, GRN_CURSOR_BY_ID);
+  if (!cursor) {
+    return GRN_FALSE;
+  }
+  size_t count = 0;
+  grn_id id;
+  while ((id = grn_table_cursor_next(ctx, cursor)) != GRN_ID_NIL) {
+    grn_obj *obj = grn_ctx_at(ctx, id);
+    if (!obj) {
+      continue;
+    }
+    switch (obj->header.type) {
+      case GRN_TABLE_HASH_KEY:
+      case GRN_TABLE_PAT_KEY:
+      case GRN_TABLE_DAT_KEY:
+      case GRN_TABLE_NO_KEY: {
+        if (count < *n) {
+          ids[count] = id;
+        }
+        count++;
+        break;
+      }
+    }
+  }
+  grn_table_cursor_close(ctx, cursor);
+  *n = count;
+  return GRN_TRUE;
+}
+
+grn_bool grngo_table_get_column_ids(grn_ctx *ctx, grn_obj *table,
+                                    grn_id *ids, size_t *n) {
+  grn_obj *columns = grn_table_create(ctx, NULL, 0, NULL,
+                                      GRN_OBJ_TABLE_HASH_KEY,
+                                      grn_ctx_at(ctx, GRN_DB_UINT32), NULL);
+  if (!columns) {
+    return GRN_FALSE;
+  }
+  grn_table_columns(ctx, table, "", 0, columns);
+  grn_table_cursor *cursor = grn_table_cursor_open(ctx, columns, NULL, 0,
+                                                   NULL, 0, 0, -1,
+                                                   GRN_CURSOR_BY_ID);
+  if (!cursor) {
+    grn_obj_unlink(ctx, columns);
+    return GRN_FALSE;
+  }
+  size_t count = 0;
+  while (grn_table_cursor_next(ctx, cursor) != GRN_ID_NIL) {
+    // The key of a record is the ID of a column.
+    void *key;
+    grn_table_cursor_get_key(ctx, cursor, &key);
+    if (count < *n) {
+      ids[count] = *(grn_id *)key;
+    }
+    count++;
+  }
+  grn_table_cursor_close(ctx, cursor);
+  grn_obj_unlink(ctx, columns);
+  *n = count;
+  return GRN_TRUE;
+}
+
+grn_bool grngo_table_get_disk_usage(grn_ctx *ctx, grn_obj *table,
+                                    uint64_t *usage, uint64_t *index_usage) {
+  *usage = grn_obj_get_disk_usage(ctx, table);
+  *index_usage = 0;
+  grn_obj *columns = grn_table_create(ctx, NULL, 0, NULL,
+                                      GRN_OBJ_TABLE_HASH_KEY,
+                                      grn_ctx_at(ctx, GRN_DB_UINT32), NULL);
+  if (!columns) {
+    return GRN_FALSE;
+  }
+  grn_table_columns(ctx, table, "", 0, columns);
+  grn_table_cursor *cursor = grn_table_cursor_open(ctx, columns, NULL, 0,
+                                                   NULL, 0, 0, -1,
+                                                   GRN_CURSOR_BY_ID);
+  if (!cursor) {
+    grn_obj_unlink(ctx, columns);
+    return GRN_FALSE;
+  }
+  while (grn_table_cursor_next(ctx, cursor) != GRN_ID_NIL) {
+    // The key of a record is the ID of a column.
+    void *key;
+    grn_table_cursor_get_key(ctx, cursor, &key);
+    grn_obj *column = grn_ctx_at(ctx, *(grn_id *)key);
+    if (!column) {
+      continue;
+    }
+    uint64_t column_usage = grn_obj_get_disk_usage(ctx, column);
+    *usage += column_usage;
+    if (column->header.type == GRN_COLUMN_INDEX) {
+      *index_usage += column_usage;
+    }
+  }
+  grn_table_cursor_close(ctx, cursor);
+  grn_obj_unlink(ctx, columns);
+  return GRN_TRUE;
+}
+
+grn_obj *grngo_table_get_tokenizer(grn_ctx *ctx, grn_obj *table) {
+  return grn_obj_get_info(ctx, table, GRN_INFO_DEFAULT_TOKENIZER, NULL);
+}
+
+grn_obj *grngo_table_get_normalizer(grn_ctx *ctx, grn_obj *table) {
+  return grn_obj_get_info(ctx, table, GRN_INFO_NORMALIZER, NULL);
+}
+
+grn_bool grngo_table_get_token_filters(grn_ctx *ctx, grn_obj *table,
+                                       grn_obj **filters, size_t *n) {
+  grn_obj buf;
+  GRN_PTR_INIT(&buf, GRN_OBJ_VECTOR, GRN_ID_NIL);
+  grn_obj_get_info(ctx, table, GRN_INFO_TOKEN_FILTERS, &buf);
+  if (ctx->rc != GRN_SUCCESS) {
+    GRN_OBJ_FIN(ctx, &buf);
+    return GRN_FALSE;
+  }
+  size_t size = GRN_BULK_VSIZE(&buf) / sizeof(grn_obj *);
+  if (size <= *n) {
+    size_t i;
+    for (i = 0; i < size; i++) {
+      filters[i] = GRN_PTR_VALUE_AT(&buf, i);
+    }
+  }
+  *n = size;
+  GRN_OBJ_FIN(ctx, &buf);
+  return GRN_TRUE;
+}
+
+grn_bool grngo_column_is_index(grn_ctx *ctx, grn_obj *column) {
+  return (column && (column->header.type == GRN_COLUMN_INDEX)) ?
+         GRN_TRUE : GRN_FALSE;
+}
+
+grn_bool grngo_column_get_sources(grn_ctx *ctx, grn_obj *column,
+                                  grn_id *ids, size_t *n) {
+  if (!grngo_column_is_index(ctx, column)) {
+    return GRN_FALSE;
+  }
+  grn_obj sources;
+  GRN_RECORD_INIT(&sources, GRN_OBJ_VECTOR, GRN_ID_NIL);
+  if (!grn_obj_get_info(ctx, column, GRN_INFO_SOURCE, &sources)) {
+    GRN_OBJ_FIN(ctx, &sources);
+    return GRN_FALSE;
+  }
+  size_t size = GRN_BULK_VSIZE(&sources) / sizeof(grn_id);
+  if (size <= *n) {
+    size_t i;
+    for (i = 0; i < size; i++) {
+      ids[i] = GRN_RECORD_VALUE_AT(&sources, i);
+    }
+  }
+  *n = size;
+  GRN_OBJ_FIN(ctx, &sources);
+  return GRN_TRUE;
+}
+
+grn_bool grngo_column_has_match_index(grn_ctx *ctx, grn_obj *column) {
+  grn_obj *index;
+  int section;
+  return (grn_column_index(ctx, column, GRN_OP_MATCH, &index, 1,
+                           &section) > 0) ? GRN_TRUE : GRN_FALSE;
 }
 
 // grngo_table_insert_row() calls grn_table_add() and converts the result.
@@ -226,6 +426,11 @@ grngo_row_info grngo_table_insert_float(grn_ctx *ctx, grn_obj *table,
   return grngo_table_insert_row(ctx, table, &key, sizeof(key));
 }
 
+grngo_row_info grngo_table_insert_time(grn_ctx *ctx, grn_obj *table,
+                                       int64_t key) {
+  return grngo_table_insert_row(ctx, table, &key, sizeof(key));
+}
+
 grngo_row_info grngo_table_insert_geo_point(grn_ctx *ctx, grn_obj *table,
                                             grn_geo_point key) {
   return grngo_table_insert_row(ctx, table, &key, sizeof(key));
@@ -236,6 +441,126 @@ grngo_row_info grngo_table_insert_text(grn_ctx *ctx, grn_obj *table,
   return grngo_table_insert_row(ctx, table, key->ptr, key->size);
 }
 
+grngo_row_info grngo_table_insert_ref(grn_ctx *ctx, grn_obj *table,
+                                      grn_id key) {
+  return grngo_table_insert_row(ctx, table, &key, sizeof(key));
+}
+
+size_t grngo_table_insert_rows(grn_ctx *ctx, grn_obj *table,
+                               const void *keys, size_t key_size, size_t n,
+                               grngo_row_info *rows) {
+  size_t i;
+  for (i = 0; i < n; i++) {
+    const char *key_ptr = (const char *)keys + (i * key_size);
+    rows[i] = grngo_table_insert_row(ctx, table, key_ptr, key_size);
+    if (rows[i].id == GRN_ID_NIL) {
+      return i;
+    }
+  }
+  return n;
+}
+
+size_t grngo_table_insert_ints(grn_ctx *ctx, grn_obj *table,
+                               grn_builtin_type data_type,
+                               const int64_t *keys, size_t n,
+                               grngo_row_info *rows) {
+  size_t i;
+  for (i = 0; i < n; i++) {
+    switch (data_type) {
+      case GRN_DB_INT8: {
+        rows[i] = grngo_table_insert_int8(ctx, table, (int8_t)keys[i]);
+        break;
+      }
+      case GRN_DB_INT16: {
+        rows[i] = grngo_table_insert_int16(ctx, table, (int16_t)keys[i]);
+        break;
+      }
+      case GRN_DB_INT32: {
+        rows[i] = grngo_table_insert_int32(ctx, table, (int32_t)keys[i]);
+        break;
+      }
+      case GRN_DB_INT64: {
+        rows[i] = grngo_table_insert_int64(ctx, table, keys[i]);
+        break;
+      }
+      case GRN_DB_UINT8: {
+        rows[i] = grngo_table_insert_uint8(ctx, table, (uint8_t)keys[i]);
+        break;
+      }
+      case GRN_DB_UINT16: {
+        rows[i] = grngo_table_insert_uint16(ctx, table, (uint16_t)keys[i]);
+        break;
+      }
+      case GRN_DB_UINT32: {
+        rows[i] = grngo_table_insert_uint32(ctx, table, (uint32_t)keys[i]);
+        break;
+      }
+      case GRN_DB_UINT64: {
+        rows[i] = grngo_table_insert_uint64(ctx, table, (uint64_t)keys[i]);
+        break;
+      }
+      default: {
+        return i;
+      }
+    }
+    if (rows[i].id == GRN_ID_NIL) {
+      return i;
+    }
+  }
+  return n;
+}
+
+size_t grngo_table_insert_texts(grn_ctx *ctx, grn_obj *table,
+                                const grngo_text *keys, size_t n,
+                                grngo_row_info *rows) {
+  size_t i;
+  for (i = 0; i < n; i++) {
+    rows[i] = grngo_table_insert_text(ctx, table, &keys[i]);
+    if (rows[i].id == GRN_ID_NIL) {
+      return i;
+    }
+  }
+  return n;
+}
+
+grn_bool grngo_table_delete_by_id(grn_ctx *ctx, grn_obj *table, grn_id id) {
+  grn_rc rc = grn_table_delete_by_id(ctx, table, id);
+  return rc == GRN_SUCCESS;
+}
+
+grn_rc grngo_table_delete_row(grn_ctx *ctx, grn_obj *table,
+                              const void *key, unsigned key_size) {
+  if (grn_table_get(ctx, table, key, key_size) == GRN_ID_NIL) {
+    return GRN_END_OF_DATA;
+  }
+  return grn_table_delete(ctx, table, key, key_size);
+}
+
+grn_bool grngo_column_clear_value(grn_ctx *ctx, grn_obj *column, grn_id id) {
+  grn_obj value;
+  GRN_VOID_INIT(&value);
+  grn_rc rc = grn_obj_set_value(ctx, column, id, &value, GRN_OBJ_SET);
+  GRN_OBJ_FIN(ctx, &value);
+  return rc == GRN_SUCCESS;
+}
+
+grn_bool grngo_column_has_value(grn_ctx *ctx, grn_obj *column, grn_id id,
+                                grn_bool *has_value) {
+  grn_obj value;
+  GRN_VOID_INIT(&value);
+  if (!grn_obj_get_value(ctx, column, id, &value)) {
+    GRN_OBJ_FIN(ctx, &value);
+    return GRN_FALSE;
+  }
+  if (value.header.type == GRN_VECTOR) {
+    *has_value = grn_vector_size(ctx, &value) != 0;
+  } else {
+    *has_value = GRN_BULK_VSIZE(&value) != 0;
+  }
+  GRN_OBJ_FIN(ctx, &value);
+  return GRN_TRUE;
+}
+
 grn_bool grngo_column_set_bool(grn_ctx *ctx, grn_obj *column,
                                grn_id id, grn_bool value) {
   grn_obj obj;
@@ -336,6 +661,30 @@ grn_bool grngo_column_set_float(grn_ctx *ctx, grn_obj *column,
   return rc == GRN_SUCCESS;
 }
 
+grn_bool grngo_column_set_float32(grn_ctx *ctx, grn_obj *column,
+                                  grn_id id, float value) {
+#ifdef GRNGO_HAVE_FLOAT32
+  grn_obj obj;
+  GRN_FLOAT32_INIT(&obj, 0);
+  GRN_FLOAT32_SET(ctx, &obj, value);
+  grn_rc rc = grn_obj_set_value(ctx, column, id, &obj, GRN_OBJ_SET);
+  GRN_OBJ_FIN(ctx, &obj);
+  return rc == GRN_SUCCESS;
+#else
+  return GRN_FALSE;
+#endif
+}
+
+grn_bool grngo_column_set_time(grn_ctx *ctx, grn_obj *column,
+                               grn_id id, int64_t value) {
+  grn_obj obj;
+  GRN_TIME_INIT(&obj, 0);
+  GRN_TIME_SET(ctx, &obj, value);
+  grn_rc rc = grn_obj_set_value(ctx, column, id, &obj, GRN_OBJ_SET);
+  GRN_OBJ_FIN(ctx, &obj);
+  return rc == GRN_SUCCESS;
+}
+
 grn_bool grngo_column_set_geo_point(grn_ctx *ctx, grn_obj *column,
                                     grn_builtin_type data_type,
                                     grn_id id, grn_geo_point value) {
@@ -365,6 +714,121 @@ grn_bool grngo_column_set_text(grn_ctx *ctx, grn_obj *column,
   return rc == GRN_SUCCESS;
 }
 
+size_t grngo_column_set_bools(grn_ctx *ctx, grn_obj *column,
+                              const grn_id *ids, const grn_bool *values,
+                              size_t n) {
+  size_t i;
+  for (i = 0; i < n; i++) {
+    if (!grngo_column_set_bool(ctx, column, ids[i], values[i])) {
+      return i;
+    }
+  }
+  return n;
+}
+
+size_t grngo_column_set_ints(grn_ctx *ctx, grn_obj *column,
+                             grn_builtin_type data_type, const grn_id *ids,
+                             const int64_t *values, size_t n) {
+  size_t i;
+  for (i = 0; i < n; i++) {
+    grn_bool ok;
+    switch (data_type) {
+      case GRN_DB_INT8: {
+        ok = grngo_column_set_int8(ctx, column, ids[i], (int8_t)values[i]);
+        break;
+      }
+      case GRN_DB_INT16: {
+        ok = grngo_column_set_int16(ctx, column, ids[i], (int16_t)values[i]);
+        break;
+      }
+      case GRN_DB_INT32: {
+        ok = grngo_column_set_int32(ctx, column, ids[i], (int32_t)values[i]);
+        break;
+      }
+      case GRN_DB_INT64: {
+        ok = grngo_column_set_int64(ctx, column, ids[i], values[i]);
+        break;
+      }
+      case GRN_DB_UINT8: {
+        ok = grngo_column_set_uint8(ctx, column, ids[i], (uint8_t)values[i]);
+        break;
+      }
+      case GRN_DB_UINT16: {
+        ok = grngo_column_set_uint16(ctx, column, ids[i],
+                                     (uint16_t)values[i]);
+        break;
+      }
+      case GRN_DB_UINT32: {
+        ok = grngo_column_set_uint32(ctx, column, ids[i],
+                                     (uint32_t)values[i]);
+        break;
+      }
+      case GRN_DB_UINT64: {
+        ok = grngo_column_set_uint64(ctx, column, ids[i],
+                                     (uint64_t)values[i]);
+        break;
+      }
+      default: {
+        return i;
+      }
+    }
+    if (!ok) {
+      return i;
+    }
+  }
+  return n;
+}
+
+size_t grngo_column_set_floats(grn_ctx *ctx, grn_obj *column,
+                               const grn_id *ids, const double *values,
+                               size_t n) {
+  size_t i;
+  for (i = 0; i < n; i++) {
+    if (!grngo_column_set_float(ctx, column, ids[i], values[i])) {
+      return i;
+    }
+  }
+  return n;
+}
+
+size_t grngo_column_set_times(grn_ctx *ctx, grn_obj *column,
+                              const grn_id *ids, const int64_t *values,
+                              size_t n) {
+  size_t i;
+  for (i = 0; i < n; i++) {
+    if (!grngo_column_set_time(ctx, column, ids[i], values[i])) {
+      return i;
+    }
+  }
+  return n;
+}
+
+size_t grngo_column_set_geo_points(grn_ctx *ctx, grn_obj *column,
+                                   grn_builtin_type data_type,
+                                   const grn_id *ids,
+                                   const grn_geo_point *values, size_t n) {
+  size_t i;
+  for (i = 0; i < n; i++) {
+    if (!grngo_column_set_geo_point(ctx, column, data_type,
+                                    ids[i], values[i])) {
+      return i;
+    }
+  }
+  return n;
+}
+
+size_t grngo_column_set_texts(grn_ctx *ctx, grn_obj *column,
+                              const grn_id *ids, const grngo_text *values,
+                              size_t n) {
+  size_t i;
+  for (i = 0; i < n; i++) {
+    if (!grngo_column_set_text(ctx, column, ids[i], &values[i])) {
+      return i;
+    }
+  }
+  return n;
+}
+
 grn_bool grngo_column_set_bool_vector(grn_ctx *ctx, grn_obj *column,
                                       grn_id id,
                                       const grngo_vector *value) {
@@ -505,6 +969,38 @@ grn_bool grngo_column_set_float_vector(grn_ctx *ctx, grn_obj *column,
   return rc == GRN_SUCCESS;
 }
 
+grn_bool grngo_column_set_float32_vector(grn_ctx *ctx, grn_obj *column,
+                                         grn_id id,
+                                         const grngo_vector *value) {
+#ifdef GRNGO_HAVE_FLOAT32
+  grn_obj obj;
+  GRN_FLOAT32_INIT(&obj, GRN_OBJ_VECTOR);
+  size_t i;
+  for (i = 0; i < value->size; i++) {
+    GRN_FLOAT32_SET_AT(ctx, &obj, i, ((const float *)value->ptr)[i]);
+  }
+  grn_rc rc = grn_obj_set_value(ctx, column, id, &obj, GRN_OBJ_SET);
+  GRN_OBJ_FIN(ctx, &obj);
+  return rc == GRN_SUCCESS;
+#else
+  return GRN_FALSE;
+#endif
+}
+
+grn_bool grngo_column_set_time_vector(grn_ctx *ctx, grn_obj *column,
+                                      grn_id id,
+                                      const grngo_vector *value) {
+  grn_obj obj;
+  GRN_TIME_INIT(&obj, GRN_OBJ_VECTOR);
+  size_t i;
+  for (i = 0; i < value->size; i++) {
+    GRN_TIME_SET_AT(ctx, &obj, i, ((const int64_t *)value->ptr)[i]);
+  }
+  grn_rc rc = grn_obj_set_value(ctx, column, id, &obj, GRN_OBJ_SET);
+  GRN_OBJ_FIN(ctx, &obj);
+  return rc == GRN_SUCCESS;
+}
+
 grn_bool grngo_column_set_geo_point_vector(grn_ctx *ctx, grn_obj *column,
                                            grn_builtin_type data_type,
                                            grn_id id,
@@ -541,6 +1037,286 @@ grn_bool grngo_column_set_text_vector(grn_ctx *ctx, grn_obj *column,
   return rc == GRN_SUCCESS;
 }
 
+// grngo_column_append_value() appends a fixed-size value to a vector column.
+static grn_bool grngo_column_append_value(grn_ctx *ctx, grn_obj *column,
+                                          grn_id id,
+                                          grn_builtin_type data_type,
+                                          const void *value, size_t size) {
+  grn_obj obj;
+  GRN_OBJ_INIT(&obj, GRN_UVECTOR, 0, data_type);
+  grn_bulk_write(ctx, &obj, (const char *)value, size);
+  grn_rc rc = grn_obj_set_value(ctx, column, id, &obj, GRN_OBJ_APPEND);
+  GRN_OBJ_FIN(ctx, &obj);
+  return rc == GRN_SUCCESS;
+}
+
+grn_bool grngo_column_append_int(grn_ctx *ctx, grn_obj *column, grn_id id,
+                                 grn_builtin_type data_type, int64_t value) {
+  switch (data_type) {
+    case GRN_DB_INT8: {
+      int8_t v = (int8_t)value;
+      return grngo_column_append_value(ctx, column, id, data_type,
+                                       &v, sizeof(v));
+    }
+    case GRN_DB_INT16: {
+      int16_t v = (int16_t)value;
+      return grngo_column_append_value(ctx, column, id, data_type,
+                                       &v, sizeof(v));
+    }
+    case GRN_DB_INT32: {
+      int32_t v = (int32_t)value;
+      return grngo_column_append_value(ctx, column, id, data_type,
+                                       &v, sizeof(v));
+    }
+    case GRN_DB_INT64: {
+      return grngo_column_append_value(ctx, column, id, data_type,
+                                       &value, sizeof(value));
+    }
+    case GRN_DB_UINT8: {
+      uint8_t v = (uint8_t)value;
+      return grngo_column_append_value(ctx, column, id, data_type,
+                                       &v, sizeof(v));
+    }
+    case GRN_DB_UINT16: {
+      uint16_t v = (uint16_t)value;
+      return grngo_column_append_value(ctx, column, id, data_type,
+                                       &v, sizeof(v));
+    }
+    case GRN_DB_UINT32: {
+      uint32_t v = (uint32_t)value;
+      return grngo_column_append_value(ctx, column, id, data_type,
+                                       &v, sizeof(v));
+    }
+    case GRN_DB_UINT64: {
+      uint64_t v = (uint64_t)value;
+      return grngo_column_append_value(ctx, column, id, data_type,
+                                       &v, sizeof(v));
+    }
+    default: {
+      return GRN_FALSE;
+    }
+  }
+}
+
+grn_bool grngo_column_append_float(grn_ctx *ctx, grn_obj *column, grn_id id,
+                                   double value) {
+  return grngo_column_append_value(ctx, column, id, GRN_DB_FLOAT,
+                                   &value, sizeof(value));
+}
+
+grn_bool grngo_column_append_text(grn_ctx *ctx, grn_obj *column, grn_id id,
+                                  const grngo_text *value) {
+  grn_obj obj;
+  GRN_TEXT_INIT(&obj, GRN_OBJ_VECTOR);
+  grn_vector_add_element(ctx, &obj, value->ptr, value->size,
+                         0, obj.header.domain);
+  grn_rc rc = grn_obj_set_value(ctx, column, id, &obj, GRN_OBJ_APPEND);
+  GRN_OBJ_FIN(ctx, &obj);
+  return rc == GRN_SUCCESS;
+}
+
+grn_bool grngo_column_update_int(grn_ctx *ctx, grn_obj *column, grn_id id,
+                                 grn_builtin_type data_type, grn_bool is_vector,
+                                 const int64_t *values, size_t n, int flags) {
+  grn_obj obj;
+  size_t i;
+  GRN_OBJ_INIT(&obj, is_vector ? GRN_UVECTOR : GRN_BULK, 0, data_type);
+  for (i = 0; i < n; i++) {
+    switch (data_type) {
+      case GRN_DB_INT8: {
+        GRN_INT8_SET_AT(ctx, &obj, i, values[i]);
+        break;
+      }
+      case GRN_DB_INT16: {
+        GRN_INT16_SET_AT(ctx, &obj, i, values[i]);
+        break;
+      }
+      case GRN_DB_INT32: {
+        GRN_INT32_SET_AT(ctx, &obj, i, values[i]);
+        break;
+      }
+      case GRN_DB_INT64: {
+        GRN_INT64_SET_AT(ctx, &obj, i, values[i]);
+        break;
+      }
+      case GRN_DB_UINT8: {
+        GRN_UINT8_SET_AT(ctx, &obj, i, values[i]);
+        break;
+      }
+      case GRN_DB_UINT16: {
+        GRN_UINT16_SET_AT(ctx, &obj, i, values[i]);
+        break;
+      }
+      case GRN_DB_UINT32: {
+        GRN_UINT32_SET_AT(ctx, &obj, i, values[i]);
+        break;
+      }
+      case GRN_DB_UINT64: {
+        GRN_UINT64_SET_AT(ctx, &obj, i, values[i]);
+        break;
+      }
+      default: {
+        GRN_OBJ_FIN(ctx, &obj);
+        return GRN_FALSE;
+      }
+    }
+  }
+  grn_rc rc = grn_obj_set_value(ctx, column, id, &obj, flags);
+  GRN_OBJ_FIN(ctx, &obj);
+  return rc == GRN_SUCCESS;
+}
+
+grn_bool grngo_column_update_float(grn_ctx *ctx, grn_obj *column, grn_id id,
+                                   grn_bool is_vector, const double *values,
+                                   size_t n, int flags) {
+  grn_obj obj;
+  size_t i;
+  GRN_FLOAT_INIT(&obj, is_vector ? GRN_OBJ_VECTOR : 0);
+  for (i = 0; i < n; i++) {
+    GRN_FLOAT_SET_AT(ctx, &obj, i, values[i]);
+  }
+  grn_rc rc = grn_obj_set_value(ctx, column, id, &obj, flags);
+  GRN_OBJ_FIN(ctx, &obj);
+  return rc == GRN_SUCCESS;
+}
+
+grn_bool grngo_column_update_text(grn_ctx *ctx, grn_obj *column, grn_id id,
+                                  grn_bool is_vector, const grngo_text *values,
+                                  size_t n, int flags) {
+  grn_obj obj;
+  size_t i;
+  if (is_vector) {
+    GRN_TEXT_INIT(&obj, GRN_OBJ_VECTOR);
+    for (i = 0; i < n; i++) {
+      grn_vector_add_element(ctx, &obj, values[i].ptr, values[i].size,
+                             0, obj.header.domain);
+    }
+  } else {
+    GRN_TEXT_INIT(&obj, 0);
+    if (n != 0) {
+      GRN_TEXT_SET(ctx, &obj, values[0].ptr, values[0].size);
+    }
+  }
+  grn_rc rc = grn_obj_set_value(ctx, column, id, &obj, flags);
+  GRN_OBJ_FIN(ctx, &obj);
+  return rc == GRN_SUCCESS;
+}
+
+grn_bool grngo_column_has_weight(grn_ctx *ctx, grn_obj *column) {
+  return (column->header.flags & GRN_OBJ_WITH_WEIGHT) ? GRN_TRUE : GRN_FALSE;
+}
+
+grn_bool grngo_column_set_weighted_vector(grn_ctx *ctx, grn_obj *column,
+                                          grn_id id,
+                                          grn_builtin_type data_type,
+                                          const void *values,
+                                          size_t value_size,
+                                          const uint32_t *weights, size_t n) {
+  grn_obj obj;
+  GRN_OBJ_INIT(&obj, GRN_VECTOR, 0, data_type);
+  size_t i;
+  for (i = 0; i < n; i++) {
+    const char *value_ptr = (const char *)values + (i * value_size);
+    grn_vector_add_element(ctx, &obj, value_ptr, value_size,
+                           weights[i], data_type);
+  }
+  grn_rc rc = grn_obj_set_value(ctx, column, id, &obj, GRN_OBJ_SET);
+  GRN_OBJ_FIN(ctx, &obj);
+  return rc == GRN_SUCCESS;
+}
+
+grn_bool grngo_column_set_weighted_int_vector(grn_ctx *ctx, grn_obj *column,
+                                              grn_id id,
+                                              grn_builtin_type data_type,
+                                              const int64_t *values,
+                                              const uint32_t *weights,
+                                              size_t n) {
+  grn_obj obj;
+  GRN_OBJ_INIT(&obj, GRN_VECTOR, 0, data_type);
+  size_t i;
+  for (i = 0; i < n; i++) {
+    union {
+      int8_t int8;
+      int16_t int16;
+      int32_t int32;
+      int64_t int64;
+      uint8_t uint8;
+      uint16_t uint16;
+      uint32_t uint32;
+      uint64_t uint64;
+    } value;
+    size_t value_size;
+    switch (data_type) {
+      case GRN_DB_INT8: {
+        value.int8 = (int8_t)values[i];
+        value_size = sizeof(int8_t);
+        break;
+      }
+      case GRN_DB_INT16: {
+        value.int16 = (int16_t)values[i];
+        value_size = sizeof(int16_t);
+        break;
+      }
+      case GRN_DB_INT32: {
+        value.int32 = (int32_t)values[i];
+        value_size = sizeof(int32_t);
+        break;
+      }
+      case GRN_DB_INT64: {
+        value.int64 = values[i];
+        value_size = sizeof(int64_t);
+        break;
+      }
+      case GRN_DB_UINT8: {
+        value.uint8 = (uint8_t)values[i];
+        value_size = sizeof(uint8_t);
+        break;
+      }
+      case GRN_DB_UINT16: {
+        value.uint16 = (uint16_t)values[i];
+        value_size = sizeof(uint16_t);
+        break;
+      }
+      case GRN_DB_UINT32: {
+        value.uint32 = (uint32_t)values[i];
+        value_size = sizeof(uint32_t);
+        break;
+      }
+      case GRN_DB_UINT64: {
+        value.uint64 = (uint64_t)values[i];
+        value_size = sizeof(uint64_t);
+        break;
+      }
+      default: {
+        GRN_OBJ_FIN(ctx, &obj);
+        return GRN_FALSE;
+      }
+    }
+    grn_vector_add_element(ctx, &obj, (const char *)&value, value_size,
+                           weights[i], data_type);
+  }
+  grn_rc rc = grn_obj_set_value(ctx, column, id, &obj, GRN_OBJ_SET);
+  GRN_OBJ_FIN(ctx, &obj);
+  return rc == GRN_SUCCESS;
+}
+
+grn_bool grngo_column_set_weighted_text_vector(grn_ctx *ctx, grn_obj *column,
+                                               grn_id id,
+                                               const grngo_vector *value,
+                                               const uint32_t *weights) {
+  grn_obj obj;
+  GRN_TEXT_INIT(&obj, GRN_OBJ_VECTOR);
+  size_t i;
+  const grngo_text *values = (const grngo_text *)value->ptr;
+  for (i = 0; i < value->size; i++) {
+    grn_vector_add_element(ctx, &obj, values[i].ptr, values[i].size,
+                           weights[i], obj.header.domain);
+  }
+  grn_rc rc = grn_obj_set_value(ctx, column, id, &obj, GRN_OBJ_SET);
+  GRN_OBJ_FIN(ctx, &obj);
+  return rc == GRN_SUCCESS;
+}
+
 grn_bool grngo_column_get_bool(grn_ctx *ctx, grn_obj *column,
                                grn_id id, grn_bool *value) {
   grn_obj value_obj;
@@ -619,6 +1395,30 @@ grn_bool grngo_column_get_float(grn_ctx *ctx, grn_obj *column,
   return GRN_TRUE;
 }
 
+grn_bool grngo_column_get_float32(grn_ctx *ctx, grn_obj *column,
+                                  grn_id id, float *value) {
+#ifdef GRNGO_HAVE_FLOAT32
+  grn_obj value_obj;
+  GRN_FLOAT32_INIT(&value_obj, 0);
+  grn_obj_get_value(ctx, column, id, &value_obj);
+  *value = GRN_FLOAT32_VALUE(&value_obj);
+  GRN_OBJ_FIN(ctx, &value_obj);
+  return GRN_TRUE;
+#else
+  return GRN_FALSE;
+#endif
+}
+
+grn_bool grngo_column_get_time(grn_ctx *ctx, grn_obj *column,
+                               grn_id id, int64_t *value) {
+  grn_obj value_obj;
+  GRN_TIME_INIT(&value_obj, 0);
+  grn_obj_get_value(ctx, column, id, &value_obj);
+  *value = GRN_TIME_VALUE(&value_obj);
+  GRN_OBJ_FIN(ctx, &value_obj);
+  return GRN_TRUE;
+}
+
 grn_bool grngo_column_get_geo_point(grn_ctx *ctx, grn_obj *column,
                                     grn_id id, grn_geo_point *value) {
   grn_obj value_obj;
@@ -629,6 +1429,24 @@ grn_bool grngo_column_get_geo_point(grn_ctx *ctx, grn_obj *column,
   return GRN_TRUE;
 }
 
+grn_obj *grngo_column_open_text(grn_ctx *ctx, grn_obj *column,
+                                grn_id id, grngo_text *value) {
+  grn_obj *buf = (grn_obj *)malloc(sizeof(grn_obj));
+  if (!buf) {
+    return NULL;
+  }
+  GRN_TEXT_INIT(buf, 0);
+  grn_obj_get_value(ctx, column, id, buf);
+  value->ptr = GRN_TEXT_VALUE(buf);
+  value->size = GRN_TEXT_LEN(buf);
+  return buf;
+}
+
+void grngo_text_close(grn_ctx *ctx, grn_obj *buf) {
+  GRN_OBJ_FIN(ctx, buf);
+  free(buf);
+}
+
 grn_bool grngo_column_get_text(grn_ctx *ctx, grn_obj *column,
                                grn_id id, grngo_text *value) {
   grn_obj value_obj;
@@ -643,6 +1461,150 @@ grn_bool grngo_column_get_text(grn_ctx *ctx, grn_obj *column,
   return GRN_TRUE;
 }
 
+grn_bool grngo_column_get_ref_id(grn_ctx *ctx, grn_obj *column,
+                                 grn_id id, grn_id *value) {
+  grn_obj value_obj;
+  GRN_RECORD_INIT(&value_obj, 0, grn_obj_get_range(ctx, column));
+  grn_obj_get_value(ctx, column, id, &value_obj);
+  *value = GRN_RECORD_VALUE(&value_obj);
+  GRN_OBJ_FIN(ctx, &value_obj);
+  return GRN_TRUE;
+}
+
+grn_bool grngo_column_get_ref_ids(grn_ctx *ctx, grn_obj *column,
+                                  grn_id id, grngo_vector *value) {
+  grn_obj value_obj;
+  GRN_RECORD_INIT(&value_obj, GRN_OBJ_VECTOR, grn_obj_get_range(ctx, column));
+  grn_obj_get_value(ctx, column, id, &value_obj);
+  size_t size = GRN_BULK_VSIZE(&value_obj) / sizeof(grn_id);
+  if (size <= value->size) {
+    size_t i;
+    for (i = 0; i < size; i++) {
+      ((grn_id *)value->ptr)[i] = GRN_RECORD_VALUE_AT(&value_obj, i);
+    }
+  }
+  value->size = size;
+  GRN_OBJ_FIN(ctx, &value_obj);
+  return GRN_TRUE;
+}
+
+// grngo_ctx_clear_error() clears the error of the previous operation, so that
+// a stale error does not fail the following batch.
+static void grngo_ctx_clear_error(grn_ctx *ctx) {
+  ctx->rc = GRN_SUCCESS;
+  ctx->errlvl = GRN_LOG_NOTICE;
+  ctx->errbuf[0] = '\0';
+}
+
+// grngo_row_exists() returns whether a row exists or not.
+static grn_bool grngo_row_exists(grn_ctx *ctx, grn_obj *table, grn_id id) {
+  return grn_table_at(ctx, table, id) != GRN_ID_NIL;
+}
+
+grn_bool grngo_column_get_bools(grn_ctx *ctx, grn_obj *table,
+                                grn_obj *column, const grn_id *ids,
+                                grn_bool *values, size_t n) {
+  size_t i;
+  grngo_ctx_clear_error(ctx);
+  for (i = 0; i < n; i++) {
+    values[i] = GRN_FALSE;
+    if (grngo_row_exists(ctx, table, ids[i])) {
+      grngo_column_get_bool(ctx, column, ids[i], &values[i]);
+    }
+  }
+  return ctx->rc == GRN_SUCCESS;
+}
+
+grn_bool grngo_column_get_ints(grn_ctx *ctx, grn_obj *table,
+                               grn_obj *column, grn_builtin_type data_type,
+                               const grn_id *ids, int64_t *values, size_t n) {
+  size_t i;
+  grngo_ctx_clear_error(ctx);
+  for (i = 0; i < n; i++) {
+    values[i] = 0;
+    if (grngo_row_exists(ctx, table, ids[i])) {
+      grngo_column_get_int(ctx, column, data_type, ids[i], &values[i]);
+    }
+  }
+  return ctx->rc == GRN_SUCCESS;
+}
+
+grn_bool grngo_column_get_floats(grn_ctx *ctx, grn_obj *table,
+                                 grn_obj *column, const grn_id *ids,
+                                 double *values, size_t n) {
+  size_t i;
+  grngo_ctx_clear_error(ctx);
+  for (i = 0; i < n; i++) {
+    values[i] = 0.0;
+    if (grngo_row_exists(ctx, table, ids[i])) {
+      grngo_column_get_float(ctx, column, ids[i], &values[i]);
+    }
+  }
+  return ctx->rc == GRN_SUCCESS;
+}
+
+grn_bool grngo_column_get_float32s(grn_ctx *ctx, grn_obj *table,
+                                   grn_obj *column, const grn_id *ids,
+                                   float *values, size_t n) {
+#ifdef GRNGO_HAVE_FLOAT32
+  size_t i;
+  grngo_ctx_clear_error(ctx);
+  for (i = 0; i < n; i++) {
+    values[i] = 0.0f;
+    if (grngo_row_exists(ctx, table, ids[i])) {
+      grngo_column_get_float32(ctx, column, ids[i], &values[i]);
+    }
+  }
+  return ctx->rc == GRN_SUCCESS;
+#else
+  return GRN_FALSE;
+#endif
+}
+
+grn_bool grngo_column_get_times(grn_ctx *ctx, grn_obj *table,
+                                grn_obj *column, const grn_id *ids,
+                                int64_t *values, size_t n) {
+  size_t i;
+  grngo_ctx_clear_error(ctx);
+  for (i = 0; i < n; i++) {
+    values[i] = 0;
+    if (grngo_row_exists(ctx, table, ids[i])) {
+      grngo_column_get_time(ctx, column, ids[i], &values[i]);
+    }
+  }
+  return ctx->rc == GRN_SUCCESS;
+}
+
+grn_bool grngo_column_get_geo_points(grn_ctx *ctx, grn_obj *table,
+                                     grn_obj *column, const grn_id *ids,
+                                     grn_geo_point *values, size_t n) {
+  size_t i;
+  grngo_ctx_clear_error(ctx);
+  for (i = 0; i < n; i++) {
+    values[i].latitude = 0;
+    values[i].longitude = 0;
+    if (grngo_row_exists(ctx, table, ids[i])) {
+      grngo_column_get_geo_point(ctx, column, ids[i], &values[i]);
+    }
+  }
+  return ctx->rc == GRN_SUCCESS;
+}
+
+grn_bool grngo_column_get_texts(grn_ctx *ctx, grn_obj *table,
+                                grn_obj *column, const grn_id *ids,
+                                grngo_text *values, size_t n) {
+  size_t i;
+  grngo_ctx_clear_error(ctx);
+  for (i = 0; i < n; i++) {
+    if (grngo_row_exists(ctx, table, ids[i])) {
+      grngo_column_get_text(ctx, column, ids[i], &values[i]);
+    } else {
+      values[i].size = 0;
+    }
+  }
+  return ctx->rc == GRN_SUCCESS;
+}
+
 grn_bool grngo_column_get_bool_vector(grn_ctx *ctx, grn_obj *column,
                                       grn_id id, grngo_vector *value) {
   grn_obj value_obj;
@@ -795,6 +1757,40 @@ grn_bool grngo_column_get_float_vector(grn_ctx *ctx, grn_obj *column,
   return GRN_TRUE;
 }
 
+grn_bool grngo_column_get_float32_vector(grn_ctx *ctx, grn_obj *column,
+                                         grn_id id, grngo_vector *value) {
+#ifdef GRNGO_HAVE_FLOAT32
+  grn_obj value_obj;
+  GRN_FLOAT32_INIT(&value_obj, GRN_OBJ_VECTOR);
+  grn_obj_get_value(ctx, column, id, &value_obj);
+  size_t size_in_bytes = GRN_BULK_VSIZE(&value_obj);
+  size_t size = size_in_bytes / sizeof(float);
+  if (size <= value->size) {
+    memcpy(value->ptr, GRN_BULK_HEAD(&value_obj), size_in_bytes);
+  }
+  value->size = size;
+  GRN_OBJ_FIN(ctx, &value_obj);
+  return GRN_TRUE;
+#else
+  return GRN_FALSE;
+#endif
+}
+
+grn_bool grngo_column_get_time_vector(grn_ctx *ctx, grn_obj *column,
+                                      grn_id id, grngo_vector *value) {
+  grn_obj value_obj;
+  GRN_TIME_INIT(&value_obj, GRN_OBJ_VECTOR);
+  grn_obj_get_value(ctx, column, id, &value_obj);
+  size_t size_in_bytes = GRN_BULK_VSIZE(&value_obj);
+  size_t size = size_in_bytes / sizeof(int64_t);
+  if (size <= value->size) {
+    memcpy(value->ptr, GRN_BULK_HEAD(&value_obj), size_in_bytes);
+  }
+  value->size = size;
+  GRN_OBJ_FIN(ctx, &value_obj);
+  return GRN_TRUE;
+}
+
 grn_bool grngo_column_get_geo_point_vector(grn_ctx *ctx, grn_obj *column,
                                            grn_id id, grngo_vector *value) {
   grn_obj value_obj;
@@ -812,6 +1808,12 @@ grn_bool grngo_column_get_geo_point_vector(grn_ctx *ctx, grn_obj *column,
 
 grn_bool grngo_column_get_text_vector(grn_ctx *ctx, grn_obj *column,
                                       grn_id id, grngo_vector *value) {
+  return grngo_column_get_weighted_text_vector(ctx, column, id, value, NULL);
+}
+
+grn_bool grngo_column_get_weighted_text_vector(grn_ctx *ctx, grn_obj *column,
+                                               grn_id id, grngo_vector *value,
+                                               uint32_t *weights) {
   grn_obj value_obj;
   GRN_TEXT_INIT(&value_obj, GRN_OBJ_VECTOR);
   grn_obj_get_value(ctx, column, id, &value_obj);
@@ -822,8 +1824,13 @@ grn_bool grngo_column_get_text_vector(grn_ctx *ctx, grn_obj *column,
       // NOTE: grn_vector_get_element() assigns the address of the text body
       //       to text_ptr, but the body may be overwritten in the next call.
       const char *text_ptr;
+      unsigned int weight;
       unsigned int text_size = grn_vector_get_element(ctx, &value_obj, i,
-                                                      &text_ptr, NULL, NULL);
+                                                      &text_ptr, &weight,
+                                                      NULL);
+      if (weights) {
+        weights[i] = weight;
+      }
       grngo_text *text = &((grngo_text *)value->ptr)[i];
       if (text_size <= text->size) {
         memcpy(text->ptr, text_ptr, text_size);
@@ -835,3 +1842,176 @@ grn_bool grngo_column_get_text_vector(grn_ctx *ctx, grn_obj *column,
   GRN_OBJ_FIN(ctx, &value_obj);
   return GRN_TRUE;
 }
+
+grn_obj *grngo_filter_open(grn_ctx *ctx, grn_obj *table,
+                           const char *str, size_t str_size,
+                           const grngo_text *var_names, size_t n_vars) {
+  grn_obj *expr, *record;
+  GRN_EXPR_CREATE_FOR_QUERY(ctx, table, expr, record);
+  if (!expr) {
+    return NULL;
+  }
+  size_t i;
+  for (i = 0; i < n_vars; i++) {
+    if (!grn_expr_add_var(ctx, expr, var_names[i].ptr, var_names[i].size)) {
+      grn_obj_unlink(ctx, expr);
+      return NULL;
+    }
+  }
+  grn_rc rc = grn_expr_parse(ctx, expr, str, str_size, NULL, GRN_OP_MATCH,
+                             GRN_OP_AND, GRN_EXPR_SYNTAX_SCRIPT);
+  if (rc != GRN_SUCCESS) {
+    grn_obj_unlink(ctx, expr);
+    return NULL;
+  }
+  return expr;
+}
+
+grn_bool grngo_filter_set_var(grn_ctx *ctx, grn_obj *expr,
+                              const char *name, size_t name_size,
+                              grn_builtin_type data_type,
+                              const void *value, size_t value_size) {
+  grn_obj *var = grn_expr_get_var(ctx, expr, name, name_size);
+  if (!var) {
+    return GRN_FALSE;
+  }
+  if (grn_obj_reinit(ctx, var, data_type, 0) != GRN_SUCCESS) {
+    return GRN_FALSE;
+  }
+  if (value_size != 0) {
+    grn_bulk_write(ctx, var, (const char *)value, value_size);
+  }
+  return ctx->rc == GRN_SUCCESS;
+}
+
+grn_obj *grngo_filter_select(grn_ctx *ctx, grn_obj *table, grn_obj *expr) {
+  grn_obj *result = grn_table_select(ctx, table, expr, NULL, GRN_OP_OR);
+  if (result && (ctx->rc != GRN_SUCCESS)) {
+    grn_obj_unlink(ctx, result);
+    return NULL;
+  }
+  return result;
+}
+
+grn_bool grngo_result_get_ids(grn_ctx *ctx, grn_obj *result,
+                              const char *sort_keys, size_t sort_keys_size,
+                              int offset, int limit, grn_id *ids, size_t *n) {
+  size_t count = 0;
+  grn_table_cursor *cursor;
+  if (sort_keys_size == 0) {
+    cursor = grn_table_cursor_open(ctx, result, NULL, 0, NULL, 0,
+                                   offset, limit, GRN_CURSOR_ASCENDING);
+    if (!cursor) {
+      return GRN_FALSE;
+    }
+    grn_id id;
+    while ((count < *n) &&
+           ((id = grn_table_cursor_next(ctx, cursor)) != GRN_ID_NIL)) {
+      // The key of a result record is the row ID.
+      grn_table_get_key(ctx, result, id, &ids[count], sizeof(grn_id));
+      count++;
+    }
+    grn_table_cursor_close(ctx, cursor);
+    *n = count;
+    return GRN_TRUE;
+  }
+  unsigned int n_keys;
+  grn_table_sort_key *keys = grn_table_sort_key_from_str(
+    ctx, sort_keys, sort_keys_size, result, &n_keys);
+  if (!keys) {
+    return GRN_FALSE;
+  }
+  grn_obj *sorted = grn_table_create(ctx, NULL, 0, NULL, GRN_OBJ_TABLE_NO_KEY,
+                                     NULL, result);
+  if (!sorted) {
+    grn_table_sort_key_close(ctx, keys, n_keys);
+    return GRN_FALSE;
+  }
+  grn_table_sort(ctx, result, offset, limit, sorted, keys, n_keys);
+  grn_table_sort_key_close(ctx, keys, n_keys);
+  cursor = grn_table_cursor_open(ctx, sorted, NULL, 0, NULL, 0, 0, -1,
+                                 GRN_CURSOR_BY_ID);
+  if (!cursor) {
+    grn_obj_unlink(ctx, sorted);
+    return GRN_FALSE;
+  }
+  while ((count < *n) && (grn_table_cursor_next(ctx, cursor) != GRN_ID_NIL)) {
+    // The value of a sorted record is the ID of a result record.
+    void *value;
+    grn_table_cursor_get_value(ctx, cursor, &value);
+    grn_table_get_key(ctx, result, *(grn_id *)value, &ids[count],
+                      sizeof(grn_id));
+    count++;
+  }
+  grn_table_cursor_close(ctx, cursor);
+  grn_obj_unlink(ctx, sorted);
+  *n = count;
+  return GRN_TRUE;
+}
+
+grn_rc grngo_table_apply(grn_ctx *ctx, grn_obj *table, grn_obj *column,
+                         const char *str, size_t str_size, size_t *n_rows) {
+  *n_rows = 0;
+  grn_obj *expr, *record;
+  GRN_EXPR_CREATE_FOR_QUERY(ctx, table, expr, record);
+  if (!expr) {
+    return ctx->rc != GRN_SUCCESS ? ctx->rc : GRN_NO_MEMORY_AVAILABLE;
+  }
+  grn_rc rc = grn_expr_parse(ctx, expr, str, str_size, NULL, GRN_OP_MATCH,
+                             GRN_OP_AND, GRN_EXPR_SYNTAX_SCRIPT);
+  if (rc != GRN_SUCCESS) {
+    grn_obj_unlink(ctx, expr);
+    return rc;
+  }
+  grn_table_cursor *cursor = grn_table_cursor_open(ctx, table, NULL, 0, NULL,
+                                                   0, 0, -1, GRN_CURSOR_BY_ID);
+  if (!cursor) {
+    grn_obj_unlink(ctx, expr);
+    return ctx->rc != GRN_SUCCESS ? ctx->rc : GRN_UNKNOWN_ERROR;
+  }
+  grn_id id;
+  while ((id = grn_table_cursor_next(ctx, cursor)) != GRN_ID_NIL) {
+    GRN_RECORD_SET(ctx, record, id);
+    grn_obj *result = grn_expr_exec(ctx, expr, 0);
+    if (ctx->rc != GRN_SUCCESS) {
+      rc = ctx->rc;
+      break;
+    }
+    if (!result) {
+      rc = GRN_INVALID_ARGUMENT;
+      break;
+    }
+    // grn_obj_set_value() casts the result to the value type of column.
+    rc = grn_obj_set_value(ctx, column, id, result, GRN_OBJ_SET);
+    if (rc != GRN_SUCCESS) {
+      break;
+    }
+    (*n_rows)++;
+  }
+  grn_table_cursor_close(ctx, cursor);
+  grn_obj_unlink(ctx, expr);
+  return rc;
+}
+
+// grngo_logger_log() passes a message to the handler in Go.
+static void grngo_logger_log(grn_ctx *ctx, grn_log_level level,
+                             const char *timestamp, const char *title,
+                             const char *message, const char *location,
+                             void *user_data) {
+  grngoLog((int)level, (char *)message);
+}
+
+void grngo_logger_set(grn_bool use_handler, grn_log_level max_level) {
+  if (use_handler) {
+    // grn_logger_set() copies the logger.
+    grn_logger logger;
+    memset(&logger, 0, sizeof(logger));
+    logger.max_level = max_level;
+    logger.flags = GRN_LOG_TIME | GRN_LOG_MESSAGE;
+    logger.log = grngo_logger_log;
+    grn_logger_set(&grn_gctx, &logger);
+  } else {
+    grn_logger_set(&grn_gctx, NULL);
+  }
+  grn_logger_set_max_level(&grn_gctx, max_level);
+}
@@ -7,9 +7,20 @@ package grngo
 import "C"
 
 import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"math"
 	"reflect"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
+	"time"
 	"unsafe"
 )
 
@@ -21,14 +32,214 @@ import (
 // - Bool: bool
 // - (U)Int8/16/32/64: int64
 // - Float: float64
-// - Time: TODO
+// - Float32: float32
+// - Time: time.Time
 // - WGS84/TokyoGeoPoint: GeoPoint
 // - (Short/Long)Text: []byte
 
 type GeoPoint struct{ Latitude, Longitude int32 }
 
+// NewGeoPointFromDegrees() creates a GeoPoint from latitude and longitude in
+// decimal degrees. The values are rounded to the nearest milliseconds of arc.
+// An error is returned if lat is out of [-90, 90] or lng is out of
+// [-180, 180].
+func NewGeoPointFromDegrees(lat, lng float64) (GeoPoint, error) {
+	if !(lat >= -90 && lat <= 90) || !(lng >= -180 && lng <= 180) {
+		return GeoPoint{}, fmt.Errorf("out of range: lat = %v, lng = %v",
+			lat, lng)
+	}
+	return GeoPoint{int32(math.Round(lat * 60 * 60 * 1000)),
+		int32(math.Round(lng * 60 * 60 * 1000))}, nil
+}
+
+// Degrees() returns latitude and longitude in decimal degrees.
+func (point GeoPoint) Degrees() (lat, lng float64) {
+	lat = float64(point.Latitude) / (60 * 60 * 1000)
+	lng = float64(point.Longitude) / (60 * 60 * 1000)
+	return lat, lng
+}
+
+// ParseGeoPoint() parses a GeoPoint in Groonga's text form.
+// Latitude and longitude are separated by 'x' or ',', and each of them is
+// given in milliseconds of arc, such as "128452975x503157902", or in decimal
+// degrees, such as "35.68,139.76".
+// Values that contain a '.' are interpreted as decimal degrees.
+func ParseGeoPoint(s string) (GeoPoint, error) {
+	pos := strings.IndexAny(s, "x,")
+	if pos == -1 {
+		return GeoPoint{}, fmt.Errorf("invalid GeoPoint: value = %s", s)
+	}
+	latStr := strings.TrimSpace(s[:pos])
+	lngStr := strings.TrimSpace(s[pos+1:])
+	if strings.Contains(latStr, ".") || strings.Contains(lngStr, ".") {
+		lat, err := strconv.ParseFloat(latStr, 64)
+		if err != nil {
+			return GeoPoint{}, err
+		}
+		lng, err := strconv.ParseFloat(lngStr, 64)
+		if err != nil {
+			return GeoPoint{}, err
+		}
+		return NewGeoPointFromDegrees(lat, lng)
+	}
+	latitude, err := strconv.ParseInt(latStr, 10, 32)
+	if err != nil {
+		return GeoPoint{}, err
+	}
+	longitude, err := strconv.ParseInt(lngStr, 10, 32)
+	if err != nil {
+		return GeoPoint{}, err
+	}
+	return GeoPoint{int32(latitude), int32(longitude)}, nil
+}
+
+// String() returns the canonical text form of Groonga, that is
+// "<latitude>x<longitude>" in milliseconds of arc.
+func (point GeoPoint) String() string {
+	return fmt.Sprintf("%dx%d", point.Latitude, point.Longitude)
+}
+
+// geoRadius is the radius of the earth in meters used by Groonga.
+const geoRadius = 6357303.0
+
+// msecToRadian() converts an angle in milliseconds of arc into radians.
+func msecToRadian(value int32) float64 {
+	return float64(value) / (60 * 60 * 1000) * math.Pi / 180
+}
+
+// DistanceTo() returns the distance to other in meters.
+// The distance is computed in the same way as geo_distance of Groonga with
+// the default "rectangle" approximation, so both points must be in the same
+// datum.
+func (point GeoPoint) DistanceTo(other GeoPoint) float64 {
+	lat1, lng1 := msecToRadian(point.Latitude), msecToRadian(point.Longitude)
+	lat2, lng2 := msecToRadian(other.Latitude), msecToRadian(other.Longitude)
+	x := (lng2 - lng1) * math.Cos((lat1+lat2)/2)
+	y := lat2 - lat1
+	return math.Sqrt(x*x+y*y) * geoRadius
+}
+
+// timeToGrnTime() converts a time.Time into a Groonga Time value, that is the
+// number of microseconds elapsed since the Unix epoch.
+// Sub-microsecond precision is truncated and the zero time.Time is converted
+// into 0.
+func timeToGrnTime(value time.Time) int64 {
+	if value.IsZero() {
+		return 0
+	}
+	return (value.Unix() * 1000000) + int64(value.Nanosecond()/1000)
+}
+
+// grnTimeToTime() converts a Groonga Time value into a time.Time.
+func grnTimeToTime(value int64) time.Time {
+	return time.Unix(value/1000000, (value%1000000)*1000)
+}
+
+// formatGrnTime() formats a Groonga Time value as seconds since the Unix
+// epoch with exactly 6 decimal places, such as "1234567890.500000".
+// Unlike a float64 division, the formatting never alters the microseconds.
+func formatGrnTime(value int64) string {
+	sign, abs := "", uint64(value)
+	if value < 0 {
+		sign, abs = "-", uint64(-value)
+	}
+	return fmt.Sprintf("%s%d.%06d", sign, abs/1000000, abs%1000000)
+}
+
 const NilID = uint32(C.GRN_ID_NIL)
 
+// ErrRowNotFound is returned if the specified row does not exist.
+var ErrRowNotFound = errors.New("row not found")
+
+// ErrReadOnly is returned if a write is requested to a database opened by
+// OpenDBReadOnly().
+var ErrReadOnly = errors.New("read-only database")
+
+// ErrLockTimeout is returned if a lock is not acquired within the timeout.
+var ErrLockTimeout = errors.New("lock timeout")
+
+// ErrAlreadyExists is returned if an object to be created already exists.
+// If Groonga rejects the creation, the error also wraps a GroongaError.
+var ErrAlreadyExists = errors.New("already exists")
+
+// GroongaError is returned if a Groonga function or command fails.
+// ErrFile, ErrLine, and ErrFunc are the location in Groonga where the error
+// is raised, and they are empty or zero if unavailable.
+type GroongaError struct {
+	Func    string // The failed function or command, such as grn_ctx_send()
+	Message string // The error message of Groonga
+	ErrFile string // The source file, such as "expr.c"
+	ErrLine int    // The line number in ErrFile
+	ErrFunc string // The function, such as "grn_expr_parse"
+	code    C.grn_rc
+}
+
+// newGroongaError() creates a GroongaError with the error message and the
+// error location of ctx.
+func newGroongaError(ctx *C.grn_ctx, funcName string, rc C.grn_rc) error {
+	err := &GroongaError{
+		Func:    funcName,
+		Message: C.GoString(&ctx.errbuf[0]),
+		code:    rc,
+	}
+	if ctx.errfile != nil {
+		err.ErrFile = C.GoString(ctx.errfile)
+		err.ErrLine = int(ctx.errline)
+	}
+	if ctx.errfunc != nil {
+		err.ErrFunc = C.GoString(ctx.errfunc)
+	}
+	return err
+}
+
+func (err *GroongaError) Error() string {
+	if err.ErrFile == "" {
+		return fmt.Sprintf("%s failed: rc = %d, err = %s", err.Func, err.code,
+			err.Message)
+	}
+	return fmt.Sprintf("%s failed: rc = %d, err = %s, location = %s:%d %s()",
+		err.Func, err.code, err.Message, err.ErrFile, err.ErrLine, err.ErrFunc)
+}
+
+// Code() returns the return code (grn_rc).
+func (err *GroongaError) Code() int {
+	return int(err.code)
+}
+
+// hasErrorCode() returns whether err is a GroongaError with one of codes.
+func hasErrorCode(err error, codes ...C.grn_rc) bool {
+	var groongaError *GroongaError
+	if !errors.As(err, &groongaError) {
+		return false
+	}
+	for _, code := range codes {
+		if groongaError.code == code {
+			return true
+		}
+	}
+	return false
+}
+
+// IsAlreadyExists() returns whether err reports that an object to be created
+// already exists.
+func IsAlreadyExists(err error) bool {
+	return errors.Is(err, ErrAlreadyExists) ||
+		hasErrorCode(err, C.GRN_FILE_EXISTS)
+}
+
+// IsNotFound() returns whether err reports that the specified object or row
+// does not exist.
+func IsNotFound(err error) bool {
+	return errors.Is(err, ErrRowNotFound) ||
+		hasErrorCode(err, C.GRN_NO_SUCH_FILE_OR_DIRECTORY, C.GRN_END_OF_DATA)
+}
+
+// IsInvalidArgument() returns whether err reports an invalid argument, such
+// as a malformed command.
+func IsInvalidArgument(err error) bool {
+	return hasErrorCode(err, C.GRN_INVALID_ARGUMENT, C.GRN_SYNTAX_ERROR)
+}
+
 type DataType int
 
 const (
@@ -49,6 +260,9 @@ const (
 	LongText      = DataType(C.GRN_DB_LONG_TEXT)
 	TokyoGeoPoint = DataType(C.GRN_DB_TOKYO_GEO_POINT)
 	WGS84GeoPoint = DataType(C.GRN_DB_WGS84_GEO_POINT)
+	// Float32 is available if Groonga supports it (10.0.2 or later).
+	// Otherwise, it is an invalid data type.
+	Float32 = DataType(C.GRNGO_DB_FLOAT32)
 )
 
 func (dataType DataType) String() string {
@@ -87,11 +301,131 @@ func (dataType DataType) String() string {
 		return "TokyoGeoPoint"
 	case WGS84GeoPoint:
 		return "WGS84GeoPoint"
+	case Float32:
+		return "Float32"
 	default:
 		return fmt.Sprintf("DataType(%d)", dataType)
 	}
 }
 
+// dataTypes is the list of valid DataTypes.
+var dataTypes = []DataType{
+	Void, Bool, Int8, Int16, Int32, Int64, UInt8, UInt16, UInt32, UInt64,
+	Float, Float32, Time, ShortText, Text, LongText, TokyoGeoPoint,
+	WGS84GeoPoint,
+}
+
+// dataTypesByName maps Groonga type names to DataTypes.
+var dataTypesByName = func() map[string]DataType {
+	dataTypesByName := make(map[string]DataType)
+	for _, dataType := range dataTypes {
+		if dataType >= 0 { // Float32 is negative if not supported.
+			dataTypesByName[dataType.String()] = dataType
+		}
+	}
+	return dataTypesByName
+}()
+
+// GroongaName() returns the Groonga type name, such as "Int32".
+// An empty string is returned if the DataType is invalid.
+func (dataType DataType) GroongaName() string {
+	if _, ok := dataTypesByName[dataType.String()]; !ok {
+		return ""
+	}
+	return dataType.String()
+}
+
+// ParseDataType() returns the DataType associated with a Groonga type name.
+// The second return value specifies whether the name is valid or not.
+// "Void" is not valid because no key, value or column can have that type.
+func ParseDataType(name string) (DataType, bool) {
+	dataType, ok := dataTypesByName[name]
+	if !ok || (dataType == Void) {
+		return Void, false
+	}
+	return dataType, true
+}
+
+// Constants for Table.Flags() and Column.Flags(), that are the header flags
+// of grn_obj. A type, such as TableHashKeyFlag, is a value in a mask, such as
+// TableTypeMaskFlag, so test it like (flags & TableTypeMaskFlag) ==
+// TableHashKeyFlag. The other flags, such as KeyWithSISFlag, are single bits.
+const (
+	TableTypeMaskFlag  = uint32(C.GRN_OBJ_TABLE_TYPE_MASK)
+	TableHashKeyFlag   = uint32(C.GRN_OBJ_TABLE_HASH_KEY)
+	TablePatKeyFlag    = uint32(C.GRN_OBJ_TABLE_PAT_KEY)
+	TableDatKeyFlag    = uint32(C.GRN_OBJ_TABLE_DAT_KEY)
+	TableNoKeyFlag     = uint32(C.GRN_OBJ_TABLE_NO_KEY)
+	KeyWithSISFlag     = uint32(C.GRN_OBJ_KEY_WITH_SIS)
+	KeyNormalizeFlag   = uint32(C.GRN_OBJ_KEY_NORMALIZE)
+	KeyVarSizeFlag     = uint32(C.GRN_OBJ_KEY_VAR_SIZE)
+	ColumnTypeMaskFlag = uint32(C.GRN_OBJ_COLUMN_TYPE_MASK)
+	ColumnScalarFlag   = uint32(C.GRN_OBJ_COLUMN_SCALAR)
+	ColumnVectorFlag   = uint32(C.GRN_OBJ_COLUMN_VECTOR)
+	ColumnIndexFlag    = uint32(C.GRN_OBJ_COLUMN_INDEX)
+	CompressMaskFlag   = uint32(C.GRN_OBJ_COMPRESS_MASK)
+	CompressNoneFlag   = uint32(C.GRN_OBJ_COMPRESS_NONE)
+	CompressZlibFlag   = uint32(C.GRN_OBJ_COMPRESS_ZLIB)
+	CompressLZ4Flag    = uint32(C.GRN_OBJ_COMPRESS_LZ4)
+	WithSectionFlag    = uint32(C.GRN_OBJ_WITH_SECTION)
+	WithWeightFlag     = uint32(C.GRN_OBJ_WITH_WEIGHT)
+	WithPositionFlag   = uint32(C.GRN_OBJ_WITH_POSITION)
+	PersistentFlag     = uint32(C.GRN_OBJ_PERSISTENT)
+)
+
+// -- DBOptions --
+
+// Constants for DBOptions.
+type Encoding int
+
+const (
+	DefaultEncoding = Encoding(iota) // GRN_ENC_DEFAULT
+	NoEncoding                       // GRN_ENC_NONE
+	EUCJPEncoding                    // GRN_ENC_EUC_JP
+	UTF8Encoding                     // GRN_ENC_UTF8
+	SJISEncoding                     // GRN_ENC_SJIS
+	Latin1Encoding                   // GRN_ENC_LATIN1
+	KOI8REncoding                    // GRN_ENC_KOI8R
+)
+
+// String() returns the name of the encoding, such as "utf8".
+func (encoding Encoding) String() string {
+	switch encoding {
+	case DefaultEncoding:
+		return "default"
+	case NoEncoding:
+		return "none"
+	case EUCJPEncoding:
+		return "euc_jp"
+	case UTF8Encoding:
+		return "utf8"
+	case SJISEncoding:
+		return "sjis"
+	case Latin1Encoding:
+		return "latin1"
+	case KOI8REncoding:
+		return "koi8r"
+	default:
+		return fmt.Sprintf("Encoding(%d)", int(encoding))
+	}
+}
+
+// DBOptions specifies options of CreateDBWithOptions() and
+// OpenDBWithOptions().
+type DBOptions struct {
+	// Encoding is the encoding of the context. Keys and values are passed
+	// through as raw bytes and the encoding only affects how Groonga
+	// interprets them, such as normalization and tokenization.
+	// DefaultEncoding means the default encoding of Groonga.
+	Encoding
+}
+
+// NewDBOptions() creates a new DBOptions object with the default settings.
+func NewDBOptions() *DBOptions {
+	var options DBOptions
+	return &options
+}
+
 // -- TableOptions --
 
 // Constants for TableOptions.
@@ -113,6 +447,16 @@ type TableOptions struct {
 	DefaultTokenizer string   // http://groonga.org/docs/reference/tokenizers.html
 	Normalizer       string   // http://groonga.org/docs/reference/normalizers.html
 	TokenFilters     []string // http://groonga.org/docs/reference/token_filters.html
+
+	// Temporary creates the table in memory without GRN_OBJ_PERSISTENT, so
+	// that it does not leave files even in a persistent database.
+	// Groonga does not support named temporary tables, so the table is
+	// anonymous in Groonga and only the DB handle knows it by name. Thus,
+	// FindTable() finds the table, but commands, such as select and load, and
+	// TableNames() do not. KeyType and ValueType must be built-in types,
+	// TokenFilters must be empty and CreateColumn() is not supported.
+	// The table is gone after Release(), RemoveTable() or DB.Close().
+	Temporary bool
 }
 
 // NewTableOptions() creates a new TableOptions object with the default
@@ -122,6 +466,14 @@ func NewTableOptions() *TableOptions {
 	return &options
 }
 
+// TableSchema is a definition of a table and its columns for
+// DB.CreateTableFromSchema().
+type TableSchema struct {
+	Name string
+	TableOptions
+	Columns []ColumnDefinition
+}
+
 // -- ColumnOptions --
 
 // Constants for ColumnOptions.
@@ -140,6 +492,10 @@ const (
 	NoCompression = CompressionType(iota)
 	ZlibCompression
 	LzoCompression
+	// UnknownCompression is returned by Column.Compression() for a compressor
+	// which is not supported by grngo, such as Zstandard.
+	// It is not available for ColumnOptions.
+	UnknownCompression
 )
 
 // http://groonga.org/ja/docs/reference/commands/column_create.html
@@ -159,6 +515,178 @@ func NewColumnOptions() *ColumnOptions {
 	return &options
 }
 
+// ColumnDefinition is a definition of a column for DB.CreateTableFromSchema().
+// Type is a value type or a table name, and nil Options means the default
+// settings.
+type ColumnDefinition struct {
+	Name    string
+	Type    string
+	Options *ColumnOptions
+}
+
+// -- SelectOptions --
+
+// SortKey is a sort key of select.
+type SortKey struct {
+	Column     string // A column name, such as "_score" and "Time"
+	Descending bool
+}
+
+// Constants for SelectOptions.
+type CacheMode int
+
+const (
+	DefaultCache = CacheMode(iota) // --cache is not given
+	ReadCache                      // --cache yes
+	NoCache                        // --cache no, the result is always fresh
+)
+
+// CalcType is an aggregate function of a drilldown.
+type CalcType int
+
+const (
+	SumCalc = CalcType(iota) // _sum
+	AvgCalc                  // _avg
+	MaxCalc                  // _max
+	MinCalc                  // _min
+)
+
+// String() returns the name of the calc type, such as "SUM".
+func (calcType CalcType) String() string {
+	switch calcType {
+	case SumCalc:
+		return "SUM"
+	case AvgCalc:
+		return "AVG"
+	case MaxCalc:
+		return "MAX"
+	case MinCalc:
+		return "MIN"
+	default:
+		return fmt.Sprintf("CalcType(%d)", int(calcType))
+	}
+}
+
+// columnName() returns the output column name of the calc type, such as
+// "_sum".
+func (calcType CalcType) columnName() string {
+	return "_" + strings.ToLower(calcType.String())
+}
+
+// DrilldownSpec specifies a labeled drilldown with aggregates.
+// http://groonga.org/docs/reference/commands/select.html#advanced-drilldown-related-parameters
+type DrilldownSpec struct {
+	Label      string     // The label of [A-Za-z0-9_], Key is used if empty
+	Key        string     // --drilldown[label].keys
+	CalcTarget string     // --drilldown[label].calc_target
+	CalcTypes  []CalcType // --drilldown[label].calc_types
+}
+
+// label() returns the label of the drilldown.
+func (spec *DrilldownSpec) label() string {
+	if spec.Label != "" {
+		return spec.Label
+	}
+	return spec.Key
+}
+
+// http://groonga.org/docs/reference/commands/select.html
+type SelectOptions struct {
+	Filter         string          // --filter
+	Query          string          // --query
+	MatchColumns   string          // --match_columns
+	SortBy         string          // --sortby
+	SortKeys       []SortKey       // --sortby, exclusive with SortBy
+	Offset         int             // --offset
+	Limit          int             // --limit, a negative value means all
+	OutputColumns  []string        // --output_columns
+	Drilldowns     []string        // --drilldown
+	DrilldownSpecs []DrilldownSpec // --drilldown[label]
+	CacheMode                      // --cache
+}
+
+// NewSelectOptions() creates a new SelectOptions object with the default
+// settings.
+func NewSelectOptions() *SelectOptions {
+	var options SelectOptions
+	options.Limit = 10
+	return &options
+}
+
+// -- LoadOptions --
+
+// http://groonga.org/docs/reference/commands/load.html
+type LoadOptions struct {
+	IfExists  string // --ifexists
+	ChunkSize int    // The maximum size of values per load command in bytes
+}
+
+// NewLoadOptions() creates a new LoadOptions object with the default
+// settings.
+func NewLoadOptions() *LoadOptions {
+	var options LoadOptions
+	options.ChunkSize = 1 << 20
+	return &options
+}
+
+// -- DumpOptions --
+
+// http://groonga.org/docs/reference/commands/dump.html
+type DumpOptions struct {
+	Tables     []string // --tables, empty means all tables
+	SchemaOnly bool     // --dump_records no
+}
+
+// NewDumpOptions() creates a new DumpOptions object with the default
+// settings.
+func NewDumpOptions() *DumpOptions {
+	var options DumpOptions
+	return &options
+}
+
+// -- CursorOptions --
+
+// Constants for CursorOptions.
+type CursorOrder int
+
+const (
+	Ascending = CursorOrder(iota)
+	Descending
+)
+
+// http://groonga.org/docs/reference/api/grn_table_cursor.html
+type CursorOptions struct {
+	CursorOrder
+	Offset       int         // The number of rows to skip
+	Limit        int         // The maximum number of rows, a negative value means all
+	MinKey       interface{} // The lower bound of keys, nil means no bound
+	MaxKey       interface{} // The upper bound of keys, nil means no bound
+	MinExclusive bool        // GRN_CURSOR_GT
+	MaxExclusive bool        // GRN_CURSOR_LT
+}
+
+// NewCursorOptions() creates a new CursorOptions object with the default
+// settings.
+func NewCursorOptions() *CursorOptions {
+	var options CursorOptions
+	options.Limit = -1
+	return &options
+}
+
+// -- DefragOptions --
+
+// http://groonga.org/docs/reference/commands/defrag.html
+type DefragOptions struct {
+	Threshold int // Passed to grn_obj_defrag() as is
+}
+
+// NewDefragOptions() creates a new DefragOptions object with the default
+// settings.
+func NewDefragOptions() *DefragOptions {
+	var options DefragOptions
+	return &options
+}
+
 // -- Groonga --
 
 // initCount is a counter for automatically initializing and finalizing
@@ -182,6 +710,9 @@ func Init() error {
 		if rc := C.grn_init(); rc != C.GRN_SUCCESS {
 			return fmt.Errorf("grn_init() failed: rc = %d", rc)
 		}
+		logMutex.Lock()
+		applyLogger()
+		logMutex.Unlock()
 	}
 	initCount++
 	return nil
@@ -205,6 +736,132 @@ func Fin() error {
 	return nil
 }
 
+// -- Logging --
+
+// Constants for SetLogLevel().
+type LogLevel int
+
+const (
+	NoneLog    = LogLevel(C.GRN_LOG_NONE)
+	EmergLog   = LogLevel(C.GRN_LOG_EMERG)
+	AlertLog   = LogLevel(C.GRN_LOG_ALERT)
+	CritLog    = LogLevel(C.GRN_LOG_CRIT)
+	ErrorLog   = LogLevel(C.GRN_LOG_ERROR)
+	WarningLog = LogLevel(C.GRN_LOG_WARNING)
+	NoticeLog  = LogLevel(C.GRN_LOG_NOTICE)
+	InfoLog    = LogLevel(C.GRN_LOG_INFO)
+	DebugLog   = LogLevel(C.GRN_LOG_DEBUG)
+	DumpLog    = LogLevel(C.GRN_LOG_DUMP)
+)
+
+func (level LogLevel) String() string {
+	switch level {
+	case NoneLog:
+		return "none"
+	case EmergLog:
+		return "emergency"
+	case AlertLog:
+		return "alert"
+	case CritLog:
+		return "critical"
+	case ErrorLog:
+		return "error"
+	case WarningLog:
+		return "warning"
+	case NoticeLog:
+		return "notice"
+	case InfoLog:
+		return "info"
+	case DebugLog:
+		return "debug"
+	case DumpLog:
+		return "dump"
+	default:
+		return fmt.Sprintf("LogLevel(%d)", level)
+	}
+}
+
+var (
+	// logMutex serializes the changes of the logger settings.
+	logMutex     sync.Mutex
+	logLevel     = NoticeLog
+	logPath      *C.char // Kept alive because Groonga may refer to it.
+	queryLogPath *C.char // Kept alive like logPath.
+	hasLogger    bool
+	// logHandlerMutex protects logHandler, which is read by grngoLog().
+	// It is separated from logMutex because Groonga may write logs while
+	// the settings are changed.
+	logHandlerMutex sync.RWMutex
+	logHandler      func(level LogLevel, message string)
+)
+
+// applyLogger() applies the logger settings to Groonga.
+// It is also called after grn_init() because it may reset the settings.
+// The caller must hold logMutex.
+func applyLogger() {
+	useHandler := C.grn_bool(C.GRN_FALSE)
+	if hasLogger {
+		useHandler = C.GRN_TRUE
+	}
+	C.grngo_logger_set(useHandler, C.grn_log_level(logLevel))
+}
+
+//export grngoLog
+func grngoLog(level C.int, message *C.char) {
+	logHandlerMutex.RLock()
+	handler := logHandler
+	logHandlerMutex.RUnlock()
+	if handler != nil {
+		handler(LogLevel(level), C.GoString(message))
+	}
+}
+
+// SetLogLevel() sets the maximum level of messages to be logged.
+// The default level is NoticeLog.
+func SetLogLevel(level LogLevel) error {
+	if (level < NoneLog) || (level > DumpLog) {
+		return fmt.Errorf("undefined log level: level = %d", level)
+	}
+	logMutex.Lock()
+	defer logMutex.Unlock()
+	logLevel = level
+	C.grn_logger_set_max_level(&C.grn_gctx, C.grn_log_level(level))
+	return nil
+}
+
+// SetLogPath() sets the path of the log file of the default logger, which is
+// used unless SetLogHandler() sets a handler.
+// An empty path disables the log file.
+func SetLogPath(path string) {
+	logMutex.Lock()
+	defer logMutex.Unlock()
+	var cPath *C.char
+	if path != "" {
+		cPath = C.CString(path)
+	}
+	C.grn_default_logger_set_path(cPath)
+	if logPath != nil {
+		C.free(unsafe.Pointer(logPath))
+	}
+	logPath = cPath
+	C.grn_logger_reopen(&C.grn_gctx)
+}
+
+// SetLogHandler() sets a handler which receives Groonga's log messages
+// instead of the log file. nil restores the default logger.
+// The handler is called synchronously by the goroutine which calls Groonga,
+// possibly while grngo holds the lock of a DB, so it must return quickly
+// and must not call grngo.
+func SetLogHandler(handler func(level LogLevel, message string)) {
+	logMutex.Lock()
+	defer logMutex.Unlock()
+	logHandlerMutex.Lock()
+	logHandler = handler
+	logHandlerMutex.Unlock()
+	hasLogger = handler != nil
+	applyLogger()
+}
+
 // openCtx() allocates memory for grn_ctx and initializes it.
 func openCtx() (*C.grn_ctx, error) {
 	if err := Init(); err != nil {
@@ -230,24 +887,205 @@ func closeCtx(ctx *C.grn_ctx) error {
 
 // -- DB --
 
+// Constants for DB.SetOutputType().
+type OutputType int
+
+const (
+	JSONOutput = OutputType(iota)
+	TSVOutput
+	MsgPackOutput
+)
+
+func (outputType OutputType) String() string {
+	switch outputType {
+	case JSONOutput:
+		return "json"
+	case TSVOutput:
+		return "tsv"
+	case MsgPackOutput:
+		return "msgpack"
+	default:
+		return fmt.Sprintf("OutputType(%d)", outputType)
+	}
+}
+
+// DB is a handle to a Groonga database.
+// A DB is safe for concurrent use by multiple goroutines, but operations are
+// serialized by an internal mutex because a grn_ctx is not thread-safe.
+// Applications which need parallel throughput should use a pool of DBs, each
+// of which is opened by OpenDB() or ConnectGQTP().
 type DB struct {
-	ctx    *C.grn_ctx
-	obj    *C.grn_obj
-	tables map[string]*Table
+	ctx        *C.grn_ctx
+	obj        *C.grn_obj
+	tables     map[string]*Table
+	mutex      sync.Mutex // Serializes operations on ctx and the caches.
+	remote     bool       // Connected to a server by ConnectGQTP().
+	attached   bool       // Attached to obj opened by another DB.
+	readOnly   bool       // Opened by OpenDBReadOnly().
+	outputType OutputType // The default output type of SendEx().
+	cmdVersion int        // Pinned by SetCommandVersion(), or 0.
+}
+
+// readCommands is the set of commands which never modify a database.
+// A read-only handle rejects the other commands.
+var readCommands = map[string]bool{
+	"cache_limit":          true,
+	"column_list":          true,
+	"dump":                 true,
+	"logical_count":        true,
+	"logical_range_filter": true,
+	"logical_select":       true,
+	"logical_shard_list":   true,
+	"normalize":            true,
+	"normalizer_list":      true,
+	"object_exist":         true,
+	"object_inspect":       true,
+	"object_list":          true,
+	"query_expand":         true,
+	"range_filter":         true,
+	"schema":               true,
+	"select":               true,
+	"status":               true,
+	"table_list":           true,
+	"table_tokenize":       true,
+	"tokenize":             true,
+	"tokenizer_list":       true,
+}
+
+// writeOptions is the set of options which make a read command modify a
+// database, such as select --load_table.
+var writeOptions = map[string]bool{
+	"load_table": true,
+}
+
+// commandName() returns the name of a command, such as "select" for
+// "select Table" and "/d/select?table=Table".
+func commandName(command string) string {
+	command = strings.TrimLeft(command, " \t\r\n")
+	if strings.HasPrefix(command, "/d/") {
+		command = command[len("/d/"):]
+		if pos := strings.IndexAny(command, "?."); pos != -1 {
+			command = command[:pos]
+		}
+		return command
+	}
+	if pos := strings.IndexAny(command, " \t\r\n"); pos != -1 {
+		command = command[:pos]
+	}
+	return command
+}
+
+// checkWritable() returns ErrReadOnly if the database is read-only.
+func (db *DB) checkWritable() error {
+	if db.readOnly {
+		return ErrReadOnly
+	}
+	return nil
+}
+
+// commandOptionNames() returns the names of the options in command, such as
+// "table" for "select --table Table" and "/d/select?table=Table".
+// Positional arguments are ignored. A quoted value which looks like an option
+// is also returned, that is harmless for checkCommand().
+func commandOptionNames(command string) []string {
+	command = strings.TrimLeft(command, " \t\r\n")
+	var names []string
+	if strings.HasPrefix(command, "/d/") {
+		pos := strings.IndexByte(command, '?')
+		if pos == -1 {
+			return nil
+		}
+		for _, param := range strings.Split(command[pos+1:], "&") {
+			if pos := strings.IndexByte(param, '='); pos != -1 {
+				param = param[:pos]
+			}
+			names = append(names, param)
+		}
+		return names
+	}
+	for _, field := range strings.Fields(command) {
+		if strings.HasPrefix(field, "--") {
+			names = append(names, field[len("--"):])
+		}
+	}
+	return names
+}
+
+// checkCommand() returns ErrReadOnly if the database is read-only and
+// command may modify the database, that is, command is not in readCommands
+// or has an option in writeOptions.
+func (db *DB) checkCommand(command string) error {
+	if !db.readOnly {
+		return nil
+	}
+	name := commandName(command)
+	if !readCommands[name] {
+		return fmt.Errorf("%w: command = <%s>", ErrReadOnly, name)
+	}
+	for _, option := range commandOptionNames(command) {
+		if writeOptions[option] {
+			return fmt.Errorf("%w: command = <%s>, option = <%s>", ErrReadOnly,
+				name, option)
+		}
+	}
+	return nil
+}
+
+// IsReadOnly() returns whether the handle is opened by OpenDBReadOnly().
+func (db *DB) IsReadOnly() bool {
+	return db.readOnly
 }
 
 // newDB() creates a new DB object.
 func newDB(ctx *C.grn_ctx, obj *C.grn_obj) *DB {
-	return &DB{ctx, obj, make(map[string]*Table)}
+	return &DB{ctx: ctx, obj: obj, tables: make(map[string]*Table),
+		outputType: JSONOutput}
+}
+
+// setCtxEncoding() sets the encoding of ctx.
+func setCtxEncoding(ctx *C.grn_ctx, encoding Encoding) error {
+	if encoding == DefaultEncoding {
+		return nil
+	}
+	if rc := C.grn_ctx_set_encoding(ctx, C.grn_encoding(encoding)); rc != C.GRN_SUCCESS {
+		return fmt.Errorf("grn_ctx_set_encoding() failed: rc = %d, encoding = %s",
+			rc, encoding)
+	}
+	return nil
+}
+
+// checkDBOptions() returns an error if options has an undefined value.
+func checkDBOptions(options *DBOptions) error {
+	if (options.Encoding < DefaultEncoding) || (options.Encoding > KOI8REncoding) {
+		return fmt.Errorf("undefined encoding: encoding = %d", options.Encoding)
+	}
+	return nil
 }
 
 // CreateDB() creates a Groonga database and returns a handle to it.
 // A temporary database is created if path is empty.
 func CreateDB(path string) (*DB, error) {
+	return CreateDBWithOptions(path, nil)
+}
+
+// CreateDBWithOptions() creates a Groonga database like CreateDB(), but the
+// context is configured with options before creation.
+// The database and tables created through the handle use options.Encoding.
+func CreateDBWithOptions(path string, options *DBOptions) (*DB, error) {
+	if options == nil {
+		options = NewDBOptions()
+	}
+	if err := checkDBOptions(options); err != nil {
+		return nil, err
+	}
 	ctx, err := openCtx()
 	if err != nil {
 		return nil, err
 	}
+	if err := setCtxEncoding(ctx, options.Encoding); err != nil {
+		closeCtx(ctx)
+		return nil, err
+	}
 	var cPath *C.char
 	if path != "" {
 		cPath = C.CString(path)
@@ -264,6 +1102,21 @@ func CreateDB(path string) (*DB, error) {
 
 // OpenDB() opens an existing Groonga database and returns a handle.
 func OpenDB(path string) (*DB, error) {
+	return OpenDBWithOptions(path, nil)
+}
+
+// OpenDBWithOptions() opens an existing Groonga database like OpenDB(), but
+// the context is configured with options after opening, because opening a
+// database resets the encoding of the context to that of the database.
+// Existing tables keep the encodings they were created with, while commands
+// and new tables use options.Encoding.
+func OpenDBWithOptions(path string, options *DBOptions) (*DB, error) {
+	if options == nil {
+		options = NewDBOptions()
+	}
+	if err := checkDBOptions(options); err != nil {
+		return nil, err
+	}
 	ctx, err := openCtx()
 	if err != nil {
 		return nil, err
@@ -276,111 +1129,502 @@ func OpenDB(path string) (*DB, error) {
 		errMsg := C.GoString(&ctx.errbuf[0])
 		return nil, fmt.Errorf("grn_db_open() failed: err = %s", errMsg)
 	}
+	if err := setCtxEncoding(ctx, options.Encoding); err != nil {
+		C.grn_obj_close(ctx, obj)
+		closeCtx(ctx)
+		return nil, err
+	}
 	return newDB(ctx, obj), nil
 }
 
-// Close() closes a handle.
-func (db *DB) Close() error {
-	rc := C.grn_obj_close(db.ctx, db.obj)
-	if rc != C.GRN_SUCCESS {
-		closeCtx(db.ctx)
-		return fmt.Errorf("grn_obj_close() failed: rc = %d", rc)
+// Encoding() returns the encoding of the context.
+func (db *DB) Encoding() Encoding {
+	db.mutex.Lock()
+	defer db.mutex.Unlock()
+	return Encoding(C.grn_ctx_get_encoding(db.ctx))
+}
+
+// OpenDBReadOnly() opens an existing Groonga database like OpenDB(), but
+// the returned handle rejects writes with ErrReadOnly.
+// Writes include commands which modify the database, such as load and
+// table_create, as well as methods such as InsertRow() and SetValue().
+// Note that other processes may still modify the database.
+func OpenDBReadOnly(path string) (*DB, error) {
+	db, err := OpenDB(path)
+	if err != nil {
+		return nil, err
 	}
-	return closeCtx(db.ctx)
+	db.readOnly = true
+	return db, nil
 }
 
-// Send() sends a raw command.
-// The given command must be well-formed.
-func (db *DB) Send(command string) error {
-	commandBytes := []byte(command)
-	var cCommand *C.char
+// ConnectGQTP() connects to a Groonga server with GQTP and returns a handle.
+// Commands, such as Send(), Recv(), Query(), and Select(), are executed by the
+// server. Tables are found with the table_list command, but operations which
+// require local objects are not available remotely. They are Table methods to
+// access rows or columns directly, such as InsertRow(), Len(), OpenCursor(),
+// CreateColumn(), and FindColumn(), and they return an error.
+func ConnectGQTP(host string, port int) (*DB, error) {
+	ctx, err := openCtx()
+	if err != nil {
+		return nil, err
+	}
+	cHost := C.CString(host)
+	defer C.free(unsafe.Pointer(cHost))
+	if rc := C.grn_ctx_connect(ctx, cHost, C.int(port), 0); rc != C.GRN_SUCCESS {
+		errMsg := C.GoString(&ctx.errbuf[0])
+		closeCtx(ctx)
+		return nil, fmt.Errorf("grn_ctx_connect() failed: rc = %d, err = %s",
+			rc, errMsg)
+	}
+	db := newDB(ctx, nil)
+	db.remote = true
+	return db, nil
+}
+
+// Close() closes a handle.
+// Cached tables and columns are released.
+func (db *DB) Close() error {
+	db.mutex.Lock()
+	defer db.mutex.Unlock()
+	for _, table := range db.tables {
+		table.release()
+	}
+	db.tables = make(map[string]*Table)
+	if db.remote || db.attached {
+		return closeCtx(db.ctx)
+	}
+	rc := C.grn_obj_close(db.ctx, db.obj)
+	if rc != C.GRN_SUCCESS {
+		closeCtx(db.ctx)
+		return fmt.Errorf("grn_obj_close() failed: rc = %d", rc)
+	}
+	return closeCtx(db.ctx)
+}
+
+// Send() sends a raw command.
+// The given command must be well-formed.
+// Note that Send() and Recv() are serialized one by one, so goroutines sharing
+// a DB should use Query() or QueryEx() to get their own results.
+func (db *DB) Send(command string) error {
+	db.mutex.Lock()
+	defer db.mutex.Unlock()
+	return db.send(command)
+}
+
+// send() sends a raw command without locking.
+func (db *DB) send(command string) error {
+	if err := db.checkCommand(command); err != nil {
+		return err
+	}
+	commandBytes := []byte(command)
+	var cCommand *C.char
 	if len(commandBytes) != 0 {
 		cCommand = (*C.char)(unsafe.Pointer(&commandBytes[0]))
 	}
 	rc := C.grn_ctx_send(db.ctx, cCommand, C.uint(len(commandBytes)), 0)
 	switch {
 	case rc != C.GRN_SUCCESS:
-		errMsg := C.GoString(&db.ctx.errbuf[0])
-		return fmt.Errorf("grn_ctx_send() failed: rc = %d, err = %s", rc, errMsg)
+		return newGroongaError(db.ctx, "grn_ctx_send()", rc)
 	case db.ctx.rc != C.GRN_SUCCESS:
-		errMsg := C.GoString(&db.ctx.errbuf[0])
-		return fmt.Errorf("grn_ctx_send() failed: ctx.rc = %d, err = %s",
-			db.ctx.rc, errMsg)
+		return newGroongaError(db.ctx, "grn_ctx_send()", db.ctx.rc)
+	}
+	return nil
+}
+
+// SetOutputType() sets the default output type of SendEx() and QueryEx().
+// The output type is given to each command as --output_type unless the
+// command has its own output_type option, and it does not affect Send(),
+// Query(), and commands executed internally, such as Select().
+func (db *DB) SetOutputType(outputType OutputType) error {
+	switch outputType {
+	case JSONOutput, TSVOutput, MsgPackOutput:
+	default:
+		return fmt.Errorf("undefined output type: outputType = %d", outputType)
+	}
+	db.mutex.Lock()
+	db.outputType = outputType
+	db.mutex.Unlock()
+	return nil
+}
+
+// OutputType() returns the default output type of SendEx() and QueryEx().
+func (db *DB) OutputType() OutputType {
+	db.mutex.Lock()
+	defer db.mutex.Unlock()
+	return db.outputType
+}
+
+// SetCommandVersion() pins the command version, which changes the output
+// format of some commands, such as select.
+// The parsers of Select() and other methods support versions 1 and 2, so
+// other versions, including 3, are rejected.
+// The version is set to the grn_ctx of a local database and is also given to
+// commands executed internally as --command_version, so that it takes effect
+// even if the default version of a server is changed.
+func (db *DB) SetCommandVersion(version int) error {
+	switch version {
+	case 1, 2:
+	default:
+		return fmt.Errorf("unsupported command version: version = %d", version)
+	}
+	db.mutex.Lock()
+	defer db.mutex.Unlock()
+	if !db.remote {
+		rc := C.grn_ctx_set_command_version(db.ctx, C.grn_command_version(version))
+		if rc != C.GRN_SUCCESS {
+			return newGroongaError(db.ctx, "grn_ctx_set_command_version()", rc)
+		}
 	}
+	db.cmdVersion = version
 	return nil
 }
 
+// CommandVersion() returns the command version.
+// For a connection by ConnectGQTP(), 0 is returned unless the version is
+// pinned by SetCommandVersion(), because the default version of the server
+// is unknown.
+func (db *DB) CommandVersion() int {
+	db.mutex.Lock()
+	defer db.mutex.Unlock()
+	if db.remote {
+		return db.cmdVersion
+	}
+	return int(C.grn_ctx_get_command_version(db.ctx))
+}
+
+// withCommandVersion() returns options with the pinned command version.
+// The caller must hold db.mutex.
+func (db *DB) withCommandVersion(options map[string]string) map[string]string {
+	if _, ok := options["command_version"]; ok || (db.cmdVersion == 0) {
+		return options
+	}
+	newOptions := make(map[string]string)
+	for key, value := range options {
+		newOptions[key] = value
+	}
+	newOptions["command_version"] = strconv.Itoa(db.cmdVersion)
+	return newOptions
+}
+
 // SendEx() sends a command with separated options.
+// See SetOutputType() for the output type.
 func (db *DB) SendEx(name string, options map[string]string) error {
+	db.mutex.Lock()
+	defer db.mutex.Unlock()
+	command, err := buildCommand(name, db.withOutputType(options))
+	if err != nil {
+		return err
+	}
+	return db.send(command)
+}
+
+// withOutputType() returns options with the default output type.
+// The caller must hold db.mutex.
+func (db *DB) withOutputType(options map[string]string) map[string]string {
+	if _, ok := options["output_type"]; ok || (db.outputType == JSONOutput) {
+		return options
+	}
+	newOptions := make(map[string]string)
+	for key, value := range options {
+		newOptions[key] = value
+	}
+	newOptions["output_type"] = db.outputType.String()
+	return newOptions
+}
+
+// commandValueReplacer escapes characters which break a single-quoted value.
+var commandValueReplacer = strings.NewReplacer(
+	"\\", "\\\\", "'", "\\'", "\n", "\\n", "\r", "\\r")
+
+// EscapeCommandValue() escapes a value to be embedded in a single-quoted
+// argument of a raw command.
+// Backslashes, single quotes, and line breaks are escaped.
+func EscapeCommandValue(value string) string {
+	return commandValueReplacer.Replace(value)
+}
+
+// QuoteCommandValue() escapes a value and encloses it in single quotes, so
+// that it can be used as an argument of a raw command.
+func QuoteCommandValue(value string) string {
+	return "'" + EscapeCommandValue(value) + "'"
+}
+
+// isOptionName() returns whether name consists of [a-z_].
+func isOptionName(name string) bool {
 	if name == "" {
-		return fmt.Errorf("invalid command: name = <%s>", name)
+		return false
 	}
 	for _, r := range name {
-		if (r != '_') && (r < 'a') && (r > 'z') {
-			return fmt.Errorf("invalid command: name = <%s>", name)
+		if (r != '_') && ((r < 'a') || (r > 'z')) {
+			return false
 		}
 	}
-	commandParts := []string{name}
-	for key, value := range options {
-		if key == "" {
-			return fmt.Errorf("invalid option: key = <%s>", key)
+	return true
+}
+
+// isDrilldownLabel() returns whether label consists of [A-Za-z0-9_].
+func isDrilldownLabel(label string) bool {
+	if label == "" {
+		return false
+	}
+	for _, r := range label {
+		switch {
+		case r == '_':
+		case (r >= 'a') && (r <= 'z'):
+		case (r >= 'A') && (r <= 'Z'):
+		case (r >= '0') && (r <= '9'):
+		default:
+			return false
 		}
-		for _, r := range key {
-			if (r != '_') && (r < 'a') && (r > 'z') {
-				return fmt.Errorf("invalid option: key = <%s>", key)
+	}
+	return true
+}
+
+// checkOptionKey() checks an option key.
+// A valid key is a name of [a-z_] or drilldown[label].name, where label
+// consists of [A-Za-z0-9_].
+func checkOptionKey(key string) error {
+	if isOptionName(key) {
+		return nil
+	}
+	const prefix = "drilldown["
+	if strings.HasPrefix(key, prefix) {
+		rest := key[len(prefix):]
+		if end := strings.Index(rest, "]."); end != -1 {
+			if isDrilldownLabel(rest[:end]) && isOptionName(rest[end+2:]) {
+				return nil
 			}
 		}
-		value = strings.Replace(value, "\\", "\\\\", -1)
-		value = strings.Replace(value, "'", "\\'", -1)
-		commandParts = append(commandParts, fmt.Sprintf("--%s '%s'", key, value))
 	}
-	return db.Send(strings.Join(commandParts, " "))
+	return fmt.Errorf("invalid option: key = <%s>", key)
 }
 
-// Recv() receives the result of commands sent by Send().
-func (db *DB) Recv() ([]byte, error) {
+// buildCommand() builds a command from a name and separated options.
+func buildCommand(name string, options map[string]string) (string, error) {
+	if name == "" {
+		return "", fmt.Errorf("invalid command: name = <%s>", name)
+	}
+	for _, r := range name {
+		if (r != '_') && ((r < 'a') || (r > 'z')) {
+			return "", fmt.Errorf("invalid command: name = <%s>", name)
+		}
+	}
+	commandParts := []string{name}
+	for key, value := range options {
+		if err := checkOptionKey(key); err != nil {
+			return "", err
+		}
+		commandParts = append(commandParts,
+			fmt.Sprintf("--%s %s", key, QuoteCommandValue(value)))
+	}
+	return strings.Join(commandParts, " "), nil
+}
+
+// recvChunk() receives a chunk of the result of commands.
+// The second return value specifies whether more chunks follow or not.
+// It is also valid if the command fails, because Groonga may still have
+// chunks to send, but it is false if grn_ctx_recv() itself fails.
+func (db *DB) recvChunk() ([]byte, bool, error) {
 	var resultBuffer *C.char
 	var resultLength C.uint
 	var flags C.int
 	rc := C.grn_ctx_recv(db.ctx, &resultBuffer, &resultLength, &flags)
+	more := (flags & C.GRN_CTX_MORE) != 0
 	switch {
 	case rc != C.GRN_SUCCESS:
-		errMsg := C.GoString(&db.ctx.errbuf[0])
-		return nil, fmt.Errorf(
-			"grn_ctx_recv() failed: rc = %d, err = %s", rc, errMsg)
+		return nil, false, newGroongaError(db.ctx, "grn_ctx_recv()", rc)
 	case db.ctx.rc != C.GRN_SUCCESS:
-		errMsg := C.GoString(&db.ctx.errbuf[0])
-		return nil, fmt.Errorf(
-			"grn_ctx_recv() failed: ctx.rc = %d, err = %s", db.ctx.rc, errMsg)
+		return nil, more, newGroongaError(db.ctx, "grn_ctx_recv()", db.ctx.rc)
 	}
 	result := C.GoBytes(unsafe.Pointer(resultBuffer), C.int(resultLength))
-	return result, nil
+	return result, more, nil
+}
+
+// drainChunks() receives and discards the remaining chunks of a result, so
+// that they are never mistaken for the result of the next command.
+func (db *DB) drainChunks() {
+	for more := true; more; {
+		_, more, _ = db.recvChunk()
+	}
+}
+
+// Recv() receives the result of commands sent by Send().
+// If Groonga returns the result in multiple chunks, Recv() receives all of
+// them and returns the concatenated result.
+func (db *DB) Recv() ([]byte, error) {
+	db.mutex.Lock()
+	defer db.mutex.Unlock()
+	return db.recv()
+}
+
+// recv() receives the result of commands without locking.
+func (db *DB) recv() ([]byte, error) {
+	result, more, err := db.recvChunk()
+	for more && (err == nil) {
+		var chunk []byte
+		chunk, more, err = db.recvChunk()
+		result = append(result, chunk...)
+	}
+	if more {
+		db.drainChunks()
+	}
+	return result, err
 }
 
 // Query() sends a raw command and receive the result.
 func (db *DB) Query(command string) ([]byte, error) {
-	if err := db.Send(command); err != nil {
-		result, _ := db.Recv()
+	db.mutex.Lock()
+	defer db.mutex.Unlock()
+	return db.query(command)
+}
+
+// query() sends a raw command and receives the result without locking.
+func (db *DB) query(command string) ([]byte, error) {
+	// Nothing is sent and so nothing is received if the command is rejected.
+	if err := db.checkCommand(command); err != nil {
+		return nil, err
+	}
+	if err := db.send(command); err != nil {
+		result, _ := db.recv()
+		return result, err
+	}
+	return db.recv()
+}
+
+// QueryContext() executes a command like Query(), but returns ctx.Err() as
+// soon as ctx is cancelled or its deadline passes.
+// On cancellation, QueryContext() asks Groonga to abort the command and
+// abandons the result. Note that the command may still run to completion in
+// Groonga, and other operations on the DB wait until then.
+func (db *DB) QueryContext(ctx context.Context, command string) ([]byte, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	var result []byte
+	var err error
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		db.mutex.Lock()
+		defer db.mutex.Unlock()
+		if err = ctx.Err(); err != nil {
+			return
+		}
+		// The watcher cancels the command only while the mutex is held, so
+		// that other commands are never cancelled.
+		stop := make(chan struct{})
+		stopped := make(chan struct{})
+		go func() {
+			defer close(stopped)
+			select {
+			case <-ctx.Done():
+				C.grngo_ctx_cancel(db.ctx)
+			case <-stop:
+			}
+		}()
+		result, err = db.query(command)
+		close(stop)
+		<-stopped
+	}()
+	select {
+	case <-done:
 		return result, err
+	case <-ctx.Done():
+		return nil, ctx.Err()
 	}
-	return db.Recv()
 }
 
 // QueryEx() sends a command with separated options and receives the result.
 func (db *DB) QueryEx(name string, options map[string]string) (
 	[]byte, error) {
-	if err := db.SendEx(name, options); err != nil {
-		result, _ := db.Recv()
-		return result, err
+	db.mutex.Lock()
+	defer db.mutex.Unlock()
+	command, err := buildCommand(name, db.withOutputType(options))
+	if err != nil {
+		return nil, err
+	}
+	return db.query(command)
+}
+
+// queryEx() sends a command with separated options as is, except for the
+// command version pinned by SetCommandVersion(), and receives the result.
+// The result is in JSON unless options has output_type.
+func (db *DB) queryEx(name string, options map[string]string) (
+	[]byte, error) {
+	db.mutex.Lock()
+	options = db.withCommandVersion(options)
+	db.mutex.Unlock()
+	command, err := buildCommand(name, options)
+	if err != nil {
+		return nil, err
+	}
+	return db.Query(command)
+}
+
+// Dump() executes the dump command and writes the result into w.
+// Each chunk of the result is written as soon as it is received, so that the
+// whole dump is never held in memory. w is called while the DB is locked and
+// so must not use the DB.
+func (db *DB) Dump(w io.Writer, options *DumpOptions) error {
+	if options == nil {
+		options = NewDumpOptions()
+	}
+	optionsMap := make(map[string]string)
+	if len(options.Tables) != 0 {
+		optionsMap["tables"] = strings.Join(options.Tables, ",")
+	}
+	if options.SchemaOnly {
+		optionsMap["dump_records"] = "no"
+	}
+	command, err := buildCommand("dump", optionsMap)
+	if err != nil {
+		return err
+	}
+	db.mutex.Lock()
+	defer db.mutex.Unlock()
+	if err := db.send(command); err != nil {
+		db.recv()
+		return err
+	}
+	for {
+		chunk, more, err := db.recvChunk()
+		if err != nil {
+			if more {
+				db.drainChunks()
+			}
+			return err
+		}
+		if _, err := w.Write(chunk); err != nil {
+			if more {
+				db.drainChunks()
+			}
+			return fmt.Errorf("io.Writer.Write() failed: %v", err)
+		}
+		if !more {
+			return nil
+		}
 	}
-	return db.Recv()
 }
 
 // CreateTable() creates a table.
+// If the table already exists, an error wrapping ErrAlreadyExists and the
+// GroongaError of table_create, if any, is returned.
 func (db *DB) CreateTable(name string, options *TableOptions) (*Table, error) {
 	if options == nil {
 		options = NewTableOptions()
 	}
+	if options.Temporary {
+		return db.createTemporaryTable(name, options)
+	}
+	// Groonga does not know temporary tables, so their names are checked here.
+	db.mutex.Lock()
+	cached, ok := db.tables[name]
+	db.mutex.Unlock()
+	if ok && cached.temporary {
+		return nil, fmt.Errorf("%w: temporary table: name = <%s>",
+			ErrAlreadyExists, name)
+	}
 	optionsMap := make(map[string]string)
 	optionsMap["name"] = name
 	switch options.TableType {
@@ -399,30 +1643,28 @@ func (db *DB) CreateTable(name string, options *TableOptions) (*Table, error) {
 		optionsMap["flags"] += "|KEY_WITH_SIS"
 	}
 	if options.KeyType != "" {
-		switch options.KeyType {
-		case "Bool", "Int8", "Int16", "Int32", "Int64", "UInt8", "UInt16",
-			"UInt32", "UInt64", "Float", "Time", "ShortText", "TokyoGeoPoint",
-			"WGS84GeoPoint":
-			optionsMap["key_type"] = options.KeyType
-		default:
-			if _, err := db.FindTable(options.KeyType); err != nil {
+		if dataType, ok := ParseDataType(options.KeyType); ok {
+			switch dataType {
+			case Void, Float32, Text, LongText:
 				return nil, fmt.Errorf("unsupported key type: options = %+v", options)
 			}
-			optionsMap["key_type"] = options.KeyType
+		} else if _, err := db.FindTable(options.KeyType); err != nil {
+			return nil, fmt.Errorf("unsupported key type: options = %+v", options)
 		}
+		optionsMap["key_type"] = options.KeyType
 	}
 	if options.ValueType != "" {
-		switch options.ValueType {
-		case "Bool", "Int8", "Int16", "Int32", "Int64", "UInt8", "UInt16",
-			"UInt32", "UInt64", "Float", "Time", "TokyoGeoPoint", "WGS84GeoPoint":
-			optionsMap["value_type"] = options.ValueType
-		default:
-			if _, err := db.FindTable(options.ValueType); err != nil {
+		if dataType, ok := ParseDataType(options.ValueType); ok {
+			switch dataType {
+			case Void, Float32, ShortText, Text, LongText:
 				return nil, fmt.Errorf("unsupported value type: options = %+v",
 					options)
 			}
-			optionsMap["value_type"] = options.ValueType
+		} else if _, err := db.FindTable(options.ValueType); err != nil {
+			return nil, fmt.Errorf("unsupported value type: options = %+v",
+				options)
 		}
+		optionsMap["value_type"] = options.ValueType
 	}
 	if options.DefaultTokenizer != "" {
 		optionsMap["default_tokenizer"] = options.DefaultTokenizer
@@ -433,122 +1675,1310 @@ func (db *DB) CreateTable(name string, options *TableOptions) (*Table, error) {
 	if len(options.TokenFilters) != 0 {
 		optionsMap["token_filters"] = strings.Join(options.TokenFilters, ",")
 	}
-	bytes, err := db.QueryEx("table_create", optionsMap)
+	bytes, err := db.queryEx("table_create", optionsMap)
 	if err != nil {
+		// Groonga has no dedicated code for an existing name.
+		if db.HasTable(name) {
+			return nil, fmt.Errorf("%w: name = <%s>: %w", ErrAlreadyExists,
+				name, err)
+		}
 		return nil, err
 	}
 	if string(bytes) != "true" {
-		return nil, fmt.Errorf("table_create failed: name = <%s>", name)
+		return nil, fmt.Errorf("table_create failed: name = <%s>, result = %s",
+			name, bytes)
 	}
 	return db.FindTable(name)
 }
 
-// FindTable() finds a table.
-func (db *DB) FindTable(name string) (*Table, error) {
-	if table, ok := db.tables[name]; ok {
-		return table, nil
+// createTemporaryTable() creates an anonymous temporary table and registers
+// it in the cache under the given name.
+func (db *DB) createTemporaryTable(name string, options *TableOptions) (
+	*Table, error) {
+	if db.remote {
+		return nil, fmt.Errorf("temporary table is not available for a remote database")
+	}
+	if err := db.checkWritable(); err != nil {
+		return nil, err
 	}
-	nameBytes := []byte(name)
-	var cName *C.char
-	if len(nameBytes) != 0 {
-		cName = (*C.char)(unsafe.Pointer(&nameBytes[0]))
+	if name == "" {
+		return nil, fmt.Errorf("empty table name")
 	}
-	obj := C.grngo_find_table(db.ctx, cName, C.int(len(nameBytes)))
-	if obj == nil {
-		return nil, fmt.Errorf("table not found: name = <%s>", name)
+	if len(options.TokenFilters) != 0 {
+		return nil, fmt.Errorf("token filters are not supported by temporary table: options = %+v",
+			options)
 	}
-	var keyInfo C.grngo_type_info
-	if ok := C.grngo_table_get_key_info(db.ctx, obj, &keyInfo); ok != C.GRN_TRUE {
-		return nil, fmt.Errorf("grngo_table_get_key_info() failed: name = <%s>",
-			name)
+	var flags C.uint
+	switch options.TableType {
+	case ArrayTable:
+		flags = C.GRN_OBJ_TABLE_NO_KEY
+	case HashTable:
+		flags = C.GRN_OBJ_TABLE_HASH_KEY
+	case PatTable:
+		flags = C.GRN_OBJ_TABLE_PAT_KEY
+	case DatTable:
+		flags = C.GRN_OBJ_TABLE_DAT_KEY
+	default:
+		return nil, fmt.Errorf("undefined table type: options = %+v", options)
 	}
-	// Check the key type.
-	keyType := DataType(keyInfo.data_type)
-	// Find the destination table if the key is table reference.
-	var keyTable *Table
-	if keyInfo.ref_table != nil {
-		if keyType == Void {
-			return nil, fmt.Errorf("reference to void: name = <%s>", name)
+	if options.WithSIS {
+		flags |= C.GRN_OBJ_KEY_WITH_SIS
+	}
+	keyType, valueType := Void, Void
+	if options.KeyType != "" {
+		dataType, ok := ParseDataType(options.KeyType)
+		if !ok {
+			return nil, fmt.Errorf("unsupported key type: options = %+v", options)
 		}
-		cKeyTableName := C.grngo_table_get_name(db.ctx, keyInfo.ref_table)
-		if cKeyTableName == nil {
-			return nil, fmt.Errorf("grngo_table_get_name() failed")
+		switch dataType {
+		case Void, Float32, Text, LongText:
+			return nil, fmt.Errorf("unsupported key type: options = %+v", options)
 		}
-		defer C.free(unsafe.Pointer(cKeyTableName))
-		var err error
-		keyTable, err = db.FindTable(C.GoString(cKeyTableName))
-		if err != nil {
-			return nil, err
+		keyType = dataType
+	}
+	if options.ValueType != "" {
+		dataType, ok := ParseDataType(options.ValueType)
+		if !ok {
+			return nil, fmt.Errorf("unsupported value type: options = %+v", options)
 		}
+		switch dataType {
+		case Void, Float32, ShortText, Text, LongText:
+			return nil, fmt.Errorf("unsupported value type: options = %+v", options)
+		}
+		valueType = dataType
 	}
-	var valueInfo C.grngo_type_info
-	if ok := C.grngo_table_get_value_info(db.ctx, obj, &valueInfo); ok != C.GRN_TRUE {
-		return nil, fmt.Errorf("grngo_table_get_value_info() failed: name = <%s>",
-			name)
+	db.mutex.Lock()
+	defer db.mutex.Unlock()
+	var keyObj, valueObj *C.grn_obj
+	if keyType != Void {
+		keyObj = C.grn_ctx_at(db.ctx, C.grn_id(keyType))
 	}
-	// Check the value type.
-	valueType := DataType(valueInfo.data_type)
-	// Find the destination table if the value is table reference.
-	var valueTable *Table
-	if valueInfo.ref_table != nil {
-		if valueType == Void {
-			return nil, fmt.Errorf("reference to void: name = <%s>", name)
+	if valueType != Void {
+		valueObj = C.grn_ctx_at(db.ctx, C.grn_id(valueType))
+	}
+	if _, err := db.findTable(name); err == nil {
+		return nil, fmt.Errorf("%w: name = <%s>", ErrAlreadyExists, name)
+	}
+	obj := C.grngo_table_create_temporary(db.ctx, flags, keyObj, valueObj)
+	if obj == nil {
+		return nil, newGroongaError(db.ctx, "grn_table_create()", db.ctx.rc)
+	}
+	infos := []struct {
+		infoType C.grn_info_type
+		name     string
+	}{
+		{C.GRN_INFO_DEFAULT_TOKENIZER, options.DefaultTokenizer},
+		{C.GRN_INFO_NORMALIZER, options.Normalizer},
+	}
+	for _, info := range infos {
+		if info.name == "" {
+			continue
 		}
-		cValueTableName := C.grngo_table_get_name(db.ctx, valueInfo.ref_table)
-		if cValueTableName == nil {
-			return nil, fmt.Errorf("grngo_table_get_name() failed")
+		infoObj, err := db.findObject(info.name)
+		if err == nil {
+			if rc := C.grn_obj_set_info(db.ctx, obj, info.infoType,
+				infoObj); rc != C.GRN_SUCCESS {
+				err = newGroongaError(db.ctx, "grn_obj_set_info()", rc)
+			}
 		}
-		defer C.free(unsafe.Pointer(cValueTableName))
-		var err error
-		valueTable, err = db.FindTable(C.GoString(cValueTableName))
 		if err != nil {
+			C.grn_obj_close(db.ctx, obj)
 			return nil, err
 		}
 	}
-	table := newTable(db, obj, name, keyType, keyTable, valueType, valueTable)
+	table := newTable(db, obj, name, keyType, nil, valueType, nil)
+	table.temporary = true
 	db.tables[name] = table
 	return table, nil
 }
 
-// InsertRow() inserts a row.
-func (db *DB) InsertRow(tableName string, key interface{}) (bool, uint32, error) {
-	table, err := db.FindTable(tableName)
+// IsTemporary() returns whether the table is created with
+// TableOptions.Temporary.
+func (table *Table) IsTemporary() bool {
+	return table.temporary
+}
+
+// tableListEntry is a row of the result of the table_list command.
+type tableListEntry struct {
+	name       string
+	domain     string // The key type
+	rangeValue string // The value type
+}
+
+// CreateTableFromSchema() creates a table and its columns.
+// Index columns are created after the other columns, so that their sources
+// can refer to columns in the same schema.
+// If a column cannot be created, the table is removed and an error is
+// returned.
+func (db *DB) CreateTableFromSchema(schema TableSchema) (*Table, error) {
+	table, err := db.CreateTable(schema.Name, &schema.TableOptions)
 	if err != nil {
-		return false, NilID, err
+		return nil, err
 	}
-	return table.InsertRow(key)
+	columns := make([]ColumnDefinition, 0, len(schema.Columns))
+	var indexColumns []ColumnDefinition
+	for _, column := range schema.Columns {
+		if (column.Options != nil) && (column.Options.ColumnType == IndexColumn) {
+			indexColumns = append(indexColumns, column)
+		} else {
+			columns = append(columns, column)
+		}
+	}
+	columns = append(columns, indexColumns...)
+	for _, column := range columns {
+		if _, err := table.CreateColumn(column.Name, column.Type,
+			column.Options); err != nil {
+			if removeErr := db.RemoveTable(schema.Name); removeErr != nil {
+				return nil, fmt.Errorf(
+					"DB.RemoveTable() failed: name = <%s>, err = %v, cause = %v",
+					schema.Name, removeErr, err)
+			}
+			return nil, err
+		}
+	}
+	return table, nil
 }
 
-// CreateColumn() creates a column.
-func (db *DB) CreateColumn(tableName, columnName string, valueType string,
-	options *ColumnOptions) (*Column, error) {
-	table, err := db.FindTable(tableName)
+// queryList() executes a command which returns a list, such as table_list,
+// and returns the rows as maps from field names to values.
+func (db *DB) queryList(name string, options map[string]string) (
+	[]map[string]interface{}, error) {
+	bytes, err := db.queryEx(name, options)
 	if err != nil {
 		return nil, err
 	}
-	return table.CreateColumn(columnName, valueType, options)
+	var list [][]interface{}
+	if err := json.Unmarshal(bytes, &list); err != nil {
+		return nil, fmt.Errorf("json.Unmarshal() failed: %v", err)
+	}
+	if len(list) == 0 {
+		return nil, fmt.Errorf("invalid result: result = %s", bytes)
+	}
+	var fieldNames []string
+	for _, field := range list[0] {
+		var fieldName string
+		if pair, ok := field.([]interface{}); ok && (len(pair) != 0) {
+			fieldName, _ = pair[0].(string)
+		}
+		fieldNames = append(fieldNames, fieldName)
+	}
+	rows := make([]map[string]interface{}, len(list)-1)
+	for i, values := range list[1:] {
+		rows[i] = make(map[string]interface{})
+		for j, value := range values {
+			if j < len(fieldNames) {
+				rows[i][fieldNames[j]] = value
+			}
+		}
+	}
+	return rows, nil
 }
 
-// FindColumn() finds a column.
-func (db *DB) FindColumn(tableName, columnName string) (*Column, error) {
-	table, err := db.FindTable(tableName)
+// listTables() executes the table_list command and returns the result.
+func (db *DB) listTables() ([]tableListEntry, error) {
+	rows, err := db.queryList("table_list", nil)
 	if err != nil {
 		return nil, err
 	}
-	return table.FindColumn(columnName)
+	entries := make([]tableListEntry, len(rows))
+	for i, row := range rows {
+		name, _ := row["name"].(string)
+		domain, _ := row["domain"].(string)
+		rangeValue, _ := row["range"].(string)
+		entries[i] = tableListEntry{name, domain, rangeValue}
+	}
+	return entries, nil
 }
 
-// -- Table --
-
-type Table struct {
-	db         *DB
-	obj        *C.grn_obj
-	name       string
-	keyType    DataType
-	keyTable   *Table
-	valueType  DataType
-	valueTable *Table
-	columns    map[string]*Column
+// TableNames() returns the names of tables in the database.
+// Names beginning with an underscore are reserved and excluded.
+func (db *DB) TableNames() ([]string, error) {
+	entries, err := db.listTables()
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		if (entry.name != "") && !strings.HasPrefix(entry.name, "_") {
+			names = append(names, entry.name)
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// TruncateAll() truncates tables like Table.Truncate(). If names is empty,
+// all the tables returned by TableNames() are truncated.
+// Index columns whose sources are in the tables are cleared by Groonga.
+// TruncateAll() stops at the first failure and the returned error tells the
+// tables truncated before it.
+func (db *DB) TruncateAll(names ...string) error {
+	if err := db.checkWritable(); err != nil {
+		return err
+	}
+	if len(names) == 0 {
+		var err error
+		if names, err = db.TableNames(); err != nil {
+			return err
+		}
+	}
+	for i, name := range names {
+		var err error
+		if db.remote {
+			_, err = db.queryEx("truncate", map[string]string{"target_name": name})
+		} else {
+			var table *Table
+			if table, err = db.FindTable(name); err == nil {
+				err = table.Truncate()
+			}
+		}
+		if err != nil {
+			return fmt.Errorf("truncate failed: name = <%s>, truncated = %v, err = %w",
+				name, names[:i], err)
+		}
+	}
+	return nil
+}
+
+// findRemoteTable() finds a table with the table_list command.
+func (db *DB) findRemoteTable(name string) (*Table, error) {
+	db.mutex.Lock()
+	table, ok := db.tables[name]
+	db.mutex.Unlock()
+	if ok {
+		return table, nil
+	}
+	entries, err := db.listTables()
+	if err != nil {
+		return nil, err
+	}
+	// resolveType() returns the data type and the referenced table.
+	resolveType := func(typeName string) (DataType, *Table, error) {
+		if typeName == "" {
+			return Void, nil, nil
+		}
+		if dataType, ok := ParseDataType(typeName); ok {
+			return dataType, nil, nil
+		}
+		refTable, err := db.FindTable(typeName)
+		if err != nil {
+			return Void, nil, err
+		}
+		return refTable.keyType, refTable, nil
+	}
+	for _, entry := range entries {
+		if entry.name != name {
+			continue
+		}
+		keyType, keyTable, err := resolveType(entry.domain)
+		if err != nil {
+			return nil, err
+		}
+		valueType, valueTable, err := resolveType(entry.rangeValue)
+		if err != nil {
+			return nil, err
+		}
+		db.mutex.Lock()
+		defer db.mutex.Unlock()
+		if table, ok := db.tables[name]; ok {
+			return table, nil
+		}
+		table := newTable(db, nil, name, keyType, keyTable, valueType, valueTable)
+		db.tables[name] = table
+		return table, nil
+	}
+	return nil, fmt.Errorf("table not found: name = <%s>", name)
+}
+
+// FindTable() finds a table.
+func (db *DB) FindTable(name string) (*Table, error) {
+	if db.remote {
+		return db.findRemoteTable(name)
+	}
+	db.mutex.Lock()
+	defer db.mutex.Unlock()
+	return db.findTable(name)
+}
+
+// findTable() finds a local table without locking.
+func (db *DB) findTable(name string) (*Table, error) {
+	if table, ok := db.tables[name]; ok {
+		return table, nil
+	}
+	nameBytes := []byte(name)
+	var cName *C.char
+	if len(nameBytes) != 0 {
+		cName = (*C.char)(unsafe.Pointer(&nameBytes[0]))
+	}
+	obj := C.grngo_find_table(db.ctx, cName, C.int(len(nameBytes)))
+	if obj == nil {
+		return nil, fmt.Errorf("table not found: name = <%s>", name)
+	}
+	var keyInfo C.grngo_type_info
+	if ok := C.grngo_table_get_key_info(db.ctx, obj, &keyInfo); ok != C.GRN_TRUE {
+		return nil, fmt.Errorf("grngo_table_get_key_info() failed: name = <%s>",
+			name)
+	}
+	// Check the key type.
+	keyType := DataType(keyInfo.data_type)
+	// Find the destination table if the key is table reference.
+	var keyTable *Table
+	if keyInfo.ref_table != nil {
+		if keyType == Void {
+			return nil, fmt.Errorf("reference to void: name = <%s>", name)
+		}
+		cKeyTableName := C.grngo_table_get_name(db.ctx, keyInfo.ref_table)
+		if cKeyTableName == nil {
+			return nil, fmt.Errorf("grngo_table_get_name() failed")
+		}
+		defer C.free(unsafe.Pointer(cKeyTableName))
+		var err error
+		keyTable, err = db.findTable(C.GoString(cKeyTableName))
+		if err != nil {
+			return nil, err
+		}
+	}
+	var valueInfo C.grngo_type_info
+	if ok := C.grngo_table_get_value_info(db.ctx, obj, &valueInfo); ok != C.GRN_TRUE {
+		return nil, fmt.Errorf("grngo_table_get_value_info() failed: name = <%s>",
+			name)
+	}
+	// Check the value type.
+	valueType := DataType(valueInfo.data_type)
+	// Find the destination table if the value is table reference.
+	var valueTable *Table
+	if valueInfo.ref_table != nil {
+		if valueType == Void {
+			return nil, fmt.Errorf("reference to void: name = <%s>", name)
+		}
+		cValueTableName := C.grngo_table_get_name(db.ctx, valueInfo.ref_table)
+		if cValueTableName == nil {
+			return nil, fmt.Errorf("grngo_table_get_name() failed")
+		}
+		defer C.free(unsafe.Pointer(cValueTableName))
+		var err error
+		valueTable, err = db.findTable(C.GoString(cValueTableName))
+		if err != nil {
+			return nil, err
+		}
+	}
+	table := newTable(db, obj, name, keyType, keyTable, valueType, valueTable)
+	db.tables[name] = table
+	return table, nil
+}
+
+// cachedColumnIDs() returns the object IDs of the cached columns.
+// Note that the ID of a pseudo or composite column is GRN_ID_NIL.
+func (db *DB) cachedColumnIDs() map[*Column]C.grn_id {
+	db.mutex.Lock()
+	defer db.mutex.Unlock()
+	ids := make(map[*Column]C.grn_id)
+	for _, table := range db.tables {
+		for _, column := range table.columns {
+			ids[column] = C.grn_obj_id(db.ctx, column.obj)
+		}
+	}
+	return ids
+}
+
+// evictTable() removes a table and tables referring to it from the cache.
+// The caller must hold db.mutex.
+func (db *DB) evictTable(table *Table) {
+	delete(db.tables, table.name)
+	for _, other := range db.tables {
+		if (other.keyTable == table) || (other.valueTable == table) {
+			db.evictTable(other)
+		}
+	}
+}
+
+// evictColumns() removes columns which are no longer available from the
+// cache. ids must be a result of cachedColumnIDs() before the removal.
+// Pseudo and composite columns are also removed and released because their
+// accessors may refer to removed objects.
+// Columns whose objects have been removed are marked as removed.
+// The caller must hold db.mutex.
+func (db *DB) evictColumns(ids map[*Column]C.grn_id) {
+	for column, id := range ids {
+		table := column.table
+		switch {
+		case id == C.GRN_ID_NIL:
+			delete(table.columns, column.name)
+			column.release()
+		case C.grn_ctx_at(db.ctx, id) == nil:
+			delete(table.columns, column.name)
+			column.obj = nil
+		case column.valueTable != nil:
+			valueTable := column.valueTable
+			if cached, ok := db.tables[valueTable.name]; !ok || (cached != valueTable) {
+				delete(table.columns, column.name)
+			}
+		}
+	}
+}
+
+// defrag() defragments obj and returns the number of defragmented segments.
+// The caller must hold db.mutex.
+func (db *DB) defrag(obj *C.grn_obj, options *DefragOptions) (int, error) {
+	if options == nil {
+		options = NewDefragOptions()
+	}
+	n := C.grn_obj_defrag(db.ctx, obj, C.int(options.Threshold))
+	if db.ctx.rc != C.GRN_SUCCESS {
+		return int(n), newGroongaError(db.ctx, "grn_obj_defrag()", db.ctx.rc)
+	}
+	return int(n), nil
+}
+
+// lock() acquires the lock of obj.
+// grn_obj_lock() retries every millisecond, so timeout is converted into the
+// number of retries. A negative timeout means no limit.
+// The caller must hold db.mutex.
+func (db *DB) lock(obj *C.grn_obj, timeout time.Duration) error {
+	nRetries := C.int(-1)
+	if timeout >= 0 {
+		ms := timeout / time.Millisecond
+		if ms > math.MaxInt32 {
+			ms = math.MaxInt32
+		}
+		nRetries = C.int(ms)
+	}
+	switch rc := C.grn_obj_lock(db.ctx, obj, C.GRN_ID_NIL, nRetries); rc {
+	case C.GRN_SUCCESS:
+		return nil
+	case C.GRN_RESOURCE_DEADLOCK_AVOIDED:
+		return fmt.Errorf("%w: timeout = %v", ErrLockTimeout, timeout)
+	default:
+		return newGroongaError(db.ctx, "grn_obj_lock()", rc)
+	}
+}
+
+// unlock() releases the lock of obj.
+// The caller must hold db.mutex.
+func (db *DB) unlock(obj *C.grn_obj) error {
+	if rc := C.grn_obj_unlock(db.ctx, obj, C.GRN_ID_NIL); rc != C.GRN_SUCCESS {
+		return newGroongaError(db.ctx, "grn_obj_unlock()", rc)
+	}
+	return nil
+}
+
+// Lock() acquires the lock of the database, which prevents other processes
+// and handles from modifying it, and must be released by Unlock().
+// Lock() waits up to timeout, rounded down to milliseconds, and returns
+// ErrLockTimeout if the lock is held by another.
+// A negative timeout means no limit and zero means no wait.
+func (db *DB) Lock(timeout time.Duration) error {
+	if db.remote {
+		return fmt.Errorf("not available for a remote database")
+	}
+	if err := db.checkWritable(); err != nil {
+		return err
+	}
+	db.mutex.Lock()
+	defer db.mutex.Unlock()
+	return db.lock(db.obj, timeout)
+}
+
+// Unlock() releases the lock acquired by Lock().
+func (db *DB) Unlock() error {
+	if db.remote {
+		return fmt.Errorf("not available for a remote database")
+	}
+	if err := db.checkWritable(); err != nil {
+		return err
+	}
+	db.mutex.Lock()
+	defer db.mutex.Unlock()
+	return db.unlock(db.obj)
+}
+
+// findObject() finds a table or a column, such as "Table.Value", by name.
+// The database itself is returned if name is empty.
+// The caller must hold db.mutex.
+func (db *DB) findObject(name string) (*C.grn_obj, error) {
+	if name == "" {
+		return db.obj, nil
+	}
+	nameBytes := []byte(name)
+	cName := (*C.char)(unsafe.Pointer(&nameBytes[0]))
+	obj := C.grn_ctx_get(db.ctx, cName, C.int(len(nameBytes)))
+	if obj == nil {
+		return nil, fmt.Errorf("object not found: name = <%s>", name)
+	}
+	return obj, nil
+}
+
+// ClearLock() forcibly clears the locks of target, which is the name of a
+// table or a column, such as "Table.Value", or the whole database if empty.
+// The locks of the columns of a table, or all the objects of the database,
+// are also cleared.
+// ClearLock() is to recover from a process which crashed while holding
+// locks, so it must not be called while another process is writing.
+// For a remote database, the lock_clear command is executed instead.
+func (db *DB) ClearLock(target string) error {
+	if db.remote {
+		options := make(map[string]string)
+		if target != "" {
+			options["target_name"] = target
+		}
+		_, err := db.queryEx("lock_clear", options)
+		return err
+	}
+	if err := db.checkWritable(); err != nil {
+		return err
+	}
+	db.mutex.Lock()
+	defer db.mutex.Unlock()
+	obj, err := db.findObject(target)
+	if err != nil {
+		return err
+	}
+	if rc := C.grn_obj_clear_lock(db.ctx, obj); rc != C.GRN_SUCCESS {
+		return newGroongaError(db.ctx, "grn_obj_clear_lock()", rc)
+	}
+	return nil
+}
+
+// IsLocked() returns whether target, which is specified like ClearLock(),
+// is locked or not.
+func (db *DB) IsLocked(target string) (bool, error) {
+	if db.remote {
+		return false, fmt.Errorf("not available for a remote database")
+	}
+	db.mutex.Lock()
+	defer db.mutex.Unlock()
+	obj, err := db.findObject(target)
+	if err != nil {
+		return false, err
+	}
+	return C.grn_obj_is_locked(db.ctx, obj) != 0, nil
+}
+
+// RegisterPlugin() registers a plugin, such as "tokenizers/mecab", so that
+// its objects, such as TokenMecab, are available.
+// For a remote database, the plugin_register command is executed instead.
+// An error is returned if the plugin is not found or fails to load.
+func (db *DB) RegisterPlugin(name string) error {
+	if name == "" {
+		return fmt.Errorf("empty plugin name")
+	}
+	if db.remote {
+		_, err := db.queryEx("plugin_register", map[string]string{"name": name})
+		return err
+	}
+	if err := db.checkWritable(); err != nil {
+		return err
+	}
+	cName := C.CString(name)
+	defer C.free(unsafe.Pointer(cName))
+	db.mutex.Lock()
+	defer db.mutex.Unlock()
+	if rc := C.grn_plugin_register(db.ctx, cName); rc != C.GRN_SUCCESS {
+		return newGroongaError(db.ctx, "grn_plugin_register()", rc)
+	}
+	return nil
+}
+
+// Defrag() defragments the whole database and returns the number of
+// defragmented segments.
+// Note that Defrag() blocks other operations on the DB until Groonga
+// finishes, so it should be called off the hot path.
+func (db *DB) Defrag(options *DefragOptions) (int, error) {
+	if db.remote {
+		return 0, fmt.Errorf("not available for a remote database")
+	}
+	if err := db.checkWritable(); err != nil {
+		return 0, err
+	}
+	db.mutex.Lock()
+	defer db.mutex.Unlock()
+	return db.defrag(db.obj, options)
+}
+
+// HasTable() returns whether a table exists or not.
+// Unlike FindTable(), HasTable() does not cache the table.
+func (db *DB) HasTable(name string) bool {
+	if db.remote {
+		entries, err := db.listTables()
+		if err != nil {
+			return false
+		}
+		for _, entry := range entries {
+			if entry.name == name {
+				return true
+			}
+		}
+		return false
+	}
+	db.mutex.Lock()
+	defer db.mutex.Unlock()
+	if _, ok := db.tables[name]; ok {
+		return true
+	}
+	nameBytes := []byte(name)
+	var cName *C.char
+	if len(nameBytes) != 0 {
+		cName = (*C.char)(unsafe.Pointer(&nameBytes[0]))
+	}
+	return C.grngo_find_table(db.ctx, cName, C.int(len(nameBytes))) != nil
+}
+
+// RemoveTable() removes a table.
+// Cached tables and columns referring to the table are also removed from the
+// cache, so FindTable() and FindColumn() never return removed objects.
+// The Table and Column objects of the removed table and of index columns
+// removed with it are no longer available after RemoveTable().
+func (db *DB) RemoveTable(name string) error {
+	table, err := db.FindTable(name)
+	if err != nil {
+		return err
+	}
+	if table.temporary {
+		return db.removeTemporaryTable(table)
+	}
+	ids := db.cachedColumnIDs()
+	bytes, err := db.queryEx("table_remove", map[string]string{"name": name})
+	if err != nil {
+		return err
+	}
+	if string(bytes) != "true" {
+		return fmt.Errorf("table_remove failed: name = <%s>", name)
+	}
+	db.mutex.Lock()
+	defer db.mutex.Unlock()
+	db.evictTable(table)
+	db.evictColumns(ids)
+	// The object has been removed and so must not be unlinked.
+	table.obj = nil
+	return nil
+}
+
+// removeTemporaryTable() closes a temporary table and removes it and its
+// cached columns from the cache.
+func (db *DB) removeTemporaryTable(table *Table) error {
+	db.mutex.Lock()
+	defer db.mutex.Unlock()
+	if table.obj == nil {
+		return fmt.Errorf("table released: name = <%s>", table.name)
+	}
+	if cached, ok := db.tables[table.name]; ok && (cached == table) {
+		db.evictTable(table)
+	}
+	// release() only releases the cached columns because obj is detached.
+	obj := table.obj
+	table.obj = nil
+	table.release()
+	if rc := C.grn_obj_close(db.ctx, obj); rc != C.GRN_SUCCESS {
+		return newGroongaError(db.ctx, "grn_obj_close()", rc)
+	}
+	return nil
+}
+
+// InsertRow() inserts a row.
+func (db *DB) InsertRow(tableName string, key interface{}) (bool, uint32, error) {
+	table, err := db.FindTable(tableName)
+	if err != nil {
+		return false, NilID, err
+	}
+	return table.InsertRow(key)
+}
+
+// CreateColumn() creates a column.
+func (db *DB) CreateColumn(tableName, columnName string, valueType string,
+	options *ColumnOptions) (*Column, error) {
+	table, err := db.FindTable(tableName)
+	if err != nil {
+		return nil, err
+	}
+	return table.CreateColumn(columnName, valueType, options)
+}
+
+// FindColumn() finds a column.
+func (db *DB) FindColumn(tableName, columnName string) (*Column, error) {
+	table, err := db.FindTable(tableName)
+	if err != nil {
+		return nil, err
+	}
+	return table.FindColumn(columnName)
+}
+
+// SetCacheLimit() sets the maximum number of cached select results by the
+// cache_limit command. Zero disables the cache.
+func (db *DB) SetCacheLimit(n int) error {
+	if n < 0 {
+		return fmt.Errorf("invalid cache limit: n = %d", n)
+	}
+	_, err := db.queryEx("cache_limit", map[string]string{"max": strconv.Itoa(n)})
+	return err
+}
+
+// -- Status --
+
+// Status is the result of the status command.
+// Fields which are not returned by the server, such as NQueries of old
+// versions of Groonga, are left zero.
+type Status struct {
+	AllocCount            int64         // The number of allocated memory blocks.
+	StartTime             time.Time     // The time when the server started.
+	Uptime                time.Duration // The elapsed time since StartTime.
+	NQueries              int64         // The number of processed queries.
+	CacheHitRate          float64       // The percentage of cache hits.
+	Version               string        // The version of Groonga.
+	CommandVersion        int           // The current command version.
+	DefaultCommandVersion int           // The default command version.
+	MaxCommandVersion     int           // The maximum command version.
+}
+
+// parseStatus() parses the JSON result of status.
+// Unknown fields are ignored.
+func parseStatus(result []byte) (*Status, error) {
+	decoder := json.NewDecoder(bytes.NewReader(result))
+	decoder.UseNumber()
+	var fields map[string]interface{}
+	if err := decoder.Decode(&fields); err != nil {
+		return nil, fmt.Errorf("json.Decoder.Decode() failed: %v", err)
+	}
+	var status Status
+	ints := []struct {
+		name  string
+		value *int64
+	}{
+		{"alloc_count", &status.AllocCount},
+		{"n_queries", &status.NQueries},
+	}
+	for _, field := range ints {
+		if value, ok := fields[field.name]; ok {
+			n, err := jsonToInt(value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid %s: %v", field.name, err)
+			}
+			*field.value = n
+		}
+	}
+	versions := []struct {
+		name  string
+		value *int
+	}{
+		{"command_version", &status.CommandVersion},
+		{"default_command_version", &status.DefaultCommandVersion},
+		{"max_command_version", &status.MaxCommandVersion},
+	}
+	for _, field := range versions {
+		if value, ok := fields[field.name]; ok {
+			n, err := jsonToInt(value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid %s: %v", field.name, err)
+			}
+			*field.value = int(n)
+		}
+	}
+	// starttime is replaced with start_time in newer versions.
+	for _, name := range []string{"start_time", "starttime"} {
+		if value, ok := fields[name]; ok {
+			sec, err := jsonToInt(value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid %s: %v", name, err)
+			}
+			status.StartTime = time.Unix(sec, 0)
+			break
+		}
+	}
+	if value, ok := fields["uptime"]; ok {
+		sec, err := jsonToInt(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid uptime: %v", err)
+		}
+		status.Uptime = time.Duration(sec) * time.Second
+	}
+	if value, ok := fields["cache_hit_rate"]; ok {
+		rate, err := jsonToFloat(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cache_hit_rate: %v", err)
+		}
+		status.CacheHitRate = rate
+	}
+	if value, ok := fields["version"]; ok {
+		version, ok := value.(string)
+		if !ok {
+			return nil, fmt.Errorf("invalid version: value = %v", value)
+		}
+		status.Version = version
+	}
+	return &status, nil
+}
+
+// Status() executes the status command and returns the result.
+func (db *DB) Status() (*Status, error) {
+	result, err := db.queryEx("status", nil)
+	if err != nil {
+		return nil, err
+	}
+	return parseStatus(result)
+}
+
+// -- QueryLog --
+
+// Constants for DB.EnableQueryLog().
+type QueryLogFlags int
+
+const (
+	NoQueryLog          = QueryLogFlags(C.GRN_QUERY_LOG_NONE)
+	CommandQueryLog     = QueryLogFlags(C.GRN_QUERY_LOG_COMMAND)     // Commands
+	ResultCodeQueryLog  = QueryLogFlags(C.GRN_QUERY_LOG_RESULT_CODE) // Elapsed time and rc
+	DestinationQueryLog = QueryLogFlags(C.GRN_QUERY_LOG_DESTINATION) // Progress, such as select(N)
+	CacheQueryLog       = QueryLogFlags(C.GRN_QUERY_LOG_CACHE)       // Cache hits
+	SizeQueryLog        = QueryLogFlags(C.GRN_QUERY_LOG_SIZE)        // Result sizes
+	ScoreQueryLog       = QueryLogFlags(C.GRN_QUERY_LOG_SCORE)       // Scores
+	AllQueryLog         = QueryLogFlags(C.GRN_QUERY_LOG_ALL)
+)
+
+// EnableQueryLog() writes the query log of Groonga into the file at path.
+// flags selects the categories of messages, such as
+// CommandQueryLog|ResultCodeQueryLog|DestinationQueryLog.
+// Note that the query log is shared by all the local databases in the
+// process, because Groonga has only one query logger.
+func (db *DB) EnableQueryLog(path string, flags QueryLogFlags) error {
+	if db.remote {
+		return fmt.Errorf("not available for a remote database")
+	}
+	if path == "" {
+		return fmt.Errorf("empty query log path")
+	}
+	if (flags & ^AllQueryLog) != 0 {
+		return fmt.Errorf("undefined query log flags: flags = %#x", int(flags))
+	}
+	logMutex.Lock()
+	defer logMutex.Unlock()
+	cPath := C.CString(path)
+	C.grn_default_query_logger_set_flags(C.uint(flags))
+	C.grn_default_query_logger_set_path(cPath)
+	if queryLogPath != nil {
+		C.free(unsafe.Pointer(queryLogPath))
+	}
+	queryLogPath = cPath
+	C.grn_query_logger_reopen(&C.grn_gctx)
+	return nil
+}
+
+// QueryLogEntry is a command in the query log.
+type QueryLogEntry struct {
+	Time      time.Time     // The time when the command started.
+	ContextID string        // The ID of the context, such as "0x7fff5fbff350".
+	Command   string        // The command, such as "select Table".
+	Elapsed   time.Duration // The elapsed time.
+	NHits     int           // The number of hits of select, or -1 if unknown.
+	RC        int           // The return code of the command.
+}
+
+// queryLogTimeLayout is the layout of timestamps in the query log.
+const queryLogTimeLayout = "2006-01-02 15:04:05.000000"
+
+// ParseQueryLog() parses the query log written by Groonga, such as
+// "2011-07-05 06:25:19.458756|0x7fff5fbff350|>select Shops", and returns the
+// finished commands in the order of completion.
+// Commands without the result line, which requires ResultCodeQueryLog, are
+// not returned.
+// NHits is taken from "select(N)", or "filter(N)" if it is missing, so it
+// requires DestinationQueryLog.
+func ParseQueryLog(r io.Reader) ([]QueryLogEntry, error) {
+	var entries []QueryLogEntry
+	running := make(map[string]*QueryLogEntry)
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 16*1024*1024)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := scanner.Text()
+		if line == "" {
+			continue
+		}
+		fields := strings.SplitN(line, "|", 3)
+		if (len(fields) != 3) || (fields[2] == "") {
+			return nil, fmt.Errorf("invalid query log: line = %d", lineNo)
+		}
+		timestamp, contextID, message := fields[0], fields[1], fields[2]
+		switch message[0] {
+		case '>':
+			t, err := time.ParseInLocation(queryLogTimeLayout, timestamp,
+				time.Local)
+			if err != nil {
+				return nil, fmt.Errorf("invalid timestamp: line = %d, err = %v",
+					lineNo, err)
+			}
+			running[contextID] = &QueryLogEntry{
+				Time:      t,
+				ContextID: contextID,
+				Command:   message[1:],
+				NHits:     -1,
+			}
+		case ':':
+			entry, ok := running[contextID]
+			if !ok {
+				continue
+			}
+			// ":000000000072779 select(19)"
+			pos := strings.IndexByte(message, ' ')
+			if pos == -1 {
+				continue
+			}
+			progress := message[pos+1:]
+			for _, name := range []string{"select(", "filter("} {
+				if !strings.HasPrefix(progress, name) {
+					continue
+				}
+				end := strings.IndexByte(progress, ')')
+				if end == -1 {
+					break
+				}
+				n, err := strconv.Atoi(progress[len(name):end])
+				if err != nil {
+					return nil, fmt.Errorf("invalid progress: line = %d, err = %v",
+						lineNo, err)
+				}
+				if (name == "select(") || (entry.NHits == -1) {
+					entry.NHits = n
+				}
+			}
+		case '<':
+			entry, ok := running[contextID]
+			if !ok {
+				continue
+			}
+			delete(running, contextID)
+			// "<000000000252718 rc=0"
+			parts := strings.Fields(message[1:])
+			if len(parts) == 0 {
+				return nil, fmt.Errorf("invalid result: line = %d", lineNo)
+			}
+			elapsed, err := strconv.ParseInt(parts[0], 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid elapsed time: line = %d, err = %v",
+					lineNo, err)
+			}
+			entry.Elapsed = time.Duration(elapsed)
+			for _, part := range parts[1:] {
+				if strings.HasPrefix(part, "rc=") {
+					if entry.RC, err = strconv.Atoi(part[len("rc="):]); err != nil {
+						return nil, fmt.Errorf("invalid rc: line = %d, err = %v",
+							lineNo, err)
+					}
+				}
+			}
+			entries = append(entries, *entry)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// -- Schema --
+
+// SchemaTable describes the definition of a table in a Schema.
+type SchemaTable struct {
+	Name         string
+	TableType    TableType
+	KeyType      DataType // The key type of KeyTable for a reference.
+	KeyTable     string   // The referenced table, or empty.
+	ValueType    DataType // The key type of ValueTable for a reference.
+	ValueTable   string   // The referenced table, or empty.
+	Tokenizer    string
+	Normalizer   string
+	TokenFilters []string
+	Columns      []ColumnSchema // Sorted by name.
+}
+
+// Schema is a snapshot of the schema of a database.
+type Schema struct {
+	Tables []SchemaTable // Sorted by name.
+}
+
+// Table() returns the definition of a table.
+// nil is returned if there is no such table.
+func (schema *Schema) Table(name string) *SchemaTable {
+	for i := range schema.Tables {
+		if schema.Tables[i].Name == name {
+			return &schema.Tables[i]
+		}
+	}
+	return nil
+}
+
+// localTableNames() returns the names of the tables by enumerating the
+// objects in the database. Names starting with "_" are excluded.
+func (db *DB) localTableNames() ([]string, error) {
+	db.mutex.Lock()
+	defer db.mutex.Unlock()
+	var n C.size_t
+	if ok := C.grngo_db_get_table_ids(db.ctx, nil, &n); ok != C.GRN_TRUE {
+		return nil, fmt.Errorf("grngo_db_get_table_ids() failed")
+	}
+	names := make([]string, 0, int(n))
+	if n == 0 {
+		return names, nil
+	}
+	ids := make([]C.grn_id, int(n))
+	if ok := C.grngo_db_get_table_ids(db.ctx, &ids[0], &n); ok != C.GRN_TRUE {
+		return nil, fmt.Errorf("grngo_db_get_table_ids() failed")
+	}
+	if int(n) < len(ids) {
+		ids = ids[:int(n)]
+	}
+	for _, id := range ids {
+		name, err := db.objName(C.grn_ctx_at(db.ctx, id))
+		if err != nil {
+			return nil, err
+		}
+		if !strings.HasPrefix(name, "_") {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// localColumnNames() returns the names of the columns of a table by
+// enumerating the objects in the database.
+func (table *Table) localColumnNames() ([]string, error) {
+	table.db.mutex.Lock()
+	defer table.db.mutex.Unlock()
+	ctx := table.db.ctx
+	var n C.size_t
+	if ok := C.grngo_table_get_column_ids(ctx, table.obj, nil, &n); ok != C.GRN_TRUE {
+		return nil, fmt.Errorf("grngo_table_get_column_ids() failed: table = <%s>",
+			table.name)
+	}
+	names := make([]string, 0, int(n))
+	if n == 0 {
+		return names, nil
+	}
+	ids := make([]C.grn_id, int(n))
+	if ok := C.grngo_table_get_column_ids(ctx, table.obj, &ids[0], &n); ok != C.GRN_TRUE {
+		return nil, fmt.Errorf("grngo_table_get_column_ids() failed: table = <%s>",
+			table.name)
+	}
+	if int(n) < len(ids) {
+		ids = ids[:int(n)]
+	}
+	for _, id := range ids {
+		name, err := table.db.objName(C.grn_ctx_at(ctx, id))
+		if err != nil {
+			return nil, err
+		}
+		names = append(names, strings.TrimPrefix(name, table.name+"."))
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// schema() returns the definition of the table.
+func (table *Table) schema() (*SchemaTable, error) {
+	info := &SchemaTable{
+		Name:      table.name,
+		KeyType:   table.keyType,
+		ValueType: table.valueType,
+	}
+	table.db.mutex.Lock()
+	switch table.obj.header._type {
+	case C.GRN_TABLE_HASH_KEY:
+		info.TableType = HashTable
+	case C.GRN_TABLE_PAT_KEY:
+		info.TableType = PatTable
+	case C.GRN_TABLE_DAT_KEY:
+		info.TableType = DatTable
+	default:
+		info.TableType = ArrayTable
+	}
+	table.db.mutex.Unlock()
+	if table.keyTable != nil {
+		info.KeyTable = table.keyTable.name
+	}
+	if table.valueTable != nil {
+		info.ValueTable = table.valueTable.name
+	}
+	var err error
+	if info.Tokenizer, err = table.Tokenizer(); err != nil {
+		return nil, err
+	}
+	if info.Normalizer, err = table.Normalizer(); err != nil {
+		return nil, err
+	}
+	if info.TokenFilters, err = table.TokenFilters(); err != nil {
+		return nil, err
+	}
+	names, err := table.localColumnNames()
+	if err != nil {
+		return nil, err
+	}
+	info.Columns = make([]ColumnSchema, len(names))
+	for i, name := range names {
+		column, err := table.FindColumn(name)
+		if err != nil {
+			return nil, err
+		}
+		if info.Columns[i], err = column.schema(); err != nil {
+			return nil, err
+		}
+	}
+	return info, nil
+}
+
+// schema() returns the definition of the column.
+func (column *Column) schema() (ColumnSchema, error) {
+	schema := ColumnSchema{
+		Name:      column.name,
+		ValueType: column.valueType,
+		IsVector:  column.isVector,
+		IsIndex:   column.isIndex,
+	}
+	db := column.table.db
+	db.mutex.Lock()
+	if column.obj == nil {
+		db.mutex.Unlock()
+		return schema, fmt.Errorf("column removed: name = <%s>", column.name)
+	}
+	schema.CompressionType = column.compression()
+	rangeObj := C.grn_ctx_at(db.ctx, C.grn_obj_get_range(db.ctx, column.obj))
+	var err error
+	if rangeObj != nil {
+		schema.TypeName, err = db.objName(rangeObj)
+	}
+	db.mutex.Unlock()
+	if err != nil {
+		return schema, err
+	}
+	if column.isIndex {
+		if schema.Sources, err = column.IndexSources(); err != nil {
+			return schema, err
+		}
+	}
+	return schema, nil
+}
+
+// Schema() returns a snapshot of the schema, that is all the tables and
+// their columns, by enumerating the objects in the database.
+// Tables and columns whose names start with "_" are excluded.
+func (db *DB) Schema() (*Schema, error) {
+	if db.remote {
+		return nil, fmt.Errorf("not available for a remote database")
+	}
+	names, err := db.localTableNames()
+	if err != nil {
+		return nil, err
+	}
+	schema := &Schema{Tables: make([]SchemaTable, len(names))}
+	for i, name := range names {
+		table, err := db.FindTable(name)
+		if err != nil {
+			return nil, err
+		}
+		info, err := table.schema()
+		if err != nil {
+			return nil, err
+		}
+		schema.Tables[i] = *info
+	}
+	return schema, nil
+}
+
+// -- Pool --
+
+// Pool is a fixed-size pool of DBs which share a Groonga database.
+// Each DB has its own grn_ctx, so operations on different DBs run in
+// parallel.
+// Note that the table and column caches are not shared, so objects removed
+// via a DB may still be cached by the others.
+type Pool struct {
+	dbs  []*DB // dbs[0] opens the database and the others attach to it.
+	idle chan *DB
+}
+
+// OpenPool() opens an existing Groonga database and returns a pool of size
+// DBs.
+func OpenPool(path string, size int) (*Pool, error) {
+	if size <= 0 {
+		return nil, fmt.Errorf("invalid pool size: size = %d", size)
+	}
+	owner, err := OpenDB(path)
+	if err != nil {
+		return nil, err
+	}
+	pool := &Pool{[]*DB{owner}, make(chan *DB, size)}
+	for len(pool.dbs) < size {
+		db, err := attachDB(owner)
+		if err != nil {
+			pool.Close()
+			return nil, err
+		}
+		pool.dbs = append(pool.dbs, db)
+	}
+	for _, db := range pool.dbs {
+		pool.idle <- db
+	}
+	return pool, nil
+}
+
+// attachDB() opens a new grn_ctx and attaches it to the database of owner.
+func attachDB(owner *DB) (*DB, error) {
+	ctx, err := openCtx()
+	if err != nil {
+		return nil, err
+	}
+	if rc := C.grn_ctx_use(ctx, owner.obj); rc != C.GRN_SUCCESS {
+		errMsg := C.GoString(&ctx.errbuf[0])
+		closeCtx(ctx)
+		return nil, fmt.Errorf("grn_ctx_use() failed: rc = %d, err = %s",
+			rc, errMsg)
+	}
+	db := newDB(ctx, owner.obj)
+	db.attached = true
+	return db, nil
+}
+
+// Size() returns the number of DBs in the pool.
+func (pool *Pool) Size() int {
+	return len(pool.dbs)
+}
+
+// Get() takes a DB from the pool.
+// If all the DBs are in use, Get() waits until one of them is returned by
+// Put().
+func (pool *Pool) Get() *DB {
+	return <-pool.idle
+}
+
+// Put() returns a DB taken by Get() to the pool.
+func (pool *Pool) Put(db *DB) {
+	pool.idle <- db
+}
+
+// Close() closes all the DBs in the pool.
+// All the DBs taken by Get() must be returned before Close().
+func (pool *Pool) Close() error {
+	var firstErr error
+	// The database must be closed after the attached contexts.
+	for i := len(pool.dbs) - 1; i >= 0; i-- {
+		if err := pool.dbs[i].Close(); (err != nil) && (firstErr == nil) {
+			firstErr = err
+		}
+	}
+	pool.dbs = nil
+	return firstErr
+}
+
+// -- Table --
+
+type Table struct {
+	db         *DB
+	obj        *C.grn_obj
+	name       string
+	keyType    DataType
+	keyTable   *Table
+	valueType  DataType
+	valueTable *Table
+	columns    map[string]*Column
+	temporary  bool // Created with TableOptions.Temporary.
 }
 
 // newTable() creates a new Table object.
@@ -566,772 +2996,5797 @@ func newTable(db *DB, obj *C.grn_obj, name string, keyType DataType,
 	return &table
 }
 
-// insertVoid() inserts an empty row.
-func (table *Table) insertVoid() (bool, uint32, error) {
-	if table.keyType != Void {
-		return false, NilID, fmt.Errorf("key type conflict")
+// Defrag() defragments the table like DB.Defrag().
+func (table *Table) Defrag(options *DefragOptions) (int, error) {
+	if err := table.checkLocal(); err != nil {
+		return 0, err
+	}
+	if err := table.db.checkWritable(); err != nil {
+		return 0, err
+	}
+	table.db.mutex.Lock()
+	defer table.db.mutex.Unlock()
+	return table.db.defrag(table.obj, options)
+}
+
+// Flags() returns the header flags of the table, such as TablePatKeyFlag and
+// KeyWithSISFlag.
+func (table *Table) Flags() (uint32, error) {
+	table.db.mutex.Lock()
+	defer table.db.mutex.Unlock()
+	if err := table.checkLocal(); err != nil {
+		return 0, err
+	}
+	return uint32(table.obj.header.flags), nil
+}
+
+// diskUsage() returns the disk usage of the table and its columns, and the
+// part of index columns.
+func (table *Table) diskUsage() (uint64, uint64, error) {
+	if err := table.checkLocal(); err != nil {
+		return 0, 0, err
+	}
+	table.db.mutex.Lock()
+	defer table.db.mutex.Unlock()
+	var usage, indexUsage C.uint64_t
+	if ok := C.grngo_table_get_disk_usage(table.db.ctx, table.obj, &usage,
+		&indexUsage); ok != C.GRN_TRUE {
+		return 0, 0, fmt.Errorf("grngo_table_get_disk_usage() failed")
+	}
+	return uint64(usage), uint64(indexUsage), nil
+}
+
+// DiskUsage() returns the disk usage of the table and its columns, including
+// index columns, in bytes.
+// Note that an index column belongs to the lexicon table, not to the table
+// of its source columns.
+func (table *Table) DiskUsage() (uint64, error) {
+	usage, _, err := table.diskUsage()
+	return usage, err
+}
+
+// IndexDiskUsage() returns the disk usage of the index columns of the table
+// in bytes, which is a part of DiskUsage().
+func (table *Table) IndexDiskUsage() (uint64, error) {
+	_, indexUsage, err := table.diskUsage()
+	return indexUsage, err
+}
+
+// Lock() acquires the lock of the table like DB.Lock().
+func (table *Table) Lock(timeout time.Duration) error {
+	if err := table.checkLocal(); err != nil {
+		return err
+	}
+	if err := table.db.checkWritable(); err != nil {
+		return err
+	}
+	table.db.mutex.Lock()
+	defer table.db.mutex.Unlock()
+	return table.db.lock(table.obj, timeout)
+}
+
+// Unlock() releases the lock acquired by Lock().
+func (table *Table) Unlock() error {
+	if err := table.checkLocal(); err != nil {
+		return err
+	}
+	if err := table.db.checkWritable(); err != nil {
+		return err
+	}
+	table.db.mutex.Lock()
+	defer table.db.mutex.Unlock()
+	return table.db.unlock(table.obj)
+}
+
+// KeyType() returns the key type of the table.
+// Void is returned if the table has no key, and the key type of the
+// referenced table is returned if the key is a reference.
+func (table *Table) KeyType() DataType {
+	return table.keyType
+}
+
+// ValueType() returns the value type of the table.
+// Void is returned if the table has no value.
+func (table *Table) ValueType() DataType {
+	return table.valueType
+}
+
+// KeyReference() returns the table referred to by the key.
+// nil is returned if the key is not a reference.
+func (table *Table) KeyReference() *Table {
+	return table.keyTable
+}
+
+// ValueReference() returns the table referred to by the value.
+// nil is returned if the value is not a reference.
+func (table *Table) ValueReference() *Table {
+	return table.valueTable
+}
+
+// objName() returns the name of obj.
+func (db *DB) objName(obj *C.grn_obj) (string, error) {
+	cName := C.grngo_obj_get_name(db.ctx, obj)
+	if cName == nil {
+		return "", fmt.Errorf("grngo_obj_get_name() failed")
+	}
+	defer C.free(unsafe.Pointer(cName))
+	return C.GoString(cName), nil
+}
+
+// valueColumn() returns a Column that wraps the table itself, so that
+// grn_obj_get_value() and grn_obj_set_value() access the values stored in
+// the table. The Column is not cached and must not be released.
+// The caller must hold db.mutex.
+func (table *Table) valueColumn() (*Column, error) {
+	if err := table.checkLocal(); err != nil {
+		return nil, err
+	}
+	if table.valueType == Void {
+		return nil, fmt.Errorf("table has no value: table = <%s>", table.name)
+	}
+	return newColumn(table, table.obj, "_value", table.valueType, false,
+		table.valueTable), nil
+}
+
+// GetRowValue() gets the value of a row, that is stored in the table.
+// The value types are the same as Column.GetValue().
+// An error is returned if the table has no value type.
+func (table *Table) GetRowValue(id uint32) (interface{}, error) {
+	table.db.mutex.Lock()
+	defer table.db.mutex.Unlock()
+	column, err := table.valueColumn()
+	if err != nil {
+		return nil, err
+	}
+	return column.getValue(id)
+}
+
+// SetRowValue() assigns the value of a row, that is stored in the table.
+// The value types are the same as Column.SetValue().
+// An error is returned if the table has no value type.
+func (table *Table) SetRowValue(id uint32, value interface{}) error {
+	table.db.mutex.Lock()
+	defer table.db.mutex.Unlock()
+	column, err := table.valueColumn()
+	if err != nil {
+		return err
+	}
+	return column.setValue(id, value)
+}
+
+// Tokenizer() returns the name of the default tokenizer, such as
+// "TokenBigram". An empty string is returned if not set.
+func (table *Table) Tokenizer() (string, error) {
+	if err := table.checkLocal(); err != nil {
+		return "", err
+	}
+	table.db.mutex.Lock()
+	defer table.db.mutex.Unlock()
+	obj := C.grngo_table_get_tokenizer(table.db.ctx, table.obj)
+	if obj == nil {
+		return "", nil
+	}
+	return table.db.objName(obj)
+}
+
+// Normalizer() returns the name of the normalizer, such as
+// "NormalizerAuto". An empty string is returned if not set.
+func (table *Table) Normalizer() (string, error) {
+	if err := table.checkLocal(); err != nil {
+		return "", err
+	}
+	table.db.mutex.Lock()
+	defer table.db.mutex.Unlock()
+	obj := C.grngo_table_get_normalizer(table.db.ctx, table.obj)
+	if obj == nil {
+		return "", nil
+	}
+	return table.db.objName(obj)
+}
+
+// TokenFilters() returns the names of the token filters, such as
+// "TokenFilterStopWord".
+func (table *Table) TokenFilters() ([]string, error) {
+	if err := table.checkLocal(); err != nil {
+		return nil, err
+	}
+	table.db.mutex.Lock()
+	defer table.db.mutex.Unlock()
+	ctx := table.db.ctx
+	var n C.size_t
+	if ok := C.grngo_table_get_token_filters(ctx, table.obj, nil, &n); ok != C.GRN_TRUE {
+		return nil, fmt.Errorf("grngo_table_get_token_filters() failed: table = <%s>",
+			table.name)
+	}
+	names := make([]string, 0, int(n))
+	if n == 0 {
+		return names, nil
+	}
+	filters := make([]*C.grn_obj, int(n))
+	if ok := C.grngo_table_get_token_filters(ctx, table.obj, &filters[0], &n); ok != C.GRN_TRUE {
+		return nil, fmt.Errorf("grngo_table_get_token_filters() failed: table = <%s>",
+			table.name)
+	}
+	if int(n) > len(filters) {
+		return nil, fmt.Errorf("token filters changed: table = <%s>", table.name)
+	}
+	for _, filter := range filters[:int(n)] {
+		name, err := table.db.objName(filter)
+		if err != nil {
+			return nil, err
+		}
+		names = append(names, name)
+	}
+	return names, nil
+}
+
+// checkLocal() returns an error if the table belongs to a remote database.
+func (table *Table) checkLocal() error {
+	if table.db.remote {
+		return fmt.Errorf("not supported by remote database: table = <%s>",
+			table.name)
+	}
+	if table.obj == nil {
+		return fmt.Errorf("table released: name = <%s>", table.name)
+	}
+	return nil
+}
+
+// checkReference() returns an error if ref, the key or value table of the
+// table, has been released. Handles keep referring to a released table even
+// after Table.Release() evicts them from the cache.
+func (table *Table) checkReference(ref *Table) error {
+	if ref.obj == nil {
+		return fmt.Errorf("referenced table released: table = <%s>, referenced = <%s>",
+			table.name, ref.name)
+	}
+	return nil
+}
+
+// release() releases the table and its cached columns.
+// The caller must hold db.mutex.
+func (table *Table) release() {
+	for _, column := range table.columns {
+		column.release()
+	}
+	table.columns = make(map[string]*Column)
+	if table.obj != nil {
+		C.grn_obj_unlink(table.db.ctx, table.obj)
+		table.obj = nil
+	}
+}
+
+// Release() releases the table and its cached columns, and removes them from
+// the cache. Tables referring to the table are also removed from the cache.
+// The Table and Column objects are no longer available after Release(), but
+// FindTable() and FindColumn() return new ones.
+// Note that the table itself is not removed from the database.
+func (table *Table) Release() {
+	db := table.db
+	db.mutex.Lock()
+	defer db.mutex.Unlock()
+	if cached, ok := db.tables[table.name]; ok && (cached == table) {
+		db.evictTable(table)
+	}
+	table.release()
+}
+
+// insertVoid() inserts an empty row.
+func (table *Table) insertVoid() (bool, uint32, error) {
+	if table.keyType != Void {
+		return false, NilID, fmt.Errorf("key type conflict")
+	}
+	rowInfo := C.grngo_table_insert_void(table.db.ctx, table.obj)
+	if rowInfo.id == C.GRN_ID_NIL {
+		return false, NilID, fmt.Errorf("grngo_table_insert_void() failed")
+	}
+	return rowInfo.inserted == C.GRN_TRUE, uint32(rowInfo.id), nil
+}
+
+// insertBool() inserts a row with Bool key.
+func (table *Table) insertBool(key bool) (bool, uint32, error) {
+	if table.keyType != Bool {
+		return false, NilID, fmt.Errorf("key type conflict")
+	}
+	grnKey := C.grn_bool(C.GRN_FALSE)
+	if key {
+		grnKey = C.grn_bool(C.GRN_TRUE)
+	}
+	rowInfo := C.grngo_table_insert_bool(table.db.ctx, table.obj, grnKey)
+	if rowInfo.id == C.GRN_ID_NIL {
+		return false, NilID, fmt.Errorf("grngo_table_insert_bool() failed")
+	}
+	return rowInfo.inserted == C.GRN_TRUE, uint32(rowInfo.id), nil
+}
+
+// insertInt() inserts a row with Int key.
+func (table *Table) insertInt(key int64) (bool, uint32, error) {
+	var rowInfo C.grngo_row_info
+	switch table.keyType {
+	case Int8:
+		grnKey := C.int8_t(key)
+		rowInfo = C.grngo_table_insert_int8(table.db.ctx, table.obj, grnKey)
+	case Int16:
+		grnKey := C.int16_t(key)
+		rowInfo = C.grngo_table_insert_int16(table.db.ctx, table.obj, grnKey)
+	case Int32:
+		grnKey := C.int32_t(key)
+		rowInfo = C.grngo_table_insert_int32(table.db.ctx, table.obj, grnKey)
+	case Int64:
+		grnKey := C.int64_t(key)
+		rowInfo = C.grngo_table_insert_int64(table.db.ctx, table.obj, grnKey)
+	case UInt8:
+		grnKey := C.uint8_t(key)
+		rowInfo = C.grngo_table_insert_uint8(table.db.ctx, table.obj, grnKey)
+	case UInt16:
+		grnKey := C.uint16_t(key)
+		rowInfo = C.grngo_table_insert_uint16(table.db.ctx, table.obj, grnKey)
+	case UInt32:
+		grnKey := C.uint32_t(key)
+		rowInfo = C.grngo_table_insert_uint32(table.db.ctx, table.obj, grnKey)
+	case UInt64:
+		grnKey := C.uint64_t(key)
+		rowInfo = C.grngo_table_insert_uint64(table.db.ctx, table.obj, grnKey)
+	default:
+		return false, NilID, fmt.Errorf("key type conflict")
+	}
+	if rowInfo.id == C.GRN_ID_NIL {
+		return false, NilID, fmt.Errorf("grngo_table_insert_int*() failed")
+	}
+	return rowInfo.inserted == C.GRN_TRUE, uint32(rowInfo.id), nil
+}
+
+// insertFloat() inserts a row with Float key.
+func (table *Table) insertFloat(key float64) (bool, uint32, error) {
+	if table.keyType != Float {
+		return false, NilID, fmt.Errorf("key type conflict")
+	}
+	grnKey := C.double(key)
+	rowInfo := C.grngo_table_insert_float(table.db.ctx, table.obj, grnKey)
+	if rowInfo.id == C.GRN_ID_NIL {
+		return false, NilID, fmt.Errorf("grngo_table_insert_float() failed")
+	}
+	return rowInfo.inserted == C.GRN_TRUE, uint32(rowInfo.id), nil
+}
+
+// insertTime() inserts a row with Time key.
+func (table *Table) insertTime(key time.Time) (bool, uint32, error) {
+	if table.keyType != Time {
+		return false, NilID, fmt.Errorf("key type conflict")
+	}
+	grnKey := C.int64_t(timeToGrnTime(key))
+	rowInfo := C.grngo_table_insert_time(table.db.ctx, table.obj, grnKey)
+	if rowInfo.id == C.GRN_ID_NIL {
+		return false, NilID, fmt.Errorf("grngo_table_insert_time() failed")
+	}
+	return rowInfo.inserted == C.GRN_TRUE, uint32(rowInfo.id), nil
+}
+
+// insertGeoPoint() inserts a row with GeoPoint key.
+func (table *Table) insertGeoPoint(key GeoPoint) (bool, uint32, error) {
+	switch table.keyType {
+	case TokyoGeoPoint, WGS84GeoPoint:
+	default:
+		return false, NilID, fmt.Errorf("key type conflict")
+	}
+	grnKey := C.grn_geo_point{C.int(key.Latitude), C.int(key.Longitude)}
+	rowInfo := C.grngo_table_insert_geo_point(table.db.ctx, table.obj, grnKey)
+	if rowInfo.id == C.GRN_ID_NIL {
+		return false, NilID, fmt.Errorf("grngo_table_insert_geo_point() failed")
+	}
+	return rowInfo.inserted == C.GRN_TRUE, uint32(rowInfo.id), nil
+}
+
+// insertText() inserts a row with Text key.
+func (table *Table) insertText(key []byte) (bool, uint32, error) {
+	if table.keyType != ShortText {
+		return false, NilID, fmt.Errorf("key type conflict")
+	}
+	var grnKey C.grngo_text
+	if len(key) != 0 {
+		grnKey.ptr = (*C.char)(unsafe.Pointer(&key[0]))
+		grnKey.size = C.size_t(len(key))
+	}
+	rowInfo := C.grngo_table_insert_text(table.db.ctx, table.obj, &grnKey)
+	if rowInfo.id == C.GRN_ID_NIL {
+		return false, NilID, fmt.Errorf("grngo_table_insert_text() failed")
+	}
+	return rowInfo.inserted == C.GRN_TRUE, uint32(rowInfo.id), nil
+}
+
+// insertRef() inserts a row with a reference key.
+func (table *Table) insertRef(key uint32) (bool, uint32, error) {
+	if table.keyTable == nil {
+		return false, NilID, fmt.Errorf("key type conflict")
+	}
+	if err := table.checkReference(table.keyTable); err != nil {
+		return false, NilID, err
+	}
+	ctx := table.db.ctx
+	if C.grn_table_at(ctx, table.keyTable.obj, C.grn_id(key)) == C.GRN_ID_NIL {
+		return false, NilID, fmt.Errorf("%w: table = <%s>, id = %d",
+			ErrRowNotFound, table.keyTable.name, key)
+	}
+	rowInfo := C.grngo_table_insert_ref(ctx, table.obj, C.grn_id(key))
+	if rowInfo.id == C.GRN_ID_NIL {
+		return false, NilID, fmt.Errorf("grngo_table_insert_ref() failed")
+	}
+	return rowInfo.inserted == C.GRN_TRUE, uint32(rowInfo.id), nil
+}
+
+// InsertRow() inserts a row.
+// The first return value specifies whether a row is inserted or not.
+// The second return value is the ID of the inserted or found row.
+// If the key of the table is a reference, a uint32 key is the ID of a row in
+// the referenced table.
+// Keys of the other integer types, such as int and uint16, and float32 keys
+// are converted by normalizeKey().
+func (table *Table) InsertRow(key interface{}) (bool, uint32, error) {
+	if err := table.checkLocal(); err != nil {
+		return false, NilID, err
+	}
+	if err := table.db.checkWritable(); err != nil {
+		return false, NilID, err
+	}
+	key, err := table.normalizeKey(key)
+	if err != nil {
+		return false, NilID, err
+	}
+	table.db.mutex.Lock()
+	defer table.db.mutex.Unlock()
+	return table.insertRow(key)
+}
+
+// insertRow() inserts a row with a key converted by normalizeKey().
+// The caller must hold db.mutex.
+func (table *Table) insertRow(key interface{}) (bool, uint32, error) {
+	switch value := key.(type) {
+	case nil:
+		return table.insertVoid()
+	case bool:
+		return table.insertBool(value)
+	case int64:
+		return table.insertInt(value)
+	case uint32:
+		return table.insertRef(value)
+	case float64:
+		return table.insertFloat(value)
+	case time.Time:
+		return table.insertTime(value)
+	case GeoPoint:
+		return table.insertGeoPoint(value)
+	case []byte:
+		return table.insertText(value)
+	default:
+		return false, NilID, fmt.Errorf(
+			"unsupported key type: typeName = <%s>", reflect.TypeOf(key).Name())
+	}
+}
+
+// InsertRows() inserts rows with keys in a single call into Groonga.
+// keys must be a slice of a key type supported by InsertRow(), such as
+// []int64, []time.Time, and [][]byte. ids[i] and inserted[i] are the results
+// of keys[i], as if InsertRow() is called for each key.
+// If an insertion fails, the results before it are returned with an error.
+func (table *Table) InsertRows(keys interface{}) (
+	ids []uint32, inserted []bool, err error) {
+	if err := table.checkLocal(); err != nil {
+		return nil, nil, err
+	}
+	if err := table.db.checkWritable(); err != nil {
+		return nil, nil, err
+	}
+	table.db.mutex.Lock()
+	defer table.db.mutex.Unlock()
+	if table.keyType == Void {
+		return nil, nil, fmt.Errorf("table has no key: table = <%s>", table.name)
+	}
+	slice := reflect.ValueOf(keys)
+	if slice.Kind() != reflect.Slice {
+		return nil, nil, fmt.Errorf("unsupported key type: type = %T", keys)
+	}
+	if slice.Len() == 0 {
+		return []uint32{}, []bool{}, nil
+	}
+	ctx := table.db.ctx
+	size := C.size_t(slice.Len())
+	rows := make([]C.grngo_row_info, slice.Len())
+	var n C.size_t
+	switch v := keys.(type) {
+	case []bool:
+		if table.keyType != Bool {
+			return nil, nil, fmt.Errorf("key type conflict")
+		}
+		grnKeys := make([]C.grn_bool, len(v))
+		for i := range v {
+			if v[i] {
+				grnKeys[i] = C.GRN_TRUE
+			}
+		}
+		n = C.grngo_table_insert_rows(ctx, table.obj, unsafe.Pointer(&grnKeys[0]),
+			C.size_t(unsafe.Sizeof(grnKeys[0])), size, &rows[0])
+	case []int64:
+		switch table.keyType {
+		case Int8, Int16, Int32, Int64, UInt8, UInt16, UInt32, UInt64:
+		default:
+			return nil, nil, fmt.Errorf("key type conflict")
+		}
+		n = C.grngo_table_insert_ints(ctx, table.obj,
+			C.grn_builtin_type(table.keyType),
+			(*C.int64_t)(unsafe.Pointer(&v[0])), size, &rows[0])
+	case []float64:
+		if table.keyType != Float {
+			return nil, nil, fmt.Errorf("key type conflict")
+		}
+		grnKeys := make([]C.double, len(v))
+		for i := range v {
+			grnKeys[i] = C.double(v[i])
+		}
+		n = C.grngo_table_insert_rows(ctx, table.obj, unsafe.Pointer(&grnKeys[0]),
+			C.size_t(unsafe.Sizeof(grnKeys[0])), size, &rows[0])
+	case []time.Time:
+		if table.keyType != Time {
+			return nil, nil, fmt.Errorf("key type conflict")
+		}
+		grnKeys := make([]C.int64_t, len(v))
+		for i := range v {
+			grnKeys[i] = C.int64_t(timeToGrnTime(v[i]))
+		}
+		n = C.grngo_table_insert_rows(ctx, table.obj, unsafe.Pointer(&grnKeys[0]),
+			C.size_t(unsafe.Sizeof(grnKeys[0])), size, &rows[0])
+	case []GeoPoint:
+		switch table.keyType {
+		case TokyoGeoPoint, WGS84GeoPoint:
+		default:
+			return nil, nil, fmt.Errorf("key type conflict")
+		}
+		grnKeys := make([]C.grn_geo_point, len(v))
+		for i := range v {
+			grnKeys[i] = C.grn_geo_point{C.int(v[i].Latitude),
+				C.int(v[i].Longitude)}
+		}
+		n = C.grngo_table_insert_rows(ctx, table.obj, unsafe.Pointer(&grnKeys[0]),
+			C.size_t(unsafe.Sizeof(grnKeys[0])), size, &rows[0])
+	case [][]byte:
+		if table.keyType != ShortText {
+			return nil, nil, fmt.Errorf("key type conflict")
+		}
+		grnKeys := make([]C.grngo_text, len(v))
+		for i := range v {
+			if len(v[i]) != 0 {
+				grnKeys[i].ptr = (*C.char)(unsafe.Pointer(&v[i][0]))
+				grnKeys[i].size = C.size_t(len(v[i]))
+			}
+		}
+		n = C.grngo_table_insert_texts(ctx, table.obj, &grnKeys[0], size,
+			&rows[0])
+	default:
+		return nil, nil, fmt.Errorf("unsupported key type: type = %T", keys)
+	}
+	ids = make([]uint32, n)
+	inserted = make([]bool, n)
+	for i := range ids {
+		ids[i] = uint32(rows[i].id)
+		inserted[i] = rows[i].inserted == C.GRN_TRUE
+	}
+	if n != size {
+		return ids, inserted, fmt.Errorf(
+			"grngo_table_insert_*() failed: n = %d, key = %v", n, slice.Index(int(n)))
+	}
+	return ids, inserted, nil
+}
+
+// GetIDByKey() finds a row associated with the given key.
+// The supported key types are the same as InsertRow().
+// The second return value specifies whether the row is found or not.
+// If the row is not found, NilID and false are returned.
+func (table *Table) GetIDByKey(key interface{}) (uint32, bool, error) {
+	if err := table.checkLocal(); err != nil {
+		return NilID, false, err
+	}
+	table.db.mutex.Lock()
+	defer table.db.mutex.Unlock()
+	if table.keyType == Void {
+		return NilID, false, fmt.Errorf("table has no key: table = <%s>",
+			table.name)
+	}
+	grnKey, err := table.encodeKey(key)
+	if err != nil {
+		return NilID, false, err
+	}
+	var ptr unsafe.Pointer
+	if len(grnKey) != 0 {
+		ptr = unsafe.Pointer(&grnKey[0])
+	}
+	id := C.grn_table_get(table.db.ctx, table.obj, ptr, C.uint(len(grnKey)))
+	if id == C.GRN_ID_NIL {
+		return NilID, false, nil
+	}
+	return uint32(id), true, nil
+}
+
+// RemoveRow() removes a row.
+// RemoveRow() fails if the row does not exist.
+func (table *Table) RemoveRow(id uint32) error {
+	if err := table.checkLocal(); err != nil {
+		return err
+	}
+	if err := table.db.checkWritable(); err != nil {
+		return err
+	}
+	table.db.mutex.Lock()
+	defer table.db.mutex.Unlock()
+	if C.grn_table_at(table.db.ctx, table.obj, C.grn_id(id)) == C.GRN_ID_NIL {
+		return fmt.Errorf("%w: table = <%s>, id = %d", ErrRowNotFound, table.name, id)
+	}
+	if ok := C.grngo_table_delete_by_id(table.db.ctx, table.obj,
+		C.grn_id(id)); ok != C.GRN_TRUE {
+		return fmt.Errorf("grngo_table_delete_by_id() failed: id = %d", id)
+	}
+	return nil
+}
+
+// Len() returns the number of rows in the table.
+func (table *Table) Len() (uint32, error) {
+	if err := table.checkLocal(); err != nil {
+		return 0, err
+	}
+	table.db.mutex.Lock()
+	defer table.db.mutex.Unlock()
+	size := C.grn_table_size(table.db.ctx, table.obj)
+	if table.db.ctx.rc != C.GRN_SUCCESS {
+		errMsg := C.GoString(&table.db.ctx.errbuf[0])
+		return 0, fmt.Errorf("grn_table_size() failed: ctx.rc = %d, err = %s",
+			table.db.ctx.rc, errMsg)
+	}
+	return uint32(size), nil
+}
+
+// Truncate() removes all the rows.
+// Columns are kept and their values are cleared.
+func (table *Table) Truncate() error {
+	if err := table.checkLocal(); err != nil {
+		return err
+	}
+	if err := table.db.checkWritable(); err != nil {
+		return err
+	}
+	table.db.mutex.Lock()
+	defer table.db.mutex.Unlock()
+	if rc := C.grn_table_truncate(table.db.ctx, table.obj); rc != C.GRN_SUCCESS {
+		errMsg := C.GoString(&table.db.ctx.errbuf[0])
+		return fmt.Errorf("grn_table_truncate() failed: rc = %d, err = %s",
+			rc, errMsg)
+	}
+	return nil
+}
+
+// RemoveRowByKey() removes a row associated with the given key.
+// The supported key types are the same as InsertRow().
+// If the key does not exist, an error wrapping ErrRowNotFound is returned.
+func (table *Table) RemoveRowByKey(key interface{}) error {
+	if err := table.checkLocal(); err != nil {
+		return err
+	}
+	if err := table.db.checkWritable(); err != nil {
+		return err
+	}
+	table.db.mutex.Lock()
+	defer table.db.mutex.Unlock()
+	if table.keyType == Void {
+		return fmt.Errorf("table has no key: table = <%s>", table.name)
+	}
+	grnKey, err := table.encodeKey(key)
+	if err != nil {
+		return err
+	}
+	var ptr unsafe.Pointer
+	if len(grnKey) != 0 {
+		ptr = unsafe.Pointer(&grnKey[0])
+	}
+	ctx := table.db.ctx
+	rc := C.grngo_table_delete_row(ctx, table.obj, ptr, C.uint(len(grnKey)))
+	switch rc {
+	case C.GRN_SUCCESS:
+		return nil
+	case C.GRN_END_OF_DATA:
+		return fmt.Errorf("%w: table = <%s>, key = %v", ErrRowNotFound,
+			table.name, key)
+	default:
+		errMsg := C.GoString(&ctx.errbuf[0])
+		return fmt.Errorf("grngo_table_delete_row() failed: rc = %d, err = %s",
+			rc, errMsg)
+	}
+}
+
+// CreateColumn() creates a column.
+// If the column already exists, an error wrapping ErrAlreadyExists and the
+// GroongaError of column_create is returned.
+func (table *Table) CreateColumn(name string, valueType string,
+	options *ColumnOptions) (*Column, error) {
+	if err := table.checkLocal(); err != nil {
+		return nil, err
+	}
+	if err := table.db.checkWritable(); err != nil {
+		return nil, err
+	}
+	if table.temporary {
+		return nil, fmt.Errorf("not supported by temporary table: table = <%s>",
+			table.name)
+	}
+	if options == nil {
+		options = NewColumnOptions()
+	}
+	optionsMap := make(map[string]string)
+	optionsMap["table"] = table.name
+	optionsMap["name"] = name
+	if _, ok := ParseDataType(valueType); !ok {
+		if _, err := table.db.FindTable(valueType); err != nil {
+			return nil, fmt.Errorf("unsupported value type: valueType = %s",
+				valueType)
+		}
+	}
+	optionsMap["type"] = valueType
+	switch options.ColumnType {
+	case ScalarColumn:
+		optionsMap["flags"] = "COLUMN_SCALAR"
+	case VectorColumn:
+		optionsMap["flags"] = "COLUMN_VECTOR"
+	case IndexColumn:
+		optionsMap["flags"] = "COLUMN_INDEX"
+	default:
+		return nil, fmt.Errorf("undefined column type: options = %+v", options)
+	}
+	switch options.CompressionType {
+	case NoCompression:
+	case ZlibCompression:
+		optionsMap["flags"] += "|COMPRESS_ZLIB"
+	case LzoCompression:
+		optionsMap["flags"] += "|COMPRESS_LZO"
+	default:
+		return nil, fmt.Errorf("undefined compression type: options = %+v", options)
+	}
+	if options.WithSection {
+		optionsMap["flags"] += "|WITH_SECTION"
+	}
+	if options.WithWeight {
+		optionsMap["flags"] += "|WITH_WEIGHT"
+	}
+	if options.WithPosition {
+		optionsMap["flags"] += "|WITH_POSITION"
+	}
+	if options.Source != "" {
+		optionsMap["source"] = options.Source
+	}
+	bytes, err := table.db.queryEx("column_create", optionsMap)
+	if err != nil {
+		// Groonga has no dedicated code for an existing name.
+		if table.HasColumn(name) {
+			return nil, fmt.Errorf("%w: table = <%s>, name = <%s>: %w",
+				ErrAlreadyExists, table.name, name, err)
+		}
+		return nil, err
+	}
+	if string(bytes) != "true" {
+		return nil, fmt.Errorf("column_create failed: name = <%s>, result = %s",
+			name, bytes)
+	}
+	return table.FindColumn(name)
+}
+
+// HasColumn() returns whether a column exists or not.
+// Unlike FindColumn(), HasColumn() does not cache the column.
+func (table *Table) HasColumn(name string) bool {
+	if table.db.remote {
+		names, err := table.ColumnNames()
+		if err != nil {
+			return false
+		}
+		for _, columnName := range names {
+			if columnName == name {
+				return true
+			}
+		}
+		return false
+	}
+	table.db.mutex.Lock()
+	defer table.db.mutex.Unlock()
+	if table.obj == nil {
+		return false
+	}
+	if _, ok := table.columns[name]; ok {
+		return true
+	}
+	nameBytes := []byte(name)
+	var cName *C.char
+	if len(nameBytes) != 0 {
+		cName = (*C.char)(unsafe.Pointer(&nameBytes[0]))
+	}
+	obj := C.grn_obj_column(table.db.ctx, table.obj, cName, C.uint(len(nameBytes)))
+	if obj == nil {
+		return false
+	}
+	C.grn_obj_unlink(table.db.ctx, obj)
+	return true
+}
+
+// RemoveColumn() removes a column.
+func (table *Table) RemoveColumn(name string) error {
+	column, err := table.FindColumn(name)
+	if err != nil {
+		return err
+	}
+	return column.Remove()
+}
+
+// findColumn() finds a column without locking.
+func (table *Table) findColumn(name string) (*Column, error) {
+	if column, ok := table.columns[name]; ok {
+		return column, nil
+	}
+	nameBytes := []byte(name)
+	var cName *C.char
+	if len(nameBytes) != 0 {
+		cName = (*C.char)(unsafe.Pointer(&nameBytes[0]))
+	}
+	obj := C.grn_obj_column(table.db.ctx, table.obj, cName, C.uint(len(name)))
+	if obj == nil {
+		return nil, fmt.Errorf("grn_obj_column() failed: table = %+v, name = <%s>", table, name)
+	}
+	var valueType DataType
+	var valueTable *Table
+	var isVector bool
+	switch name {
+	case "_id":
+		valueType = UInt32
+	case "_key":
+		valueType = table.keyType
+		valueTable = table.keyTable
+	case "_value":
+		valueType = table.valueType
+		valueTable = table.valueTable
+	default:
+		if C.grngo_column_is_index(table.db.ctx, obj) == C.GRN_TRUE {
+			// An index column has no value type.
+			column := newColumn(table, obj, name, Void, false, nil)
+			column.isIndex = true
+			table.columns[name] = column
+			return column, nil
+		}
+		var valueInfo C.grngo_type_info
+		if ok := C.grngo_column_get_value_info(table.db.ctx, obj, &valueInfo); ok != C.GRN_TRUE {
+			return nil, fmt.Errorf("grngo_column_get_value_info() failed: name = <%s>",
+				name)
+		}
+		// Check the value type.
+		valueType = DataType(valueInfo.data_type)
+		isVector = valueInfo.dimension > 0
+		// Find the destination table if the value is table reference.
+		if valueInfo.ref_table != nil {
+			if valueType == Void {
+				return nil, fmt.Errorf("reference to void: name = <%s>", name)
+			}
+			cValueTableName := C.grngo_table_get_name(table.db.ctx, valueInfo.ref_table)
+			if cValueTableName == nil {
+				return nil, fmt.Errorf("grngo_table_get_name() failed")
+			}
+			defer C.free(unsafe.Pointer(cValueTableName))
+			var err error
+			valueTable, err = table.db.findTable(C.GoString(cValueTableName))
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	column := newColumn(table, obj, name, valueType, isVector, valueTable)
+	table.columns[name] = column
+	return column, nil
+}
+
+// FindColumn() finds a column.
+func (table *Table) FindColumn(name string) (*Column, error) {
+	if err := table.checkLocal(); err != nil {
+		return nil, err
+	}
+	table.db.mutex.Lock()
+	defer table.db.mutex.Unlock()
+	if column, ok := table.columns[name]; ok {
+		return column, nil
+	}
+	delimPos := strings.IndexByte(name, '.')
+	if delimPos == -1 {
+		return table.findColumn(name)
+	}
+	columnNames := strings.Split(name, ".")
+	column, err := table.findColumn(columnNames[0])
+	if err != nil {
+		return nil, err
+	}
+	// A segment may be _id, _key or _value at any depth, so the value type and
+	// the destination table are taken from the last segment.
+	isVector := column.isVector
+	for _, columnName := range columnNames[1:] {
+		if column.valueTable == nil {
+			return nil, fmt.Errorf("not table reference: column.name = <%s>", column.name)
+		}
+		refTable := column.valueTable
+		if err := column.table.checkReference(refTable); err != nil {
+			return nil, err
+		}
+		switch columnName {
+		case "_key":
+			if refTable.keyType == Void {
+				return nil, fmt.Errorf("table has no key: table = <%s>", refTable.name)
+			}
+		case "_value":
+			if refTable.valueType == Void {
+				return nil, fmt.Errorf("table has no value: table = <%s>", refTable.name)
+			}
+		}
+		column, err = refTable.findColumn(columnName)
+		if err != nil {
+			return nil, err
+		}
+		if column.isVector {
+			if isVector {
+				return nil, fmt.Errorf("vector of vector is not supported")
+			}
+			isVector = true
+		}
+	}
+	nameBytes := []byte(name)
+	var cName *C.char
+	if len(nameBytes) != 0 {
+		cName = (*C.char)(unsafe.Pointer(&nameBytes[0]))
+	}
+	obj := C.grn_obj_column(table.db.ctx, table.obj, cName, C.uint(len(name)))
+	if obj == nil {
+		return nil, fmt.Errorf("grn_obj_column() failed: name = <%s>", name)
+	}
+	column = newColumn(table, obj, name, column.valueType, isVector, column.valueTable)
+	table.columns[name] = column
+	return column, nil
+}
+
+// sortBy() returns --sortby for options.SortBy or options.SortKeys.
+// Columns in options.SortKeys must be valid names. For a local database, they
+// must also exist, except for pseudo columns, such as _key and _score.
+func (table *Table) sortBy(options *SelectOptions) (string, error) {
+	if len(options.SortKeys) == 0 {
+		return options.SortBy, nil
+	}
+	if options.SortBy != "" {
+		return "", fmt.Errorf("both SortBy and SortKeys are specified")
+	}
+	keys := make([]string, len(options.SortKeys))
+	for i, key := range options.SortKeys {
+		if key.Column == "" {
+			return "", fmt.Errorf("empty sort key: i = %d", i)
+		}
+		if err := checkFilterColumn(key.Column); err != nil {
+			return "", err
+		}
+		if !table.db.remote && !strings.HasPrefix(key.Column, "_") &&
+			!table.HasColumn(key.Column) {
+			return "", fmt.Errorf("column not found: table = <%s>, name = <%s>",
+				table.name, key.Column)
+		}
+		keys[i] = key.Column
+		if key.Descending {
+			keys[i] = "-" + key.Column
+		}
+	}
+	return strings.Join(keys, ","), nil
+}
+
+// Select() searches the table and returns the result.
+func (table *Table) Select(options *SelectOptions) (*Records, error) {
+	if options == nil {
+		options = NewSelectOptions()
+	}
+	optionsMap := make(map[string]string)
+	optionsMap["table"] = table.name
+	if options.Filter != "" {
+		optionsMap["filter"] = options.Filter
+	}
+	if options.Query != "" {
+		optionsMap["query"] = options.Query
+	}
+	if options.MatchColumns != "" {
+		optionsMap["match_columns"] = options.MatchColumns
+	}
+	sortBy, err := table.sortBy(options)
+	if err != nil {
+		return nil, err
+	}
+	if sortBy != "" {
+		optionsMap["sortby"] = sortBy
+	}
+	optionsMap["offset"] = strconv.Itoa(options.Offset)
+	optionsMap["limit"] = strconv.Itoa(options.Limit)
+	outputColumns := options.OutputColumns
+	if options.Query != "" {
+		// _score is always output for a full-text query.
+		if len(outputColumns) == 0 {
+			outputColumns = []string{"_id", "_key", "*", "_score"}
+		} else if !containsString(outputColumns, "_score") {
+			outputColumns = append(outputColumns[:len(outputColumns):len(outputColumns)],
+				"_score")
+		}
+	}
+	if len(outputColumns) != 0 {
+		optionsMap["output_columns"] = strings.Join(outputColumns, ",")
+	}
+	if len(options.Drilldowns) != 0 {
+		optionsMap["drilldown"] = strings.Join(options.Drilldowns, ",")
+	}
+	labels := make(map[string]bool)
+	for _, key := range options.Drilldowns {
+		labels[key] = true
+	}
+	for i := range options.DrilldownSpecs {
+		spec := &options.DrilldownSpecs[i]
+		label := spec.label()
+		switch {
+		case spec.Key == "":
+			return nil, fmt.Errorf("empty drilldown key: i = %d", i)
+		case !isDrilldownLabel(label):
+			return nil, fmt.Errorf("invalid drilldown label: label = <%s>", label)
+		case labels[label]:
+			return nil, fmt.Errorf("duplicate drilldown label: label = <%s>", label)
+		}
+		labels[label] = true
+		prefix := "drilldown[" + label + "]."
+		optionsMap[prefix+"keys"] = spec.Key
+		outputColumns := []string{"_key", "_nsubrecs"}
+		if len(spec.CalcTypes) != 0 {
+			if spec.CalcTarget == "" {
+				return nil, fmt.Errorf("empty calc target: label = <%s>", label)
+			}
+			calcTypes := make([]string, len(spec.CalcTypes))
+			for j, calcType := range spec.CalcTypes {
+				switch calcType {
+				case SumCalc, AvgCalc, MaxCalc, MinCalc:
+				default:
+					return nil, fmt.Errorf("undefined calc type: label = <%s>, calcType = %d",
+						label, calcType)
+				}
+				calcTypes[j] = calcType.String()
+				outputColumns = append(outputColumns, calcType.columnName())
+			}
+			optionsMap[prefix+"calc_target"] = spec.CalcTarget
+			optionsMap[prefix+"calc_types"] = strings.Join(calcTypes, ",")
+		}
+		optionsMap[prefix+"output_columns"] = strings.Join(outputColumns, ",")
+	}
+	switch options.CacheMode {
+	case DefaultCache:
+	case ReadCache:
+		optionsMap["cache"] = "yes"
+	case NoCache:
+		optionsMap["cache"] = "no"
+	default:
+		return nil, fmt.Errorf("undefined cache mode: options = %+v", options)
+	}
+	bytes, err := table.db.queryEx("select", optionsMap)
+	if err != nil {
+		return nil, err
+	}
+	return table.db.parseSelectResult(bytes, options.Drilldowns,
+		options.DrilldownSpecs)
+}
+
+// SelectCount() returns the number of rows matching options.Filter,
+// options.Query and options.MatchColumns.
+// No rows are transferred because the select is executed with --limit 0, and
+// the other options, such as SortBy and Drilldowns, are ignored.
+func (table *Table) SelectCount(options *SelectOptions) (int, error) {
+	newOptions := NewSelectOptions()
+	if options != nil {
+		newOptions.Filter = options.Filter
+		newOptions.Query = options.Query
+		newOptions.MatchColumns = options.MatchColumns
+		newOptions.CacheMode = options.CacheMode
+	}
+	newOptions.Limit = 0
+	newOptions.OutputColumns = []string{"_id"}
+	records, err := table.Select(newOptions)
+	if err != nil {
+		return 0, err
+	}
+	return records.NHits, nil
+}
+
+// SelectNearby() searches the table for rows whose GeoPoint column value is
+// within radius meters from center. The search uses geo_in_circle and is
+// combined with options.Filter if given.
+// center is interpreted in the datum of the column, TokyoGeoPoint or
+// WGS84GeoPoint, so it must be given in the same datum.
+func (table *Table) SelectNearby(columnName string, center GeoPoint,
+	radius float64, options *SelectOptions) (*Records, error) {
+	column, err := table.FindColumn(columnName)
+	if err != nil {
+		return nil, err
+	}
+	switch column.valueType {
+	case TokyoGeoPoint, WGS84GeoPoint:
+	default:
+		return nil, fmt.Errorf("not a GeoPoint column: name = <%s>, valueType = %s",
+			columnName, column.valueType)
+	}
+	if options == nil {
+		options = NewSelectOptions()
+	}
+	newOptions := *options
+	newOptions.Filter = fmt.Sprintf("geo_in_circle(%s, \"%s\", %s)",
+		columnName, center,
+		strconv.FormatFloat(radius, 'f', -1, 64))
+	if options.Filter != "" {
+		newOptions.Filter = fmt.Sprintf("(%s) && (%s)", newOptions.Filter,
+			options.Filter)
+	}
+	return table.Select(&newOptions)
+}
+
+// Apply() evaluates expr, such as "popularity * 2", for each row and stores
+// the result into the column specified by targetColumn.
+// The result is converted into the value type of the target column.
+// Apply() returns the number of updated rows, which is less than the number
+// of rows if an error occurs.
+func (table *Table) Apply(targetColumn string, expr string) (int, error) {
+	if err := table.checkLocal(); err != nil {
+		return 0, err
+	}
+	if err := table.db.checkWritable(); err != nil {
+		return 0, err
+	}
+	if expr == "" {
+		return 0, fmt.Errorf("empty expression")
+	}
+	table.db.mutex.Lock()
+	defer table.db.mutex.Unlock()
+	column, err := table.findColumn(targetColumn)
+	if err != nil {
+		return 0, err
+	}
+	if column.isIndex {
+		return 0, fmt.Errorf("not supported by index column: name = <%s>",
+			column.name)
+	}
+	exprBytes := []byte(expr)
+	var nRows C.size_t
+	rc := C.grngo_table_apply(table.db.ctx, table.obj, column.obj,
+		(*C.char)(unsafe.Pointer(&exprBytes[0])), C.size_t(len(exprBytes)),
+		&nRows)
+	if rc != C.GRN_SUCCESS {
+		return int(nRows), newGroongaError(table.db.ctx, "grngo_table_apply()", rc)
+	}
+	return int(nRows), nil
+}
+
+// geoMaxLongitude is 180 degrees in milliseconds of arc.
+const geoMaxLongitude = 180 * 60 * 60 * 1000
+
+// SelectInRectangle() searches the table for rows whose GeoPoint column
+// value is within the rectangle from topLeft to bottomRight. The search uses
+// geo_in_rectangle and is combined with options.Filter if given.
+// If topLeft.Longitude is greater than bottomRight.Longitude, the rectangle
+// is regarded as crossing the 180th meridian and split into two rectangles.
+// The points are interpreted in the datum of the column like SelectNearby().
+func (table *Table) SelectInRectangle(columnName string,
+	topLeft, bottomRight GeoPoint, options *SelectOptions) (*Records, error) {
+	column, err := table.FindColumn(columnName)
+	if err != nil {
+		return nil, err
+	}
+	switch column.valueType {
+	case TokyoGeoPoint, WGS84GeoPoint:
+	default:
+		return nil, fmt.Errorf("not a GeoPoint column: name = <%s>, valueType = %s",
+			columnName, column.valueType)
+	}
+	if column.isVector {
+		return nil, fmt.Errorf("vector column is not supported: name = <%s>",
+			columnName)
+	}
+	if topLeft.Latitude < bottomRight.Latitude {
+		return nil, fmt.Errorf("topLeft is below bottomRight: topLeft = %s, bottomRight = %s",
+			topLeft, bottomRight)
+	}
+	if options == nil {
+		options = NewSelectOptions()
+	}
+	newOptions := *options
+	if topLeft.Longitude <= bottomRight.Longitude {
+		newOptions.Filter = fmt.Sprintf("geo_in_rectangle(%s, \"%s\", \"%s\")",
+			columnName, topLeft, bottomRight)
+	} else {
+		east := GeoPoint{bottomRight.Latitude, geoMaxLongitude}
+		west := GeoPoint{topLeft.Latitude, -geoMaxLongitude}
+		newOptions.Filter = fmt.Sprintf(
+			"geo_in_rectangle(%s, \"%s\", \"%s\") || geo_in_rectangle(%s, \"%s\", \"%s\")",
+			columnName, topLeft, east, columnName, west, bottomRight)
+	}
+	if options.Filter != "" {
+		newOptions.Filter = fmt.Sprintf("(%s) && (%s)", newOptions.Filter,
+			options.Filter)
+	}
+	return table.Select(&newOptions)
+}
+
+// SelectMatch() searches the table for rows matching query, that is a query
+// in the query syntax, such as "Groonga OR Mroonga", like Select() with
+// options.Query and options.MatchColumns.
+// matchColumns is passed as --match_columns as is, so that the whole syntax,
+// such as weights ("title * 2 || body") and scorers, is available, and
+// errors in it are reported by Groonga as a GroongaError.
+func (table *Table) SelectMatch(query, matchColumns string,
+	options *SelectOptions) (*Records, error) {
+	if query == "" {
+		return nil, fmt.Errorf("empty query")
+	}
+	if matchColumns == "" {
+		return nil, fmt.Errorf("empty match_columns")
+	}
+	if options == nil {
+		options = NewSelectOptions()
+	}
+	newOptions := *options
+	newOptions.Query = query
+	newOptions.MatchColumns = matchColumns
+	return table.Select(&newOptions)
+}
+
+// InsertStruct() inserts a row and assigns the fields of record, that must
+// be a struct or a struct pointer, to the associated columns.
+// See getStructFields() for the rules to associate fields with columns.
+// A field with a tag must be associated with an existing column, while a field
+// without a tag is skipped if there is no such column. Pseudo columns, such as
+// _key, are always skipped.
+// Values are validated before insertion and all the offending fields are
+// reported in the returned error.
+func (table *Table) InsertStruct(key interface{}, record interface{}) (
+	uint32, error) {
+	structValue := reflect.ValueOf(record)
+	if (structValue.Kind() == reflect.Ptr) && !structValue.IsNil() {
+		structValue = structValue.Elem()
+	}
+	if structValue.Kind() != reflect.Struct {
+		return NilID, fmt.Errorf("unsupported record type: type = %T", record)
+	}
+	structType := structValue.Type()
+	var columns []*Column
+	var values []interface{}
+	var errMsgs []string
+	for _, field := range getStructFields(structType) {
+		if strings.HasPrefix(field.column, "_") {
+			continue
+		}
+		fieldName := structType.Field(field.index).Name
+		column, err := table.FindColumn(field.column)
+		if err != nil {
+			if field.tagged {
+				errMsgs = append(errMsgs, fmt.Sprintf("%s: %v", fieldName, err))
+			}
+			continue
+		}
+		value, err := column.reflectToValue(structValue.Field(field.index))
+		if err != nil {
+			errMsgs = append(errMsgs, fmt.Sprintf("%s: %v", fieldName, err))
+			continue
+		}
+		columns = append(columns, column)
+		values = append(values, value)
+	}
+	if len(errMsgs) != 0 {
+		return NilID, fmt.Errorf("invalid fields: %s", strings.Join(errMsgs, ", "))
+	}
+	_, id, err := table.InsertRow(key)
+	if err != nil {
+		return NilID, err
+	}
+	for i, column := range columns {
+		if err := column.SetValue(id, values[i]); err != nil {
+			return id, fmt.Errorf("Column.SetValue() failed: name = <%s>, err = %v",
+				column.name, err)
+		}
+	}
+	return id, nil
+}
+
+// InsertAndSet() inserts a row and sets values, that maps column names to
+// values, to the new row. The columns are resolved with FindColumn() before
+// insertion, and then the values are set in the order of the column names.
+// The insertion and the assignments are done while the DB is locked, so that
+// other goroutines never see a partially set row.
+// If a value cannot be set, the row is left inserted and the returned error
+// tells the offending column, as well as the ID of the row.
+// If the key already exists, nothing is set and the ID of the existing row is
+// returned with an error wrapping ErrAlreadyExists.
+func (table *Table) InsertAndSet(key interface{},
+	values map[string]interface{}) (uint32, error) {
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	columns := make([]*Column, len(names))
+	for i, name := range names {
+		column, err := table.FindColumn(name)
+		if err != nil {
+			return NilID, err
+		}
+		columns[i] = column
+	}
+	key, err := table.normalizeKey(key)
+	if err != nil {
+		return NilID, err
+	}
+	table.db.mutex.Lock()
+	defer table.db.mutex.Unlock()
+	if err := table.checkLocal(); err != nil {
+		return NilID, err
+	}
+	if err := table.db.checkWritable(); err != nil {
+		return NilID, err
+	}
+	inserted, id, err := table.insertRow(key)
+	if err != nil {
+		return NilID, err
+	}
+	if !inserted {
+		return id, fmt.Errorf("%w: table = <%s>, key = %v", ErrAlreadyExists,
+			table.name, key)
+	}
+	for i, column := range columns {
+		if err := column.setValue(id, values[names[i]]); err != nil {
+			return id, fmt.Errorf("Column.SetValue() failed: name = <%s>, err = %w",
+				names[i], err)
+		}
+	}
+	return id, nil
+}
+
+// SetRow() assigns values, that maps column names to values, to an existing
+// row with a single load command, so that Groonga applies them together.
+// Columns are resolved with FindColumn() and values are converted like
+// SetValue() and InsertStruct() before loading. If a value is invalid, nothing
+// is assigned and the returned error tells the first offending column in the
+// order of the column names.
+// Pseudo columns other than _value and nil values are not supported.
+func (table *Table) SetRow(id uint32, values map[string]interface{}) error {
+	if err := table.checkLocal(); err != nil {
+		return err
+	}
+	if err := table.db.checkWritable(); err != nil {
+		return err
+	}
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	record := map[string]interface{}{"_id": id}
+	for _, name := range names {
+		if strings.HasPrefix(name, "_") && (name != "_value") {
+			return fmt.Errorf("invalid column name: name = <%s>", name)
+		}
+		value := values[name]
+		if value == nil {
+			return fmt.Errorf("nil value: name = <%s>", name)
+		}
+		column, err := table.FindColumn(name)
+		if err != nil {
+			return err
+		}
+		if value, err = column.normalizeValue(value); err == nil {
+			value, err = column.reflectToValue(reflect.ValueOf(value))
+		}
+		if err != nil {
+			return fmt.Errorf("invalid value: name = <%s>, err = %w", name, err)
+		}
+		record[name] = valueToJSON(value)
+	}
+	value, err := json.Marshal([]map[string]interface{}{record})
+	if err != nil {
+		return fmt.Errorf("json.Marshal() failed: %v", err)
+	}
+	// The row is checked and loaded in one critical section, so that load
+	// never re-creates a row deleted concurrently.
+	table.db.mutex.Lock()
+	defer table.db.mutex.Unlock()
+	if table.obj == nil {
+		return fmt.Errorf("table released: name = <%s>", table.name)
+	}
+	if C.grn_table_at(table.db.ctx, table.obj, C.grn_id(id)) == C.GRN_ID_NIL {
+		return fmt.Errorf("%w: table = <%s>, id = %d", ErrRowNotFound,
+			table.name, id)
+	}
+	if len(names) == 0 {
+		return nil
+	}
+	command, err := buildCommand("load", table.db.withCommandVersion(
+		table.loadOptionsMap(value, NewLoadOptions())))
+	if err != nil {
+		return err
+	}
+	bytes, err := table.db.query(command)
+	if err != nil {
+		return err
+	}
+	n, err := parseLoadResult(bytes)
+	if err != nil {
+		return err
+	}
+	if n != 1 {
+		return fmt.Errorf("load failed: table = <%s>, id = %d, n = %d",
+			table.name, id, n)
+	}
+	return nil
+}
+
+// checkIntRange() returns an error if value does not fit in dataType.
+// UInt64 accepts only non-negative values.
+func checkIntRange(dataType DataType, value int64) error {
+	var min, max int64
+	switch dataType {
+	case Int8:
+		min, max = math.MinInt8, math.MaxInt8
+	case Int16:
+		min, max = math.MinInt16, math.MaxInt16
+	case Int32:
+		min, max = math.MinInt32, math.MaxInt32
+	case UInt8:
+		min, max = 0, math.MaxUint8
+	case UInt16:
+		min, max = 0, math.MaxUint16
+	case UInt32:
+		min, max = 0, math.MaxUint32
+	case UInt64:
+		min, max = 0, math.MaxInt64
+	default:
+		return nil
+	}
+	if (value < min) || (value > max) {
+		return fmt.Errorf("out of range: type = %s, value = %d",
+			dataType, value)
+	}
+	return nil
+}
+
+// reflectToInt() converts an integer into int64 for dataType.
+// An error is returned if value does not fit in dataType. A UInt64 value
+// greater than math.MaxInt64 is converted into a negative number, whose bits
+// are restored by the conversion into uint64_t.
+func reflectToInt(dataType DataType, value reflect.Value) (int64, error) {
+	switch value.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if err := checkIntRange(dataType, value.Int()); err != nil {
+			return 0, err
+		}
+		return value.Int(), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32,
+		reflect.Uint64, reflect.Uintptr:
+		v := value.Uint()
+		if v > math.MaxInt64 {
+			if dataType != UInt64 {
+				return 0, fmt.Errorf("out of range: type = %s, value = %d",
+					dataType, v)
+			}
+			return int64(v), nil
+		}
+		if err := checkIntRange(dataType, int64(v)); err != nil {
+			return 0, err
+		}
+		return int64(v), nil
+	}
+	return 0, fmt.Errorf("value type conflict: type = %s, valueType = %s",
+		value.Type(), dataType)
+}
+
+// normalizeNumber() converts a value of an integer type into int64 with
+// reflectToInt() and a value of a float type into float64, or float32 for
+// Float32. Values of the other types are returned as is.
+// It is the shared part of normalizeKey() and normalizeValue().
+func normalizeNumber(dataType DataType, value interface{}) (interface{}, error) {
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32,
+		reflect.Uint64, reflect.Uintptr:
+		return reflectToInt(dataType, v)
+	case reflect.Float32, reflect.Float64:
+		f := v.Float()
+		if dataType != Float32 {
+			return f, nil
+		}
+		if !math.IsInf(f, 0) && !math.IsNaN(f) && (math.Abs(f) > math.MaxFloat32) {
+			return nil, fmt.Errorf("out of range: type = %s, value = %g",
+				dataType, f)
+		}
+		return float32(f), nil
+	}
+	return value, nil
+}
+
+// normalizeKey() converts a key of an integer type into int64 and a key of a
+// float type into float64, so that InsertRow() and encodeKey() accept keys
+// such as 42 (int) and float32(1.5).
+// An integer key must fit in the key type. A uint32 key is kept as is if the
+// key of the table is a reference.
+func (table *Table) normalizeKey(key interface{}) (interface{}, error) {
+	switch key.(type) {
+	case nil, bool, float64, time.Time, GeoPoint, []byte:
+		return key, nil
+	case uint32:
+		if table.keyTable != nil {
+			return key, nil
+		}
+	}
+	return normalizeNumber(table.keyType, key)
+}
+
+// encodeKey() converts a key into the binary representation of the key type.
+// The supported key types are the same as InsertRow().
+func (table *Table) encodeKey(key interface{}) ([]byte, error) {
+	key, err := table.normalizeKey(key)
+	if err != nil {
+		return nil, err
+	}
+	var ptr unsafe.Pointer
+	var size uintptr
+	switch value := key.(type) {
+	case bool:
+		if table.keyType != Bool {
+			return nil, fmt.Errorf("key type conflict")
+		}
+		grnKey := C.grn_bool(C.GRN_FALSE)
+		if value {
+			grnKey = C.grn_bool(C.GRN_TRUE)
+		}
+		ptr, size = unsafe.Pointer(&grnKey), unsafe.Sizeof(grnKey)
+	case int64:
+		switch table.keyType {
+		case Int8:
+			grnKey := C.int8_t(value)
+			ptr, size = unsafe.Pointer(&grnKey), unsafe.Sizeof(grnKey)
+		case Int16:
+			grnKey := C.int16_t(value)
+			ptr, size = unsafe.Pointer(&grnKey), unsafe.Sizeof(grnKey)
+		case Int32:
+			grnKey := C.int32_t(value)
+			ptr, size = unsafe.Pointer(&grnKey), unsafe.Sizeof(grnKey)
+		case Int64:
+			grnKey := C.int64_t(value)
+			ptr, size = unsafe.Pointer(&grnKey), unsafe.Sizeof(grnKey)
+		case UInt8:
+			grnKey := C.uint8_t(value)
+			ptr, size = unsafe.Pointer(&grnKey), unsafe.Sizeof(grnKey)
+		case UInt16:
+			grnKey := C.uint16_t(value)
+			ptr, size = unsafe.Pointer(&grnKey), unsafe.Sizeof(grnKey)
+		case UInt32:
+			grnKey := C.uint32_t(value)
+			ptr, size = unsafe.Pointer(&grnKey), unsafe.Sizeof(grnKey)
+		case UInt64:
+			grnKey := C.uint64_t(value)
+			ptr, size = unsafe.Pointer(&grnKey), unsafe.Sizeof(grnKey)
+		default:
+			return nil, fmt.Errorf("key type conflict")
+		}
+	case uint32:
+		if table.keyTable == nil {
+			return nil, fmt.Errorf("key type conflict")
+		}
+		grnKey := C.grn_id(value)
+		ptr, size = unsafe.Pointer(&grnKey), unsafe.Sizeof(grnKey)
+	case float64:
+		if table.keyType != Float {
+			return nil, fmt.Errorf("key type conflict")
+		}
+		grnKey := C.double(value)
+		ptr, size = unsafe.Pointer(&grnKey), unsafe.Sizeof(grnKey)
+	case time.Time:
+		if table.keyType != Time {
+			return nil, fmt.Errorf("key type conflict")
+		}
+		grnKey := C.int64_t(timeToGrnTime(value))
+		ptr, size = unsafe.Pointer(&grnKey), unsafe.Sizeof(grnKey)
+	case GeoPoint:
+		switch table.keyType {
+		case TokyoGeoPoint, WGS84GeoPoint:
+		default:
+			return nil, fmt.Errorf("key type conflict")
+		}
+		grnKey := C.grn_geo_point{C.int(value.Latitude), C.int(value.Longitude)}
+		ptr, size = unsafe.Pointer(&grnKey), unsafe.Sizeof(grnKey)
+	case []byte:
+		if table.keyType != ShortText {
+			return nil, fmt.Errorf("key type conflict")
+		}
+		return value, nil
+	case nil:
+		return nil, fmt.Errorf("key type conflict")
+	default:
+		return nil, fmt.Errorf(
+			"unsupported key type: typeName = <%s>", reflect.TypeOf(key).Name())
+	}
+	return C.GoBytes(ptr, C.int(size)), nil
+}
+
+// decodeKey() converts the binary representation of a key into a Go value,
+// that is the reverse of encodeKey().
+// A reference key is returned as the ID of the referenced row.
+func (table *Table) decodeKey(key []byte) (interface{}, error) {
+	if table.keyTable != nil {
+		if len(key) != int(unsafe.Sizeof(C.grn_id(0))) {
+			return nil, fmt.Errorf("invalid key size: size = %d", len(key))
+		}
+		return uint32(*(*C.grn_id)(unsafe.Pointer(&key[0]))), nil
+	}
+	var size uintptr
+	switch table.keyType {
+	case Bool:
+		size = unsafe.Sizeof(C.grn_bool(0))
+	case Int8, UInt8:
+		size = 1
+	case Int16, UInt16:
+		size = 2
+	case Int32, UInt32:
+		size = 4
+	case Int64, UInt64, Float, Time:
+		size = 8
+	case TokyoGeoPoint, WGS84GeoPoint:
+		size = unsafe.Sizeof(C.grn_geo_point{})
+	case ShortText:
+		return key, nil
+	default:
+		return nil, fmt.Errorf("unsupported key type: keyType = %s", table.keyType)
+	}
+	if uintptr(len(key)) != size {
+		return nil, fmt.Errorf("invalid key size: size = %d", len(key))
+	}
+	ptr := unsafe.Pointer(&key[0])
+	switch table.keyType {
+	case Bool:
+		return *(*C.grn_bool)(ptr) == C.GRN_TRUE, nil
+	case Int8:
+		return int64(*(*C.int8_t)(ptr)), nil
+	case Int16:
+		return int64(*(*C.int16_t)(ptr)), nil
+	case Int32:
+		return int64(*(*C.int32_t)(ptr)), nil
+	case Int64:
+		return int64(*(*C.int64_t)(ptr)), nil
+	case UInt8:
+		return int64(*(*C.uint8_t)(ptr)), nil
+	case UInt16:
+		return int64(*(*C.uint16_t)(ptr)), nil
+	case UInt32:
+		return int64(*(*C.uint32_t)(ptr)), nil
+	case UInt64:
+		return int64(*(*C.uint64_t)(ptr)), nil
+	case Float:
+		return float64(*(*C.double)(ptr)), nil
+	case Time:
+		return grnTimeToTime(int64(*(*C.int64_t)(ptr))), nil
+	default: // TokyoGeoPoint, WGS84GeoPoint
+		grnKey := *(*C.grn_geo_point)(ptr)
+		return GeoPoint{int32(grnKey.latitude), int32(grnKey.longitude)}, nil
+	}
+}
+
+// valueToJSON() converts a value into a value which is encoded in the format
+// of the load command by json.Marshal().
+func valueToJSON(value interface{}) interface{} {
+	switch v := value.(type) {
+	case []byte:
+		return string(v)
+	case time.Time:
+		return json.Number(formatGrnTime(timeToGrnTime(v)))
+	case GeoPoint:
+		return v.String()
+	case [][]byte:
+		values := make([]string, len(v))
+		for i := range v {
+			values[i] = string(v[i])
+		}
+		return values
+	case []time.Time:
+		values := make([]json.Number, len(v))
+		for i := range v {
+			values[i] = json.Number(formatGrnTime(timeToGrnTime(v[i])))
+		}
+		return values
+	case []GeoPoint:
+		values := make([]string, len(v))
+		for i := range v {
+			values[i] = v[i].String()
+		}
+		return values
+	default:
+		return value
+	}
+}
+
+// loadChunk() loads records in JSON with the load command and returns the
+// number of loaded records.
+func (table *Table) loadChunk(values []byte, options *LoadOptions) (
+	uint32, error) {
+	bytes, err := table.db.queryEx("load", table.loadOptionsMap(values, options))
+	if err != nil {
+		return 0, err
+	}
+	return parseLoadResult(bytes)
+}
+
+// loadOptionsMap() returns the options of the load command for records in
+// JSON.
+func (table *Table) loadOptionsMap(values []byte, options *LoadOptions) map[string]string {
+	optionsMap := make(map[string]string)
+	optionsMap["table"] = table.name
+	optionsMap["values"] = string(values)
+	if options.IfExists != "" {
+		optionsMap["ifexists"] = options.IfExists
+	}
+	return optionsMap
+}
+
+// parseLoadResult() returns the number of loaded records in the result of
+// the load command.
+func parseLoadResult(bytes []byte) (uint32, error) {
+	n, err := strconv.ParseUint(string(bytes), 10, 32)
+	if err != nil {
+		return 0, fmt.Errorf("strconv.ParseUint() failed: result = %s, err = %v",
+			bytes, err)
+	}
+	return uint32(n), nil
+}
+
+// Load() loads records with the load command and returns the number of loaded
+// records.
+// Each record maps column names to values of the types which SetValue()
+// accepts. If the table has a key, each record must have "_key".
+// Records are split into chunks so that the size of values in a command does
+// not exceed options.ChunkSize.
+// If an error occurs, the number of records loaded so far is returned.
+func (table *Table) Load(records []map[string]interface{},
+	options *LoadOptions) (uint32, error) {
+	if options == nil {
+		options = NewLoadOptions()
+	}
+	var n uint32
+	var chunk bytes.Buffer
+	for i, record := range records {
+		_, hasKey := record["_key"]
+		switch {
+		case (table.keyType == Void) && hasKey:
+			return n, fmt.Errorf("table has no key: table = <%s>", table.name)
+		case (table.keyType != Void) && !hasKey:
+			return n, fmt.Errorf("record has no key: i = %d", i)
+		}
+		values := make(map[string]interface{})
+		for name, value := range record {
+			values[name] = valueToJSON(value)
+		}
+		value, err := json.Marshal(values)
+		if err != nil {
+			return n, fmt.Errorf("json.Marshal() failed: i = %d, err = %v", i, err)
+		}
+		if (chunk.Len() != 0) && (chunk.Len()+len(value)+1 > options.ChunkSize) {
+			chunk.WriteByte(']')
+			count, err := table.loadChunk(chunk.Bytes(), options)
+			n += count
+			if err != nil {
+				return n, err
+			}
+			chunk.Reset()
+		}
+		if chunk.Len() == 0 {
+			chunk.WriteByte('[')
+		} else {
+			chunk.WriteByte(',')
+		}
+		chunk.Write(value)
+	}
+	if chunk.Len() != 0 {
+		chunk.WriteByte(']')
+		count, err := table.loadChunk(chunk.Bytes(), options)
+		n += count
+		if err != nil {
+			return n, err
+		}
+	}
+	return n, nil
+}
+
+// OpenCursor() opens a cursor to iterate over rows.
+// If options has MinKey or MaxKey, the table must be a PatTable or DatTable
+// and rows are iterated in key order. Otherwise, rows are iterated in ID
+// order.
+// Rows are read lazily and the cursor must be closed by TableCursor.Close().
+func (table *Table) OpenCursor(options *CursorOptions) (*TableCursor, error) {
+	if err := table.checkLocal(); err != nil {
+		return nil, err
+	}
+	table.db.mutex.Lock()
+	defer table.db.mutex.Unlock()
+	if options == nil {
+		options = NewCursorOptions()
+	}
+	flags := C.int(C.GRN_CURSOR_BY_ID)
+	var minKey, maxKey []byte
+	if (options.MinKey != nil) || (options.MaxKey != nil) {
+		switch table.obj.header._type {
+		case C.GRN_TABLE_PAT_KEY, C.GRN_TABLE_DAT_KEY:
+		default:
+			return nil, fmt.Errorf("key range is not supported: table = <%s>",
+				table.name)
+		}
+		flags = C.GRN_CURSOR_BY_KEY
+		if options.MinKey != nil {
+			key, err := table.encodeKey(options.MinKey)
+			if err != nil {
+				return nil, err
+			}
+			minKey = key
+			if options.MinExclusive {
+				flags |= C.GRN_CURSOR_GT
+			}
+		}
+		if options.MaxKey != nil {
+			key, err := table.encodeKey(options.MaxKey)
+			if err != nil {
+				return nil, err
+			}
+			maxKey = key
+			if options.MaxExclusive {
+				flags |= C.GRN_CURSOR_LT
+			}
+		}
+	}
+	switch options.CursorOrder {
+	case Ascending:
+		flags |= C.GRN_CURSOR_ASCENDING
+	case Descending:
+		flags |= C.GRN_CURSOR_DESCENDING
+	default:
+		return nil, fmt.Errorf("undefined cursor order: order = %d",
+			options.CursorOrder)
+	}
+	var minPtr, maxPtr unsafe.Pointer
+	if len(minKey) != 0 {
+		minPtr = unsafe.Pointer(&minKey[0])
+	}
+	if len(maxKey) != 0 {
+		maxPtr = unsafe.Pointer(&maxKey[0])
+	}
+	obj := C.grn_table_cursor_open(table.db.ctx, table.obj,
+		minPtr, C.uint(len(minKey)), maxPtr, C.uint(len(maxKey)),
+		C.int(options.Offset), C.int(options.Limit), flags)
+	if obj == nil {
+		errMsg := C.GoString(&table.db.ctx.errbuf[0])
+		return nil, fmt.Errorf("grn_table_cursor_open() failed: err = %s", errMsg)
+	}
+	return newTableCursor(table, obj), nil
+}
+
+// ColumnNames() returns the names of columns in the table.
+// Pseudo columns, such as _id and _key, are excluded and index columns are
+// included.
+func (table *Table) ColumnNames() ([]string, error) {
+	schemas, err := table.ColumnSchemas()
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, len(schemas))
+	for i, schema := range schemas {
+		names[i] = schema.Name
+	}
+	return names, nil
+}
+
+// ColumnSchemas() returns the definitions of columns in the table.
+// Pseudo columns, such as _id and _key, are excluded and index columns are
+// included.
+func (table *Table) ColumnSchemas() ([]ColumnSchema, error) {
+	rows, err := table.db.queryList("column_list",
+		map[string]string{"table": table.name})
+	if err != nil {
+		return nil, err
+	}
+	schemas := make([]ColumnSchema, 0, len(rows))
+	for _, row := range rows {
+		var schema ColumnSchema
+		schema.Name, _ = row["name"].(string)
+		if (schema.Name == "") || strings.HasPrefix(schema.Name, "_") {
+			continue
+		}
+		schema.TypeName, _ = row["range"].(string)
+		if dataType, ok := ParseDataType(schema.TypeName); ok {
+			schema.ValueType = dataType
+		} else if refTable, err := table.db.FindTable(schema.TypeName); err == nil {
+			schema.ValueType = refTable.keyType
+		}
+		columnType, _ := row["type"].(string)
+		flags, _ := row["flags"].(string)
+		schema.IsIndex = columnType == "index"
+		schema.IsVector = strings.Contains(flags, "COLUMN_VECTOR")
+		switch {
+		case strings.Contains(flags, "COMPRESS_ZLIB"):
+			schema.CompressionType = ZlibCompression
+		case strings.Contains(flags, "COMPRESS_LZ4"),
+			strings.Contains(flags, "COMPRESS_LZO"):
+			schema.CompressionType = LzoCompression
+		case strings.Contains(flags, "COMPRESS_"):
+			schema.CompressionType = UnknownCompression
+		}
+		if sources, ok := row["source"].([]interface{}); ok {
+			for _, source := range sources {
+				if source, ok := source.(string); ok {
+					schema.Sources = append(schema.Sources, source)
+				}
+			}
+		}
+		if schema.IsIndex {
+			schema.ValueType = Void
+		}
+		schemas = append(schemas, schema)
+	}
+	sort.Slice(schemas, func(i, j int) bool {
+		return schemas[i].Name < schemas[j].Name
+	})
+	return schemas, nil
+}
+
+// -- Column --
+
+// ColumnSchema describes the definition of a column.
+type ColumnSchema struct {
+	Name      string
+	ValueType DataType // The key type for a reference, Void for an index.
+	TypeName  string   // The name of the value type or referenced table.
+	IsVector  bool
+	IsIndex   bool
+	Sources   []string // The source columns of an index.
+	CompressionType
+}
+
+type Column struct {
+	table      *Table
+	obj        *C.grn_obj
+	name       string
+	valueType  DataType
+	isVector   bool
+	isIndex    bool
+	valueTable *Table
+}
+
+// newColumn() creates a new Column object.
+func newColumn(table *Table, obj *C.grn_obj, name string,
+	valueType DataType, isVector bool, valueTable *Table) *Column {
+	var column Column
+	column.table = table
+	column.obj = obj
+	column.name = name
+	column.valueType = valueType
+	column.isVector = isVector
+	column.valueTable = valueTable
+	return &column
+}
+
+// ValueType() returns the value type of the column.
+// The key type of the referenced table is returned for a reference column,
+// and Void is returned for an index column.
+func (column *Column) ValueType() DataType {
+	return column.valueType
+}
+
+// IsVector() returns whether the column is a vector column.
+func (column *Column) IsVector() bool {
+	return column.isVector
+}
+
+// IsReference() returns whether the column refers to a table.
+func (column *Column) IsReference() bool {
+	return column.valueTable != nil
+}
+
+// release() releases the column.
+// The caller must hold db.mutex.
+func (column *Column) release() {
+	if column.obj != nil {
+		C.grn_obj_unlink(column.table.db.ctx, column.obj)
+		column.obj = nil
+	}
+}
+
+// Release() releases the column and removes it from the cache.
+// The Column object is no longer available after Release(), but FindColumn()
+// returns a new one.
+// Note that the column itself is not removed from the database.
+func (column *Column) Release() {
+	column.table.db.mutex.Lock()
+	defer column.table.db.mutex.Unlock()
+	table := column.table
+	if cached, ok := table.columns[column.name]; ok && (cached == column) {
+		delete(table.columns, column.name)
+	}
+	column.release()
+}
+
+// Defrag() defragments the column like DB.Defrag().
+func (column *Column) Defrag(options *DefragOptions) (int, error) {
+	column.table.db.mutex.Lock()
+	defer column.table.db.mutex.Unlock()
+	if column.obj == nil {
+		return 0, fmt.Errorf("column removed: name = <%s>", column.name)
+	}
+	if err := column.table.db.checkWritable(); err != nil {
+		return 0, err
+	}
+	return column.table.db.defrag(column.obj, options)
+}
+
+// IsIndex() returns whether the column is an index column.
+func (column *Column) IsIndex() bool {
+	return column.isIndex
+}
+
+// compression() returns the compression type in the column flags.
+// The caller must hold db.mutex.
+func (column *Column) compression() CompressionType {
+	switch column.obj.header._type {
+	case C.GRN_COLUMN_FIX_SIZE, C.GRN_COLUMN_VAR_SIZE:
+	default:
+		// Index columns and pseudo columns, such as _key, are not compressed.
+		return NoCompression
+	}
+	switch column.obj.header.flags & C.GRN_OBJ_COMPRESS_MASK {
+	case C.GRN_OBJ_COMPRESS_NONE:
+		return NoCompression
+	case C.GRN_OBJ_COMPRESS_ZLIB:
+		return ZlibCompression
+	case C.GRN_OBJ_COMPRESS_LZ4:
+		// COMPRESS_LZO is an alias for COMPRESS_LZ4.
+		return LzoCompression
+	default:
+		return UnknownCompression
+	}
+}
+
+// Compression() returns the compression type of the column.
+// UnknownCompression is returned if the column is compressed by a compressor
+// which is not supported by grngo.
+func (column *Column) Compression() (CompressionType, error) {
+	column.table.db.mutex.Lock()
+	defer column.table.db.mutex.Unlock()
+	if column.obj == nil {
+		return NoCompression, fmt.Errorf("column removed: name = <%s>",
+			column.name)
+	}
+	return column.compression(), nil
+}
+
+// Flags() returns the header flags of the column, such as ColumnVectorFlag
+// and WithWeightFlag.
+// The flags of a pseudo column, such as _key, are not meaningful.
+func (column *Column) Flags() (uint32, error) {
+	column.table.db.mutex.Lock()
+	defer column.table.db.mutex.Unlock()
+	if column.obj == nil {
+		return 0, fmt.Errorf("column removed: name = <%s>", column.name)
+	}
+	return uint32(column.obj.header.flags), nil
+}
+
+// HasMatchIndex() returns whether the column has an index for full-text
+// search.
+func (column *Column) HasMatchIndex() bool {
+	column.table.db.mutex.Lock()
+	defer column.table.db.mutex.Unlock()
+	if column.obj == nil {
+		return false
+	}
+	return C.grngo_column_has_match_index(column.table.db.ctx,
+		column.obj) == C.GRN_TRUE
+}
+
+// DiskUsage() returns the disk usage of the column in bytes.
+func (column *Column) DiskUsage() (uint64, error) {
+	column.table.db.mutex.Lock()
+	defer column.table.db.mutex.Unlock()
+	if column.obj == nil {
+		return 0, fmt.Errorf("column removed: name = <%s>", column.name)
+	}
+	return uint64(C.grn_obj_get_disk_usage(column.table.db.ctx, column.obj)), nil
+}
+
+// Rebuild() rebuilds an index column from its sources with grn_obj_reindex().
+// It is useful after loading many records.
+// Note that Rebuild() blocks other operations on the DB until Groonga
+// finishes.
+func (column *Column) Rebuild() error {
+	db := column.table.db
+	db.mutex.Lock()
+	defer db.mutex.Unlock()
+	if column.obj == nil {
+		return fmt.Errorf("column removed: name = <%s>", column.name)
+	}
+	if !column.isIndex {
+		return fmt.Errorf("not an index column: name = <%s>", column.name)
+	}
+	if err := db.checkWritable(); err != nil {
+		return err
+	}
+	if rc := C.grn_obj_reindex(db.ctx, column.obj); rc != C.GRN_SUCCESS {
+		return newGroongaError(db.ctx, "grn_obj_reindex()", rc)
+	}
+	return nil
+}
+
+// IndexSources() returns the full names of the sources of an index column,
+// such as "Table.Column". The name of a table is returned if the index
+// column indexes its keys.
+func (column *Column) IndexSources() ([]string, error) {
+	column.table.db.mutex.Lock()
+	defer column.table.db.mutex.Unlock()
+	if column.obj == nil {
+		return nil, fmt.Errorf("column removed: name = <%s>", column.name)
+	}
+	if !column.isIndex {
+		return nil, fmt.Errorf("not an index column: name = <%s>", column.name)
+	}
+	ctx := column.table.db.ctx
+	var n C.size_t
+	if ok := C.grngo_column_get_sources(ctx, column.obj, nil, &n); ok != C.GRN_TRUE {
+		return nil, fmt.Errorf("grngo_column_get_sources() failed: name = <%s>",
+			column.name)
+	}
+	sources := make([]string, 0, int(n))
+	if n == 0 {
+		return sources, nil
+	}
+	ids := make([]C.grn_id, int(n))
+	if ok := C.grngo_column_get_sources(ctx, column.obj, &ids[0], &n); ok != C.GRN_TRUE {
+		return nil, fmt.Errorf("grngo_column_get_sources() failed: name = <%s>",
+			column.name)
+	}
+	for _, id := range ids[:int(n)] {
+		cName := C.grngo_obj_get_name(ctx, C.grn_ctx_at(ctx, id))
+		if cName == nil {
+			return nil, fmt.Errorf("grngo_obj_get_name() failed: id = %d", id)
+		}
+		sources = append(sources, C.GoString(cName))
+		C.free(unsafe.Pointer(cName))
+	}
+	return sources, nil
+}
+
+// Remove() removes the column.
+// Index columns depending on the column are also removed by Groonga and
+// cached columns referring to removed objects are evicted.
+// The Column object is no longer available after removal.
+func (column *Column) Remove() error {
+	db := column.table.db
+	db.mutex.Lock()
+	removed := column.obj == nil
+	db.mutex.Unlock()
+	if removed {
+		return fmt.Errorf("column removed: name = <%s>", column.name)
+	}
+	if strings.HasPrefix(column.name, "_") ||
+		(strings.IndexByte(column.name, '.') != -1) {
+		return fmt.Errorf("not removable: name = <%s>", column.name)
+	}
+	ids := db.cachedColumnIDs()
+	optionsMap := make(map[string]string)
+	optionsMap["table"] = column.table.name
+	optionsMap["name"] = column.name
+	bytes, err := db.queryEx("column_remove", optionsMap)
+	if err != nil {
+		return err
+	}
+	if string(bytes) != "true" {
+		return fmt.Errorf("column_remove failed: name = <%s>", column.name)
+	}
+	db.mutex.Lock()
+	defer db.mutex.Unlock()
+	db.evictColumns(ids)
+	column.obj = nil
+	return nil
+}
+
+// setBool() assigns a Bool value.
+func (column *Column) setBool(id uint32, value bool) error {
+	if (column.valueType != Bool) || column.isVector {
+		return fmt.Errorf("value type conflict")
+	}
+	var grnValue C.grn_bool = C.GRN_FALSE
+	if value {
+		grnValue = C.GRN_TRUE
+	}
+	if ok := C.grngo_column_set_bool(column.table.db.ctx, column.obj,
+		C.grn_id(id), grnValue); ok != C.GRN_TRUE {
+		return fmt.Errorf("grngo_column_set_bool() failed")
+	}
+	return nil
+}
+
+// setInt() assigns an Int value.
+func (column *Column) setInt(id uint32, value int64) error {
+	if column.isVector {
+		return fmt.Errorf("value type conflict")
+	}
+	ctx := column.table.db.ctx
+	var ok C.grn_bool
+	switch column.valueType {
+	case Int8:
+		grnValue := C.int8_t(value)
+		ok = C.grngo_column_set_int8(ctx, column.obj, C.grn_id(id), grnValue)
+	case Int16:
+		grnValue := C.int16_t(value)
+		ok = C.grngo_column_set_int16(ctx, column.obj, C.grn_id(id), grnValue)
+	case Int32:
+		grnValue := C.int32_t(value)
+		ok = C.grngo_column_set_int32(ctx, column.obj, C.grn_id(id), grnValue)
+	case Int64:
+		grnValue := C.int64_t(value)
+		ok = C.grngo_column_set_int64(ctx, column.obj, C.grn_id(id), grnValue)
+	case UInt8:
+		grnValue := C.uint8_t(value)
+		ok = C.grngo_column_set_uint8(ctx, column.obj, C.grn_id(id), grnValue)
+	case UInt16:
+		grnValue := C.uint16_t(value)
+		ok = C.grngo_column_set_uint16(ctx, column.obj, C.grn_id(id), grnValue)
+	case UInt32:
+		grnValue := C.uint32_t(value)
+		ok = C.grngo_column_set_uint32(ctx, column.obj, C.grn_id(id), grnValue)
+	case UInt64:
+		grnValue := C.uint64_t(value)
+		ok = C.grngo_column_set_uint64(ctx, column.obj, C.grn_id(id), grnValue)
+	default:
+		return fmt.Errorf("value type conflict")
+	}
+	if ok != C.GRN_TRUE {
+		return fmt.Errorf("grngo_column_set_int*() failed")
+	}
+	return nil
+}
+
+// setFloat() assigns a Float value.
+func (column *Column) setFloat(id uint32, value float64) error {
+	if (column.valueType != Float) || column.isVector {
+		return fmt.Errorf("value type conflict")
+	}
+	grnValue := C.double(value)
+	if ok := C.grngo_column_set_float(column.table.db.ctx, column.obj,
+		C.grn_id(id), grnValue); ok != C.GRN_TRUE {
+		return fmt.Errorf("grngo_column_set_float() failed")
+	}
+	return nil
+}
+
+// setFloat32() assigns a Float32 value.
+func (column *Column) setFloat32(id uint32, value float32) error {
+	if (column.valueType != Float32) || column.isVector {
+		return fmt.Errorf("value type conflict")
+	}
+	grnValue := C.float(value)
+	if ok := C.grngo_column_set_float32(column.table.db.ctx, column.obj,
+		C.grn_id(id), grnValue); ok != C.GRN_TRUE {
+		return fmt.Errorf("grngo_column_set_float32() failed")
+	}
+	return nil
+}
+
+// setTime() assigns a Time value.
+// Note that the value is truncated to microseconds.
+func (column *Column) setTime(id uint32, value time.Time) error {
+	if (column.valueType != Time) || column.isVector {
+		return fmt.Errorf("value type conflict")
+	}
+	grnValue := C.int64_t(timeToGrnTime(value))
+	if ok := C.grngo_column_set_time(column.table.db.ctx, column.obj,
+		C.grn_id(id), grnValue); ok != C.GRN_TRUE {
+		return fmt.Errorf("grngo_column_set_time() failed")
+	}
+	return nil
+}
+
+// setGeoPoint() assigns a GeoPoint value.
+func (column *Column) setGeoPoint(id uint32, value GeoPoint) error {
+	switch column.valueType {
+	case TokyoGeoPoint, WGS84GeoPoint:
+	default:
+		return fmt.Errorf("value type conflict")
+	}
+	if column.isVector {
+		return fmt.Errorf("value type conflict")
+	}
+	grnValue := C.grn_geo_point{C.int(value.Latitude), C.int(value.Longitude)}
+	if ok := C.grngo_column_set_geo_point(column.table.db.ctx, column.obj,
+		C.grn_builtin_type(column.valueType),
+		C.grn_id(id), grnValue); ok != C.GRN_TRUE {
+		return fmt.Errorf("grngo_column_set_geo_point() failed")
+	}
+	return nil
+}
+
+// setText() assigns a Text value.
+func (column *Column) setText(id uint32, value []byte) error {
+	switch column.valueType {
+	case ShortText, Text, LongText:
+	default:
+		return fmt.Errorf("value type conflict")
+	}
+	if column.isVector {
+		return fmt.Errorf("value type conflict")
+	}
+	var grnValue C.grngo_text
+	if len(value) != 0 {
+		grnValue.ptr = (*C.char)(unsafe.Pointer(&value[0]))
+		grnValue.size = C.size_t(len(value))
+	}
+	if ok := C.grngo_column_set_text(column.table.db.ctx, column.obj,
+		C.grn_id(id), &grnValue); ok != C.GRN_TRUE {
+		return fmt.Errorf("grngo_column_set_text() failed")
+	}
+	return nil
+}
+
+// setBoolVector() assigns a Bool vector.
+func (column *Column) setBoolVector(id uint32, value []bool) error {
+	grnValue := make([]C.grn_bool, len(value))
+	for i, v := range value {
+		if v {
+			grnValue[i] = C.GRN_TRUE
+		}
+	}
+	var grnVector C.grngo_vector
+	if len(grnValue) != 0 {
+		grnVector.ptr = unsafe.Pointer(&grnValue[0])
+		grnVector.size = C.size_t(len(grnValue))
+	}
+	if ok := C.grngo_column_set_bool_vector(column.table.db.ctx, column.obj,
+		C.grn_id(id), &grnVector); ok != C.GRN_TRUE {
+		return fmt.Errorf("grngo_column_set_bool_vector() failed")
+	}
+	return nil
+}
+
+// setIntVector() assigns an Int vector.
+func (column *Column) setIntVector(id uint32, value []int64) error {
+	var grnVector C.grngo_vector
+	if len(value) != 0 {
+		grnVector.ptr = unsafe.Pointer(&value[0])
+		grnVector.size = C.size_t(len(value))
+	}
+	ctx := column.table.db.ctx
+	obj := column.obj
+	var ok C.grn_bool
+	switch column.valueType {
+	case Int8:
+		ok = C.grngo_column_set_int8_vector(ctx, obj, C.grn_id(id), &grnVector)
+	case Int16:
+		ok = C.grngo_column_set_int16_vector(ctx, obj, C.grn_id(id), &grnVector)
+	case Int32:
+		ok = C.grngo_column_set_int32_vector(ctx, obj, C.grn_id(id), &grnVector)
+	case Int64:
+		ok = C.grngo_column_set_int64_vector(ctx, obj, C.grn_id(id), &grnVector)
+	case UInt8:
+		ok = C.grngo_column_set_uint8_vector(ctx, obj, C.grn_id(id), &grnVector)
+	case UInt16:
+		ok = C.grngo_column_set_uint16_vector(ctx, obj, C.grn_id(id), &grnVector)
+	case UInt32:
+		ok = C.grngo_column_set_uint32_vector(ctx, obj, C.grn_id(id), &grnVector)
+	case UInt64:
+		ok = C.grngo_column_set_uint64_vector(ctx, obj, C.grn_id(id), &grnVector)
+	default:
+		return fmt.Errorf("value type conflict")
+	}
+	if ok != C.GRN_TRUE {
+		return fmt.Errorf("grngo_column_set_int*_vector() failed")
+	}
+	return nil
+}
+
+// setFloatVector() assigns a Float vector.
+func (column *Column) setFloatVector(id uint32, value []float64) error {
+	var grnVector C.grngo_vector
+	if len(value) != 0 {
+		grnVector.ptr = unsafe.Pointer(&value[0])
+		grnVector.size = C.size_t(len(value))
+	}
+	if ok := C.grngo_column_set_float_vector(column.table.db.ctx, column.obj,
+		C.grn_id(id), &grnVector); ok != C.GRN_TRUE {
+		return fmt.Errorf("grngo_column_set_float_vector() failed")
+	}
+	return nil
+}
+
+// setFloat32Vector() assigns a Float32 vector.
+func (column *Column) setFloat32Vector(id uint32, value []float32) error {
+	if (column.valueType != Float32) || !column.isVector {
+		return fmt.Errorf("value type conflict")
+	}
+	var grnVector C.grngo_vector
+	if len(value) != 0 {
+		grnVector.ptr = unsafe.Pointer(&value[0])
+		grnVector.size = C.size_t(len(value))
+	}
+	if ok := C.grngo_column_set_float32_vector(column.table.db.ctx, column.obj,
+		C.grn_id(id), &grnVector); ok != C.GRN_TRUE {
+		return fmt.Errorf("grngo_column_set_float32_vector() failed")
+	}
+	return nil
+}
+
+// setTimeVector() assigns a Time vector.
+// Note that the values are truncated to microseconds.
+func (column *Column) setTimeVector(id uint32, value []time.Time) error {
+	if column.valueType != Time {
+		return fmt.Errorf("value type conflict")
+	}
+	grnValue := make([]int64, len(value))
+	for i, v := range value {
+		grnValue[i] = timeToGrnTime(v)
+	}
+	var grnVector C.grngo_vector
+	if len(grnValue) != 0 {
+		grnVector.ptr = unsafe.Pointer(&grnValue[0])
+		grnVector.size = C.size_t(len(grnValue))
+	}
+	if ok := C.grngo_column_set_time_vector(column.table.db.ctx, column.obj,
+		C.grn_id(id), &grnVector); ok != C.GRN_TRUE {
+		return fmt.Errorf("grngo_column_set_time_vector() failed")
+	}
+	return nil
+}
+
+// setGeoPointVector() assigns a GeoPoint vector.
+func (column *Column) setGeoPointVector(id uint32, value []GeoPoint) error {
+	grnValue := make([]C.grn_geo_point, len(value))
+	for i, v := range value {
+		grnValue[i] = C.grn_geo_point{C.int(v.Latitude), C.int(v.Longitude)}
+	}
+	var grnVector C.grngo_vector
+	if len(grnValue) != 0 {
+		grnVector.ptr = unsafe.Pointer(&grnValue[0])
+		grnVector.size = C.size_t(len(grnValue))
+	}
+	if ok := C.grngo_column_set_geo_point_vector(column.table.db.ctx,
+		column.obj, C.grn_builtin_type(column.valueType),
+		C.grn_id(id), &grnVector); ok != C.GRN_TRUE {
+		return fmt.Errorf("grngo_column_set_geo_point_vector() failed")
+	}
+	return nil
+}
+
+// setTextVector() assigns a Text vector.
+func (column *Column) setTextVector(id uint32, value [][]byte) error {
+	grnValue := make([]C.grngo_text, len(value))
+	for i, v := range value {
+		if len(v) != 0 {
+			grnValue[i].ptr = (*C.char)(unsafe.Pointer(&v[0]))
+			grnValue[i].size = C.size_t(len(v))
+		}
+	}
+	var grnVector C.grngo_vector
+	if len(grnValue) != 0 {
+		grnVector.ptr = unsafe.Pointer(&grnValue[0])
+		grnVector.size = C.size_t(len(grnValue))
+	}
+	if ok := C.grngo_column_set_text_vector(column.table.db.ctx,
+		column.obj, C.grn_id(id), &grnVector); ok != C.GRN_TRUE {
+		return fmt.Errorf("grngo_column_set_text_vector() failed")
+	}
+	return nil
+}
+
+// clearValue() clears a value.
+func (column *Column) clearValue(id uint32) error {
+	if err := column.table.db.checkWritable(); err != nil {
+		return err
+	}
+	if column.isIndex {
+		return fmt.Errorf("not supported by index column: name = <%s>",
+			column.name)
+	}
+	if ok := C.grngo_column_clear_value(column.table.db.ctx, column.obj,
+		C.grn_id(id)); ok != C.GRN_TRUE {
+		return fmt.Errorf("grngo_column_clear_value() failed")
+	}
+	return nil
+}
+
+// ClearValue() clears a value, that is, a scalar becomes 0 or empty, and a
+// vector becomes empty.
+func (column *Column) ClearValue(id uint32) error {
+	column.table.db.mutex.Lock()
+	defer column.table.db.mutex.Unlock()
+	if column.obj == nil {
+		return fmt.Errorf("column removed: name = <%s>", column.name)
+	}
+	return column.clearValue(id)
+}
+
+// normalizeValue() converts a value of an integer type into int64 and a
+// value of a float type into float64, or float32 for a Float32 column, so
+// that SetValue() accepts values such as 42 (int) and float32(1.5).
+// A []uint64 is converted into []int64 in the same way.
+// An integer value must fit in the value type.
+func (column *Column) normalizeValue(value interface{}) (interface{}, error) {
+	switch v := value.(type) {
+	case nil, bool, time.Time, GeoPoint, []byte:
+		return value, nil
+	case []uint64:
+		values := make([]int64, len(v))
+		for i := range v {
+			value, err := column.normalizeValue(v[i])
+			if err != nil {
+				return nil, err
+			}
+			values[i] = value.(int64)
+		}
+		return values, nil
+	}
+	return normalizeNumber(column.valueType, value)
+}
+
+// SetValue() assigns a value.
+// If value is nil, SetValue() clears the value like ClearValue().
+// Values of the other integer and float types, such as int and float32, are
+// converted by normalizeValue().
+func (column *Column) SetValue(id uint32, value interface{}) error {
+	column.table.db.mutex.Lock()
+	defer column.table.db.mutex.Unlock()
+	return column.setValue(id, value)
+}
+
+// setValue() assigns a value without locking.
+func (column *Column) setValue(id uint32, value interface{}) error {
+	if column.obj == nil {
+		return fmt.Errorf("column removed: name = <%s>", column.name)
+	}
+	if err := column.table.db.checkWritable(); err != nil {
+		return err
+	}
+	value, err := column.normalizeValue(value)
+	if err != nil {
+		return err
+	}
+	switch v := value.(type) {
+	case nil:
+		return column.clearValue(id)
+	case bool:
+		return column.setBool(id, v)
+	case int64:
+		return column.setInt(id, v)
+	case float64:
+		return column.setFloat(id, v)
+	case float32:
+		return column.setFloat32(id, v)
+	case time.Time:
+		return column.setTime(id, v)
+	case GeoPoint:
+		return column.setGeoPoint(id, v)
+	case []byte:
+		return column.setText(id, v)
+	case []bool:
+		return column.setBoolVector(id, v)
+	case []int64:
+		return column.setIntVector(id, v)
+	case []float64:
+		return column.setFloatVector(id, v)
+	case []float32:
+		return column.setFloat32Vector(id, v)
+	case []time.Time:
+		return column.setTimeVector(id, v)
+	case []GeoPoint:
+		return column.setGeoPointVector(id, v)
+	case [][]byte:
+		return column.setTextVector(id, v)
+	default:
+		return fmt.Errorf("unsupported value type: name = <%s>",
+			reflect.TypeOf(value).Name())
+	}
+}
+
+// SetValues() assigns values[i] to the row ids[i] for each i.
+// The supported value types are []bool, []int64, []float64, []time.Time,
+// []GeoPoint, and [][]byte, and all the assignments are done in one cgo call.
+// If ids and values have different lengths, SetValues() fails without any
+// assignment. If an assignment fails, the values before it remain assigned
+// and the returned error reports the number of them.
+func (column *Column) SetValues(ids []uint32, values interface{}) error {
+	column.table.db.mutex.Lock()
+	defer column.table.db.mutex.Unlock()
+	if column.obj == nil {
+		return fmt.Errorf("column removed: name = <%s>", column.name)
+	}
+	if err := column.table.db.checkWritable(); err != nil {
+		return err
+	}
+	if column.isVector {
+		return fmt.Errorf("value type conflict")
+	}
+	slice := reflect.ValueOf(values)
+	if slice.Kind() != reflect.Slice {
+		return fmt.Errorf("unsupported value type: type = %T", values)
+	}
+	if slice.Len() != len(ids) {
+		return fmt.Errorf("length mismatch: len(ids) = %d, len(values) = %d",
+			len(ids), slice.Len())
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+	ctx := column.table.db.ctx
+	grnIDs := (*C.grn_id)(unsafe.Pointer(&ids[0]))
+	size := C.size_t(len(ids))
+	var n C.size_t
+	switch v := values.(type) {
+	case []bool:
+		if column.valueType != Bool {
+			return fmt.Errorf("value type conflict")
+		}
+		grnValues := make([]C.grn_bool, len(v))
+		for i := range v {
+			if v[i] {
+				grnValues[i] = C.GRN_TRUE
+			}
+		}
+		n = C.grngo_column_set_bools(ctx, column.obj, grnIDs, &grnValues[0], size)
+	case []int64:
+		switch column.valueType {
+		case Int8, Int16, Int32, Int64, UInt8, UInt16, UInt32, UInt64:
+		default:
+			return fmt.Errorf("value type conflict")
+		}
+		n = C.grngo_column_set_ints(ctx, column.obj,
+			C.grn_builtin_type(column.valueType), grnIDs,
+			(*C.int64_t)(unsafe.Pointer(&v[0])), size)
+	case []float64:
+		if column.valueType != Float {
+			return fmt.Errorf("value type conflict")
+		}
+		n = C.grngo_column_set_floats(ctx, column.obj, grnIDs,
+			(*C.double)(unsafe.Pointer(&v[0])), size)
+	case []time.Time:
+		if column.valueType != Time {
+			return fmt.Errorf("value type conflict")
+		}
+		grnValues := make([]C.int64_t, len(v))
+		for i := range v {
+			grnValues[i] = C.int64_t(timeToGrnTime(v[i]))
+		}
+		n = C.grngo_column_set_times(ctx, column.obj, grnIDs, &grnValues[0], size)
+	case []GeoPoint:
+		switch column.valueType {
+		case TokyoGeoPoint, WGS84GeoPoint:
+		default:
+			return fmt.Errorf("value type conflict")
+		}
+		grnValues := make([]C.grn_geo_point, len(v))
+		for i := range v {
+			grnValues[i] = C.grn_geo_point{C.int(v[i].Latitude),
+				C.int(v[i].Longitude)}
+		}
+		n = C.grngo_column_set_geo_points(ctx, column.obj,
+			C.grn_builtin_type(column.valueType), grnIDs, &grnValues[0], size)
+	case [][]byte:
+		switch column.valueType {
+		case ShortText, Text, LongText:
+		default:
+			return fmt.Errorf("value type conflict")
+		}
+		grnValues := make([]C.grngo_text, len(v))
+		for i := range v {
+			if len(v[i]) != 0 {
+				grnValues[i].ptr = (*C.char)(unsafe.Pointer(&v[i][0]))
+				grnValues[i].size = C.size_t(len(v[i]))
+			}
+		}
+		n = C.grngo_column_set_texts(ctx, column.obj, grnIDs, &grnValues[0], size)
+	default:
+		return fmt.Errorf("unsupported value type: type = %T", values)
+	}
+	if n != size {
+		return fmt.Errorf("grngo_column_set_*s() failed: n = %d, id = %d",
+			n, ids[n])
+	}
+	return nil
+}
+
+// checkWeightedVector() checks whether the column is a vector column with
+// weights and values and weights have the same length.
+// The caller must hold db.mutex.
+func (column *Column) checkWeightedVector(numValues, numWeights int) error {
+	if column.obj == nil {
+		return fmt.Errorf("column removed: name = <%s>", column.name)
+	}
+	if !column.isVector {
+		return fmt.Errorf("not a vector column: name = <%s>", column.name)
+	}
+	if C.grngo_column_has_weight(column.table.db.ctx, column.obj) != C.GRN_TRUE {
+		return fmt.Errorf("column is not WITH_WEIGHT: name = <%s>", column.name)
+	}
+	if numValues != numWeights {
+		return fmt.Errorf("length mismatch: len(values) = %d, len(weights) = %d",
+			numValues, numWeights)
+	}
+	return nil
+}
+
+// setWeightedVector() assigns a vector of numbers with weights.
+// values must point to n values of dataType.
+func (column *Column) setWeightedVector(id uint32, dataType DataType,
+	values unsafe.Pointer, valueSize uintptr, weights []uint32) error {
+	var grnWeights *C.uint32_t
+	if len(weights) != 0 {
+		grnWeights = (*C.uint32_t)(unsafe.Pointer(&weights[0]))
+	}
+	if ok := C.grngo_column_set_weighted_vector(column.table.db.ctx,
+		column.obj, C.grn_id(id), C.grn_builtin_type(dataType), values,
+		C.size_t(valueSize), grnWeights, C.size_t(len(weights))); ok != C.GRN_TRUE {
+		return fmt.Errorf("grngo_column_set_weighted_vector() failed")
+	}
+	return nil
+}
+
+// SetWeightedIntVector() assigns an Int vector with weights.
+// The column must be a vector column created with WithWeight and weights[i]
+// is the weight of values[i]. values are converted into the value type of the
+// column and must fit in it.
+func (column *Column) SetWeightedIntVector(id uint32, values []int64,
+	weights []uint32) error {
+	column.table.db.mutex.Lock()
+	defer column.table.db.mutex.Unlock()
+	if err := column.checkWeightedVector(len(values), len(weights)); err != nil {
+		return err
+	}
+	if err := column.table.db.checkWritable(); err != nil {
+		return err
+	}
+	switch column.valueType {
+	case Int8, Int16, Int32, Int64, UInt8, UInt16, UInt32, UInt64:
+	default:
+		return fmt.Errorf("value type conflict")
+	}
+	if column.valueType != UInt64 {
+		for _, value := range values {
+			if err := checkIntRange(column.valueType, value); err != nil {
+				return err
+			}
+		}
+	}
+	var grnValues *C.int64_t
+	var grnWeights *C.uint32_t
+	if len(values) != 0 {
+		grnValues = (*C.int64_t)(unsafe.Pointer(&values[0]))
+		grnWeights = (*C.uint32_t)(unsafe.Pointer(&weights[0]))
+	}
+	if ok := C.grngo_column_set_weighted_int_vector(column.table.db.ctx,
+		column.obj, C.grn_id(id), C.grn_builtin_type(column.valueType),
+		grnValues, grnWeights, C.size_t(len(values))); ok != C.GRN_TRUE {
+		return fmt.Errorf("grngo_column_set_weighted_int_vector() failed")
+	}
+	return nil
+}
+
+// SetWeightedFloatVector() assigns a Float vector with weights.
+// See SetWeightedIntVector() for details.
+func (column *Column) SetWeightedFloatVector(id uint32, values []float64,
+	weights []uint32) error {
+	column.table.db.mutex.Lock()
+	defer column.table.db.mutex.Unlock()
+	if err := column.checkWeightedVector(len(values), len(weights)); err != nil {
+		return err
+	}
+	if err := column.table.db.checkWritable(); err != nil {
+		return err
+	}
+	if column.valueType != Float {
+		return fmt.Errorf("value type conflict")
+	}
+	var ptr unsafe.Pointer
+	if len(values) != 0 {
+		ptr = unsafe.Pointer(&values[0])
+	}
+	return column.setWeightedVector(id, Float, ptr, unsafe.Sizeof(float64(0)),
+		weights)
+}
+
+// SetWeightedTextVector() assigns a Text vector with weights.
+// See SetWeightedIntVector() for details.
+func (column *Column) SetWeightedTextVector(id uint32, values [][]byte,
+	weights []uint32) error {
+	column.table.db.mutex.Lock()
+	defer column.table.db.mutex.Unlock()
+	if err := column.checkWeightedVector(len(values), len(weights)); err != nil {
+		return err
+	}
+	if err := column.table.db.checkWritable(); err != nil {
+		return err
+	}
+	switch column.valueType {
+	case ShortText, Text, LongText:
+	default:
+		return fmt.Errorf("value type conflict")
+	}
+	grnValues := make([]C.grngo_text, len(values))
+	for i, v := range values {
+		if len(v) != 0 {
+			grnValues[i].ptr = (*C.char)(unsafe.Pointer(&v[0]))
+			grnValues[i].size = C.size_t(len(v))
+		}
+	}
+	var grnVector C.grngo_vector
+	var grnWeights *C.uint32_t
+	if len(grnValues) != 0 {
+		grnVector.ptr = unsafe.Pointer(&grnValues[0])
+		grnVector.size = C.size_t(len(grnValues))
+		grnWeights = (*C.uint32_t)(unsafe.Pointer(&weights[0]))
+	}
+	if ok := C.grngo_column_set_weighted_text_vector(column.table.db.ctx,
+		column.obj, C.grn_id(id), &grnVector, grnWeights); ok != C.GRN_TRUE {
+		return fmt.Errorf("grngo_column_set_weighted_text_vector() failed")
+	}
+	return nil
+}
+
+// checkAppend() checks whether a value can be appended to the column.
+// The caller must hold db.mutex.
+func (column *Column) checkAppend(id uint32) error {
+	if column.obj == nil {
+		return fmt.Errorf("column removed: name = <%s>", column.name)
+	}
+	if err := column.table.db.checkWritable(); err != nil {
+		return err
+	}
+	if !column.isVector {
+		return fmt.Errorf("not a vector column: name = <%s>", column.name)
+	}
+	if C.grn_table_at(column.table.db.ctx, column.table.obj,
+		C.grn_id(id)) == C.GRN_ID_NIL {
+		return fmt.Errorf("%w: table = <%s>, id = %d", ErrRowNotFound,
+			column.table.name, id)
+	}
+	return nil
+}
+
+// AppendInt() appends an Int value to a vector column without reading the
+// stored vector.
+func (column *Column) AppendInt(id uint32, value int64) error {
+	column.table.db.mutex.Lock()
+	defer column.table.db.mutex.Unlock()
+	if err := column.checkAppend(id); err != nil {
+		return err
+	}
+	switch column.valueType {
+	case Int8, Int16, Int32, Int64, UInt8, UInt16, UInt32, UInt64:
+	default:
+		return fmt.Errorf("value type conflict")
+	}
+	if ok := C.grngo_column_append_int(column.table.db.ctx, column.obj,
+		C.grn_id(id), C.grn_builtin_type(column.valueType),
+		C.int64_t(value)); ok != C.GRN_TRUE {
+		return fmt.Errorf("grngo_column_append_int() failed")
+	}
+	return nil
+}
+
+// AppendFloat() appends a Float value to a vector column without reading the
+// stored vector.
+func (column *Column) AppendFloat(id uint32, value float64) error {
+	column.table.db.mutex.Lock()
+	defer column.table.db.mutex.Unlock()
+	if err := column.checkAppend(id); err != nil {
+		return err
+	}
+	if column.valueType != Float {
+		return fmt.Errorf("value type conflict")
+	}
+	if ok := C.grngo_column_append_float(column.table.db.ctx, column.obj,
+		C.grn_id(id), C.double(value)); ok != C.GRN_TRUE {
+		return fmt.Errorf("grngo_column_append_float() failed")
+	}
+	return nil
+}
+
+// AppendText() appends a Text value to a vector column without reading the
+// stored vector.
+func (column *Column) AppendText(id uint32, value []byte) error {
+	column.table.db.mutex.Lock()
+	defer column.table.db.mutex.Unlock()
+	if err := column.checkAppend(id); err != nil {
+		return err
+	}
+	switch column.valueType {
+	case ShortText, Text, LongText:
+	default:
+		return fmt.Errorf("value type conflict")
+	}
+	var grnValue C.grngo_text
+	if len(value) != 0 {
+		grnValue.ptr = (*C.char)(unsafe.Pointer(&value[0]))
+		grnValue.size = C.size_t(len(value))
+	}
+	if ok := C.grngo_column_append_text(column.table.db.ctx, column.obj,
+		C.grn_id(id), &grnValue); ok != C.GRN_TRUE {
+		return fmt.Errorf("grngo_column_append_text() failed")
+	}
+	return nil
+}
+
+// UpdateMode specifies how Column.Update() updates a value.
+type UpdateMode int
+
+const (
+	SetUpdate     = UpdateMode(iota) // GRN_OBJ_SET, the same as SetValue()
+	AppendUpdate                     // GRN_OBJ_APPEND, for Text and vectors
+	PrependUpdate                    // GRN_OBJ_PREPEND, for Text and vectors
+	IncrUpdate                       // GRN_OBJ_INCR, for numeric scalars
+	DecrUpdate                       // GRN_OBJ_DECR, for numeric scalars
+)
+
+// Update() updates a value in the manner specified by mode.
+// IncrUpdate and DecrUpdate add and subtract a number to and from a numeric
+// value atomically. AppendUpdate and PrependUpdate concatenate bytes to a
+// Text value, or elements to a vector, where value may be an element or a
+// slice of elements.
+func (column *Column) Update(id uint32, value interface{}, mode UpdateMode) error {
+	var flags C.int
+	switch mode {
+	case SetUpdate:
+		return column.SetValue(id, value)
+	case AppendUpdate:
+		flags = C.GRN_OBJ_APPEND
+	case PrependUpdate:
+		flags = C.GRN_OBJ_PREPEND
+	case IncrUpdate:
+		flags = C.GRN_OBJ_INCR
+	case DecrUpdate:
+		flags = C.GRN_OBJ_DECR
+	default:
+		return fmt.Errorf("undefined update mode: mode = %d", mode)
+	}
+	column.table.db.mutex.Lock()
+	defer column.table.db.mutex.Unlock()
+	if column.obj == nil {
+		return fmt.Errorf("column removed: name = <%s>", column.name)
+	}
+	if err := column.table.db.checkWritable(); err != nil {
+		return err
+	}
+	if C.grn_table_at(column.table.db.ctx, column.table.obj,
+		C.grn_id(id)) == C.GRN_ID_NIL {
+		return fmt.Errorf("%w: table = <%s>, id = %d", ErrRowNotFound,
+			column.table.name, id)
+	}
+	value, err := column.normalizeValue(value)
+	if err != nil {
+		return err
+	}
+	if f, ok := value.(float32); ok {
+		value = float64(f)
+	}
+	if (mode == IncrUpdate) || (mode == DecrUpdate) {
+		if column.isVector {
+			return fmt.Errorf("not a scalar column: name = <%s>", column.name)
+		}
+	} else if !column.isVector {
+		switch column.valueType {
+		case ShortText, Text, LongText:
+		default:
+			return fmt.Errorf("neither a Text nor a vector column: name = <%s>",
+				column.name)
+		}
+	}
+	isVector := C.grn_bool(C.GRN_FALSE)
+	if column.isVector {
+		isVector = C.GRN_TRUE
+	}
+	switch v := value.(type) {
+	case int64:
+		return column.updateInts(id, []int64{v}, isVector, flags)
+	case []int64:
+		if !column.isVector {
+			return fmt.Errorf("value type conflict")
+		}
+		return column.updateInts(id, v, isVector, flags)
+	case float64:
+		return column.updateFloats(id, []float64{v}, isVector, flags)
+	case []float64:
+		if !column.isVector {
+			return fmt.Errorf("value type conflict")
+		}
+		return column.updateFloats(id, v, isVector, flags)
+	case []byte:
+		if (mode == IncrUpdate) || (mode == DecrUpdate) {
+			return fmt.Errorf("value type conflict")
+		}
+		return column.updateTexts(id, [][]byte{v}, isVector, flags)
+	case [][]byte:
+		if !column.isVector {
+			return fmt.Errorf("value type conflict")
+		}
+		return column.updateTexts(id, v, isVector, flags)
+	default:
+		return fmt.Errorf("unsupported value type: name = <%s>, type = %T",
+			column.name, value)
+	}
+}
+
+// updateInts() updates a value with Int values.
+// The caller must hold db.mutex.
+func (column *Column) updateInts(id uint32, values []int64,
+	isVector C.grn_bool, flags C.int) error {
+	switch column.valueType {
+	case Int8, Int16, Int32, Int64, UInt8, UInt16, UInt32, UInt64:
+	case Float:
+		floats := make([]float64, len(values))
+		for i, value := range values {
+			floats[i] = float64(value)
+		}
+		return column.updateFloats(id, floats, isVector, flags)
+	default:
+		return fmt.Errorf("value type conflict")
+	}
+	if len(values) == 0 {
+		return nil
+	}
+	if ok := C.grngo_column_update_int(column.table.db.ctx, column.obj,
+		C.grn_id(id), C.grn_builtin_type(column.valueType), isVector,
+		(*C.int64_t)(unsafe.Pointer(&values[0])), C.size_t(len(values)),
+		flags); ok != C.GRN_TRUE {
+		return newGroongaError(column.table.db.ctx, "grngo_column_update_int()",
+			column.table.db.ctx.rc)
+	}
+	return nil
+}
+
+// updateFloats() updates a value with Float values.
+// The caller must hold db.mutex.
+func (column *Column) updateFloats(id uint32, values []float64,
+	isVector C.grn_bool, flags C.int) error {
+	if column.valueType != Float {
+		return fmt.Errorf("value type conflict")
+	}
+	if len(values) == 0 {
+		return nil
+	}
+	if ok := C.grngo_column_update_float(column.table.db.ctx, column.obj,
+		C.grn_id(id), isVector, (*C.double)(unsafe.Pointer(&values[0])),
+		C.size_t(len(values)), flags); ok != C.GRN_TRUE {
+		return newGroongaError(column.table.db.ctx, "grngo_column_update_float()",
+			column.table.db.ctx.rc)
+	}
+	return nil
+}
+
+// updateTexts() updates a value with Text values.
+// The caller must hold db.mutex.
+func (column *Column) updateTexts(id uint32, values [][]byte,
+	isVector C.grn_bool, flags C.int) error {
+	switch column.valueType {
+	case ShortText, Text, LongText:
+	default:
+		return fmt.Errorf("value type conflict")
+	}
+	if len(values) == 0 {
+		return nil
+	}
+	grnValues := make([]C.grngo_text, len(values))
+	for i, value := range values {
+		if len(value) != 0 {
+			grnValues[i].ptr = (*C.char)(unsafe.Pointer(&value[0]))
+			grnValues[i].size = C.size_t(len(value))
+		}
+	}
+	if ok := C.grngo_column_update_text(column.table.db.ctx, column.obj,
+		C.grn_id(id), isVector, &grnValues[0], C.size_t(len(values)),
+		flags); ok != C.GRN_TRUE {
+		return newGroongaError(column.table.db.ctx, "grngo_column_update_text()",
+			column.table.db.ctx.rc)
+	}
+	return nil
+}
+
+// checkTextStream() checks whether a Text value of the row can be streamed.
+// The caller must hold db.mutex.
+func (column *Column) checkTextStream(id uint32) error {
+	if column.obj == nil {
+		return fmt.Errorf("column removed: name = <%s>", column.name)
+	}
+	switch column.valueType {
+	case ShortText, Text, LongText:
+	default:
+		return fmt.Errorf("value type conflict")
+	}
+	if column.isVector {
+		return fmt.Errorf("value type conflict")
+	}
+	if C.grn_table_at(column.table.db.ctx, column.table.obj,
+		C.grn_id(id)) == C.GRN_ID_NIL {
+		return fmt.Errorf("%w: table = <%s>, id = %d", ErrRowNotFound,
+			column.table.name, id)
+	}
+	return nil
+}
+
+// textChunkSize is the maximum size of a write by GetTextTo().
+const textChunkSize = 64 * 1024
+
+// GetTextTo() writes a Text value into w and returns the number of written
+// bytes. The value is written from the buffer of Groonga in chunks of at most
+// textChunkSize bytes without being copied into Go memory.
+// w is called while the DB is locked and so must not use the DB.
+func (column *Column) GetTextTo(id uint32, w io.Writer) (int64, error) {
+	column.table.db.mutex.Lock()
+	defer column.table.db.mutex.Unlock()
+	if err := column.checkTextStream(id); err != nil {
+		return 0, err
+	}
+	ctx := column.table.db.ctx
+	var grnValue C.grngo_text
+	buf := C.grngo_column_open_text(ctx, column.obj, C.grn_id(id), &grnValue)
+	if buf == nil {
+		return 0, fmt.Errorf("grngo_column_open_text() failed")
+	}
+	defer C.grngo_text_close(ctx, buf)
+	if grnValue.size == 0 {
+		return 0, nil
+	}
+	value := unsafe.Slice((*byte)(unsafe.Pointer(grnValue.ptr)), int(grnValue.size))
+	var total int64
+	for len(value) != 0 {
+		chunk := value
+		if len(chunk) > textChunkSize {
+			chunk = chunk[:textChunkSize]
+		}
+		n, err := w.Write(chunk)
+		total += int64(n)
+		if err != nil {
+			return total, fmt.Errorf("io.Writer.Write() failed: %v", err)
+		}
+		value = value[len(chunk):]
+	}
+	return total, nil
+}
+
+// SetTextFrom() reads r until EOF and assigns the content as a Text value.
+// The whole content is read before the assignment, so that concurrent calls
+// never interleave and r may use the DB. If reading fails, the value is not
+// changed.
+func (column *Column) SetTextFrom(id uint32, r io.Reader) error {
+	value, err := io.ReadAll(r)
+	if err != nil {
+		return fmt.Errorf("io.Reader.Read() failed: %v", err)
+	}
+	column.table.db.mutex.Lock()
+	defer column.table.db.mutex.Unlock()
+	if err := column.checkTextStream(id); err != nil {
+		return err
+	}
+	if err := column.table.db.checkWritable(); err != nil {
+		return err
+	}
+	var grnValue C.grngo_text
+	if len(value) != 0 {
+		grnValue.ptr = (*C.char)(unsafe.Pointer(&value[0]))
+		grnValue.size = C.size_t(len(value))
+	}
+	if ok := C.grngo_column_set_text(column.table.db.ctx, column.obj,
+		C.grn_id(id), &grnValue); ok != C.GRN_TRUE {
+		return fmt.Errorf("grngo_column_set_text() failed")
+	}
+	return nil
+}
+
+// GetWeightedTextVector() gets a Text vector and its weights.
+// The column must be a vector column created with WithWeight and weights[i]
+// is the weight of values[i].
+func (column *Column) GetWeightedTextVector(id uint32) (
+	values [][]byte, weights []uint32, err error) {
+	column.table.db.mutex.Lock()
+	defer column.table.db.mutex.Unlock()
+	if err := column.checkWeightedVector(0, 0); err != nil {
+		return nil, nil, err
+	}
+	switch column.valueType {
+	case ShortText, Text, LongText:
+	default:
+		return nil, nil, fmt.Errorf("value type conflict")
+	}
+	ctx := column.table.db.ctx
+	if C.grn_table_at(ctx, column.table.obj, C.grn_id(id)) == C.GRN_ID_NIL {
+		return nil, nil, fmt.Errorf("%w: table = <%s>, id = %d", ErrRowNotFound,
+			column.table.name, id)
+	}
+	var grnVector C.grngo_vector
+	if ok := C.grngo_column_get_weighted_text_vector(ctx, column.obj,
+		C.grn_id(id), &grnVector, nil); ok != C.GRN_TRUE {
+		return nil, nil, fmt.Errorf("grngo_column_get_weighted_text_vector() failed")
+	}
+	if grnVector.size == 0 {
+		return make([][]byte, 0), make([]uint32, 0), nil
+	}
+	grnValues := make([]C.grngo_text, int(grnVector.size))
+	grnVector.ptr = unsafe.Pointer(&grnValues[0])
+	weights = make([]uint32, int(grnVector.size))
+	grnWeights := (*C.uint32_t)(unsafe.Pointer(&weights[0]))
+	if ok := C.grngo_column_get_weighted_text_vector(ctx, column.obj,
+		C.grn_id(id), &grnVector, grnWeights); ok != C.GRN_TRUE {
+		return nil, nil, fmt.Errorf("grngo_column_get_weighted_text_vector() failed")
+	}
+	values = make([][]byte, int(grnVector.size))
+	for i, grnValue := range grnValues {
+		if grnValue.size != 0 {
+			values[i] = make([]byte, int(grnValue.size))
+			grnValues[i].ptr = (*C.char)(unsafe.Pointer(&values[i][0]))
+		}
+	}
+	if ok := C.grngo_column_get_weighted_text_vector(ctx, column.obj,
+		C.grn_id(id), &grnVector, grnWeights); ok != C.GRN_TRUE {
+		return nil, nil, fmt.Errorf("grngo_column_get_weighted_text_vector() failed")
+	}
+	return values, weights, nil
+}
+
+// getBool() gets a Bool value.
+func (column *Column) getBool(id uint32) (interface{}, error) {
+	var grnValue C.grn_bool
+	if ok := C.grngo_column_get_bool(column.table.db.ctx, column.obj,
+		C.grn_id(id), &grnValue); ok != C.GRN_TRUE {
+		return nil, fmt.Errorf("grngo_column_get_bool() failed")
+	}
+	return grnValue == C.GRN_TRUE, nil
+}
+
+// getInt() gets an Int value.
+func (column *Column) getInt(id uint32) (interface{}, error) {
+	var grnValue C.int64_t
+	if ok := C.grngo_column_get_int(column.table.db.ctx, column.obj,
+		C.grn_builtin_type(column.valueType),
+		C.grn_id(id), &grnValue); ok != C.GRN_TRUE {
+		return nil, fmt.Errorf("grngo_column_get_int() failed")
+	}
+	return int64(grnValue), nil
+}
+
+// getFloat() gets a Float value.
+func (column *Column) getFloat(id uint32) (interface{}, error) {
+	var grnValue C.double
+	if ok := C.grngo_column_get_float(column.table.db.ctx, column.obj,
+		C.grn_id(id), &grnValue); ok != C.GRN_TRUE {
+		return nil, fmt.Errorf("grngo_column_get_float() failed")
+	}
+	return float64(grnValue), nil
+}
+
+// getFloat32() gets a Float32 value.
+func (column *Column) getFloat32(id uint32) (interface{}, error) {
+	var grnValue C.float
+	if ok := C.grngo_column_get_float32(column.table.db.ctx, column.obj,
+		C.grn_id(id), &grnValue); ok != C.GRN_TRUE {
+		return nil, fmt.Errorf("grngo_column_get_float32() failed")
+	}
+	return float32(grnValue), nil
+}
+
+// getTime() gets a Time value.
+func (column *Column) getTime(id uint32) (interface{}, error) {
+	var grnValue C.int64_t
+	if ok := C.grngo_column_get_time(column.table.db.ctx, column.obj,
+		C.grn_id(id), &grnValue); ok != C.GRN_TRUE {
+		return nil, fmt.Errorf("grngo_column_get_time() failed")
+	}
+	return grnTimeToTime(int64(grnValue)), nil
+}
+
+// getGeoPoint() gets a GeoPoint value.
+func (column *Column) getGeoPoint(id uint32) (interface{}, error) {
+	var grnValue C.grn_geo_point
+	if ok := C.grngo_column_get_geo_point(column.table.db.ctx, column.obj,
+		C.grn_id(id), &grnValue); ok != C.GRN_TRUE {
+		return nil, fmt.Errorf("grngo_column_get_geo_point() failed")
+	}
+	return GeoPoint{int32(grnValue.latitude), int32(grnValue.longitude)}, nil
+}
+
+// getTextInto() gets a Text value into buf.
+// grngo_column_get_text() copies the value only if it fits in buf, so buf is
+// reallocated and the value is read again only if buf is too small.
+func (column *Column) getTextInto(id uint32, buf []byte) ([]byte, error) {
+	buf = buf[:cap(buf)]
+	var grnValue C.grngo_text
+	if len(buf) != 0 {
+		grnValue.ptr = (*C.char)(unsafe.Pointer(&buf[0]))
+		grnValue.size = C.size_t(len(buf))
+	}
+	if ok := C.grngo_column_get_text(column.table.db.ctx, column.obj,
+		C.grn_id(id), &grnValue); ok != C.GRN_TRUE {
+		return nil, fmt.Errorf("grngo_column_get_text() failed")
+	}
+	size := int(grnValue.size)
+	if size <= len(buf) {
+		return buf[:size], nil
+	}
+	buf = make([]byte, size)
+	grnValue.ptr = (*C.char)(unsafe.Pointer(&buf[0]))
+	if ok := C.grngo_column_get_text(column.table.db.ctx, column.obj,
+		C.grn_id(id), &grnValue); ok != C.GRN_TRUE {
+		return nil, fmt.Errorf("grngo_column_get_text() failed")
+	}
+	return buf, nil
+}
+
+// getText() gets a Text value.
+func (column *Column) getText(id uint32) (interface{}, error) {
+	var grnValue C.grngo_text
+	if ok := C.grngo_column_get_text(column.table.db.ctx, column.obj,
+		C.grn_id(id), &grnValue); ok != C.GRN_TRUE {
+		return nil, fmt.Errorf("grngo_column_get_text() failed")
+	}
+	if grnValue.size == 0 {
+		return make([]byte, 0), nil
+	}
+	value := make([]byte, int(grnValue.size))
+	grnValue.ptr = (*C.char)(unsafe.Pointer(&value[0]))
+	if ok := C.grngo_column_get_text(column.table.db.ctx, column.obj,
+		C.grn_id(id), &grnValue); ok != C.GRN_TRUE {
+		return nil, fmt.Errorf("grngo_column_get_text() failed")
+	}
+	return value, nil
+}
+
+// getBoolVector() gets a BoolVector.
+func (column *Column) getBoolVector(id uint32) (interface{}, error) {
+	var grnVector C.grngo_vector
+	if ok := C.grngo_column_get_bool_vector(column.table.db.ctx, column.obj,
+		C.grn_id(id), &grnVector); ok != C.GRN_TRUE {
+		return nil, fmt.Errorf("grngo_column_get_bool_vector() failed")
+	}
+	if grnVector.size == 0 {
+		return make([]bool, 0), nil
+	}
+	grnValue := make([]C.grn_bool, int(grnVector.size))
+	grnVector.ptr = unsafe.Pointer(&grnValue[0])
+	if ok := C.grngo_column_get_bool_vector(column.table.db.ctx, column.obj,
+		C.grn_id(id), &grnVector); ok != C.GRN_TRUE {
+		return nil, fmt.Errorf("grngo_column_get_bool_vector() failed")
+	}
+	value := make([]bool, int(grnVector.size))
+	for i, v := range grnValue {
+		value[i] = (v == C.GRN_TRUE)
+	}
+	return value, nil
+}
+
+// getIntVector() gets a IntVector.
+func (column *Column) getIntVector(id uint32) (interface{}, error) {
+	var grnValue C.grngo_vector
+	if ok := C.grngo_column_get_int_vector(column.table.db.ctx, column.obj,
+		C.grn_builtin_type(column.valueType),
+		C.grn_id(id), &grnValue); ok != C.GRN_TRUE {
+		return nil, fmt.Errorf("grngo_column_get_int_vector() failed")
+	}
+	if grnValue.size == 0 {
+		return make([]int64, 0), nil
+	}
+	value := make([]int64, int(grnValue.size))
+	grnValue.ptr = unsafe.Pointer(&value[0])
+	if ok := C.grngo_column_get_int_vector(column.table.db.ctx, column.obj,
+		C.grn_builtin_type(column.valueType),
+		C.grn_id(id), &grnValue); ok != C.GRN_TRUE {
+		return nil, fmt.Errorf("grngo_column_get_int_vector() failed")
+	}
+	return value, nil
+}
+
+// getFloatVector() gets a FloatVector.
+func (column *Column) getFloatVector(id uint32) (interface{}, error) {
+	var grnValue C.grngo_vector
+	if ok := C.grngo_column_get_float_vector(column.table.db.ctx, column.obj,
+		C.grn_id(id), &grnValue); ok != C.GRN_TRUE {
+		return nil, fmt.Errorf("grngo_column_get_float_vector() failed")
+	}
+	if grnValue.size == 0 {
+		return make([]float64, 0), nil
+	}
+	value := make([]float64, int(grnValue.size))
+	grnValue.ptr = unsafe.Pointer(&value[0])
+	if ok := C.grngo_column_get_float_vector(column.table.db.ctx, column.obj,
+		C.grn_id(id), &grnValue); ok != C.GRN_TRUE {
+		return nil, fmt.Errorf("grngo_column_get_float_vector() failed")
+	}
+	return value, nil
+}
+
+// getFloat32Vector() gets a Float32Vector.
+func (column *Column) getFloat32Vector(id uint32) (interface{}, error) {
+	var grnValue C.grngo_vector
+	if ok := C.grngo_column_get_float32_vector(column.table.db.ctx, column.obj,
+		C.grn_id(id), &grnValue); ok != C.GRN_TRUE {
+		return nil, fmt.Errorf("grngo_column_get_float32_vector() failed")
+	}
+	if grnValue.size == 0 {
+		return make([]float32, 0), nil
+	}
+	value := make([]float32, int(grnValue.size))
+	grnValue.ptr = unsafe.Pointer(&value[0])
+	if ok := C.grngo_column_get_float32_vector(column.table.db.ctx, column.obj,
+		C.grn_id(id), &grnValue); ok != C.GRN_TRUE {
+		return nil, fmt.Errorf("grngo_column_get_float32_vector() failed")
+	}
+	return value, nil
+}
+
+// getTimeVector() gets a TimeVector.
+func (column *Column) getTimeVector(id uint32) (interface{}, error) {
+	var grnVector C.grngo_vector
+	if ok := C.grngo_column_get_time_vector(column.table.db.ctx, column.obj,
+		C.grn_id(id), &grnVector); ok != C.GRN_TRUE {
+		return nil, fmt.Errorf("grngo_column_get_time_vector() failed")
+	}
+	if grnVector.size == 0 {
+		return make([]time.Time, 0), nil
+	}
+	grnValue := make([]int64, int(grnVector.size))
+	grnVector.ptr = unsafe.Pointer(&grnValue[0])
+	if ok := C.grngo_column_get_time_vector(column.table.db.ctx, column.obj,
+		C.grn_id(id), &grnVector); ok != C.GRN_TRUE {
+		return nil, fmt.Errorf("grngo_column_get_time_vector() failed")
+	}
+	value := make([]time.Time, int(grnVector.size))
+	for i, v := range grnValue {
+		value[i] = grnTimeToTime(v)
+	}
+	return value, nil
+}
+
+// getGeoPointVector() gets a GeoPointVector.
+func (column *Column) getGeoPointVector(id uint32) (interface{}, error) {
+	var grnValue C.grngo_vector
+	if ok := C.grngo_column_get_geo_point_vector(column.table.db.ctx, column.obj,
+		C.grn_id(id), &grnValue); ok != C.GRN_TRUE {
+		return nil, fmt.Errorf("grngo_column_get_geo_point_vector() failed")
+	}
+	if grnValue.size == 0 {
+		return make([]GeoPoint, 0), nil
+	}
+	grnPoints := make([]C.grn_geo_point, int(grnValue.size))
+	grnValue.ptr = unsafe.Pointer(&grnPoints[0])
+	if ok := C.grngo_column_get_geo_point_vector(column.table.db.ctx, column.obj,
+		C.grn_id(id), &grnValue); ok != C.GRN_TRUE {
+		return nil, fmt.Errorf("grngo_column_get_geo_point_vector() failed")
+	}
+	value := make([]GeoPoint, len(grnPoints))
+	for i, v := range grnPoints {
+		value[i] = GeoPoint{int32(v.latitude), int32(v.longitude)}
+	}
+	return value, nil
+}
+
+// getTextVector() gets a TextVector.
+func (column *Column) getTextVector(id uint32) (interface{}, error) {
+	var grnVector C.grngo_vector
+	if ok := C.grngo_column_get_text_vector(column.table.db.ctx, column.obj,
+		C.grn_id(id), &grnVector); ok != C.GRN_TRUE {
+		return nil, fmt.Errorf("grngo_column_get_text_vector() failed")
+	}
+	if grnVector.size == 0 {
+		return make([][]byte, 0), nil
+	}
+	grnValues := make([]C.grngo_text, int(grnVector.size))
+	grnVector.ptr = unsafe.Pointer(&grnValues[0])
+	if ok := C.grngo_column_get_text_vector(column.table.db.ctx, column.obj,
+		C.grn_id(id), &grnVector); ok != C.GRN_TRUE {
+		return nil, fmt.Errorf("grngo_column_get_text_vector() failed")
+	}
+	value := make([][]byte, int(grnVector.size))
+	for i, grnValue := range grnValues {
+		if grnValue.size != 0 {
+			value[i] = make([]byte, int(grnValue.size))
+			grnValues[i].ptr = (*C.char)(unsafe.Pointer(&value[i][0]))
+		}
+	}
+	if ok := C.grngo_column_get_text_vector(column.table.db.ctx, column.obj,
+		C.grn_id(id), &grnVector); ok != C.GRN_TRUE {
+		return nil, fmt.Errorf("grngo_column_get_text_vector() failed")
+	}
+	return value, nil
+}
+
+// reflectToScalar() converts a Go value into a scalar value which
+// Column.SetValue() accepts for dataType.
+func reflectToScalar(dataType DataType, value reflect.Value) (
+	interface{}, error) {
+	switch dataType {
+	case Bool:
+		if value.Kind() == reflect.Bool {
+			return value.Bool(), nil
+		}
+	case Int8, Int16, Int32, Int64, UInt8, UInt16, UInt32, UInt64:
+		switch value.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32,
+			reflect.Int64, reflect.Uint, reflect.Uint8, reflect.Uint16,
+			reflect.Uint32, reflect.Uint64:
+			return reflectToInt(dataType, value)
+		}
+	case Float:
+		switch value.Kind() {
+		case reflect.Float32, reflect.Float64:
+			return value.Float(), nil
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32,
+			reflect.Int64:
+			return float64(value.Int()), nil
+		}
+	case Float32:
+		switch value.Kind() {
+		case reflect.Float32, reflect.Float64:
+			return float32(value.Float()), nil
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32,
+			reflect.Int64:
+			return float32(value.Int()), nil
+		}
+	case Time:
+		if v, ok := value.Interface().(time.Time); ok {
+			return v, nil
+		}
+	case TokyoGeoPoint, WGS84GeoPoint:
+		if v, ok := value.Interface().(GeoPoint); ok {
+			return v, nil
+		}
+	case ShortText, Text, LongText:
+		switch v := value.Interface().(type) {
+		case string:
+			return []byte(v), nil
+		case []byte:
+			return v, nil
+		}
+	}
+	return nil, fmt.Errorf("value type conflict: type = %s, valueType = %s",
+		value.Type(), dataType)
+}
+
+// reflectToValue() converts a Go value into a value which Column.SetValue()
+// accepts for the column.
+func (column *Column) reflectToValue(value reflect.Value) (interface{}, error) {
+	if !column.isVector {
+		return reflectToScalar(column.valueType, value)
+	}
+	if (value.Kind() != reflect.Slice) && (value.Kind() != reflect.Array) {
+		return nil, fmt.Errorf("value type conflict: type = %s, valueType = []%s",
+			value.Type(), column.valueType)
+	}
+	values := make([]interface{}, value.Len())
+	for i := range values {
+		v, err := reflectToScalar(column.valueType, value.Index(i))
+		if err != nil {
+			return nil, err
+		}
+		values[i] = v
+	}
+	return makeVector(column.valueType, values), nil
+}
+
+// HasValue() reports whether a value is stored, so that an unset value can
+// be distinguished from a stored zero value.
+// A text value or a vector is regarded as unset if it is empty.
+// Groonga does not keep track of unset fixed-size values, such as integers,
+// so HasValue() always returns true for them.
+func (column *Column) HasValue(id uint32) (bool, error) {
+	column.table.db.mutex.Lock()
+	defer column.table.db.mutex.Unlock()
+	if column.obj == nil {
+		return false, fmt.Errorf("column removed: name = <%s>", column.name)
+	}
+	if column.isIndex {
+		return false, fmt.Errorf("not supported by index column: name = <%s>",
+			column.name)
+	}
+	if C.grn_table_at(column.table.db.ctx, column.table.obj,
+		C.grn_id(id)) == C.GRN_ID_NIL {
+		return false, fmt.Errorf("%w: table = <%s>, id = %d", ErrRowNotFound,
+			column.table.name, id)
+	}
+	var hasValue C.grn_bool
+	if ok := C.grngo_column_has_value(column.table.db.ctx, column.obj,
+		C.grn_id(id), &hasValue); ok != C.GRN_TRUE {
+		return false, fmt.Errorf("grngo_column_has_value() failed")
+	}
+	return hasValue == C.GRN_TRUE, nil
+}
+
+// GetValue() gets a value.
+func (column *Column) GetValue(id uint32) (interface{}, error) {
+	column.table.db.mutex.Lock()
+	defer column.table.db.mutex.Unlock()
+	return column.getValue(id)
+}
+
+// getValue() gets a value without locking.
+func (column *Column) getValue(id uint32) (interface{}, error) {
+	if column.obj == nil {
+		return nil, fmt.Errorf("column removed: name = <%s>", column.name)
+	}
+	if C.grn_table_at(column.table.db.ctx, column.table.obj,
+		C.grn_id(id)) == C.GRN_ID_NIL {
+		return nil, fmt.Errorf("%w: table = <%s>, id = %d", ErrRowNotFound,
+			column.table.name, id)
+	}
+	if !column.isVector {
+		switch column.valueType {
+		case Bool:
+			return column.getBool(id)
+		case Int8, Int16, Int32, Int64, UInt8, UInt16, UInt32, UInt64:
+			return column.getInt(id)
+		case Float:
+			return column.getFloat(id)
+		case Float32:
+			return column.getFloat32(id)
+		case Time:
+			return column.getTime(id)
+		case ShortText, Text, LongText:
+			return column.getText(id)
+		case TokyoGeoPoint, WGS84GeoPoint:
+			return column.getGeoPoint(id)
+		}
+	} else {
+		switch column.valueType {
+		case Bool:
+			return column.getBoolVector(id)
+		case Int8, Int16, Int32, Int64, UInt8, UInt16, UInt32, UInt64:
+			return column.getIntVector(id)
+		case Float:
+			return column.getFloatVector(id)
+		case Float32:
+			return column.getFloat32Vector(id)
+		case Time:
+			return column.getTimeVector(id)
+		case ShortText, Text, LongText:
+			return column.getTextVector(id)
+		case TokyoGeoPoint, WGS84GeoPoint:
+			return column.getGeoPointVector(id)
+		}
+	}
+	return nil, fmt.Errorf("undefined value type: valueType = %d", column.valueType)
+}
+
+// GetTextInto() gets a value of a ShortText/Text/LongText scalar column into
+// buf and returns the slice of the value.
+// buf is reused if its capacity is large enough, otherwise a new buffer is
+// allocated, so a read loop can avoid allocations by passing the returned
+// slice to the next call. The returned slice is overwritten by the next call
+// with the same buffer.
+func (column *Column) GetTextInto(id uint32, buf []byte) ([]byte, error) {
+	column.table.db.mutex.Lock()
+	defer column.table.db.mutex.Unlock()
+	if column.obj == nil {
+		return nil, fmt.Errorf("column removed: name = <%s>", column.name)
+	}
+	if column.isVector {
+		return nil, fmt.Errorf("vector column is not supported: name = <%s>",
+			column.name)
+	}
+	switch column.valueType {
+	case ShortText, Text, LongText:
+	default:
+		return nil, fmt.Errorf("not a text column: name = <%s>, valueType = %s",
+			column.name, column.valueType)
+	}
+	if C.grn_table_at(column.table.db.ctx, column.table.obj,
+		C.grn_id(id)) == C.GRN_ID_NIL {
+		return nil, fmt.Errorf("%w: table = <%s>, id = %d", ErrRowNotFound,
+			column.table.name, id)
+	}
+	return column.getTextInto(id, buf)
+}
+
+// GetUint() gets a value of a UInt8/16/32/64 scalar column as uint64.
+// GetValue() returns an int64 for the same value, so a UInt64 value greater
+// than math.MaxInt64 is returned as a negative number.
+func (column *Column) GetUint(id uint32) (uint64, error) {
+	column.table.db.mutex.Lock()
+	defer column.table.db.mutex.Unlock()
+	if column.obj == nil {
+		return 0, fmt.Errorf("column removed: name = <%s>", column.name)
+	}
+	if column.isVector {
+		return 0, fmt.Errorf("vector column is not supported: name = <%s>",
+			column.name)
+	}
+	switch column.valueType {
+	case UInt8, UInt16, UInt32, UInt64:
+	default:
+		return 0, fmt.Errorf("not an unsigned integer column: name = <%s>, valueType = %s",
+			column.name, column.valueType)
+	}
+	if C.grn_table_at(column.table.db.ctx, column.table.obj,
+		C.grn_id(id)) == C.GRN_ID_NIL {
+		return 0, fmt.Errorf("%w: table = <%s>, id = %d", ErrRowNotFound,
+			column.table.name, id)
+	}
+	value, err := column.getInt(id)
+	if err != nil {
+		return 0, err
+	}
+	// The bits of a UInt64 value are kept by the conversion into int64_t.
+	return uint64(value.(int64)), nil
+}
+
+// GetUintVector() gets a value of a UInt8/16/32/64 vector column as []uint64.
+// GetValue() returns an []int64 for the same value, so a UInt64 element
+// greater than math.MaxInt64 is returned as a negative number.
+func (column *Column) GetUintVector(id uint32) ([]uint64, error) {
+	column.table.db.mutex.Lock()
+	defer column.table.db.mutex.Unlock()
+	if column.obj == nil {
+		return nil, fmt.Errorf("column removed: name = <%s>", column.name)
+	}
+	if !column.isVector {
+		return nil, fmt.Errorf("not a vector column: name = <%s>", column.name)
+	}
+	switch column.valueType {
+	case UInt8, UInt16, UInt32, UInt64:
+	default:
+		return nil, fmt.Errorf("not an unsigned integer column: name = <%s>, valueType = %s",
+			column.name, column.valueType)
+	}
+	if C.grn_table_at(column.table.db.ctx, column.table.obj,
+		C.grn_id(id)) == C.GRN_ID_NIL {
+		return nil, fmt.Errorf("%w: table = <%s>, id = %d", ErrRowNotFound,
+			column.table.name, id)
+	}
+	value, err := column.getIntVector(id)
+	if err != nil {
+		return nil, err
+	}
+	// The bits of UInt64 elements are kept by the conversion into int64_t.
+	elements := value.([]int64)
+	values := make([]uint64, len(elements))
+	for i, element := range elements {
+		values[i] = uint64(element)
+	}
+	return values, nil
+}
+
+// checkGeoPoint() checks whether GeoPoint values of the row can be read.
+// The caller must hold db.mutex.
+func (column *Column) checkGeoPoint(id uint32, isVector bool) error {
+	if column.obj == nil {
+		return fmt.Errorf("column removed: name = <%s>", column.name)
+	}
+	if column.isVector != isVector {
+		if isVector {
+			return fmt.Errorf("not a vector column: name = <%s>", column.name)
+		}
+		return fmt.Errorf("vector column is not supported: name = <%s>",
+			column.name)
 	}
-	rowInfo := C.grngo_table_insert_void(table.db.ctx, table.obj)
-	if rowInfo.id == C.GRN_ID_NIL {
-		return false, NilID, fmt.Errorf("grngo_table_insert_void() failed")
+	switch column.valueType {
+	case TokyoGeoPoint, WGS84GeoPoint:
+	default:
+		return fmt.Errorf("not a GeoPoint column: name = <%s>, valueType = %s",
+			column.name, column.valueType)
 	}
-	return rowInfo.inserted == C.GRN_TRUE, uint32(rowInfo.id), nil
+	if C.grn_table_at(column.table.db.ctx, column.table.obj,
+		C.grn_id(id)) == C.GRN_ID_NIL {
+		return fmt.Errorf("%w: table = <%s>, id = %d", ErrRowNotFound,
+			column.table.name, id)
+	}
+	return nil
 }
 
-// insertBool() inserts a row with Bool key.
-func (table *Table) insertBool(key bool) (bool, uint32, error) {
-	if table.keyType != Bool {
-		return false, NilID, fmt.Errorf("key type conflict")
+// GetGeoPointDegrees() gets a value of a GeoPoint scalar column as latitude
+// and longitude in decimal degrees, like GeoPoint.Degrees().
+func (column *Column) GetGeoPointDegrees(id uint32) (lat, lng float64, err error) {
+	column.table.db.mutex.Lock()
+	defer column.table.db.mutex.Unlock()
+	if err := column.checkGeoPoint(id, false); err != nil {
+		return 0.0, 0.0, err
+	}
+	value, err := column.getGeoPoint(id)
+	if err != nil {
+		return 0.0, 0.0, err
 	}
-	grnKey := C.grn_bool(C.GRN_FALSE)
-	if key {
-		grnKey = C.grn_bool(C.GRN_TRUE)
+	lat, lng = value.(GeoPoint).Degrees()
+	return lat, lng, nil
+}
+
+// GetGeoPointDegreesVector() gets a value of a GeoPoint vector column as
+// latitudes and longitudes in decimal degrees, where lats[i] and lngs[i] are
+// of the i-th element.
+func (column *Column) GetGeoPointDegreesVector(id uint32) (
+	lats, lngs []float64, err error) {
+	column.table.db.mutex.Lock()
+	defer column.table.db.mutex.Unlock()
+	if err := column.checkGeoPoint(id, true); err != nil {
+		return nil, nil, err
+	}
+	value, err := column.getGeoPointVector(id)
+	if err != nil {
+		return nil, nil, err
 	}
-	rowInfo := C.grngo_table_insert_bool(table.db.ctx, table.obj, grnKey)
-	if rowInfo.id == C.GRN_ID_NIL {
-		return false, NilID, fmt.Errorf("grngo_table_insert_bool() failed")
+	points := value.([]GeoPoint)
+	lats = make([]float64, len(points))
+	lngs = make([]float64, len(points))
+	for i, point := range points {
+		lats[i], lngs[i] = point.Degrees()
 	}
-	return rowInfo.inserted == C.GRN_TRUE, uint32(rowInfo.id), nil
+	return lats, lngs, nil
 }
 
-// insertInt() inserts a row with Int key.
-func (table *Table) insertInt(key int64) (bool, uint32, error) {
-	var rowInfo C.grngo_row_info
-	switch table.keyType {
-	case Int8:
-		grnKey := C.int8_t(key)
-		rowInfo = C.grngo_table_insert_int8(table.db.ctx, table.obj, grnKey)
-	case Int16:
-		grnKey := C.int16_t(key)
-		rowInfo = C.grngo_table_insert_int16(table.db.ctx, table.obj, grnKey)
-	case Int32:
-		grnKey := C.int32_t(key)
-		rowInfo = C.grngo_table_insert_int32(table.db.ctx, table.obj, grnKey)
-	case Int64:
-		grnKey := C.int64_t(key)
-		rowInfo = C.grngo_table_insert_int64(table.db.ctx, table.obj, grnKey)
-	case UInt8:
-		grnKey := C.uint8_t(key)
-		rowInfo = C.grngo_table_insert_uint8(table.db.ctx, table.obj, grnKey)
-	case UInt16:
-		grnKey := C.uint16_t(key)
-		rowInfo = C.grngo_table_insert_uint16(table.db.ctx, table.obj, grnKey)
-	case UInt32:
-		grnKey := C.uint32_t(key)
-		rowInfo = C.grngo_table_insert_uint32(table.db.ctx, table.obj, grnKey)
-	case UInt64:
-		grnKey := C.uint64_t(key)
-		rowInfo = C.grngo_table_insert_uint64(table.db.ctx, table.obj, grnKey)
-	default:
-		return false, NilID, fmt.Errorf("key type conflict")
+// typeName() returns the type name of the column in the form of select,
+// that is the name of a built-in type or a referenced table.
+func (column *Column) typeName() string {
+	if column.valueTable != nil {
+		return column.valueTable.name
 	}
-	if rowInfo.id == C.GRN_ID_NIL {
-		return false, NilID, fmt.Errorf("grngo_table_insert_int*() failed")
+	return column.valueType.GroongaName()
+}
+
+// outputValue() gets a value in the form of Records, that is, a reference is
+// converted into the key of the referenced row.
+// nil is returned if the row refers to nothing.
+// The caller must hold db.mutex.
+func (column *Column) outputValue(id uint32) (interface{}, error) {
+	if column.valueTable == nil {
+		return column.getValue(id)
+	}
+	if err := column.table.checkReference(column.valueTable); err != nil {
+		return nil, err
 	}
-	return rowInfo.inserted == C.GRN_TRUE, uint32(rowInfo.id), nil
+	keyColumn, err := column.valueTable.findColumn("_key")
+	if err != nil {
+		return nil, err
+	}
+	if !column.isVector {
+		refID, err := column.getRefID(id)
+		if (err != nil) || (refID == NilID) {
+			return nil, err
+		}
+		return keyColumn.outputValue(refID)
+	}
+	refIDs, err := column.getRefIDs(id)
+	if err != nil {
+		return nil, err
+	}
+	values := make([]interface{}, len(refIDs))
+	for i, refID := range refIDs {
+		if values[i], err = keyColumn.outputValue(refID); err != nil {
+			return nil, err
+		}
+	}
+	return makeVector(column.valueTable.keyType, values), nil
 }
 
-// insertFloat() inserts a row with Float key.
-func (table *Table) insertFloat(key float64) (bool, uint32, error) {
-	if table.keyType != Float {
-		return false, NilID, fmt.Errorf("key type conflict")
+// GetTyped() gets a value like Column.GetValue() and returns it as T.
+// If the value is not a T, GetTyped() returns an error instead of panicking.
+// For example, GetTyped[int64](column, id) gets a value of an Int column.
+func GetTyped[T any](column *Column, id uint32) (T, error) {
+	var zero T
+	value, err := column.GetValue(id)
+	if err != nil {
+		return zero, err
 	}
-	grnKey := C.double(key)
-	rowInfo := C.grngo_table_insert_float(table.db.ctx, table.obj, grnKey)
-	if rowInfo.id == C.GRN_ID_NIL {
-		return false, NilID, fmt.Errorf("grngo_table_insert_float() failed")
+	typedValue, ok := value.(T)
+	if !ok {
+		return zero, fmt.Errorf("value type conflict: name = <%s>, type = %T, expected = %T",
+			column.name, value, zero)
 	}
-	return rowInfo.inserted == C.GRN_TRUE, uint32(rowInfo.id), nil
+	return typedValue, nil
 }
 
-// insertGeoPoint() inserts a row with GeoPoint key.
-func (table *Table) insertGeoPoint(key GeoPoint) (bool, uint32, error) {
-	switch table.keyType {
+// GetRefID() gets the ID of the row referred to by a scalar reference column.
+// NilID is returned if the row refers to nothing.
+func (column *Column) GetRefID(id uint32) (uint32, error) {
+	column.table.db.mutex.Lock()
+	defer column.table.db.mutex.Unlock()
+	return column.getRefID(id)
+}
+
+// getRefID() gets the ID of the referred row without locking.
+func (column *Column) getRefID(id uint32) (uint32, error) {
+	if column.obj == nil {
+		return NilID, fmt.Errorf("column removed: name = <%s>", column.name)
+	}
+	if (column.valueTable == nil) || column.isVector {
+		return NilID, fmt.Errorf("not a scalar reference column: name = <%s>",
+			column.name)
+	}
+	if C.grn_table_at(column.table.db.ctx, column.table.obj,
+		C.grn_id(id)) == C.GRN_ID_NIL {
+		return NilID, fmt.Errorf("%w: table = <%s>, id = %d", ErrRowNotFound,
+			column.table.name, id)
+	}
+	var grnValue C.grn_id
+	if ok := C.grngo_column_get_ref_id(column.table.db.ctx, column.obj,
+		C.grn_id(id), &grnValue); ok != C.GRN_TRUE {
+		return NilID, fmt.Errorf("grngo_column_get_ref_id() failed")
+	}
+	return uint32(grnValue), nil
+}
+
+// GetRefIDs() gets the IDs of the rows referred to by a vector reference
+// column.
+func (column *Column) GetRefIDs(id uint32) ([]uint32, error) {
+	column.table.db.mutex.Lock()
+	defer column.table.db.mutex.Unlock()
+	return column.getRefIDs(id)
+}
+
+// getRefIDs() gets the IDs of the referred rows without locking.
+func (column *Column) getRefIDs(id uint32) ([]uint32, error) {
+	if column.obj == nil {
+		return nil, fmt.Errorf("column removed: name = <%s>", column.name)
+	}
+	if (column.valueTable == nil) || !column.isVector {
+		return nil, fmt.Errorf("not a vector reference column: name = <%s>",
+			column.name)
+	}
+	if C.grn_table_at(column.table.db.ctx, column.table.obj,
+		C.grn_id(id)) == C.GRN_ID_NIL {
+		return nil, fmt.Errorf("%w: table = <%s>, id = %d", ErrRowNotFound,
+			column.table.name, id)
+	}
+	var grnValue C.grngo_vector
+	if ok := C.grngo_column_get_ref_ids(column.table.db.ctx, column.obj,
+		C.grn_id(id), &grnValue); ok != C.GRN_TRUE {
+		return nil, fmt.Errorf("grngo_column_get_ref_ids() failed")
+	}
+	if grnValue.size == 0 {
+		return make([]uint32, 0), nil
+	}
+	value := make([]uint32, int(grnValue.size))
+	grnValue.ptr = unsafe.Pointer(&value[0])
+	if ok := C.grngo_column_get_ref_ids(column.table.db.ctx, column.obj,
+		C.grn_id(id), &grnValue); ok != C.GRN_TRUE {
+		return nil, fmt.Errorf("grngo_column_get_ref_ids() failed")
+	}
+	return value[:int(grnValue.size)], nil
+}
+
+// GetValues() gets values of the rows ids[i] for each i.
+// The return value is a slice of the type which GetValue() returns for the
+// column, such as []int64 and [][]byte, and values are read in one cgo call,
+// except that Text values need one more call to copy their bodies.
+// If a row does not exist, the value for a stored zero, such as 0 and
+// time.Unix(0, 0), is stored at that index like GetValue() for a zero.
+// Vector columns are not supported.
+func (column *Column) GetValues(ids []uint32) (interface{}, error) {
+	column.table.db.mutex.Lock()
+	defer column.table.db.mutex.Unlock()
+	if column.obj == nil {
+		return nil, fmt.Errorf("column removed: name = <%s>", column.name)
+	}
+	if column.isVector {
+		return nil, fmt.Errorf("vector column is not supported: name = <%s>",
+			column.name)
+	}
+	ctx := column.table.db.ctx
+	table := column.table.obj
+	var grnIDs *C.grn_id
+	if len(ids) != 0 {
+		grnIDs = (*C.grn_id)(unsafe.Pointer(&ids[0]))
+	}
+	size := C.size_t(len(ids))
+	switch column.valueType {
+	case Bool:
+		grnValues := make([]C.grn_bool, len(ids)+1)
+		if ok := C.grngo_column_get_bools(ctx, table, column.obj, grnIDs,
+			&grnValues[0], size); ok != C.GRN_TRUE {
+			return nil, fmt.Errorf("grngo_column_get_bools() failed")
+		}
+		values := make([]bool, len(ids))
+		for i := range values {
+			values[i] = grnValues[i] == C.GRN_TRUE
+		}
+		return values, nil
+	case Int8, Int16, Int32, Int64, UInt8, UInt16, UInt32, UInt64:
+		values := make([]int64, len(ids)+1)
+		if ok := C.grngo_column_get_ints(ctx, table, column.obj,
+			C.grn_builtin_type(column.valueType), grnIDs,
+			(*C.int64_t)(unsafe.Pointer(&values[0])), size); ok != C.GRN_TRUE {
+			return nil, fmt.Errorf("grngo_column_get_ints() failed")
+		}
+		return values[:len(ids)], nil
+	case Float:
+		values := make([]float64, len(ids)+1)
+		if ok := C.grngo_column_get_floats(ctx, table, column.obj, grnIDs,
+			(*C.double)(unsafe.Pointer(&values[0])), size); ok != C.GRN_TRUE {
+			return nil, fmt.Errorf("grngo_column_get_floats() failed")
+		}
+		return values[:len(ids)], nil
+	case Float32:
+		values := make([]float32, len(ids)+1)
+		if ok := C.grngo_column_get_float32s(ctx, table, column.obj, grnIDs,
+			(*C.float)(unsafe.Pointer(&values[0])), size); ok != C.GRN_TRUE {
+			return nil, fmt.Errorf("grngo_column_get_float32s() failed")
+		}
+		return values[:len(ids)], nil
+	case Time:
+		grnValues := make([]C.int64_t, len(ids)+1)
+		if ok := C.grngo_column_get_times(ctx, table, column.obj, grnIDs,
+			&grnValues[0], size); ok != C.GRN_TRUE {
+			return nil, fmt.Errorf("grngo_column_get_times() failed")
+		}
+		values := make([]time.Time, len(ids))
+		for i := range values {
+			values[i] = grnTimeToTime(int64(grnValues[i]))
+		}
+		return values, nil
 	case TokyoGeoPoint, WGS84GeoPoint:
+		grnValues := make([]C.grn_geo_point, len(ids)+1)
+		if ok := C.grngo_column_get_geo_points(ctx, table, column.obj, grnIDs,
+			&grnValues[0], size); ok != C.GRN_TRUE {
+			return nil, fmt.Errorf("grngo_column_get_geo_points() failed")
+		}
+		values := make([]GeoPoint, len(ids))
+		for i := range values {
+			values[i] = GeoPoint{int32(grnValues[i].latitude),
+				int32(grnValues[i].longitude)}
+		}
+		return values, nil
+	case ShortText, Text, LongText:
+		grnValues := make([]C.grngo_text, len(ids)+1)
+		if ok := C.grngo_column_get_texts(ctx, table, column.obj, grnIDs,
+			&grnValues[0], size); ok != C.GRN_TRUE {
+			return nil, fmt.Errorf("grngo_column_get_texts() failed")
+		}
+		totalSize := 0
+		for i := range ids {
+			totalSize += int(grnValues[i].size)
+		}
+		buf := make([]byte, totalSize+1)
+		values := make([][]byte, len(ids))
+		offset := 0
+		for i := range ids {
+			textSize := int(grnValues[i].size)
+			values[i] = buf[offset : offset+textSize : offset+textSize]
+			grnValues[i].ptr = (*C.char)(unsafe.Pointer(&buf[offset]))
+			offset += textSize
+		}
+		if totalSize != 0 {
+			if ok := C.grngo_column_get_texts(ctx, table, column.obj, grnIDs,
+				&grnValues[0], size); ok != C.GRN_TRUE {
+				return nil, fmt.Errorf("grngo_column_get_texts() failed")
+			}
+		}
+		return values, nil
+	}
+	return nil, fmt.Errorf("undefined value type: valueType = %d", column.valueType)
+}
+
+// -- Filter --
+
+// Filter is a filter expression compiled by Table.PrepareFilter().
+type Filter struct {
+	table    *Table
+	expr     *C.grn_obj
+	varNames []string // Variable names, such as "$min".
+}
+
+// parseFilterVarNames() returns the names of variables, such as "$min", in a
+// filter expression. Names in string literals are ignored.
+func parseFilterVarNames(expr string) []string {
+	var names []string
+	found := make(map[string]bool)
+	var quote byte
+	for i := 0; i < len(expr); i++ {
+		c := expr[i]
+		switch {
+		case quote != 0:
+			if c == '\\' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case (c == '"') || (c == '\''):
+			quote = c
+		case c == '$':
+			j := i + 1
+			for (j < len(expr)) && ((expr[j] == '_') ||
+				((expr[j] >= 'a') && (expr[j] <= 'z')) ||
+				((expr[j] >= 'A') && (expr[j] <= 'Z')) ||
+				((j > i+1) && (expr[j] >= '0') && (expr[j] <= '9'))) {
+				j++
+			}
+			if j > i+1 {
+				name := expr[i:j]
+				if !found[name] {
+					found[name] = true
+					names = append(names, name)
+				}
+			}
+			i = j - 1
+		}
+	}
+	return names
+}
+
+// PrepareFilter() compiles a filter expression, such as "value >= $min", so
+// that Filter.Select() can run it repeatedly without parsing it again.
+// Variables are written as $name and bound by Filter.Select().
+// The Filter must be closed by Filter.Close().
+func (table *Table) PrepareFilter(expr string) (*Filter, error) {
+	if expr == "" {
+		return nil, fmt.Errorf("empty filter")
+	}
+	varNames := parseFilterVarNames(expr)
+	// The extra element makes &grnVarNames[0] valid even if there are no
+	// variables.
+	grnVarNames := make([]C.grngo_text, len(varNames)+1)
+	for i, name := range varNames {
+		nameBytes := []byte(name)
+		grnVarNames[i].ptr = (*C.char)(unsafe.Pointer(&nameBytes[0]))
+		grnVarNames[i].size = C.size_t(len(nameBytes))
+	}
+	exprBytes := []byte(expr)
+	table.db.mutex.Lock()
+	defer table.db.mutex.Unlock()
+	if err := table.checkLocal(); err != nil {
+		return nil, err
+	}
+	obj := C.grngo_filter_open(table.db.ctx, table.obj,
+		(*C.char)(unsafe.Pointer(&exprBytes[0])), C.size_t(len(exprBytes)),
+		&grnVarNames[0], C.size_t(len(varNames)))
+	if obj == nil {
+		return nil, newGroongaError(table.db.ctx, "grngo_filter_open()",
+			table.db.ctx.rc)
+	}
+	return &Filter{table: table, expr: obj, varNames: varNames}, nil
+}
+
+// VarNames() returns the names of variables, such as "$min".
+func (filter *Filter) VarNames() []string {
+	return filter.varNames
+}
+
+// Close() releases the compiled expression.
+// Close() does nothing if the Filter is already closed.
+func (filter *Filter) Close() error {
+	db := filter.table.db
+	db.mutex.Lock()
+	defer db.mutex.Unlock()
+	if filter.expr == nil {
+		return nil
+	}
+	rc := C.grn_obj_unlink(db.ctx, filter.expr)
+	filter.expr = nil
+	if rc != C.GRN_SUCCESS {
+		return newGroongaError(db.ctx, "grn_obj_unlink()", rc)
+	}
+	return nil
+}
+
+// setVar() sets a value to a variable.
+// The caller must hold db.mutex.
+func (filter *Filter) setVar(name string, value interface{}) error {
+	var dataType C.grn_builtin_type
+	var ptr unsafe.Pointer
+	var size int
+	switch v := value.(type) {
+	case bool:
+		grnValue := C.grn_bool(C.GRN_FALSE)
+		if v {
+			grnValue = C.grn_bool(C.GRN_TRUE)
+		}
+		dataType, ptr, size = C.GRN_DB_BOOL, unsafe.Pointer(&grnValue), 1
+	case int:
+		return filter.setVar(name, int64(v))
+	case int8:
+		return filter.setVar(name, int64(v))
+	case int16:
+		return filter.setVar(name, int64(v))
+	case int32:
+		return filter.setVar(name, int64(v))
+	case int64:
+		dataType, ptr, size = C.GRN_DB_INT64, unsafe.Pointer(&v), 8
+	case uint:
+		return filter.setVar(name, uint64(v))
+	case uint8:
+		return filter.setVar(name, uint64(v))
+	case uint16:
+		return filter.setVar(name, uint64(v))
+	case uint32:
+		return filter.setVar(name, uint64(v))
+	case uint64:
+		dataType, ptr, size = C.GRN_DB_UINT64, unsafe.Pointer(&v), 8
+	case float32:
+		return filter.setVar(name, float64(v))
+	case float64:
+		dataType, ptr, size = C.GRN_DB_FLOAT, unsafe.Pointer(&v), 8
+	case time.Time:
+		grnValue := timeToGrnTime(v)
+		dataType, ptr, size = C.GRN_DB_TIME, unsafe.Pointer(&grnValue), 8
+	case GeoPoint:
+		// A GeoPoint is bound as a string, such as "100x200", like
+		// formatFilterValue().
+		return filter.setVar(name, []byte(v.String()))
+	case []byte:
+		dataType, size = C.GRN_DB_TEXT, len(v)
+		if size != 0 {
+			ptr = unsafe.Pointer(&v[0])
+		}
+	case string:
+		return filter.setVar(name, []byte(v))
 	default:
-		return false, NilID, fmt.Errorf("key type conflict")
+		return fmt.Errorf("unsupported value type: name = <%s>, type = %T",
+			name, value)
 	}
-	grnKey := C.grn_geo_point{C.int(key.Latitude), C.int(key.Longitude)}
-	rowInfo := C.grngo_table_insert_geo_point(table.db.ctx, table.obj, grnKey)
-	if rowInfo.id == C.GRN_ID_NIL {
-		return false, NilID, fmt.Errorf("grngo_table_insert_geo_point() failed")
+	nameBytes := []byte(name)
+	if ok := C.grngo_filter_set_var(filter.table.db.ctx, filter.expr,
+		(*C.char)(unsafe.Pointer(&nameBytes[0])), C.size_t(len(nameBytes)),
+		dataType, ptr, C.size_t(size)); ok != C.GRN_TRUE {
+		return fmt.Errorf("grngo_filter_set_var() failed: name = <%s>", name)
 	}
-	return rowInfo.inserted == C.GRN_TRUE, uint32(rowInfo.id), nil
+	return nil
 }
 
-// insertText() inserts a row with Text key.
-func (table *Table) insertText(key []byte) (bool, uint32, error) {
-	if table.keyType != ShortText {
-		return false, NilID, fmt.Errorf("key type conflict")
+// selectIDs() binds args and returns the number of matched rows and the IDs
+// of rows in the range specified by options and sortBy.
+// The caller must hold db.mutex.
+func (filter *Filter) selectIDs(args map[string]interface{},
+	options *SelectOptions, sortBy string) (int, []uint32, error) {
+	table := filter.table
+	if filter.expr == nil {
+		return 0, nil, fmt.Errorf("filter closed")
+	}
+	if table.obj == nil {
+		return 0, nil, fmt.Errorf("table released: name = <%s>", table.name)
+	}
+	for name := range args {
+		if !containsString(filter.varNames, name) {
+			return 0, nil, fmt.Errorf("undefined variable: name = <%s>", name)
+		}
 	}
-	var grnKey C.grngo_text
-	if len(key) != 0 {
-		grnKey.ptr = (*C.char)(unsafe.Pointer(&key[0]))
-		grnKey.size = C.size_t(len(key))
+	for _, name := range filter.varNames {
+		value, ok := args[name]
+		if !ok {
+			return 0, nil, fmt.Errorf("unbound variable: name = <%s>", name)
+		}
+		if err := filter.setVar(name, value); err != nil {
+			return 0, nil, err
+		}
 	}
-	rowInfo := C.grngo_table_insert_text(table.db.ctx, table.obj, &grnKey)
-	if rowInfo.id == C.GRN_ID_NIL {
-		return false, NilID, fmt.Errorf("grngo_table_insert_text() failed")
+	result := C.grngo_filter_select(table.db.ctx, table.obj, filter.expr)
+	if result == nil {
+		return 0, nil, newGroongaError(table.db.ctx, "grngo_filter_select()",
+			table.db.ctx.rc)
+	}
+	defer C.grn_obj_unlink(table.db.ctx, result)
+	nHits := int(C.grn_table_size(table.db.ctx, result))
+	n := nHits
+	if (options.Limit >= 0) && (options.Limit < n) {
+		n = options.Limit
+	}
+	if n == 0 {
+		return nHits, make([]uint32, 0), nil
+	}
+	ids := make([]uint32, n)
+	size := C.size_t(n)
+	sortKeys := []byte(sortBy + "\x00")
+	if ok := C.grngo_result_get_ids(table.db.ctx, result,
+		(*C.char)(unsafe.Pointer(&sortKeys[0])), C.size_t(len(sortKeys)-1),
+		C.int(options.Offset), C.int(options.Limit),
+		(*C.grn_id)(unsafe.Pointer(&ids[0])), &size); ok != C.GRN_TRUE {
+		return 0, nil, newGroongaError(table.db.ctx, "grngo_result_get_ids()",
+			table.db.ctx.rc)
+	}
+	return nHits, ids[:int(size)], nil
+}
+
+// Select() binds args to the variables and returns the rows matching the
+// filter. args must have a value for each variable, such as "$min", and the
+// supported value types are bool, integer types, float32, float64,
+// time.Time, GeoPoint, []byte and string.
+// options.Filter, options.Query, options.MatchColumns, options.Drilldowns
+// and options.DrilldownSpecs must be empty.
+// If options.OutputColumns is empty, _id, _key (if any) and all the data
+// columns are output. _score and _nsubrecs are not supported because the
+// output columns are read from the table, not from the result.
+// options.CacheMode is ignored because the result is never cached.
+func (filter *Filter) Select(args map[string]interface{},
+	options *SelectOptions) (*Records, error) {
+	if options == nil {
+		options = NewSelectOptions()
 	}
-	return rowInfo.inserted == C.GRN_TRUE, uint32(rowInfo.id), nil
+	if (options.Filter != "") || (options.Query != "") ||
+		(options.MatchColumns != "") || (len(options.Drilldowns) != 0) ||
+		(len(options.DrilldownSpecs) != 0) {
+		return nil, fmt.Errorf("filter, query, match_columns and drilldown are not supported by Filter.Select()")
+	}
+	sortBy, err := filter.table.sortBy(options)
+	if err != nil {
+		return nil, err
+	}
+	table := filter.table
+	names := options.OutputColumns
+	if len(names) == 0 {
+		names = []string{"_id"}
+		if table.keyType != Void {
+			names = append(names, "_key")
+		}
+		schemas, err := table.ColumnSchemas()
+		if err != nil {
+			return nil, err
+		}
+		for _, schema := range schemas {
+			if !schema.IsIndex {
+				names = append(names, schema.Name)
+			}
+		}
+	}
+	records := &Records{
+		db:      table.db,
+		names:   make([]string, len(names)),
+		types:   make([]string, len(names)),
+		indices: make(map[string]int),
+	}
+	columns := make([]*Column, len(names))
+	for i, name := range names {
+		switch name {
+		case "_score", "_nsubrecs":
+			return nil, fmt.Errorf("not supported by Filter.Select(): name = <%s>", name)
+		}
+		if columns[i], err = table.FindColumn(name); err != nil {
+			return nil, err
+		}
+		records.names[i] = name
+		records.types[i] = columns[i].typeName()
+		records.indices[name] = i
+	}
+	// Rows are matched and read in one critical section, so that concurrent
+	// deletions never make the IDs stale.
+	table.db.mutex.Lock()
+	defer table.db.mutex.Unlock()
+	nHits, ids, err := filter.selectIDs(args, options, sortBy)
+	if err != nil {
+		return nil, err
+	}
+	records.NHits = nHits
+	records.rows = make([][]interface{}, len(ids))
+	for i, id := range ids {
+		row := make([]interface{}, len(columns))
+		for j, column := range columns {
+			if row[j], err = column.outputValue(id); err != nil {
+				return nil, err
+			}
+		}
+		records.rows[i] = row
+	}
+	return records, nil
 }
 
-// InsertRow() inserts a row.
-// The first return value specifies whether a row is inserted or not.
-// The second return value is the ID of the inserted or found row.
-func (table *Table) InsertRow(key interface{}) (bool, uint32, error) {
-	switch value := key.(type) {
-	case nil:
-		return table.insertVoid()
+// containsString() returns whether values contains value.
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// -- FilterExpr --
+
+// FilterExpr is a filter expression built by Equal(), Match(), And(), etc.
+// Values are rendered as literals, so that they cannot change the structure
+// of the expression.
+// The first error in building an expression is reported by Build().
+type FilterExpr struct {
+	expr string
+	err  error
+}
+
+// Build() returns the filter string for SelectOptions.Filter.
+func (expr FilterExpr) Build() (string, error) {
+	if expr.err != nil {
+		return "", expr.err
+	}
+	if expr.expr == "" {
+		return "", fmt.Errorf("empty filter expression")
+	}
+	return expr.expr, nil
+}
+
+// filterStringReplacer escapes characters which break a double-quoted string
+// literal of a filter expression.
+var filterStringReplacer = strings.NewReplacer("\\", "\\\\", "\"", "\\\"")
+
+// quoteFilterString() returns a double-quoted string literal.
+func quoteFilterString(value string) string {
+	return "\"" + filterStringReplacer.Replace(value) + "\""
+}
+
+// checkFilterColumn() returns an error if name is not a column name or a
+// column path, such as "_key" and "category.name".
+func checkFilterColumn(name string) error {
+	if name == "" {
+		return fmt.Errorf("empty column name")
+	}
+	for _, part := range strings.Split(name, ".") {
+		if part == "" {
+			return fmt.Errorf("invalid column name: name = <%s>", name)
+		}
+		for i, r := range part {
+			switch {
+			case (r == '_') || ((r >= 'a') && (r <= 'z')) ||
+				((r >= 'A') && (r <= 'Z')):
+			case (i != 0) && (r >= '0') && (r <= '9'):
+			default:
+				return fmt.Errorf("invalid column name: name = <%s>", name)
+			}
+		}
+	}
+	return nil
+}
+
+// formatFilterValue() returns a literal of a value.
+// The supported value types are bool, integer types, float32, float64,
+// time.Time, GeoPoint, []byte and string.
+func formatFilterValue(value interface{}) (string, error) {
+	switch v := value.(type) {
 	case bool:
-		return table.insertBool(value)
+		return strconv.FormatBool(v), nil
+	case int:
+		return strconv.FormatInt(int64(v), 10), nil
+	case int8:
+		return strconv.FormatInt(int64(v), 10), nil
+	case int16:
+		return strconv.FormatInt(int64(v), 10), nil
+	case int32:
+		return strconv.FormatInt(int64(v), 10), nil
 	case int64:
-		return table.insertInt(value)
+		return strconv.FormatInt(v, 10), nil
+	case uint:
+		return strconv.FormatUint(uint64(v), 10), nil
+	case uint8:
+		return strconv.FormatUint(uint64(v), 10), nil
+	case uint16:
+		return strconv.FormatUint(uint64(v), 10), nil
+	case uint32:
+		return strconv.FormatUint(uint64(v), 10), nil
+	case uint64:
+		return strconv.FormatUint(v, 10), nil
+	case float32:
+		return formatFilterValue(float64(v))
 	case float64:
-		return table.insertFloat(value)
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			return "", fmt.Errorf("unsupported float value: value = %v", v)
+		}
+		return strconv.FormatFloat(v, 'f', -1, 64), nil
+	case time.Time:
+		// Time is compared as seconds since the Unix epoch.
+		return formatGrnTime(timeToGrnTime(v)), nil
 	case GeoPoint:
-		return table.insertGeoPoint(value)
+		return quoteFilterString(v.String()), nil
 	case []byte:
-		return table.insertText(value)
+		return quoteFilterString(string(v)), nil
+	case string:
+		return quoteFilterString(v), nil
 	default:
-		return false, NilID, fmt.Errorf(
-			"unsupported key type: typeName = <%s>", reflect.TypeOf(key).Name())
+		return "", fmt.Errorf("unsupported value type: type = %T", value)
 	}
 }
 
-// CreateColumn() creates a column.
-func (table *Table) CreateColumn(name string, valueType string,
-	options *ColumnOptions) (*Column, error) {
-	if options == nil {
-		options = NewColumnOptions()
+// newFilterComparison() returns an expression "column op value".
+func newFilterComparison(column, op string, value interface{}) FilterExpr {
+	if err := checkFilterColumn(column); err != nil {
+		return FilterExpr{err: err}
 	}
-	optionsMap := make(map[string]string)
-	optionsMap["table"] = table.name
-	optionsMap["name"] = name
-	switch valueType {
-	case "Bool", "Int8", "Int16", "Int32", "Int64", "UInt8", "UInt16",
-		"UInt32", "UInt64", "Float", "Time", "ShortText", "Text", "LongText",
-		"TokyoGeoPoint", "WGS84GeoPoint":
-		optionsMap["type"] = valueType
-	default:
-		if _, err := table.db.FindTable(valueType); err != nil {
-			return nil, fmt.Errorf("unsupported value type: valueType = %s", valueType)
-		}
-		optionsMap["type"] = valueType
+	literal, err := formatFilterValue(value)
+	if err != nil {
+		return FilterExpr{err: fmt.Errorf("%v: column = <%s>", err, column)}
 	}
-	switch options.ColumnType {
-	case ScalarColumn:
-		optionsMap["flags"] = "COLUMN_SCALAR"
-	case VectorColumn:
-		optionsMap["flags"] = "COLUMN_VECTOR"
-	case IndexColumn:
-		optionsMap["flags"] = "COLUMN_INDEX"
-	default:
-		return nil, fmt.Errorf("undefined column type: options = %+v", options)
+	return FilterExpr{expr: column + " " + op + " " + literal}
+}
+
+// Equal() returns an expression "column == value".
+func Equal(column string, value interface{}) FilterExpr {
+	return newFilterComparison(column, "==", value)
+}
+
+// NotEqual() returns an expression "column != value".
+func NotEqual(column string, value interface{}) FilterExpr {
+	return newFilterComparison(column, "!=", value)
+}
+
+// LessThan() returns an expression "column < value".
+func LessThan(column string, value interface{}) FilterExpr {
+	return newFilterComparison(column, "<", value)
+}
+
+// LessEqual() returns an expression "column <= value".
+func LessEqual(column string, value interface{}) FilterExpr {
+	return newFilterComparison(column, "<=", value)
+}
+
+// GreaterThan() returns an expression "column > value".
+func GreaterThan(column string, value interface{}) FilterExpr {
+	return newFilterComparison(column, ">", value)
+}
+
+// GreaterEqual() returns an expression "column >= value".
+func GreaterEqual(column string, value interface{}) FilterExpr {
+	return newFilterComparison(column, ">=", value)
+}
+
+// Match() returns an expression "column @ value", that is a full-text search.
+func Match(column string, value interface{}) FilterExpr {
+	return newFilterComparison(column, "@", value)
+}
+
+// Prefix() returns an expression "column @^ value", that is a prefix search.
+func Prefix(column string, value interface{}) FilterExpr {
+	return newFilterComparison(column, "@^", value)
+}
+
+// Suffix() returns an expression "column @$ value", that is a suffix search.
+func Suffix(column string, value interface{}) FilterExpr {
+	return newFilterComparison(column, "@$", value)
+}
+
+// joinFilterExprs() joins exprs with op and encloses the result in
+// parentheses.
+func joinFilterExprs(op string, exprs []FilterExpr) FilterExpr {
+	if len(exprs) == 0 {
+		return FilterExpr{err: fmt.Errorf("no operands: op = <%s>", op)}
+	}
+	if len(exprs) == 1 {
+		return exprs[0]
+	}
+	operands := make([]string, len(exprs))
+	for i, expr := range exprs {
+		if expr.err != nil {
+			return expr
+		}
+		if expr.expr == "" {
+			return FilterExpr{err: fmt.Errorf("empty operand: op = <%s>, i = %d",
+				op, i)}
+		}
+		operands[i] = expr.expr
 	}
-	switch options.CompressionType {
-	case NoCompression:
-	case ZlibCompression:
-		optionsMap["flags"] = "|COMPRESS_ZLIB"
-	case LzoCompression:
-		optionsMap["flags"] = "|COMRESS_LZO"
-	default:
-		return nil, fmt.Errorf("undefined compression type: options = %+v", options)
+	return FilterExpr{expr: "(" + strings.Join(operands, " "+op+" ") + ")"}
+}
+
+// And() returns an expression which is true if all the exprs are true.
+func And(exprs ...FilterExpr) FilterExpr {
+	return joinFilterExprs("&&", exprs)
+}
+
+// Or() returns an expression which is true if any of the exprs is true.
+func Or(exprs ...FilterExpr) FilterExpr {
+	return joinFilterExprs("||", exprs)
+}
+
+// -- Records --
+
+// ColumnInfo describes an output column of select.
+type ColumnInfo struct {
+	Name string
+	Type DataType // Void if the type is not a built-in type.
+}
+
+// DrilldownEntry is a drilldown result, that is a group of records.
+type DrilldownEntry struct {
+	Key        interface{}        // The group key.
+	Count      int                // The number of records in the group.
+	Aggregates map[string]float64 // Aggregates, such as "_sum", or nil.
+}
+
+// Records stores records returned by select.
+type Records struct {
+	db         *DB
+	NHits      int                         // The number of matched records.
+	names      []string                    // Column names.
+	types      []string                    // Column type names.
+	indices    map[string]int              // Column indices.
+	rows       [][]interface{}             // Decoded values.
+	drilldowns map[string][]DrilldownEntry // Drilldown results.
+}
+
+// parseSelectResult() parses the JSON result of select.
+// drilldowns specifies the drilldown keys, each of which is followed by a
+// result block. If specs is not empty, the last block is an object that maps
+// the labels to the result blocks.
+func (db *DB) parseSelectResult(result []byte, drilldowns []string,
+	specs []DrilldownSpec) (*Records, error) {
+	decoder := json.NewDecoder(bytes.NewReader(result))
+	decoder.UseNumber()
+	var blocks []interface{}
+	if err := decoder.Decode(&blocks); err != nil {
+		return nil, fmt.Errorf("json.Decoder.Decode() failed: %v", err)
+	}
+	if len(blocks) == 0 {
+		return nil, fmt.Errorf("invalid result: no blocks")
+	}
+	block, ok := blocks[0].([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("invalid result: block = %v", blocks[0])
+	}
+	records, err := db.parseRecords(block)
+	if err != nil {
+		return nil, err
 	}
-	if options.WithSection {
-		optionsMap["flags"] += "|WITH_SECTION"
+	nBlocks := len(drilldowns) + 1
+	if len(specs) != 0 {
+		nBlocks++
 	}
-	if options.WithWeight {
-		optionsMap["flags"] += "|WITH_WEIGHT"
+	if len(blocks) != nBlocks {
+		return nil, fmt.Errorf("invalid result: blocks = %d, drilldowns = %d, specs = %d",
+			len(blocks), len(drilldowns), len(specs))
 	}
-	if options.WithPosition {
-		optionsMap["flags"] += "|WITH_POSITION"
+	records.drilldowns = make(map[string][]DrilldownEntry)
+	for i, key := range drilldowns {
+		block, ok := blocks[i+1].([]interface{})
+		if !ok {
+			return nil, fmt.Errorf("invalid result: block = %v", blocks[i+1])
+		}
+		entries, err := db.parseDrilldown(block, nil)
+		if err != nil {
+			return nil, fmt.Errorf("invalid drilldown: key = <%s>, err = %v",
+				key, err)
+		}
+		records.drilldowns[key] = entries
 	}
-	if options.Source != "" {
-		optionsMap["source"] = options.Source
+	if len(specs) != 0 {
+		labeled, ok := blocks[len(blocks)-1].(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("invalid result: block = %v", blocks[len(blocks)-1])
+		}
+		for i := range specs {
+			label := specs[i].label()
+			block, ok := labeled[label].([]interface{})
+			if !ok {
+				return nil, fmt.Errorf("invalid drilldown: label = <%s>, block = %v",
+					label, labeled[label])
+			}
+			entries, err := db.parseDrilldown(block, specs[i].CalcTypes)
+			if err != nil {
+				return nil, fmt.Errorf("invalid drilldown: label = <%s>, err = %v",
+					label, err)
+			}
+			records.drilldowns[label] = entries
+		}
 	}
-	bytes, err := table.db.QueryEx("column_create", optionsMap)
+	return records, nil
+}
+
+// parseDrilldown() parses a drilldown result block, that is a result block
+// with _key, _nsubrecs and the columns for calcTypes, such as _sum.
+func (db *DB) parseDrilldown(block []interface{}, calcTypes []CalcType) (
+	[]DrilldownEntry, error) {
+	records, err := db.parseRecords(block)
 	if err != nil {
 		return nil, err
 	}
-	if string(bytes) != "true" {
-		return nil, fmt.Errorf("column_create failed: name = <%s>", name)
+	entries := make([]DrilldownEntry, records.Len())
+	for i := range entries {
+		if entries[i].Key, err = records.Get(i, "_key"); err != nil {
+			return nil, err
+		}
+		count, err := records.GetInt(i, "_nsubrecs")
+		if err != nil {
+			return nil, err
+		}
+		entries[i].Count = int(count)
+		if len(calcTypes) == 0 {
+			continue
+		}
+		entries[i].Aggregates = make(map[string]float64)
+		for _, calcType := range calcTypes {
+			name := calcType.columnName()
+			value, err := records.Get(i, name)
+			if err != nil {
+				return nil, err
+			}
+			switch v := value.(type) {
+			case int64:
+				entries[i].Aggregates[name] = float64(v)
+			case float64:
+				entries[i].Aggregates[name] = v
+			default:
+				return nil, fmt.Errorf("value type conflict: name = <%s>", name)
+			}
+		}
 	}
-	return table.FindColumn(name)
+	return entries, nil
 }
 
-// findColumn() finds a column.
-func (table *Table) findColumn(name string) (*Column, error) {
-	if column, ok := table.columns[name]; ok {
-		return column, nil
-	}
-	nameBytes := []byte(name)
-	var cName *C.char
-	if len(nameBytes) != 0 {
-		cName = (*C.char)(unsafe.Pointer(&nameBytes[0]))
-	}
-	obj := C.grn_obj_column(table.db.ctx, table.obj, cName, C.uint(len(name)))
-	if obj == nil {
-		return nil, fmt.Errorf("grn_obj_column() failed: table = %+v, name = <%s>", table, name)
+// parseRecords() parses a result block, that is
+// [[nHits], [[name, type], ...], [value, ...], ...].
+func (db *DB) parseRecords(block []interface{}) (*Records, error) {
+	if len(block) < 2 {
+		return nil, fmt.Errorf("invalid block: block = %v", block)
+	}
+	var records Records
+	records.db = db
+	nHits, ok := block[0].([]interface{})
+	if !ok || (len(nHits) != 1) {
+		return nil, fmt.Errorf("invalid header: nHits = %v", block[0])
+	}
+	n, err := jsonToInt(nHits[0])
+	if err != nil {
+		return nil, err
 	}
-	var valueType DataType
-	var valueTable *Table
-	var isVector bool
-	switch name {
-	case "_id":
-		valueType = UInt32
-	case "_key":
-		valueType = table.keyType
-		valueTable = table.keyTable
-	case "_value":
-		valueType = table.valueType
-		valueTable = table.valueTable
-	default:
-		var valueInfo C.grngo_type_info
-		if ok := C.grngo_column_get_value_info(table.db.ctx, obj, &valueInfo); ok != C.GRN_TRUE {
-			return nil, fmt.Errorf("grngo_column_get_value_info() failed: name = <%s>",
-				name)
+	records.NHits = int(n)
+	columns, ok := block[1].([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("invalid header: columns = %v", block[1])
+	}
+	records.names = make([]string, len(columns))
+	records.types = make([]string, len(columns))
+	records.indices = make(map[string]int)
+	for i, column := range columns {
+		pair, ok := column.([]interface{})
+		if !ok || (len(pair) != 2) {
+			return nil, fmt.Errorf("invalid header: column = %v", column)
 		}
-		// Check the value type.
-		valueType = DataType(valueInfo.data_type)
-		isVector = valueInfo.dimension > 0
-		// Find the destination table if the value is table reference.
-		if valueInfo.ref_table != nil {
-			if valueType == Void {
-				return nil, fmt.Errorf("reference to void: name = <%s>", name)
-			}
-			cValueTableName := C.grngo_table_get_name(table.db.ctx, valueInfo.ref_table)
-			if cValueTableName == nil {
-				return nil, fmt.Errorf("grngo_table_get_name() failed")
-			}
-			defer C.free(unsafe.Pointer(cValueTableName))
-			var err error
-			valueTable, err = table.db.FindTable(C.GoString(cValueTableName))
-			if err != nil {
-				return nil, err
+		name, ok := pair[0].(string)
+		if !ok {
+			return nil, fmt.Errorf("invalid header: column = %v", column)
+		}
+		typeName, ok := pair[1].(string)
+		if !ok {
+			return nil, fmt.Errorf("invalid header: column = %v", column)
+		}
+		records.names[i] = name
+		records.types[i] = typeName
+		records.indices[name] = i
+	}
+	records.rows = make([][]interface{}, len(block)-2)
+	for i, row := range block[2:] {
+		values, ok := row.([]interface{})
+		if !ok || (len(values) != len(columns)) {
+			return nil, fmt.Errorf("invalid row: row = %v", row)
+		}
+		for j, value := range values {
+			if values[j], err = db.jsonToValue(records.types[j], value); err != nil {
+				return nil, fmt.Errorf("invalid value: column = <%s>, err = %v",
+					records.names[j], err)
 			}
 		}
+		records.rows[i] = values
 	}
-	column := newColumn(table, obj, name, valueType, isVector, valueTable)
-	table.columns[name] = column
-	return column, nil
+	return &records, nil
 }
 
-// FindColumn() finds a column.
-func (table *Table) FindColumn(name string) (*Column, error) {
-	if column, ok := table.columns[name]; ok {
-		return column, nil
+// jsonToInt() converts a decoded JSON number into an int64.
+func jsonToInt(value interface{}) (int64, error) {
+	number, ok := value.(json.Number)
+	if !ok {
+		return 0, fmt.Errorf("not number: value = %v", value)
 	}
-	delimPos := strings.IndexByte(name, '.')
-	if delimPos == -1 {
-		return table.findColumn(name)
+	return number.Int64()
+}
+
+// jsonToFloat() converts a decoded JSON number into a float64.
+func jsonToFloat(value interface{}) (float64, error) {
+	number, ok := value.(json.Number)
+	if !ok {
+		return 0, fmt.Errorf("not number: value = %v", value)
 	}
-	columnNames := strings.Split(name, ".")
-	column, err := table.findColumn(columnNames[0])
+	return number.Float64()
+}
+
+// jsonToTime() converts a decoded JSON number, that is the number of seconds
+// since the Unix epoch, into a time.Time.
+// The number is parsed as a decimal string to avoid rounding errors.
+func jsonToTime(value interface{}) (time.Time, error) {
+	number, ok := value.(json.Number)
+	if !ok {
+		return time.Time{}, fmt.Errorf("not number: value = %v", value)
+	}
+	str := string(number)
+	if strings.ContainsAny(str, "eE") {
+		sec, err := number.Float64()
+		if err != nil {
+			return time.Time{}, err
+		}
+		return grnTimeToTime(int64(sec * 1000000)), nil
+	}
+	negative := strings.HasPrefix(str, "-")
+	str = strings.TrimPrefix(str, "-")
+	usecStr := "000000"
+	if pos := strings.IndexByte(str, '.'); pos != -1 {
+		usecStr = (str[pos+1:] + usecStr)[:6]
+		str = str[:pos]
+	}
+	sec, err := strconv.ParseInt(str, 10, 64)
 	if err != nil {
-		return nil, err
+		return time.Time{}, err
 	}
-	isVector := column.isVector
-	valueTable := column.valueTable
-	for _, columnName := range columnNames[1:] {
-		if column.valueTable == nil {
-			return nil, fmt.Errorf("not table reference: column.name = <%s>", column.name)
+	usec, err := strconv.ParseInt(usecStr, 10, 64)
+	if err != nil {
+		return time.Time{}, err
+	}
+	grnTime := (sec * 1000000) + usec
+	if negative {
+		grnTime = -grnTime
+	}
+	return grnTimeToTime(grnTime), nil
+}
+
+// jsonToGeoPoint() converts a decoded JSON string, such as
+// "<latitude>x<longitude>" in milliseconds, into a GeoPoint.
+func jsonToGeoPoint(value interface{}) (GeoPoint, error) {
+	str, ok := value.(string)
+	if !ok {
+		return GeoPoint{}, fmt.Errorf("not string: value = %v", value)
+	}
+	return ParseGeoPoint(str)
+}
+
+// jsonToScalar() converts a decoded JSON scalar into a Go value.
+// If dataType is not a built-in type, the value is returned as is.
+func jsonToScalar(dataType DataType, value interface{}) (interface{}, error) {
+	if value == nil {
+		return nil, nil
+	}
+	switch dataType {
+	case Bool:
+		v, ok := value.(bool)
+		if !ok {
+			return nil, fmt.Errorf("not bool: value = %v", value)
 		}
-		column, err = column.valueTable.findColumn(columnName)
+		return v, nil
+	case Int8, Int16, Int32, Int64, UInt8, UInt16, UInt32, UInt64:
+		return jsonToInt(value)
+	case Float:
+		return jsonToFloat(value)
+	case Float32:
+		v, err := jsonToFloat(value)
 		if err != nil {
 			return nil, err
 		}
-		if column.isVector {
-			if isVector {
-				return nil, fmt.Errorf("vector of vector is not supported")
-			}
-			isVector = true
+		return float32(v), nil
+	case Time:
+		return jsonToTime(value)
+	case TokyoGeoPoint, WGS84GeoPoint:
+		return jsonToGeoPoint(value)
+	case ShortText, Text, LongText:
+		v, ok := value.(string)
+		if !ok {
+			return nil, fmt.Errorf("not string: value = %v", value)
 		}
+		return []byte(v), nil
 	}
-	nameBytes := []byte(name)
-	var cName *C.char
-	if len(nameBytes) != 0 {
-		cName = (*C.char)(unsafe.Pointer(&nameBytes[0]))
-	}
-	obj := C.grn_obj_column(table.db.ctx, table.obj, cName, C.uint(len(name)))
-	if obj == nil {
-		return nil, fmt.Errorf("grn_obj_column() failed: name = <%s>", name)
-	}
-	column = newColumn(table, obj, name, column.valueType, isVector, valueTable)
-	table.columns[name] = column
-	return column, nil
+	return value, nil
 }
 
-// -- Column --
-
-type Column struct {
-	table      *Table
-	obj        *C.grn_obj
-	name       string
-	valueType  DataType
-	isVector   bool
-	valueTable *Table
+// jsonToValue() converts a decoded JSON value into a Go value.
+// A reference is converted into the key of the referenced row and a vector is
+// converted into a slice of the element type, such as []int64.
+// null is converted into nil.
+func (db *DB) jsonToValue(typeName string, value interface{}) (
+	interface{}, error) {
+	dataType, ok := ParseDataType(typeName)
+	if !ok {
+		// Use the key type if the type is a table reference.
+		if table, err := db.FindTable(typeName); err == nil {
+			dataType = table.keyType
+		}
+	}
+	elements, ok := value.([]interface{})
+	if !ok {
+		return jsonToScalar(dataType, value)
+	}
+	values := make([]interface{}, len(elements))
+	for i, element := range elements {
+		v, err := jsonToScalar(dataType, element)
+		if err != nil {
+			return nil, err
+		}
+		values[i] = v
+	}
+	return makeVector(dataType, values), nil
 }
 
-// newColumn() creates a new Column object.
-func newColumn(table *Table, obj *C.grn_obj, name string,
-	valueType DataType, isVector bool, valueTable *Table) *Column {
-	var column Column
-	column.table = table
-	column.obj = obj
-	column.name = name
-	column.valueType = valueType
-	column.isVector = isVector
-	column.valueTable = valueTable
-	return &column
+// makeVector() converts values into a slice of the element type, such as
+// []int64. values must be scalars returned by jsonToScalar() or
+// reflectToScalar().
+// If dataType is not a built-in type, values is returned as is.
+func makeVector(dataType DataType, values []interface{}) interface{} {
+	switch dataType {
+	case Bool:
+		vector := make([]bool, len(values))
+		for i, v := range values {
+			vector[i], _ = v.(bool)
+		}
+		return vector
+	case Int8, Int16, Int32, Int64, UInt8, UInt16, UInt32, UInt64:
+		vector := make([]int64, len(values))
+		for i, v := range values {
+			vector[i], _ = v.(int64)
+		}
+		return vector
+	case Float:
+		vector := make([]float64, len(values))
+		for i, v := range values {
+			vector[i], _ = v.(float64)
+		}
+		return vector
+	case Float32:
+		vector := make([]float32, len(values))
+		for i, v := range values {
+			vector[i], _ = v.(float32)
+		}
+		return vector
+	case Time:
+		vector := make([]time.Time, len(values))
+		for i, v := range values {
+			vector[i], _ = v.(time.Time)
+		}
+		return vector
+	case TokyoGeoPoint, WGS84GeoPoint:
+		vector := make([]GeoPoint, len(values))
+		for i, v := range values {
+			vector[i], _ = v.(GeoPoint)
+		}
+		return vector
+	case ShortText, Text, LongText:
+		vector := make([][]byte, len(values))
+		for i, v := range values {
+			vector[i], _ = v.([]byte)
+		}
+		return vector
+	}
+	return values
 }
 
-// setBool() assigns a Bool value.
-func (column *Column) setBool(id uint32, value bool) error {
-	if (column.valueType != Bool) || column.isVector {
-		return fmt.Errorf("value type conflict")
-	}
-	var grnValue C.grn_bool = C.GRN_FALSE
-	if value {
-		grnValue = C.GRN_TRUE
+// Len() returns the number of records.
+// Note that Len() may be less than NHits because of offset and limit.
+func (records *Records) Len() int {
+	return len(records.rows)
+}
+
+// ColumnNames() returns the names of the output columns.
+func (records *Records) ColumnNames() []string {
+	return records.names
+}
+
+// Drilldowns() returns the drilldown results for each key in
+// SelectOptions.Drilldowns. The entries are in the order of the result.
+func (records *Records) Drilldowns() map[string][]DrilldownEntry {
+	return records.drilldowns
+}
+
+// Columns() returns the names and types of the output columns.
+// The type of a table reference column is Void.
+func (records *Records) Columns() []ColumnInfo {
+	columns := make([]ColumnInfo, len(records.names))
+	for i, name := range records.names {
+		dataType, _ := ParseDataType(records.types[i])
+		columns[i] = ColumnInfo{name, dataType}
+	}
+	return columns
+}
+
+// Get() returns a value of the i-th record.
+// If the value is null, nil is returned.
+func (records *Records) Get(i int, name string) (interface{}, error) {
+	if (i < 0) || (i >= len(records.rows)) {
+		return nil, fmt.Errorf("out of range: i = %d, len = %d",
+			i, len(records.rows))
+	}
+	j, ok := records.indices[name]
+	if !ok {
+		return nil, fmt.Errorf("column not found: name = <%s>", name)
+	}
+	return records.rows[i][j], nil
+}
+
+// get() returns a non-null value of the i-th record.
+func (records *Records) get(i int, name string) (interface{}, error) {
+	value, err := records.Get(i, name)
+	if err != nil {
+		return nil, err
 	}
-	if ok := C.grngo_column_set_bool(column.table.db.ctx, column.obj,
-		C.grn_id(id), grnValue); ok != C.GRN_TRUE {
-		return fmt.Errorf("grngo_column_set_bool() failed")
+	if value == nil {
+		return nil, fmt.Errorf("null value: i = %d, name = <%s>", i, name)
 	}
-	return nil
+	return value, nil
 }
 
-// setInt() assigns an Int value.
-func (column *Column) setInt(id uint32, value int64) error {
-	if column.isVector {
-		return fmt.Errorf("value type conflict")
-	}
-	ctx := column.table.db.ctx
-	var ok C.grn_bool
-	switch column.valueType {
-	case Int8:
-		grnValue := C.int8_t(value)
-		ok = C.grngo_column_set_int8(ctx, column.obj, C.grn_id(id), grnValue)
-	case Int16:
-		grnValue := C.int16_t(value)
-		ok = C.grngo_column_set_int16(ctx, column.obj, C.grn_id(id), grnValue)
-	case Int32:
-		grnValue := C.int32_t(value)
-		ok = C.grngo_column_set_int32(ctx, column.obj, C.grn_id(id), grnValue)
-	case Int64:
-		grnValue := C.int64_t(value)
-		ok = C.grngo_column_set_int64(ctx, column.obj, C.grn_id(id), grnValue)
-	case UInt8:
-		grnValue := C.uint8_t(value)
-		ok = C.grngo_column_set_uint8(ctx, column.obj, C.grn_id(id), grnValue)
-	case UInt16:
-		grnValue := C.uint16_t(value)
-		ok = C.grngo_column_set_uint16(ctx, column.obj, C.grn_id(id), grnValue)
-	case UInt32:
-		grnValue := C.uint32_t(value)
-		ok = C.grngo_column_set_uint32(ctx, column.obj, C.grn_id(id), grnValue)
-	case UInt64:
-		grnValue := C.uint64_t(value)
-		ok = C.grngo_column_set_uint64(ctx, column.obj, C.grn_id(id), grnValue)
-	default:
-		return fmt.Errorf("value type conflict")
+// GetBool() returns a Bool value of the i-th record.
+func (records *Records) GetBool(i int, name string) (bool, error) {
+	value, err := records.get(i, name)
+	if err != nil {
+		return false, err
 	}
-	if ok != C.GRN_TRUE {
-		return fmt.Errorf("grngo_column_set_int*() failed")
+	v, ok := value.(bool)
+	if !ok {
+		return false, fmt.Errorf("value type conflict: name = <%s>", name)
 	}
-	return nil
+	return v, nil
 }
 
-// setFloat() assigns a Float value.
-func (column *Column) setFloat(id uint32, value float64) error {
-	if (column.valueType != Float) || column.isVector {
-		return fmt.Errorf("value type conflict")
+// GetInt() returns an Int value of the i-th record.
+func (records *Records) GetInt(i int, name string) (int64, error) {
+	value, err := records.get(i, name)
+	if err != nil {
+		return 0, err
 	}
-	grnValue := C.double(value)
-	if ok := C.grngo_column_set_float(column.table.db.ctx, column.obj,
-		C.grn_id(id), grnValue); ok != C.GRN_TRUE {
-		return fmt.Errorf("grngo_column_set_float() failed")
+	v, ok := value.(int64)
+	if !ok {
+		return 0, fmt.Errorf("value type conflict: name = <%s>", name)
 	}
-	return nil
+	return v, nil
 }
 
-// setGeoPoint() assigns a GeoPoint value.
-func (column *Column) setGeoPoint(id uint32, value GeoPoint) error {
-	switch column.valueType {
-	case TokyoGeoPoint, WGS84GeoPoint:
-	default:
-		return fmt.Errorf("value type conflict")
+// GetFloat() returns a Float value of the i-th record.
+func (records *Records) GetFloat(i int, name string) (float64, error) {
+	value, err := records.get(i, name)
+	if err != nil {
+		return 0.0, err
 	}
-	if column.isVector {
-		return fmt.Errorf("value type conflict")
+	v, ok := value.(float64)
+	if !ok {
+		return 0.0, fmt.Errorf("value type conflict: name = <%s>", name)
 	}
-	grnValue := C.grn_geo_point{C.int(value.Latitude), C.int(value.Longitude)}
-	if ok := C.grngo_column_set_geo_point(column.table.db.ctx, column.obj,
-		C.grn_builtin_type(column.valueType),
-		C.grn_id(id), grnValue); ok != C.GRN_TRUE {
-		return fmt.Errorf("grngo_column_set_geo_point() failed")
+	return v, nil
+}
+
+// GetTime() returns a Time value of the i-th record.
+func (records *Records) GetTime(i int, name string) (time.Time, error) {
+	value, err := records.get(i, name)
+	if err != nil {
+		return time.Time{}, err
 	}
-	return nil
+	v, ok := value.(time.Time)
+	if !ok {
+		return time.Time{}, fmt.Errorf("value type conflict: name = <%s>", name)
+	}
+	return v, nil
 }
 
-// setText() assigns a Text value.
-func (column *Column) setText(id uint32, value []byte) error {
-	switch column.valueType {
-	case ShortText, Text, LongText:
-	default:
-		return fmt.Errorf("value type conflict")
+// GetGeoPoint() returns a GeoPoint value of the i-th record.
+func (records *Records) GetGeoPoint(i int, name string) (GeoPoint, error) {
+	value, err := records.get(i, name)
+	if err != nil {
+		return GeoPoint{}, err
 	}
-	if column.isVector {
-		return fmt.Errorf("value type conflict")
+	v, ok := value.(GeoPoint)
+	if !ok {
+		return GeoPoint{}, fmt.Errorf("value type conflict: name = <%s>", name)
 	}
-	var grnValue C.grngo_text
-	if len(value) != 0 {
-		grnValue.ptr = (*C.char)(unsafe.Pointer(&value[0]))
-		grnValue.size = C.size_t(len(value))
+	return v, nil
+}
+
+// GetText() returns a Text value of the i-th record.
+func (records *Records) GetText(i int, name string) ([]byte, error) {
+	value, err := records.get(i, name)
+	if err != nil {
+		return nil, err
 	}
-	if ok := C.grngo_column_set_text(column.table.db.ctx, column.obj,
-		C.grn_id(id), &grnValue); ok != C.GRN_TRUE {
-		return fmt.Errorf("grngo_column_set_text() failed")
+	v, ok := value.([]byte)
+	if !ok {
+		return nil, fmt.Errorf("value type conflict: name = <%s>", name)
 	}
-	return nil
+	return v, nil
 }
 
-// setBoolVector() assigns a Bool vector.
-func (column *Column) setBoolVector(id uint32, value []bool) error {
-	grnValue := make([]C.grn_bool, len(value))
-	for i, v := range value {
-		if v {
-			grnValue[i] = C.GRN_TRUE
+// Score() returns the _score value of the i-th record.
+// _score is output by Table.Select() if SelectOptions.Query is given. If
+// there is no _score column, 0.0 is returned.
+func (records *Records) Score(i int) (float64, error) {
+	if _, ok := records.indices["_score"]; !ok {
+		if (i < 0) || (i >= len(records.rows)) {
+			return 0.0, fmt.Errorf("out of range: i = %d, len = %d",
+				i, len(records.rows))
 		}
+		return 0.0, nil
 	}
-	var grnVector C.grngo_vector
-	if len(grnValue) != 0 {
-		grnVector.ptr = unsafe.Pointer(&grnValue[0])
-		grnVector.size = C.size_t(len(grnValue))
+	value, err := records.get(i, "_score")
+	if err != nil {
+		return 0.0, err
 	}
-	if ok := C.grngo_column_set_bool_vector(column.table.db.ctx, column.obj,
-		C.grn_id(id), &grnVector); ok != C.GRN_TRUE {
-		return fmt.Errorf("grngo_column_set_bool_vector() failed")
+	switch v := value.(type) {
+	case int64:
+		return float64(v), nil
+	case float64:
+		return v, nil
+	default:
+		return 0.0, fmt.Errorf("value type conflict: name = <_score>")
 	}
-	return nil
 }
 
-// setIntVector() assigns an Int vector.
-func (column *Column) setIntVector(id uint32, value []int64) error {
-	var grnVector C.grngo_vector
-	if len(value) != 0 {
-		grnVector.ptr = unsafe.Pointer(&value[0])
-		grnVector.size = C.size_t(len(value))
-	}
-	ctx := column.table.db.ctx
-	obj := column.obj
-	var ok C.grn_bool
-	switch column.valueType {
-	case Int8:
-		ok = C.grngo_column_set_int8_vector(ctx, obj, C.grn_id(id), &grnVector)
-	case Int16:
-		ok = C.grngo_column_set_int16_vector(ctx, obj, C.grn_id(id), &grnVector)
-	case Int32:
-		ok = C.grngo_column_set_int32_vector(ctx, obj, C.grn_id(id), &grnVector)
-	case Int64:
-		ok = C.grngo_column_set_int64_vector(ctx, obj, C.grn_id(id), &grnVector)
-	case UInt8:
-		ok = C.grngo_column_set_uint8_vector(ctx, obj, C.grn_id(id), &grnVector)
-	case UInt16:
-		ok = C.grngo_column_set_uint16_vector(ctx, obj, C.grn_id(id), &grnVector)
-	case UInt32:
-		ok = C.grngo_column_set_uint32_vector(ctx, obj, C.grn_id(id), &grnVector)
-	case UInt64:
-		ok = C.grngo_column_set_uint64_vector(ctx, obj, C.grn_id(id), &grnVector)
+// textToString() converts Text values into strings. The other values are
+// returned as is.
+func textToString(value interface{}) interface{} {
+	switch v := value.(type) {
+	case []byte:
+		return string(v)
+	case [][]byte:
+		values := make([]string, len(v))
+		for i := range v {
+			values[i] = string(v[i])
+		}
+		return values
 	default:
-		return fmt.Errorf("value type conflict")
+		return value
 	}
-	if ok != C.GRN_TRUE {
-		return fmt.Errorf("grngo_column_set_int*_vector() failed")
+}
+
+// ToMaps() returns the records as maps from the column names to the values.
+// Text values are converted into strings and vectors of them into []string.
+// The other values are returned as is, such as int64, time.Time and
+// GeoPoint.
+func (records *Records) ToMaps() []map[string]interface{} {
+	maps := make([]map[string]interface{}, len(records.rows))
+	for i, row := range records.rows {
+		m := make(map[string]interface{}, len(records.names))
+		for j, name := range records.names {
+			m[name] = textToString(row[j])
+		}
+		maps[i] = m
 	}
-	return nil
+	return maps
 }
 
-// setFloatVector() assigns a Float vector.
-func (column *Column) setFloatVector(id uint32, value []float64) error {
-	var grnVector C.grngo_vector
-	if len(value) != 0 {
-		grnVector.ptr = unsafe.Pointer(&value[0])
-		grnVector.size = C.size_t(len(value))
+// geoPointJSON is the JSON representation of a GeoPoint in Records.
+type geoPointJSON struct {
+	Lat float64 `json:"lat"`
+	Lng float64 `json:"lng"`
+}
+
+// recordValueToJSON() converts a value of ToMaps() into a value which is
+// encoded by json.Marshal() in Records.MarshalJSON().
+func recordValueToJSON(value interface{}) interface{} {
+	switch v := value.(type) {
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	case GeoPoint:
+		lat, lng := v.Degrees()
+		return geoPointJSON{lat, lng}
+	case []time.Time:
+		values := make([]string, len(v))
+		for i := range v {
+			values[i] = v[i].Format(time.RFC3339Nano)
+		}
+		return values
+	case []GeoPoint:
+		values := make([]geoPointJSON, len(v))
+		for i := range v {
+			values[i].Lat, values[i].Lng = v[i].Degrees()
+		}
+		return values
+	default:
+		return value
 	}
-	if ok := C.grngo_column_set_float_vector(column.table.db.ctx, column.obj,
-		C.grn_id(id), &grnVector); ok != C.GRN_TRUE {
-		return fmt.Errorf("grngo_column_set_float_vector() failed")
+}
+
+// MarshalJSON() encodes the records as an array of objects, such as
+// [{"_id":1,"_key":"Key"}], for json.Marshal().
+// Times are encoded in RFC 3339 and GeoPoints are encoded as
+// {"lat":35.6,"lng":139.7} in decimal degrees.
+func (records *Records) MarshalJSON() ([]byte, error) {
+	maps := records.ToMaps()
+	for _, m := range maps {
+		for name, value := range m {
+			m[name] = recordValueToJSON(value)
+		}
 	}
-	return nil
+	return json.Marshal(maps)
 }
 
-// setGeoPointVector() assigns a GeoPoint vector.
-func (column *Column) setGeoPointVector(id uint32, value []GeoPoint) error {
-	var grnVector C.grngo_vector
-	if len(value) != 0 {
-		grnVector.ptr = unsafe.Pointer(&value[0])
-		grnVector.size = C.size_t(len(value))
+// structField associates a struct field with a column.
+type structField struct {
+	index  int    // Field index.
+	column string // Column name.
+	tagged bool   // Whether the column name is given by a tag.
+}
+
+// getStructFields() returns the exported fields of a struct type and the
+// associated column names.
+// A column name is given by a `grngo:"name"` tag or the lowercased field
+// name, and fields tagged with `grngo:"-"` are ignored.
+func getStructFields(structType reflect.Type) []structField {
+	var fields []structField
+	for i := 0; i < structType.NumField(); i++ {
+		field := structType.Field(i)
+		if field.PkgPath != "" {
+			// Unexported.
+			continue
+		}
+		name := field.Tag.Get("grngo")
+		tagged := true
+		switch name {
+		case "-":
+			continue
+		case "":
+			name = strings.ToLower(field.Name)
+			tagged = false
+		}
+		fields = append(fields, structField{i, name, tagged})
 	}
-	if ok := C.grngo_column_set_geo_point_vector(column.table.db.ctx,
-		column.obj, C.grn_builtin_type(column.valueType),
-		C.grn_id(id), &grnVector); ok != C.GRN_TRUE {
-		return fmt.Errorf("grngo_column_set_geo_point_vector() failed")
+	return fields
+}
+
+// assignValue() assigns a decoded value to dest with type conversion.
+func assignValue(dest reflect.Value, value interface{}) error {
+	if value == nil {
+		dest.Set(reflect.Zero(dest.Type()))
+		return nil
 	}
-	return nil
+	src := reflect.ValueOf(value)
+	if src.Type().AssignableTo(dest.Type()) {
+		dest.Set(src)
+		return nil
+	}
+	switch v := value.(type) {
+	case int64:
+		switch dest.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32,
+			reflect.Int64:
+			if dest.OverflowInt(v) {
+				return fmt.Errorf("overflow: value = %d", v)
+			}
+			dest.SetInt(v)
+			return nil
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32,
+			reflect.Uint64:
+			if (v < 0) || dest.OverflowUint(uint64(v)) {
+				return fmt.Errorf("overflow: value = %d", v)
+			}
+			dest.SetUint(uint64(v))
+			return nil
+		case reflect.Float32, reflect.Float64:
+			dest.SetFloat(float64(v))
+			return nil
+		}
+	case float64:
+		switch dest.Kind() {
+		case reflect.Float32, reflect.Float64:
+			dest.SetFloat(v)
+			return nil
+		}
+	case float32:
+		switch dest.Kind() {
+		case reflect.Float32, reflect.Float64:
+			dest.SetFloat(float64(v))
+			return nil
+		}
+	case []byte:
+		switch dest.Kind() {
+		case reflect.String:
+			dest.SetString(string(v))
+			return nil
+		}
+	case string:
+		switch dest.Kind() {
+		case reflect.String:
+			dest.SetString(v)
+			return nil
+		}
+		if dest.Type() == reflect.TypeOf(GeoPoint{}) {
+			geoPoint, err := jsonToGeoPoint(v)
+			if err != nil {
+				return err
+			}
+			dest.Set(reflect.ValueOf(geoPoint))
+			return nil
+		}
+	}
+	if (src.Kind() == reflect.Slice) && (dest.Kind() == reflect.Slice) {
+		slice := reflect.MakeSlice(dest.Type(), src.Len(), src.Len())
+		for i := 0; i < src.Len(); i++ {
+			if err := assignValue(slice.Index(i), src.Index(i).Interface()); err != nil {
+				return err
+			}
+		}
+		dest.Set(slice)
+		return nil
+	}
+	return fmt.Errorf("type mismatch: src = %s, dest = %s", src.Type(), dest.Type())
 }
 
-// setTextVector() assigns a Text vector.
-func (column *Column) setTextVector(id uint32, value [][]byte) error {
-	grnValue := make([]C.grngo_text, len(value))
-	for i, v := range value {
-		if len(v) != 0 {
-			grnValue[i].ptr = (*C.char)(unsafe.Pointer(&v[0]))
-			grnValue[i].size = C.size_t(len(v))
+// Scan() stores the records into dest, that must be a pointer to a slice of
+// structs or struct pointers.
+// See getStructFields() for the rules to associate fields with columns.
+// Fields which have no associated column are skipped.
+func (records *Records) Scan(dest interface{}) error {
+	ptr := reflect.ValueOf(dest)
+	if (ptr.Kind() != reflect.Ptr) || ptr.IsNil() ||
+		(ptr.Elem().Kind() != reflect.Slice) {
+		return fmt.Errorf("unsupported dest type: type = %T", dest)
+	}
+	sliceType := ptr.Elem().Type()
+	elemType := sliceType.Elem()
+	isPtr := elemType.Kind() == reflect.Ptr
+	if isPtr {
+		elemType = elemType.Elem()
+	}
+	if elemType.Kind() != reflect.Struct {
+		return fmt.Errorf("unsupported dest type: type = %T", dest)
+	}
+	fields := getStructFields(elemType)
+	slice := reflect.MakeSlice(sliceType, len(records.rows), len(records.rows))
+	for i, row := range records.rows {
+		elem := reflect.New(elemType)
+		for _, field := range fields {
+			j, ok := records.indices[field.column]
+			if !ok {
+				continue
+			}
+			if err := assignValue(elem.Elem().Field(field.index), row[j]); err != nil {
+				return fmt.Errorf("assignValue() failed: field = %s, err = %v",
+					elemType.Field(field.index).Name, err)
+			}
+		}
+		if isPtr {
+			slice.Index(i).Set(elem)
+		} else {
+			slice.Index(i).Set(elem.Elem())
 		}
 	}
-	var grnVector C.grngo_vector
-	if len(grnValue) != 0 {
-		grnVector.ptr = unsafe.Pointer(&grnValue[0])
-		grnVector.size = C.size_t(len(grnValue))
+	ptr.Elem().Set(slice)
+	return nil
+}
+
+// -- TableCursor --
+
+type TableCursor struct {
+	table  *Table
+	obj    *C.grn_table_cursor
+	id     C.grn_id
+	keyBuf []byte // Reused by Key().
+}
+
+// newTableCursor() creates a new TableCursor object.
+func newTableCursor(table *Table, obj *C.grn_table_cursor) *TableCursor {
+	return &TableCursor{table, obj, C.GRN_ID_NIL, nil}
+}
+
+// Next() moves the cursor to the next row.
+// It returns false if there are no more rows or the cursor is closed.
+func (cursor *TableCursor) Next() bool {
+	cursor.table.db.mutex.Lock()
+	defer cursor.table.db.mutex.Unlock()
+	if cursor.obj == nil {
+		return false
+	}
+	cursor.id = C.grn_table_cursor_next(cursor.table.db.ctx, cursor.obj)
+	return cursor.id != C.GRN_ID_NIL
+}
+
+// ID() returns the ID of the current row.
+// NilID is returned if Next() has not been called or returned false.
+func (cursor *TableCursor) ID() uint32 {
+	return uint32(cursor.id)
+}
+
+// Key() returns the key of the current row in the same type as the key given
+// to InsertRow(), such as int64 and []byte.
+// An error is returned if the table has no key or there is no current row.
+func (cursor *TableCursor) Key() (interface{}, error) {
+	table := cursor.table
+	if table.keyType == Void {
+		return nil, fmt.Errorf("table has no key: table = <%s>", table.name)
+	}
+	table.db.mutex.Lock()
+	defer table.db.mutex.Unlock()
+	if (cursor.obj == nil) || (cursor.id == C.GRN_ID_NIL) {
+		return nil, fmt.Errorf("no current row")
+	}
+	if cursor.keyBuf == nil {
+		cursor.keyBuf = make([]byte, C.GRN_TABLE_MAX_KEY_SIZE)
+	}
+	size := C.grn_table_get_key(table.db.ctx, table.obj, cursor.id,
+		unsafe.Pointer(&cursor.keyBuf[0]), C.int(len(cursor.keyBuf)))
+	if size == 0 {
+		return nil, fmt.Errorf("grn_table_get_key() failed: id = %d", cursor.id)
+	}
+	// The key is copied because decodeKey() returns a ShortText key as is.
+	key := make([]byte, int(size))
+	copy(key, cursor.keyBuf)
+	return table.decodeKey(key)
+}
+
+// Close() closes the cursor.
+// It is safe to close a cursor more than once.
+func (cursor *TableCursor) Close() error {
+	cursor.table.db.mutex.Lock()
+	defer cursor.table.db.mutex.Unlock()
+	if cursor.obj == nil {
+		return nil
 	}
-	if ok := C.grngo_column_set_text_vector(column.table.db.ctx,
-		column.obj, C.grn_id(id), &grnVector); ok != C.GRN_TRUE {
-		return fmt.Errorf("grngo_column_set_text_vector() failed")
+	rc := C.grn_table_cursor_close(cursor.table.db.ctx, cursor.obj)
+	cursor.obj = nil
+	cursor.id = C.GRN_ID_NIL
+	if rc != C.GRN_SUCCESS {
+		return fmt.Errorf("grn_table_cursor_close() failed: rc = %d", rc)
 	}
 	return nil
 }
 
-// SetValue() assigns a value.
-func (column *Column) SetValue(id uint32, value interface{}) error {
-	switch v := value.(type) {
-	case bool:
-		return column.setBool(id, v)
-	case int64:
-		return column.setInt(id, v)
-	case float64:
-		return column.setFloat(id, v)
-	case GeoPoint:
-		return column.setGeoPoint(id, v)
-	case []byte:
-		return column.setText(id, v)
-	case []bool:
-		return column.setBoolVector(id, v)
-	case []int64:
-		return column.setIntVector(id, v)
-	case []float64:
-		return column.setFloatVector(id, v)
-	case []GeoPoint:
-		return column.setGeoPointVector(id, v)
-	case [][]byte:
-		return column.setTextVector(id, v)
+// -- ColumnCursor --
+
+// columnCursorBatchSize is the number of values fetched by a ColumnCursor at
+// once.
+const columnCursorBatchSize = 1000
+
+// ColumnCursor iterates over the values of a column in ID order.
+type ColumnCursor struct {
+	column *Column
+	cursor *TableCursor
+	ids    []uint32      // The IDs of the current batch.
+	values reflect.Value // values.Index(i) is the value of ids[i].
+	index  int
+	err    error
+	closed bool
+}
+
+// canGetValues() returns whether GetValues() supports the column.
+func (column *Column) canGetValues() bool {
+	if column.isVector || column.isIndex || (column.valueTable != nil) {
+		return false
+	}
+	switch column.valueType {
+	case Bool, Int8, Int16, Int32, Int64, UInt8, UInt16, UInt32, UInt64,
+		Float, Float32, Time, TokyoGeoPoint, WGS84GeoPoint, ShortText, Text,
+		LongText:
+		return true
 	default:
-		return fmt.Errorf("unsupported value type: name = <%s>",
-			reflect.TypeOf(value).Name())
+		return false
 	}
 }
 
-// getBool() gets a Bool value.
-func (column *Column) getBool(id uint32) (interface{}, error) {
-	var grnValue C.grn_bool
-	if ok := C.grngo_column_get_bool(column.table.db.ctx, column.obj,
-		C.grn_id(id), &grnValue); ok != C.GRN_TRUE {
-		return nil, fmt.Errorf("grngo_column_get_bool() failed")
+// ScanAll() opens a cursor to iterate over all the rows of the table and
+// their values of the column in ID order.
+// Values are fetched in batches by GetValues() if it supports the column, or
+// else one by one by GetValue(), and they are in the types which GetValue()
+// returns, such as int64 and []byte.
+// The cursor must be closed by ColumnCursor.Close().
+func (column *Column) ScanAll() (*ColumnCursor, error) {
+	if column.isIndex {
+		return nil, fmt.Errorf("index column is not supported: name = <%s>",
+			column.name)
+	}
+	cursor, err := column.table.OpenCursor(nil)
+	if err != nil {
+		return nil, err
 	}
-	return grnValue == C.GRN_TRUE, nil
+	return &ColumnCursor{column: column, cursor: cursor, index: -1}, nil
 }
 
-// getInt() gets an Int value.
-func (column *Column) getInt(id uint32) (interface{}, error) {
-	var grnValue C.int64_t
-	if ok := C.grngo_column_get_int(column.table.db.ctx, column.obj,
-		C.grn_builtin_type(column.valueType),
-		C.grn_id(id), &grnValue); ok != C.GRN_TRUE {
-		return nil, fmt.Errorf("grngo_column_get_int() failed")
+// fetch() fetches the next batch.
+func (cursor *ColumnCursor) fetch() error {
+	ids := cursor.ids[:0]
+	for (len(ids) < columnCursorBatchSize) && cursor.cursor.Next() {
+		ids = append(ids, cursor.cursor.ID())
+	}
+	cursor.ids = ids
+	cursor.index = -1
+	if len(ids) == 0 {
+		cursor.values = reflect.Value{}
+		return nil
 	}
-	return int64(grnValue), nil
+	column := cursor.column
+	if column.canGetValues() {
+		values, err := column.GetValues(ids)
+		if err != nil {
+			return err
+		}
+		cursor.values = reflect.ValueOf(values)
+		return nil
+	}
+	values := make([]interface{}, len(ids))
+	for i, id := range ids {
+		value, err := column.GetValue(id)
+		if err != nil {
+			return err
+		}
+		values[i] = value
+	}
+	cursor.values = reflect.ValueOf(values)
+	return nil
 }
 
-// getFloat() gets a Float value.
-func (column *Column) getFloat(id uint32) (interface{}, error) {
-	var grnValue C.double
-	if ok := C.grngo_column_get_float(column.table.db.ctx, column.obj,
-		C.grn_id(id), &grnValue); ok != C.GRN_TRUE {
-		return nil, fmt.Errorf("grngo_column_get_float() failed")
-	}
-	return float64(grnValue), nil
+// Next() moves the cursor to the next row and fetches the next batch if
+// needed. It returns false if there are no more rows, an error occurs, or the
+// cursor is closed. Err() returns the error.
+func (cursor *ColumnCursor) Next() bool {
+	if cursor.closed || (cursor.err != nil) {
+		return false
+	}
+	cursor.index++
+	if cursor.index < len(cursor.ids) {
+		return true
+	}
+	if err := cursor.fetch(); err != nil {
+		cursor.err = err
+		return false
+	}
+	cursor.index++
+	return len(cursor.ids) != 0
 }
 
-// getGeoPoint() gets a GeoPoint value.
-func (column *Column) getGeoPoint(id uint32) (interface{}, error) {
-	var grnValue C.grn_geo_point
-	if ok := C.grngo_column_get_geo_point(column.table.db.ctx, column.obj,
-		C.grn_id(id), &grnValue); ok != C.GRN_TRUE {
-		return nil, fmt.Errorf("grngo_column_get_geo_point() failed")
-	}
-	return GeoPoint{int32(grnValue.latitude), int32(grnValue.longitude)}, nil
+// Err() returns the error which stopped Next().
+func (cursor *ColumnCursor) Err() error {
+	return cursor.err
 }
 
-// getText() gets a Text value.
-func (column *Column) getText(id uint32) (interface{}, error) {
-	var grnValue C.grngo_text
-	if ok := C.grngo_column_get_text(column.table.db.ctx, column.obj,
-		C.grn_id(id), &grnValue); ok != C.GRN_TRUE {
-		return nil, fmt.Errorf("grngo_column_get_text() failed")
-	}
-	if grnValue.size == 0 {
-		return make([]byte, 0), nil
+// ID() returns the ID of the current row.
+// NilID is returned if there is no current row.
+func (cursor *ColumnCursor) ID() uint32 {
+	if (cursor.index < 0) || (cursor.index >= len(cursor.ids)) {
+		return NilID
 	}
-	value := make([]byte, int(grnValue.size))
-	grnValue.ptr = (*C.char)(unsafe.Pointer(&value[0]))
-	if ok := C.grngo_column_get_text(column.table.db.ctx, column.obj,
-		C.grn_id(id), &grnValue); ok != C.GRN_TRUE {
-		return nil, fmt.Errorf("grngo_column_get_text() failed")
+	return cursor.ids[cursor.index]
+}
+
+// Value() returns the value of the current row.
+// nil is returned if there is no current row.
+func (cursor *ColumnCursor) Value() interface{} {
+	if (cursor.index < 0) || (cursor.index >= len(cursor.ids)) {
+		return nil
 	}
-	return value, nil
+	return cursor.values.Index(cursor.index).Interface()
 }
 
-// getBoolVector() gets a BoolVector.
-func (column *Column) getBoolVector(id uint32) (interface{}, error) {
-	var grnVector C.grngo_vector
-	if ok := C.grngo_column_get_bool_vector(column.table.db.ctx, column.obj,
-		C.grn_id(id), &grnVector); ok != C.GRN_TRUE {
-		return nil, fmt.Errorf("grngo_column_get_bool_vector() failed")
+// Close() closes the cursor.
+// It is safe to close a cursor more than once.
+func (cursor *ColumnCursor) Close() error {
+	cursor.closed = true
+	cursor.ids = nil
+	cursor.values = reflect.Value{}
+	cursor.index = -1
+	return cursor.cursor.Close()
+}
+
+// -- SelectCursor --
+
+// selectCursorPageSize is the number of records fetched by a SelectCursor at
+// once.
+const selectCursorPageSize = 1000
+
+// SelectCursor iterates over the result of select page by page, so that the
+// whole result is not kept in memory.
+type SelectCursor struct {
+	table     *Table
+	options   SelectOptions
+	pageSize  int
+	offset    int // The offset of the next page.
+	remaining int // The number of records to be fetched, or -1 if unlimited.
+	records   *Records
+	index     int
+	err       error
+	closed    bool
+}
+
+// SelectCursor() executes select like Select(), but fetches the result with
+// offset and limit page by page while the returned cursor is iterated.
+// options.Offset and options.Limit specify the range of the whole result.
+// options.Drilldowns and options.DrilldownSpecs are not supported.
+// The result should be sorted with options.SortBy or options.SortKeys and
+// the table should not be modified during iteration, or else records may be
+// skipped or repeated across pages.
+func (table *Table) SelectCursor(options *SelectOptions) (*SelectCursor, error) {
+	if options == nil {
+		options = NewSelectOptions()
 	}
-	if grnVector.size == 0 {
-		return make([]bool, 0), nil
+	if (len(options.Drilldowns) != 0) || (len(options.DrilldownSpecs) != 0) {
+		return nil, fmt.Errorf("drilldown is not supported by SelectCursor()")
 	}
-	grnValue := make([]C.grn_bool, int(grnVector.size))
-	grnVector.ptr = unsafe.Pointer(&grnValue[0])
-	if ok := C.grngo_column_get_bool_vector(column.table.db.ctx, column.obj,
-		C.grn_id(id), &grnVector); ok != C.GRN_TRUE {
-		return nil, fmt.Errorf("grngo_column_get_bool_vector() failed")
+	cursor := &SelectCursor{
+		table:     table,
+		options:   *options,
+		pageSize:  selectCursorPageSize,
+		offset:    options.Offset,
+		remaining: options.Limit,
+		index:     -1,
 	}
-	value := make([]bool, int(grnVector.size))
-	for i, v := range grnValue {
-		value[i] = (v == C.GRN_TRUE)
+	if cursor.remaining < 0 {
+		cursor.remaining = -1
 	}
-	return value, nil
+	if err := cursor.fetch(); err != nil {
+		return nil, err
+	}
+	return cursor, nil
 }
 
-// getIntVector() gets a IntVector.
-func (column *Column) getIntVector(id uint32) (interface{}, error) {
-	var grnValue C.grngo_vector
-	if ok := C.grngo_column_get_int_vector(column.table.db.ctx, column.obj,
-		C.grn_builtin_type(column.valueType),
-		C.grn_id(id), &grnValue); ok != C.GRN_TRUE {
-		return nil, fmt.Errorf("grngo_column_get_int_vector() failed")
-	}
-	if grnValue.size == 0 {
-		return make([]int64, 0), nil
+// fetch() fetches the next page.
+func (cursor *SelectCursor) fetch() error {
+	limit := cursor.pageSize
+	if (cursor.remaining >= 0) && (cursor.remaining < limit) {
+		limit = cursor.remaining
+	}
+	options := cursor.options
+	options.Offset = cursor.offset
+	options.Limit = limit
+	records, err := cursor.table.Select(&options)
+	if err != nil {
+		return err
 	}
-	value := make([]int64, int(grnValue.size))
-	grnValue.ptr = unsafe.Pointer(&value[0])
-	if ok := C.grngo_column_get_int_vector(column.table.db.ctx, column.obj,
-		C.grn_builtin_type(column.valueType),
-		C.grn_id(id), &grnValue); ok != C.GRN_TRUE {
-		return nil, fmt.Errorf("grngo_column_get_int_vector() failed")
+	cursor.records = records
+	cursor.index = -1
+	cursor.offset += records.Len()
+	if cursor.remaining >= 0 {
+		cursor.remaining -= records.Len()
 	}
-	return value, nil
+	return nil
 }
 
-// getFloatVector() gets a FloatVector.
-func (column *Column) getFloatVector(id uint32) (interface{}, error) {
-	var grnValue C.grngo_vector
-	if ok := C.grngo_column_get_float_vector(column.table.db.ctx, column.obj,
-		C.grn_id(id), &grnValue); ok != C.GRN_TRUE {
-		return nil, fmt.Errorf("grngo_column_get_float_vector() failed")
-	}
-	if grnValue.size == 0 {
-		return make([]float64, 0), nil
-	}
-	value := make([]float64, int(grnValue.size))
-	grnValue.ptr = unsafe.Pointer(&value[0])
-	if ok := C.grngo_column_get_float_vector(column.table.db.ctx, column.obj,
-		C.grn_id(id), &grnValue); ok != C.GRN_TRUE {
-		return nil, fmt.Errorf("grngo_column_get_float_vector() failed")
+// Next() moves the cursor to the next record and fetches the next page if
+// needed. It returns false if there are no more records, an error occurs, or
+// the cursor is closed. Err() returns the error.
+func (cursor *SelectCursor) Next() bool {
+	if cursor.closed || (cursor.err != nil) {
+		return false
+	}
+	cursor.index++
+	if cursor.index < cursor.records.Len() {
+		return true
+	}
+	if (cursor.records.Len() < cursor.pageSize) || (cursor.remaining == 0) {
+		return false
+	}
+	if err := cursor.fetch(); err != nil {
+		cursor.err = err
+		return false
+	}
+	cursor.index = 0
+	return cursor.records.Len() != 0
+}
+
+// Err() returns the error which stopped Next().
+func (cursor *SelectCursor) Err() error {
+	return cursor.err
+}
+
+// NHits() returns the number of matched records.
+func (cursor *SelectCursor) NHits() int {
+	return cursor.records.NHits
+}
+
+// ColumnNames() returns the names of the output columns.
+func (cursor *SelectCursor) ColumnNames() []string {
+	return cursor.records.ColumnNames()
+}
+
+// Get() returns a value of the current record.
+func (cursor *SelectCursor) Get(name string) (interface{}, error) {
+	if cursor.closed {
+		return nil, fmt.Errorf("cursor closed")
 	}
-	return value, nil
+	return cursor.records.Get(cursor.index, name)
 }
 
-// getGeoPointVector() gets a GeoPointVector.
-func (column *Column) getGeoPointVector(id uint32) (interface{}, error) {
-	var grnValue C.grngo_vector
-	if ok := C.grngo_column_get_geo_point_vector(column.table.db.ctx, column.obj,
-		C.grn_id(id), &grnValue); ok != C.GRN_TRUE {
-		return nil, fmt.Errorf("grngo_column_get_geo_point_vector() failed")
+// Scan() stores the values of the current record into dest, that are
+// pointers, such as *int64 and *string, in the order of the output columns.
+func (cursor *SelectCursor) Scan(dest ...interface{}) error {
+	if cursor.closed {
+		return fmt.Errorf("cursor closed")
+	}
+	if (cursor.index < 0) || (cursor.index >= cursor.records.Len()) {
+		return fmt.Errorf("no current record")
+	}
+	row := cursor.records.rows[cursor.index]
+	if len(dest) != len(row) {
+		return fmt.Errorf("wrong number of dest: dest = %d, columns = %d",
+			len(dest), len(row))
+	}
+	for i, d := range dest {
+		ptr := reflect.ValueOf(d)
+		if (ptr.Kind() != reflect.Ptr) || ptr.IsNil() {
+			return fmt.Errorf("unsupported dest type: type = %T", d)
+		}
+		if err := assignValue(ptr.Elem(), row[i]); err != nil {
+			return fmt.Errorf("assignValue() failed: column = <%s>, err = %v",
+				cursor.records.names[i], err)
+		}
 	}
-	if grnValue.size == 0 {
-		return make([]GeoPoint, 0), nil
+	return nil
+}
+
+// Close() closes the cursor and stops fetching pages.
+// It is safe to close a cursor more than once.
+func (cursor *SelectCursor) Close() error {
+	cursor.closed = true
+	cursor.records = &Records{}
+	return nil
+}
+
+// -- WriteBatch --
+
+// WriteBatch buffers row insertions and value assignments of a table, and
+// flushes them with the load command on Commit().
+// Note that WriteBatch is a buffering mechanism for throughput, not a
+// transaction. Other operations see the changes as soon as each load command
+// completes, and a failed Commit() may leave some records loaded.
+type WriteBatch struct {
+	table   *Table
+	options LoadOptions
+	records []map[string]interface{}
+	indices map[string]int // Record indices by encoded keys.
+}
+
+// NewBatch() creates a new WriteBatch for the table.
+// options is used to flush records on Commit(), and nil means the default
+// settings.
+func (table *Table) NewBatch(options *LoadOptions) *WriteBatch {
+	if options == nil {
+		options = NewLoadOptions()
 	}
-	value := make([]GeoPoint, int(grnValue.size))
-	grnValue.ptr = unsafe.Pointer(&value[0])
-	if ok := C.grngo_column_get_geo_point_vector(column.table.db.ctx, column.obj,
-		C.grn_id(id), &grnValue); ok != C.GRN_TRUE {
-		return nil, fmt.Errorf("grngo_column_get_geo_point_vector() failed")
+	return &WriteBatch{
+		table:   table,
+		options: *options,
+		indices: make(map[string]int),
 	}
-	return value, nil
 }
 
-// getTextVector() gets a TextVector.
-func (column *Column) getTextVector(id uint32) (interface{}, error) {
-	var grnVector C.grngo_vector
-	if ok := C.grngo_column_get_text_vector(column.table.db.ctx, column.obj,
-		C.grn_id(id), &grnVector); ok != C.GRN_TRUE {
-		return nil, fmt.Errorf("grngo_column_get_text_vector() failed")
+// Len() returns the number of buffered records.
+func (batch *WriteBatch) Len() int {
+	return len(batch.records)
+}
+
+// InsertRow() buffers a row insertion and returns the index of the buffered
+// record, which is passed to SetValue().
+// key must be nil if the table has no key. Otherwise, the same record is
+// returned for the same key.
+func (batch *WriteBatch) InsertRow(key interface{}) (int, error) {
+	table := batch.table
+	if table.keyType == Void {
+		if key != nil {
+			return 0, fmt.Errorf("table has no key: table = <%s>", table.name)
+		}
+		batch.records = append(batch.records, make(map[string]interface{}))
+		return len(batch.records) - 1, nil
 	}
-	if grnVector.size == 0 {
-		return make([][]byte, 0), nil
+	if key == nil {
+		return 0, fmt.Errorf("key type conflict")
 	}
-	grnValues := make([]C.grngo_text, int(grnVector.size))
-	grnVector.ptr = unsafe.Pointer(&grnValues[0])
-	if ok := C.grngo_column_get_text_vector(column.table.db.ctx, column.obj,
-		C.grn_id(id), &grnVector); ok != C.GRN_TRUE {
-		return nil, fmt.Errorf("grngo_column_get_text_vector() failed")
+	encodedKey, err := table.encodeKey(key)
+	if err != nil {
+		return 0, err
 	}
-	value := make([][]byte, int(grnVector.size))
-	for i, grnValue := range grnValues {
-		if grnValue.size != 0 {
-			value[i] = make([]byte, int(grnValue.size))
-			grnValues[i].ptr = (*C.char)(unsafe.Pointer(&value[i][0]))
-		}
+	if i, ok := batch.indices[string(encodedKey)]; ok {
+		return i, nil
 	}
-	if ok := C.grngo_column_get_text_vector(column.table.db.ctx, column.obj,
-		C.grn_id(id), &grnVector); ok != C.GRN_TRUE {
-		return nil, fmt.Errorf("grngo_column_get_text_vector() failed")
+	batch.records = append(batch.records, map[string]interface{}{"_key": key})
+	i := len(batch.records) - 1
+	batch.indices[string(encodedKey)] = i
+	return i, nil
+}
+
+// SetValue() buffers a value assignment to the i-th buffered record.
+// The value types are the same as Column.SetValue().
+func (batch *WriteBatch) SetValue(i int, columnName string, value interface{}) error {
+	if (i < 0) || (i >= len(batch.records)) {
+		return fmt.Errorf("invalid index: i = %d, len = %d", i, len(batch.records))
 	}
-	return value, nil
+	if strings.HasPrefix(columnName, "_") && (columnName != "_value") {
+		return fmt.Errorf("invalid column name: name = <%s>", columnName)
+	}
+	batch.records[i][columnName] = value
+	return nil
 }
 
-// GetValue() gets a value.
-func (column *Column) GetValue(id uint32) (interface{}, error) {
-	if !column.isVector {
-		switch column.valueType {
-		case Bool:
-			return column.getBool(id)
-		case Int8, Int16, Int32, Int64, UInt8, UInt16, UInt32, UInt64:
-			return column.getInt(id)
-		case Float:
-			return column.getFloat(id)
-		case ShortText, Text, LongText:
-			return column.getText(id)
-		case TokyoGeoPoint, WGS84GeoPoint:
-			return column.getGeoPoint(id)
-		}
-	} else {
-		switch column.valueType {
-		case Bool:
-			return column.getBoolVector(id)
-		case Int8, Int16, Int32, Int64, UInt8, UInt16, UInt32, UInt64:
-			return column.getIntVector(id)
-		case Float:
-			return column.getFloatVector(id)
-		case ShortText, Text, LongText:
-			return column.getTextVector(id)
-		case TokyoGeoPoint, WGS84GeoPoint:
-			return column.getGeoPointVector(id)
-		}
+// Commit() flushes the buffered records with Table.Load() and returns the
+// number of loaded records.
+// On success, the batch becomes empty. On failure, the buffered records are
+// kept, so that Commit() can be retried or Rollback() can discard them.
+func (batch *WriteBatch) Commit() (uint32, error) {
+	if len(batch.records) == 0 {
+		return 0, nil
+	}
+	n, err := batch.table.Load(batch.records, &batch.options)
+	if err != nil {
+		return n, err
 	}
-	return nil, fmt.Errorf("undefined value type: valueType = %d", column.valueType)
+	batch.Rollback()
+	return n, nil
+}
+
+// Rollback() discards the buffered records.
+// Records loaded by Commit() are not affected.
+func (batch *WriteBatch) Rollback() {
+	batch.records = nil
+	batch.indices = make(map[string]int)
 }
@@ -6,6 +6,19 @@
 
 #include <groonga.h>
 
+// GRNGO_HAVE_FLOAT32 is defined if Groonga supports Float32 (10.0.2 or later).
+// GRNGO_DB_FLOAT32 is GRN_DB_FLOAT32 if supported, or an invalid ID if not.
+#if defined(GRN_VERSION_OR_LATER)
+#  if GRN_VERSION_OR_LATER(10, 0, 2)
+#    define GRNGO_HAVE_FLOAT32
+#  endif
+#endif
+#ifdef GRNGO_HAVE_FLOAT32
+#  define GRNGO_DB_FLOAT32 GRN_DB_FLOAT32
+#else
+#  define GRNGO_DB_FLOAT32 (-1)
+#endif
+
 typedef struct {
   char *ptr;
   size_t size;
@@ -21,6 +34,15 @@ typedef struct {
 // If not found, NULL is returned.
 grn_obj *grngo_find_table(grn_ctx *ctx, const char *name, int name_len);
 
+// grngo_table_create_temporary() creates an anonymous temporary table.
+// flags must not have GRN_OBJ_PERSISTENT.
+grn_obj *grngo_table_create_temporary(grn_ctx *ctx, unsigned int flags,
+                                      grn_obj *key_type, grn_obj *value_type);
+
+// grngo_ctx_cancel() requests cancellation of the running command.
+// It is called from another thread and the command may not be interrupted.
+void grngo_ctx_cancel(grn_ctx *ctx);
+
 typedef struct {
   grn_builtin_type  data_type;  // Data type (GRN_DB_VOID, GRN_DB_BOOL, etc.).
                       // If the type is table reference, the key type of the
@@ -43,6 +65,42 @@ grn_bool grngo_column_get_value_info(grn_ctx *ctx, grn_obj *column,
 // On success, a non-NULL pointer is returned and it must be freed by free().
 // On failure, NULL is returned.
 char *grngo_table_get_name(grn_ctx *ctx, grn_obj *table);
+// grngo_obj_get_name() returns the name of obj like grngo_table_get_name().
+// The name of a column includes the table name, such as "Table.Column".
+char *grngo_obj_get_name(grn_ctx *ctx, grn_obj *obj);
+// grngo_db_get_table_ids() gets the IDs of the tables in the database.
+// ids[i] is set for i < *n, and then *n is set to the actual number.
+grn_bool grngo_db_get_table_ids(grn_ctx *ctx, grn_id *ids, size_t *n);
+// grngo_table_get_column_ids() gets the IDs of the columns of a table.
+// ids[i] is set for i < *n, and then *n is set to the actual number.
+grn_bool grngo_table_get_column_ids(grn_ctx *ctx, grn_obj *table,
+                                    grn_id *ids, size_t *n);
+// grngo_table_get_disk_usage() gets the disk usage of a table and its
+// columns in bytes. *index_usage is set to the part of index columns.
+grn_bool grngo_table_get_disk_usage(grn_ctx *ctx, grn_obj *table,
+                                    uint64_t *usage, uint64_t *index_usage);
+// grngo_table_get_tokenizer() returns the default tokenizer of a table.
+// If not set, NULL is returned.
+grn_obj *grngo_table_get_tokenizer(grn_ctx *ctx, grn_obj *table);
+// grngo_table_get_normalizer() returns the normalizer of a table.
+// If not set, NULL is returned.
+grn_obj *grngo_table_get_normalizer(grn_ctx *ctx, grn_obj *table);
+// grngo_table_get_token_filters() gets the token filters of a table.
+// filters[i] is set if *n >= the actual number of token filters, and then *n
+// is set to the actual number.
+grn_bool grngo_table_get_token_filters(grn_ctx *ctx, grn_obj *table,
+                                       grn_obj **filters, size_t *n);
+
+// grngo_column_is_index() returns whether the column is an index column.
+grn_bool grngo_column_is_index(grn_ctx *ctx, grn_obj *column);
+// grngo_column_get_sources() gets the object IDs of the sources of an index
+// column. ids[i] is set if *n >= the actual number of sources, and then *n
+// is set to the actual number.
+grn_bool grngo_column_get_sources(grn_ctx *ctx, grn_obj *column,
+                                  grn_id *ids, size_t *n);
+// grngo_column_has_match_index() returns whether the column has an index
+// usable for full-text search.
+grn_bool grngo_column_has_match_index(grn_ctx *ctx, grn_obj *column);
 
 typedef struct {
   grn_id   id;       // Row ID, GRN_ID_NIL means the info is invalid.
@@ -74,13 +132,54 @@ grngo_row_info grngo_table_insert_uint64(grn_ctx *ctx, grn_obj *table,
 // grngo_table_insert_float() inserts a row with Float key.
 grngo_row_info grngo_table_insert_float(grn_ctx *ctx, grn_obj *table,
                                         double key);
+// grngo_table_insert_time() inserts a row with Time key.
+grngo_row_info grngo_table_insert_time(grn_ctx *ctx, grn_obj *table,
+                                       int64_t key);
 // grngo_table_insert_geo_point() inserts a row with GeoPoint key.
 grngo_row_info grngo_table_insert_geo_point(grn_ctx *ctx, grn_obj *table,
                                             grn_geo_point key);
 // grngo_table_insert_text() inserts a row with Text key.
 grngo_row_info grngo_table_insert_text(grn_ctx *ctx, grn_obj *table,
                                        const grngo_text *key);
+// grngo_table_insert_ref() inserts a row with a reference key.
+// key is the ID of a row in the referenced table.
+grngo_row_info grngo_table_insert_ref(grn_ctx *ctx, grn_obj *table,
+                                      grn_id key);
+// grngo_table_insert_rows() inserts rows with fixed-size keys.
+// keys must point to n keys of key_size bytes, and the results are stored
+// into rows. It stops at the first failure and returns the number of
+// processed keys.
+size_t grngo_table_insert_rows(grn_ctx *ctx, grn_obj *table,
+                               const void *keys, size_t key_size, size_t n,
+                               grngo_row_info *rows);
+// grngo_table_insert_ints() inserts rows with Int keys like
+// grngo_table_insert_rows().
+size_t grngo_table_insert_ints(grn_ctx *ctx, grn_obj *table,
+                               grn_builtin_type data_type,
+                               const int64_t *keys, size_t n,
+                               grngo_row_info *rows);
+// grngo_table_insert_texts() inserts rows with Text keys like
+// grngo_table_insert_rows().
+size_t grngo_table_insert_texts(grn_ctx *ctx, grn_obj *table,
+                                const grngo_text *keys, size_t n,
+                                grngo_row_info *rows);
 
+// grngo_table_delete_by_id() removes a row.
+grn_bool grngo_table_delete_by_id(grn_ctx *ctx, grn_obj *table, grn_id id);
+
+// grngo_table_delete_row() removes a row with a key, that is the binary
+// representation of the key type.
+// If the key does not exist, GRN_END_OF_DATA is returned.
+grn_rc grngo_table_delete_row(grn_ctx *ctx, grn_obj *table,
+                              const void *key, unsigned key_size);
+
+// grngo_column_clear_value() clears a value, that is, a scalar becomes 0 or
+// empty and a vector becomes empty.
+grn_bool grngo_column_clear_value(grn_ctx *ctx, grn_obj *column, grn_id id);
+// grngo_column_has_value() checks whether a stored value is non-empty.
+// A fixed-size value is always non-empty.
+grn_bool grngo_column_has_value(grn_ctx *ctx, grn_obj *column, grn_id id,
+                                grn_bool *has_value);
 // grngo_column_set_bool() assigns a Bool value.
 grn_bool grngo_column_set_bool(grn_ctx *ctx, grn_obj *column,
                                grn_id id, grn_bool value);
@@ -104,6 +203,13 @@ grn_bool grngo_column_set_uint64(grn_ctx *ctx, grn_obj *column,
 // grngo_column_set_float() assigns a Float value.
 grn_bool grngo_column_set_float(grn_ctx *ctx, grn_obj *column,
                                 grn_id id, double value);
+// grngo_column_set_float32() assigns a Float32 value.
+// If Float32 is not supported, GRN_FALSE is returned.
+grn_bool grngo_column_set_float32(grn_ctx *ctx, grn_obj *column,
+                                  grn_id id, float value);
+// grngo_column_set_time() assigns a Time value.
+grn_bool grngo_column_set_time(grn_ctx *ctx, grn_obj *column,
+                               grn_id id, int64_t value);
 // grngo_column_set_geo_point() assigns a GeoPoint value.
 grn_bool grngo_column_set_geo_point(grn_ctx *ctx, grn_obj *column,
                                     grn_builtin_type data_type,
@@ -111,6 +217,27 @@ grn_bool grngo_column_set_geo_point(grn_ctx *ctx, grn_obj *column,
 // grngo_column_set_text() assigns a Text value.
 grn_bool grngo_column_set_text(grn_ctx *ctx, grn_obj *column,
                                grn_id id, const grngo_text *value);
+// grngo_column_set_*s() assign values[i] to the row ids[i] for each i < n.
+// They stop at the first failure and return the number of assigned values.
+size_t grngo_column_set_bools(grn_ctx *ctx, grn_obj *column,
+                              const grn_id *ids, const grn_bool *values,
+                              size_t n);
+size_t grngo_column_set_ints(grn_ctx *ctx, grn_obj *column,
+                             grn_builtin_type data_type, const grn_id *ids,
+                             const int64_t *values, size_t n);
+size_t grngo_column_set_floats(grn_ctx *ctx, grn_obj *column,
+                               const grn_id *ids, const double *values,
+                               size_t n);
+size_t grngo_column_set_times(grn_ctx *ctx, grn_obj *column,
+                              const grn_id *ids, const int64_t *values,
+                              size_t n);
+size_t grngo_column_set_geo_points(grn_ctx *ctx, grn_obj *column,
+                                   grn_builtin_type data_type,
+                                   const grn_id *ids,
+                                   const grn_geo_point *values, size_t n);
+size_t grngo_column_set_texts(grn_ctx *ctx, grn_obj *column,
+                              const grn_id *ids, const grngo_text *values,
+                              size_t n);
 // grngo_column_set_bool_vector() assigns a Bool vector.
 grn_bool grngo_column_set_bool_vector(grn_ctx *ctx, grn_obj *column,
                                       grn_id id,
@@ -144,6 +271,15 @@ grn_bool grngo_column_set_uint64_vector(grn_ctx *ctx, grn_obj *column,
 grn_bool grngo_column_set_float_vector(grn_ctx *ctx, grn_obj *column,
                                        grn_id id,
                                        const grngo_vector *value);
+// grngo_column_set_float32_vector() assigns a Float32 vector.
+// If Float32 is not supported, GRN_FALSE is returned.
+grn_bool grngo_column_set_float32_vector(grn_ctx *ctx, grn_obj *column,
+                                         grn_id id,
+                                         const grngo_vector *value);
+// grngo_column_set_time_vector() assigns a Time vector.
+grn_bool grngo_column_set_time_vector(grn_ctx *ctx, grn_obj *column,
+                                      grn_id id,
+                                      const grngo_vector *value);
 // grngo_column_set_geo_point_vector() assigns a GeoPoint vector.
 grn_bool grngo_column_set_geo_point_vector(grn_ctx *ctx, grn_obj *column,
                                            grn_builtin_type data_type,
@@ -154,6 +290,54 @@ grn_bool grngo_column_set_geo_point_vector(grn_ctx *ctx, grn_obj *column,
 grn_bool grngo_column_set_text_vector(grn_ctx *ctx, grn_obj *column,
                                       grn_id id,
                                       const grngo_vector *value);
+// grngo_column_has_weight() returns whether the column is WITH_WEIGHT.
+grn_bool grngo_column_has_weight(grn_ctx *ctx, grn_obj *column);
+// grngo_column_set_weighted_vector() assigns a vector with weights.
+// values must point to n values of value_size bytes whose type is data_type,
+// that must be the value type of the column.
+grn_bool grngo_column_set_weighted_vector(grn_ctx *ctx, grn_obj *column,
+                                          grn_id id,
+                                          grn_builtin_type data_type,
+                                          const void *values,
+                                          size_t value_size,
+                                          const uint32_t *weights, size_t n);
+// grngo_column_set_weighted_int_vector() assigns an Int vector with weights.
+// values are converted into data_type, that must be the value type of the
+// column.
+grn_bool grngo_column_set_weighted_int_vector(grn_ctx *ctx, grn_obj *column,
+                                              grn_id id,
+                                              grn_builtin_type data_type,
+                                              const int64_t *values,
+                                              const uint32_t *weights,
+                                              size_t n);
+// grngo_column_append_int() appends an Int value to a vector column.
+grn_bool grngo_column_append_int(grn_ctx *ctx, grn_obj *column, grn_id id,
+                                 grn_builtin_type data_type, int64_t value);
+// grngo_column_append_float() appends a Float value to a vector column.
+grn_bool grngo_column_append_float(grn_ctx *ctx, grn_obj *column, grn_id id,
+                                   double value);
+// grngo_column_append_text() appends a Text value to a vector column.
+grn_bool grngo_column_append_text(grn_ctx *ctx, grn_obj *column, grn_id id,
+                                  const grngo_text *value);
+// grngo_column_update_int() updates a value with Int values in the manner
+// specified by flags, such as GRN_OBJ_INCR. A scalar value is values[0].
+grn_bool grngo_column_update_int(grn_ctx *ctx, grn_obj *column, grn_id id,
+                                 grn_builtin_type data_type, grn_bool is_vector,
+                                 const int64_t *values, size_t n, int flags);
+// grngo_column_update_float() updates a value with Float values.
+grn_bool grngo_column_update_float(grn_ctx *ctx, grn_obj *column, grn_id id,
+                                   grn_bool is_vector, const double *values,
+                                   size_t n, int flags);
+// grngo_column_update_text() updates a value with Text values.
+grn_bool grngo_column_update_text(grn_ctx *ctx, grn_obj *column, grn_id id,
+                                  grn_bool is_vector, const grngo_text *values,
+                                  size_t n, int flags);
+// grngo_column_set_weighted_text_vector() assigns a Text vector with weights.
+// value must refer to an array of grngo_text.
+grn_bool grngo_column_set_weighted_text_vector(grn_ctx *ctx, grn_obj *column,
+                                               grn_id id,
+                                               const grngo_vector *value,
+                                               const uint32_t *weights);
 
 // grngo_column_get_X_vector() sets *(X *)(value.ptr)[i] if value->size >=
 // the actual vector size.
@@ -171,12 +355,60 @@ grn_bool grngo_column_get_int(grn_ctx *ctx, grn_obj *column,
 // grngo_column_get_float() gets a stored Float value.
 grn_bool grngo_column_get_float(grn_ctx *ctx, grn_obj *column,
                                 grn_id id, double *value);
+// grngo_column_get_float32() gets a stored Float32 value.
+// If Float32 is not supported, GRN_FALSE is returned.
+grn_bool grngo_column_get_float32(grn_ctx *ctx, grn_obj *column,
+                                  grn_id id, float *value);
+// grngo_column_get_time() gets a stored Time value.
+grn_bool grngo_column_get_time(grn_ctx *ctx, grn_obj *column,
+                               grn_id id, int64_t *value);
 // grngo_column_get_geo_point() gets a stored GeoPoint value.
 grn_bool grngo_column_get_geo_point(grn_ctx *ctx, grn_obj *column,
                                     grn_id id, grn_geo_point *value);
 // grngo_column_get_text() gets a stored Text value.
 grn_bool grngo_column_get_text(grn_ctx *ctx, grn_obj *column,
                                grn_id id, grngo_text *value);
+// grngo_column_open_text() gets a stored Text value without copying it.
+// value refers to the body in the returned buffer, which must be closed by
+// grngo_text_close(). On failure, NULL is returned.
+grn_obj *grngo_column_open_text(grn_ctx *ctx, grn_obj *column,
+                                grn_id id, grngo_text *value);
+// grngo_text_close() closes a buffer returned by grngo_column_open_text().
+void grngo_text_close(grn_ctx *ctx, grn_obj *buf);
+// grngo_column_get_ref_id() gets a stored reference (row ID).
+grn_bool grngo_column_get_ref_id(grn_ctx *ctx, grn_obj *column,
+                                 grn_id id, grn_id *value);
+// grngo_column_get_ref_ids() gets a stored reference vector (row IDs).
+// value must refer to an array of grn_id.
+grn_bool grngo_column_get_ref_ids(grn_ctx *ctx, grn_obj *column,
+                                  grn_id id, grngo_vector *value);
+// grngo_column_get_*s() get stored values of the rows ids[i] for each i < n.
+// If a row does not exist in table, a zero value is stored.
+// They clear the error of ctx before reading values.
+// grngo_column_get_texts() stores the size of each value and copies its body
+// only if the buffer is large enough, like grngo_column_get_text().
+grn_bool grngo_column_get_bools(grn_ctx *ctx, grn_obj *table,
+                                grn_obj *column, const grn_id *ids,
+                                grn_bool *values, size_t n);
+grn_bool grngo_column_get_ints(grn_ctx *ctx, grn_obj *table,
+                               grn_obj *column, grn_builtin_type data_type,
+                               const grn_id *ids, int64_t *values, size_t n);
+grn_bool grngo_column_get_floats(grn_ctx *ctx, grn_obj *table,
+                                 grn_obj *column, const grn_id *ids,
+                                 double *values, size_t n);
+// grngo_column_get_float32s() fails if Float32 is not supported.
+grn_bool grngo_column_get_float32s(grn_ctx *ctx, grn_obj *table,
+                                   grn_obj *column, const grn_id *ids,
+                                   float *values, size_t n);
+grn_bool grngo_column_get_times(grn_ctx *ctx, grn_obj *table,
+                                grn_obj *column, const grn_id *ids,
+                                int64_t *values, size_t n);
+grn_bool grngo_column_get_geo_points(grn_ctx *ctx, grn_obj *table,
+                                     grn_obj *column, const grn_id *ids,
+                                     grn_geo_point *values, size_t n);
+grn_bool grngo_column_get_texts(grn_ctx *ctx, grn_obj *table,
+                                grn_obj *column, const grn_id *ids,
+                                grngo_text *values, size_t n);
 // grngo_column_get_bool_vector() gets a stored Bool vector.
 grn_bool grngo_column_get_bool_vector(grn_ctx *ctx, grn_obj *column,
                                       grn_id id, grngo_vector *value);
@@ -187,6 +419,13 @@ grn_bool grngo_column_get_int_vector(grn_ctx *ctx, grn_obj *column,
 // grngo_column_get_float_vector() gets a stored Float vector.
 grn_bool grngo_column_get_float_vector(grn_ctx *ctx, grn_obj *column,
                                        grn_id id, grngo_vector *value);
+// grngo_column_get_float32_vector() gets a stored Float32 vector.
+// If Float32 is not supported, GRN_FALSE is returned.
+grn_bool grngo_column_get_float32_vector(grn_ctx *ctx, grn_obj *column,
+                                         grn_id id, grngo_vector *value);
+// grngo_column_get_time_vector() gets a stored Time vector.
+grn_bool grngo_column_get_time_vector(grn_ctx *ctx, grn_obj *column,
+                                      grn_id id, grngo_vector *value);
 // grngo_column_get_geo_point_vector() gets a stored GeoPoint vector.
 grn_bool grngo_column_get_geo_point_vector(grn_ctx *ctx, grn_obj *column,
                                            grn_id id, grngo_vector *value);
@@ -194,5 +433,43 @@ grn_bool grngo_column_get_geo_point_vector(grn_ctx *ctx, grn_obj *column,
 // value must refer to an array of grngo_text.
 grn_bool grngo_column_get_text_vector(grn_ctx *ctx, grn_obj *column,
                                       grn_id id, grngo_vector *value);
+// grngo_column_get_weighted_text_vector() gets a stored Text vector and its
+// weights like grngo_column_get_text_vector().
+// weights[i] is set if value->size >= the actual vector size.
+grn_bool grngo_column_get_weighted_text_vector(grn_ctx *ctx, grn_obj *column,
+                                               grn_id id, grngo_vector *value,
+                                               uint32_t *weights);
+
+// grngo_filter_open() compiles a filter expression for table.
+// var_names[i] is declared as a variable before the expression is parsed.
+// On failure, NULL is returned.
+grn_obj *grngo_filter_open(grn_ctx *ctx, grn_obj *table,
+                           const char *str, size_t str_size,
+                           const grngo_text *var_names, size_t n_vars);
+// grngo_filter_set_var() sets a value to a variable of a filter.
+// value must refer to a value of data_type.
+grn_bool grngo_filter_set_var(grn_ctx *ctx, grn_obj *expr,
+                              const char *name, size_t name_size,
+                              grn_builtin_type data_type,
+                              const void *value, size_t value_size);
+// grngo_filter_select() selects rows matching a filter.
+// The returned temporary table must be closed by grn_obj_unlink().
+// On failure, NULL is returned.
+grn_obj *grngo_filter_select(grn_ctx *ctx, grn_obj *table, grn_obj *expr);
+// grngo_result_get_ids() gets the row IDs of the records in result.
+// If sort_keys is not empty, the records are sorted before offset and limit
+// are applied. *n must be the size of ids and is set to the number of stored
+// IDs.
+grn_bool grngo_result_get_ids(grn_ctx *ctx, grn_obj *result,
+                              const char *sort_keys, size_t sort_keys_size,
+                              int offset, int limit, grn_id *ids, size_t *n);
+// grngo_table_apply() evaluates an expression for each row of table and
+// stores the result into column. *n_rows is set to the number of updated
+// rows, even on failure.
+grn_rc grngo_table_apply(grn_ctx *ctx, grn_obj *table, grn_obj *column,
+                         const char *str, size_t str_size, size_t *n_rows);
+// grngo_logger_set() sets a logger which passes messages to grngoLog() if
+// use_handler is true, or the default logger otherwise.
+void grngo_logger_set(grn_bool use_handler, grn_log_level max_level);
 
 #endif  // GRNGO_H
@@ -1,13 +1,21 @@
 package grngo
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io/ioutil"
+	"math"
 	"math/rand"
 	"os"
 	"reflect"
 	"strconv"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 )
 
 // createTempDB() creates a database for tests.
@@ -67,6 +75,42 @@ func createTempColumn(tb testing.TB, tableName string,
 	return dirPath, dbPath, db, table, column
 }
 
+func TestSetLogHandler(t *testing.T) {
+	dirPath, _, db := createTempDB(t)
+	defer removeTempDB(t, dirPath, db)
+
+	var mutex sync.Mutex
+	var messages []string
+	SetLogHandler(func(level LogLevel, message string) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		if level <= ErrorLog {
+			messages = append(messages, message)
+		}
+	})
+	defer SetLogHandler(nil)
+	if err := SetLogLevel(DebugLog); err != nil {
+		t.Fatalf("SetLogLevel() failed: %v", err)
+	}
+	defer SetLogLevel(NoticeLog)
+
+	if _, err := db.Query("no_such_command"); err == nil {
+		t.Fatalf("DB.Query() succeeded for an undefined command")
+	}
+	mutex.Lock()
+	n := len(messages)
+	mutex.Unlock()
+	if n == 0 {
+		t.Fatalf("SetLogHandler() failed: no error messages")
+	}
+	if err := SetLogLevel(LogLevel(100)); err == nil {
+		t.Fatalf("SetLogLevel() succeeded for an undefined level")
+	}
+	if s := WarningLog.String(); s != "warning" {
+		t.Fatalf("LogLevel.String() failed: s = %s", s)
+	}
+}
+
 func TestCreateDB(t *testing.T) {
 	dirPath, _, db := createTempDB(t)
 	removeTempDB(t, dirPath, db)
@@ -82,455 +126,1134 @@ func TestOpenDB(t *testing.T) {
 	removeTempDB(t, dirPath, db)
 }
 
-func testDBCreateTableWithKey(t *testing.T, keyType string) {
-	options := NewTableOptions()
-	options.TableType = PatTable
-	options.KeyType = keyType
-	dirPath, _, db, _ := createTempTable(t, "Table", options)
-	removeTempDB(t, dirPath, db)
-}
+func TestCreateDBWithOptions(t *testing.T) {
+	dirPath, err := ioutil.TempDir("", "grngo_test")
+	if err != nil {
+		t.Fatalf("ioutil.TempDir() failed: %v", err)
+	}
+	defer os.RemoveAll(dirPath)
+	dbPath := dirPath + "/db"
+	options := NewDBOptions()
+	options.Encoding = EUCJPEncoding
+	db, err := CreateDBWithOptions(dbPath, options)
+	if err != nil {
+		t.Fatalf("CreateDBWithOptions() failed: %v", err)
+	}
+	defer db.Close()
+	if encoding := db.Encoding(); encoding != EUCJPEncoding {
+		t.Fatalf("DB.Encoding() failed: encoding = %s", encoding)
+	}
+	tableOptions := NewTableOptions()
+	tableOptions.TableType = HashTable
+	tableOptions.KeyType = "ShortText"
+	table, err := db.CreateTable("Table", tableOptions)
+	if err != nil {
+		t.Fatalf("DB.CreateTable() failed: %v", err)
+	}
+	key := []byte("\xa5\xb0\xa5\xeb\xa1\xbc\xa5\xf3\xa5\xac") // "Groonga" in EUC-JP.
+	if _, _, err := table.InsertRow(key); err != nil {
+		t.Fatalf("Table.InsertRow() failed: %v", err)
+	}
 
-func testDBCreateTableWithValue(t *testing.T, valueType string) {
-	options := NewTableOptions()
-	options.TableType = PatTable
-	options.ValueType = valueType
-	dirPath, _, db, _ := createTempTable(t, "Table", options)
-	removeTempDB(t, dirPath, db)
+	options.Encoding = UTF8Encoding
+	db2, err := OpenDBWithOptions(dbPath, options)
+	if err != nil {
+		t.Fatalf("OpenDBWithOptions() failed: %v", err)
+	}
+	defer db2.Close()
+	if encoding := db2.Encoding(); encoding != UTF8Encoding {
+		t.Fatalf("DB.Encoding() failed: encoding = %s", encoding)
+	}
+
+	options.Encoding = Encoding(-1)
+	if _, err := OpenDBWithOptions(dbPath, options); err == nil {
+		t.Fatalf("OpenDBWithOptions() succeeded with an undefined encoding")
+	}
 }
 
-func testDBCreateTableWithRefKey(t *testing.T, keyType string) {
-	options := NewTableOptions()
-	options.TableType = PatTable
-	options.KeyType = keyType
-	dirPath, _, db, _ := createTempTable(t, "To", options)
+func TestOpenDBReadOnly(t *testing.T) {
+	dirPath, dbPath, db, table, column :=
+		createTempColumn(t, "Table", nil, "Value", "Int32", nil)
 	defer removeTempDB(t, dirPath, db)
+	_, id, err := table.InsertRow(nil)
+	if err != nil {
+		t.Fatalf("Table.InsertRow() failed: %v", err)
+	}
+	if err := column.SetValue(id, int64(123)); err != nil {
+		t.Fatalf("Column.SetValue() failed: %v", err)
+	}
+	tagsOptions := NewColumnOptions()
+	tagsOptions.ColumnType = VectorColumn
+	tagsOptions.WithWeight = true
+	tags, err := table.CreateColumn("Tags", "ShortText", tagsOptions)
+	if err != nil {
+		t.Fatalf("Table.CreateColumn() failed: %v", err)
+	}
+	tagValues := [][]byte{[]byte("Apple"), []byte("Banana")}
+	if err := tags.SetWeightedTextVector(id, tagValues, []uint32{1, 2}); err != nil {
+		t.Fatalf("Column.SetWeightedTextVector() failed: %v", err)
+	}
 
-	options = NewTableOptions()
-	options.TableType = PatTable
-	options.KeyType = "To"
-	_, err := db.CreateTable("From", options)
+	db2, err := OpenDBReadOnly(dbPath)
 	if err != nil {
-		t.Fatalf("DB.CreateTable() failed: %v", err)
+		t.Fatalf("OpenDBReadOnly() failed: %v", err)
+	}
+	defer db2.Close()
+	if !db2.IsReadOnly() || db.IsReadOnly() {
+		t.Fatalf("DB.IsReadOnly() failed")
+	}
+	column2, err := db2.FindColumn("Table", "Value")
+	if err != nil {
+		t.Fatalf("DB.FindColumn() failed: %v", err)
+	}
+	if value, err := column2.GetValue(id); err != nil || value.(int64) != 123 {
+		t.Fatalf("Column.GetValue() failed: value = %v, err = %v", value, err)
+	}
+	if _, err := db2.Query("select Table"); err != nil {
+		t.Fatalf("DB.Query() failed: %v", err)
+	}
+	tags2, err := db2.FindColumn("Table", "Tags")
+	if err != nil {
+		t.Fatalf("DB.FindColumn() failed: %v", err)
+	}
+	values, weights, err := tags2.GetWeightedTextVector(id)
+	if err != nil {
+		t.Fatalf("Column.GetWeightedTextVector() failed: %v", err)
+	}
+	if !reflect.DeepEqual(values, tagValues) ||
+		!reflect.DeepEqual(weights, []uint32{1, 2}) {
+		t.Fatalf("Column.GetWeightedTextVector() failed: values = %q, weights = %v",
+			values, weights)
+	}
+	err = tags2.SetWeightedTextVector(id, tagValues, []uint32{3, 4})
+	if !errors.Is(err, ErrReadOnly) {
+		t.Fatalf("Column.SetWeightedTextVector() failed: err = %v", err)
+	}
+	if _, _, err := column2.table.InsertRow(nil); !errors.Is(err, ErrReadOnly) {
+		t.Fatalf("Table.InsertRow() failed: err = %v", err)
+	}
+	if err := column2.SetValue(id, int64(456)); !errors.Is(err, ErrReadOnly) {
+		t.Fatalf("Column.SetValue() failed: err = %v", err)
+	}
+	if _, err := db2.CreateTable("Table2", nil); !errors.Is(err, ErrReadOnly) {
+		t.Fatalf("DB.CreateTable() failed: err = %v", err)
+	}
+	commands := []string{"load --table Table", "/d/delete?table=Table&id=1",
+		"object_remove Table", "column_copy Table Value Table Value2",
+		"select Table --load_table Table --load_columns Value --load_values Value",
+		"/d/select?table=Table&load_table=Table"}
+	for _, command := range commands {
+		if _, err := db2.Query(command); !errors.Is(err, ErrReadOnly) {
+			t.Fatalf("DB.Query() failed: command = %s, err = %v", command, err)
+		}
+	}
+	for _, command := range []string{"status", "table_list", "/d/select?table=Table"} {
+		if _, err := db2.Query(command); err != nil {
+			t.Fatalf("DB.Query() failed: command = %s, err = %v", command, err)
+		}
 	}
 }
 
-func testDBCreateTableWithRefValue(t *testing.T, keyType string) {
-	options := NewTableOptions()
-	options.TableType = PatTable
-	options.KeyType = keyType
-	dirPath, _, db, _ := createTempTable(t, "To", options)
+func TestDBSendExWithInvalidName(t *testing.T) {
+	dirPath, _, db := createTempDB(t)
 	defer removeTempDB(t, dirPath, db)
 
-	options = NewTableOptions()
-	options.ValueType = ""
-	_, err := db.CreateTable("From", options)
-	if err != nil {
-		t.Fatalf("DB.CreateTable() failed: %v", err)
+	for _, name := range []string{"table create", "Table_create", "table-create"} {
+		if err := db.SendEx(name, nil); err == nil {
+			t.Fatalf("DB.SendEx() succeeded for an invalid name: name = <%s>", name)
+		}
+	}
+	options := map[string]string{"Name": "Table"}
+	if err := db.SendEx("table_create", options); err == nil {
+		t.Fatalf("DB.SendEx() succeeded for an invalid option: options = %v",
+			options)
+	}
+	options = map[string]string{"name ": "Table"}
+	if err := db.SendEx("table_create", options); err == nil {
+		t.Fatalf("DB.SendEx() succeeded for an invalid option: options = %v",
+			options)
 	}
 }
 
-func TestDBCreateTableWithoutKeyValue(t *testing.T) {
-	dirPath, _, db, _ := createTempTable(t, "Table", nil)
-	removeTempDB(t, dirPath, db)
+func TestEscapeCommandValue(t *testing.T) {
+	cases := map[string]string{
+		"":             "",
+		"abc":          "abc",
+		"it's":         `it\'s`,
+		`a\b`:          `a\\b`,
+		"line1\nline2": `line1\nline2`,
+		"cr\r":         `cr\r`,
+		`\'`:           `\\\'`,
+	}
+	for value, expected := range cases {
+		if escaped := EscapeCommandValue(value); escaped != expected {
+			t.Fatalf("EscapeCommandValue() failed: value = %q, escaped = %q",
+				value, escaped)
+		}
+	}
+	if quoted := QuoteCommandValue("it's\n"); quoted != `'it\'s\n'` {
+		t.Fatalf("QuoteCommandValue() failed: quoted = %q", quoted)
+	}
 }
 
-func TestDBCreateTableWithBoolKey(t *testing.T) {
-	testDBCreateTableWithKey(t, "Bool")
+func TestReflectToScalarRange(t *testing.T) {
+	validCases := []struct {
+		dataType DataType
+		value    interface{}
+		expected int64
+	}{
+		{Int8, int64(math.MinInt8), math.MinInt8},
+		{Int8, uint8(math.MaxInt8), math.MaxInt8},
+		{Int16, int(math.MinInt16), math.MinInt16},
+		{Int32, uint32(math.MaxInt32), math.MaxInt32},
+		{Int64, uint64(math.MaxInt64), math.MaxInt64},
+		{UInt8, uint8(math.MaxUint8), math.MaxUint8},
+		{UInt16, int32(math.MaxUint16), math.MaxUint16},
+		{UInt32, uint32(math.MaxUint32), math.MaxUint32},
+		{UInt64, uint64(math.MaxUint64), -1},
+	}
+	for _, c := range validCases {
+		value, err := reflectToScalar(c.dataType, reflect.ValueOf(c.value))
+		if err != nil {
+			t.Fatalf("reflectToScalar() failed: dataType = %s, value = %v, err = %v",
+				c.dataType, c.value, err)
+		}
+		if value != c.expected {
+			t.Fatalf("reflectToScalar() failed: dataType = %s, value = %v",
+				c.dataType, value)
+		}
+	}
+	invalidCases := []struct {
+		dataType DataType
+		value    interface{}
+	}{
+		{Int8, int64(math.MinInt8 - 1)},
+		{Int8, uint8(math.MaxInt8 + 1)},
+		{Int16, int(math.MaxInt16 + 1)},
+		{Int32, int64(math.MinInt32 - 1)},
+		{Int64, uint64(math.MaxInt64 + 1)},
+		{UInt8, int(-1)},
+		{UInt8, uint16(math.MaxUint8 + 1)},
+		{UInt16, int32(math.MaxUint16 + 1)},
+		{UInt32, uint64(math.MaxUint32 + 1)},
+		{UInt64, int64(-1)},
+	}
+	for _, c := range invalidCases {
+		if _, err := reflectToScalar(c.dataType, reflect.ValueOf(c.value)); err == nil {
+			t.Fatalf("reflectToScalar() succeeded: dataType = %s, value = %v",
+				c.dataType, c.value)
+		}
+	}
 }
 
-func TestDBCreateTableWithIntKey(t *testing.T) {
-	testDBCreateTableWithKey(t, "Int64")
+func TestBuildCommand(t *testing.T) {
+	validKeys := []string{"table", "output_columns",
+		"drilldown[label].keys", "drilldown[Label_1].calc_types"}
+	for _, key := range validKeys {
+		command, err := buildCommand("select", map[string]string{key: "x"})
+		if err != nil {
+			t.Fatalf("buildCommand() failed: key = <%s>, err = %v", key, err)
+		}
+		if expected := "select --" + key + " 'x'"; command != expected {
+			t.Fatalf("buildCommand() failed: command = <%s>", command)
+		}
+	}
+	invalidKeys := []string{"", "Table", "drilldown[].keys",
+		"drilldown[a b].keys", "drilldown[a].", "drilldown[a]keys",
+		"drilldown[a].keys --load_table", "drilldown[a]].keys",
+		"drilldown[a-b].keys", "drilldown[a.keys"}
+	for _, key := range invalidKeys {
+		if _, err := buildCommand("select", map[string]string{key: "x"}); err == nil {
+			t.Fatalf("buildCommand() succeeded: key = <%s>", key)
+		}
+	}
 }
 
-func TestDBCreateTableWithFloatKey(t *testing.T) {
-	testDBCreateTableWithKey(t, "Float")
-}
+func TestDBQueryContext(t *testing.T) {
+	dirPath, _, db := createTempDB(t)
+	defer removeTempDB(t, dirPath, db)
 
-func TestDBCreateTableWithTokyoGeoPointKey(t *testing.T) {
-	testDBCreateTableWithKey(t, "TokyoGeoPoint")
-}
+	result, err := db.QueryContext(context.Background(), "status")
+	if err != nil {
+		t.Fatalf("DB.QueryContext() failed: %v", err)
+	}
+	if len(result) == 0 {
+		t.Fatalf("DB.QueryContext() returned an empty result")
+	}
 
-func TestDBCreateTableWithWGS84GeoPointKey(t *testing.T) {
-	testDBCreateTableWithKey(t, "WGS84GeoPoint")
-}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := db.QueryContext(ctx, "status"); err != context.Canceled {
+		t.Fatalf("DB.QueryContext() failed: err = %v", err)
+	}
+	ctx, cancel = context.WithTimeout(context.Background(), 0)
+	defer cancel()
+	if _, err := db.QueryContext(ctx, "status"); err != context.DeadlineExceeded {
+		t.Fatalf("DB.QueryContext() failed: err = %v", err)
+	}
 
-func TestDBCreateTableWithTextKey(t *testing.T) {
-	testDBCreateTableWithKey(t, "ShortText")
+	// Cancel a slow query while it is running.
+	if _, err := db.Query("table_create Table TABLE_NO_KEY"); err != nil {
+		t.Fatalf("DB.Query() failed: %v", err)
+	}
+	if _, err := db.Query("column_create Table Value COLUMN_SCALAR Int32"); err != nil {
+		t.Fatalf("DB.Query() failed: %v", err)
+	}
+	var load bytes.Buffer
+	load.WriteString("load --table Table --values '[")
+	const numRows = 200000
+	for i := 0; i < numRows; i++ {
+		if i != 0 {
+			load.WriteByte(',')
+		}
+		fmt.Fprintf(&load, `{"Value":%d}`, i)
+	}
+	load.WriteString("]'")
+	if _, err := db.Query(load.String()); err != nil {
+		t.Fatalf("DB.Query() failed: %v", err)
+	}
+	ctx, cancel = context.WithCancel(context.Background())
+	timer := time.AfterFunc(10*time.Millisecond, cancel)
+	defer timer.Stop()
+	_, err = db.QueryContext(ctx, "select Table --filter 'Value % 3 != 1'"+
+		" --sortby -Value --limit -1 --output_columns _id,Value")
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("DB.QueryContext() failed: err = %v", err)
+	}
+	if _, err := db.Query("status"); err != nil {
+		t.Fatalf("DB.Query() failed after cancellation: %v", err)
+	}
 }
 
-func TestDBCreateTableWithBoolValue(t *testing.T) {
-	testDBCreateTableWithValue(t, "Bool")
-}
+func TestDBQueryConcurrently(t *testing.T) {
+	dirPath, _, db, table := createTempTable(t, "Table", nil)
+	defer removeTempDB(t, dirPath, db)
 
-func TestDBCreateTableWithIntValue(t *testing.T) {
-	testDBCreateTableWithValue(t, "Int64")
+	if _, _, err := table.InsertRow(nil); err != nil {
+		t.Fatalf("Table.InsertRow() failed: %v", err)
+	}
+	command := "select Table --output_columns _id"
+	expected, err := db.Query(command)
+	if err != nil {
+		t.Fatalf("DB.Query() failed: %v", err)
+	}
+	const numGoroutines = 8
+	const numQueries = 100
+	var wg sync.WaitGroup
+	errs := make(chan error, numGoroutines)
+	for i := 0; i < numGoroutines; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < numQueries; j++ {
+				result, err := db.Query(command)
+				if err != nil {
+					errs <- fmt.Errorf("DB.Query() failed: %v", err)
+					return
+				}
+				if !bytes.Equal(result, expected) {
+					errs <- fmt.Errorf("DB.Query() returned a wrong result: %s", result)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Fatal(err)
+	}
 }
 
-func TestDBCreateTableWithFloatValue(t *testing.T) {
-	testDBCreateTableWithValue(t, "Float")
-}
+func TestPool(t *testing.T) {
+	dirPath, dbPath, db, table := createTempTable(t, "Table", nil)
+	defer removeTempDB(t, dirPath, db)
 
-func TestDBCreateTableWithTokyoGeoPointValue(t *testing.T) {
-	testDBCreateTableWithValue(t, "TokyoGeoPoint")
+	if _, _, err := table.InsertRow(nil); err != nil {
+		t.Fatalf("Table.InsertRow() failed: %v", err)
+	}
+	if _, err := OpenPool(dbPath, 0); err == nil {
+		t.Fatalf("OpenPool() succeeded for size 0")
+	}
+	pool, err := OpenPool(dbPath, 4)
+	if err != nil {
+		t.Fatalf("OpenPool() failed: %v", err)
+	}
+	if size := pool.Size(); size != 4 {
+		t.Fatalf("Pool.Size() failed: size = %d", size)
+	}
+	var wg sync.WaitGroup
+	errs := make(chan error, 8)
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			pooledDB := pool.Get()
+			defer pool.Put(pooledDB)
+			pooledTable, err := pooledDB.FindTable("Table")
+			if err != nil {
+				errs <- fmt.Errorf("DB.FindTable() failed: %v", err)
+				return
+			}
+			if n, err := pooledTable.Len(); err != nil || n != 1 {
+				errs <- fmt.Errorf("Table.Len() failed: n = %d, err = %v", n, err)
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Error(err)
+	}
+	if err := pool.Close(); err != nil {
+		t.Fatalf("Pool.Close() failed: %v", err)
+	}
 }
 
-func TestDBCreateTableWithWGS84GeoPointValue(t *testing.T) {
-	testDBCreateTableWithValue(t, "WGS84GeoPoint")
-}
+func TestDBSetOutputType(t *testing.T) {
+	dirPath, _, db, table := createTempTable(t, "Table", nil)
+	defer removeTempDB(t, dirPath, db)
 
-func TestDBCreateTableWithBoolRefKey(t *testing.T) {
-	testDBCreateTableWithRefKey(t, "Bool")
-}
+	if _, _, err := table.InsertRow(nil); err != nil {
+		t.Fatalf("Table.InsertRow() failed: %v", err)
+	}
+	if err := db.SetOutputType(TSVOutput); err != nil {
+		t.Fatalf("DB.SetOutputType() failed: %v", err)
+	}
+	result, err := db.QueryEx("select", map[string]string{"table": "Table"})
+	if err != nil {
+		t.Fatalf("DB.QueryEx() failed: %v", err)
+	}
+	if strings.HasPrefix(string(result), "[") {
+		t.Fatalf("DB.QueryEx() ignored the output type: result = %s", result)
+	}
+	result, err = db.QueryEx("select",
+		map[string]string{"table": "Table", "output_type": "json"})
+	if err != nil {
+		t.Fatalf("DB.QueryEx() failed: %v", err)
+	}
+	if !strings.HasPrefix(string(result), "[") {
+		t.Fatalf("DB.QueryEx() ignored output_type: result = %s", result)
+	}
 
-func TestDBCreateTableWithIntRefKey(t *testing.T) {
-	testDBCreateTableWithRefKey(t, "Int64")
+	// Internal commands must not be affected by the output type.
+	if _, err := table.CreateColumn("Value", "Int32", nil); err != nil {
+		t.Fatalf("Table.CreateColumn() failed: %v", err)
+	}
+	records, err := table.Select(nil)
+	if err != nil {
+		t.Fatalf("Table.Select() failed: %v", err)
+	}
+	if records.NHits != 1 {
+		t.Fatalf("Table.Select() failed: NHits = %d", records.NHits)
+	}
+	if err := db.SetOutputType(OutputType(100)); err == nil {
+		t.Fatalf("DB.SetOutputType() succeeded for an undefined output type")
+	}
 }
 
-func TestDBCreateTableWithFloatRefKey(t *testing.T) {
-	testDBCreateTableWithRefKey(t, "Float")
-}
+func TestDBSetCommandVersion(t *testing.T) {
+	dirPath, _, db, table := createTempTable(t, "Table", nil)
+	defer removeTempDB(t, dirPath, db)
 
-func TestDBCreateTableWithTokyoGeoPointRefKey(t *testing.T) {
-	testDBCreateTableWithRefKey(t, "TokyoGeoPoint")
+	if _, _, err := table.InsertRow(nil); err != nil {
+		t.Fatalf("Table.InsertRow() failed: %v", err)
+	}
+	for _, version := range []int{1, 2} {
+		if err := db.SetCommandVersion(version); err != nil {
+			t.Fatalf("DB.SetCommandVersion() failed: %v", err)
+		}
+		if v := db.CommandVersion(); v != version {
+			t.Fatalf("DB.CommandVersion() failed: version = %d", v)
+		}
+		records, err := table.Select(nil)
+		if err != nil {
+			t.Fatalf("Table.Select() failed: %v", err)
+		}
+		if records.NHits != 1 {
+			t.Fatalf("Table.Select() failed: NHits = %d", records.NHits)
+		}
+	}
+	for _, version := range []int{0, 3} {
+		if err := db.SetCommandVersion(version); err == nil {
+			t.Fatalf("DB.SetCommandVersion() succeeded for an unsupported version: version = %d",
+				version)
+		}
+	}
+	if v := db.CommandVersion(); v != 2 {
+		t.Fatalf("DB.CommandVersion() failed: version = %d", v)
+	}
 }
 
-func TestDBCreateTableWithWGS84GeoPointRefKey(t *testing.T) {
-	testDBCreateTableWithRefKey(t, "WGS84GeoPoint")
-}
+func TestDBStatus(t *testing.T) {
+	dirPath, _, db := createTempDB(t)
+	defer removeTempDB(t, dirPath, db)
 
-func TestDBCreateTableWithTextRefKey(t *testing.T) {
-	testDBCreateTableWithRefKey(t, "ShortText")
+	status, err := db.Status()
+	if err != nil {
+		t.Fatalf("DB.Status() failed: %v", err)
+	}
+	if status.Version == "" {
+		t.Fatalf("DB.Status() failed: status = %+v", status)
+	}
+	if status.StartTime.IsZero() || (status.Uptime < 0) {
+		t.Fatalf("DB.Status() failed: status = %+v", status)
+	}
 }
 
-func TestDBCreateTableWithBoolRefValue(t *testing.T) {
-	testDBCreateTableWithRefValue(t, "Bool")
+func TestParseStatus(t *testing.T) {
+	status, err := parseStatus([]byte(`{"alloc_count":123,"starttime":1000,` +
+		`"uptime":60,"version":"9.0.0","n_queries":5,"cache_hit_rate":12.5,` +
+		`"command_version":1,"default_command_version":1,` +
+		`"max_command_version":3,"unknown_field":{"a":[1,2]}}`))
+	if err != nil {
+		t.Fatalf("parseStatus() failed: %v", err)
+	}
+	expected := Status{
+		AllocCount:            123,
+		StartTime:             time.Unix(1000, 0),
+		Uptime:                time.Minute,
+		NQueries:              5,
+		CacheHitRate:          12.5,
+		Version:               "9.0.0",
+		CommandVersion:        1,
+		DefaultCommandVersion: 1,
+		MaxCommandVersion:     3,
+	}
+	if *status != expected {
+		t.Fatalf("parseStatus() failed: status = %+v", status)
+	}
+	if _, err := parseStatus([]byte(`{"alloc_count":"abc"}`)); err == nil {
+		t.Fatalf("parseStatus() succeeded for an invalid alloc_count")
+	}
+	if _, err := parseStatus([]byte(`[]`)); err == nil {
+		t.Fatalf("parseStatus() succeeded for an invalid result")
+	}
 }
 
-func TestDBCreateTableWithIntRefValue(t *testing.T) {
-	testDBCreateTableWithRefValue(t, "Int64")
+func TestParseQueryLog(t *testing.T) {
+	log := `2011-07-05 06:25:19.458756|0x7fff5fbff350|>select Shops --filter 'x > 1'
+2011-07-05 06:25:19.458760|0x7fff5fbff360|>status
+2011-07-05 06:25:19.458829|0x7fff5fbff350|:000000000072779 filter(19): x greater 1
+2011-07-05 06:25:19.458856|0x7fff5fbff350|:000000000099998 select(19)
+2011-07-05 06:25:19.458963|0x7fff5fbff350|:000000000206820 output(10)
+2011-07-05 06:25:19.459008|0x7fff5fbff350|<000000000252718 rc=0
+2011-07-05 06:25:19.459010|0x7fff5fbff370|>load --table Shops
+2011-07-05 06:25:19.459020|0x7fff5fbff360|<000000000000260 rc=-22
+`
+	entries, err := ParseQueryLog(strings.NewReader(log))
+	if err != nil {
+		t.Fatalf("ParseQueryLog() failed: %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("ParseQueryLog() failed: entries = %+v", entries)
+	}
+	entry := entries[0]
+	if (entry.Command != "select Shops --filter 'x > 1'") ||
+		(entry.ContextID != "0x7fff5fbff350") ||
+		(entry.Elapsed != 252718*time.Nanosecond) ||
+		(entry.NHits != 19) || (entry.RC != 0) {
+		t.Fatalf("ParseQueryLog() failed: entry = %+v", entry)
+	}
+	expectedTime := time.Date(2011, 7, 5, 6, 25, 19, 458756000, time.Local)
+	if !entry.Time.Equal(expectedTime) {
+		t.Fatalf("ParseQueryLog() failed: time = %v", entry.Time)
+	}
+	entry = entries[1]
+	if (entry.Command != "status") || (entry.NHits != -1) || (entry.RC != -22) {
+		t.Fatalf("ParseQueryLog() failed: entry = %+v", entry)
+	}
+	if _, err := ParseQueryLog(strings.NewReader("invalid\n")); err == nil {
+		t.Fatalf("ParseQueryLog() succeeded for an invalid log")
+	}
 }
 
-func TestDBCreateTableWithFloatRefValue(t *testing.T) {
-	testDBCreateTableWithRefValue(t, "Float")
-}
+func TestDBEnableQueryLog(t *testing.T) {
+	dirPath, _, db, table := createTempTable(t, "Table", nil)
+	defer removeTempDB(t, dirPath, db)
 
-func TestDBCreateTableWithTokyoGeoPointRefValue(t *testing.T) {
-	testDBCreateTableWithRefValue(t, "TokyoGeoPoint")
+	path := dirPath + "/query.log"
+	if err := db.EnableQueryLog(path, AllQueryLog); err != nil {
+		t.Fatalf("DB.EnableQueryLog() failed: %v", err)
+	}
+	defer db.EnableQueryLog(path, NoQueryLog)
+	if _, _, err := table.InsertRow(nil); err != nil {
+		t.Fatalf("Table.InsertRow() failed: %v", err)
+	}
+	if _, err := table.Select(nil); err != nil {
+		t.Fatalf("Table.Select() failed: %v", err)
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("os.Open() failed: %v", err)
+	}
+	defer file.Close()
+	entries, err := ParseQueryLog(file)
+	if err != nil {
+		t.Fatalf("ParseQueryLog() failed: %v", err)
+	}
+	found := false
+	for _, entry := range entries {
+		if strings.HasPrefix(entry.Command, "select") && (entry.NHits == 1) {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatalf("ParseQueryLog() failed: entries = %+v", entries)
+	}
+	if err := db.EnableQueryLog("", AllQueryLog); err == nil {
+		t.Fatalf("DB.EnableQueryLog() succeeded for an empty path")
+	}
 }
 
-func TestDBCreateTableWithWGS84GeoPointRefValue(t *testing.T) {
-	testDBCreateTableWithRefValue(t, "WGS84GeoPoint")
-}
+func TestDBRecvLargeResult(t *testing.T) {
+	dirPath, _, db, table := createTempTable(t, "Table", nil)
+	defer removeTempDB(t, dirPath, db)
 
-func TestDBCreateTableWithTextRefValue(t *testing.T) {
-	testDBCreateTableWithRefValue(t, "ShortText")
-}
-
-func generateRandomKey(keyType string) interface{} {
-	switch keyType {
-	case "Bool":
-	  return (rand.Int() & 1) == 1
-	case "Int8":
-		return int64(int8(rand.Int()))
-	case "Int16":
-		return int64(int16(rand.Int()))
-	case "Int32":
-		return int64(int32(rand.Int()))
-	case "Int64":
-		return rand.Int63()
-	case "UInt8":
-		return int64(uint8(rand.Int()))
-	case "UInt16":
-		return int64(uint16(rand.Int()))
-	case "UInt32":
-		return int64(rand.Uint32())
-	case "UInt64":
-		return rand.Int63()
-	case "Float":
-		return rand.Float64()
-	case "TokyoGeoPoint", "WGS84GeoPoint":
-		const (
-			MinLatitude  = 73531000
-			MaxLatitude  = 164006000
-			MinLongitude = 439451000
-			MaxLongitude = 554351000
-		)
-		latitude := MinLatitude + rand.Intn(MaxLatitude-MinLatitude+1)
-		longitude := MinLongitude + rand.Intn(MaxLongitude-MinLongitude+1)
-		return GeoPoint{int32(latitude), int32(longitude)}
-	case "ShortText":
-		return []byte(strconv.Itoa(rand.Int()))
-	default:
-		return nil
-	}
-}
-
-func testTableInsertRow(t *testing.T, keyType string) {
-	options := NewTableOptions()
-	if keyType != "" {
-		options.TableType = PatTable
+	column, err := table.CreateColumn("Value", "LongText", nil)
+	if err != nil {
+		t.Fatalf("Table.CreateColumn() failed: %v", err)
 	}
-	options.KeyType = keyType
-	dirPath, _, db, table := createTempTable(t, "Table", options)
-	defer removeTempDB(t, dirPath, db)
-
-	count := 0
-	for i := 0; i < 100; i++ {
-		inserted, _, err := table.InsertRow(generateRandomKey(keyType))
+	const numRows = 3000
+	value := []byte(strings.Repeat("0123456789", 100))
+	for i := 0; i < numRows; i++ {
+		_, id, err := table.InsertRow(nil)
 		if err != nil {
 			t.Fatalf("Table.InsertRow() failed: %v", err)
 		}
-		if inserted {
-			count++
+		if err := column.SetValue(id, value); err != nil {
+			t.Fatalf("Column.SetValue() failed: %v", err)
 		}
 	}
-	t.Logf("keyType = <%s>, count = %d", keyType, count)
+	result, err := db.QueryEx("select", map[string]string{
+		"table": "Table", "limit": "-1", "output_columns": "_id,Value"})
+	if err != nil {
+		t.Fatalf("DB.QueryEx() failed: %v", err)
+	}
+	var blocks [][][]interface{}
+	if err := json.Unmarshal(result, &blocks); err != nil {
+		t.Fatalf("json.Unmarshal() failed: %v", err)
+	}
+	// The first block consists of the number of hits, column info, and rows.
+	if (len(blocks) == 0) || (len(blocks[0]) != numRows+2) {
+		t.Fatalf("DB.Recv() returned an incomplete result: len = %d", len(result))
+	}
 }
 
-func TestTableInsertRowWithoutKey(t *testing.T) {
-	testTableInsertRow(t, "")
-}
+func TestDBDump(t *testing.T) {
+	options := NewTableOptions()
+	options.TableType = HashTable
+	options.KeyType = "ShortText"
+	dirPath, _, db, table := createTempTable(t, "Table", options)
+	defer removeTempDB(t, dirPath, db)
 
-func TestTableInsertRowWithBoolKey(t *testing.T) {
-	testTableInsertRow(t, "Bool")
-}
+	if _, err := db.CreateTable("Other", nil); err != nil {
+		t.Fatalf("DB.CreateTable() failed: %v", err)
+	}
+	if _, _, err := table.InsertRow([]byte("Key")); err != nil {
+		t.Fatalf("Table.InsertRow() failed: %v", err)
+	}
 
-func TestTableInsertRowWithInt8Key(t *testing.T) {
-	testTableInsertRow(t, "Int8")
-}
+	var buf bytes.Buffer
+	dumpOptions := NewDumpOptions()
+	dumpOptions.Tables = []string{"Table"}
+	if err := db.Dump(&buf, dumpOptions); err != nil {
+		t.Fatalf("DB.Dump() failed: %v", err)
+	}
+	dump := buf.String()
+	if !strings.Contains(dump, "table_create Table") ||
+		!strings.Contains(dump, "load --table Table") ||
+		!strings.Contains(dump, "\"Key\"") {
+		t.Fatalf("DB.Dump() failed: dump = %s", dump)
+	}
 
-func TestTableInsertRowWithInt16Key(t *testing.T) {
-	testTableInsertRow(t, "Int16")
-}
+	buf.Reset()
+	dumpOptions.SchemaOnly = true
+	if err := db.Dump(&buf, dumpOptions); err != nil {
+		t.Fatalf("DB.Dump() failed: %v", err)
+	}
+	dump = buf.String()
+	if !strings.Contains(dump, "table_create Table") ||
+		strings.Contains(dump, "load --table") {
+		t.Fatalf("DB.Dump() failed: dump = %s", dump)
+	}
 
-func TestTableInsertRowWithInt32Key(t *testing.T) {
-	testTableInsertRow(t, "Int32")
-}
+	// Chunks are written as they are received.
+	writer := &recordingWriter{}
+	if err := db.Dump(writer, nil); err != nil {
+		t.Fatalf("DB.Dump() failed: %v", err)
+	}
+	if (len(writer.sizes) == 0) ||
+		!strings.Contains(writer.buf.String(), "table_create Table") {
+		t.Fatalf("DB.Dump() failed: sizes = %v, dump = %s",
+			writer.sizes, writer.buf.String())
+	}
 
-func TestTableInsertRowWithInt64Key(t *testing.T) {
-	testTableInsertRow(t, "Int64")
+	// A failed write stops the dump and the rest of the result is discarded.
+	writer = &recordingWriter{fail: true}
+	if err := db.Dump(writer, nil); err == nil {
+		t.Fatalf("DB.Dump() succeeded despite a write error")
+	}
+	if len(writer.sizes) != 1 {
+		t.Fatalf("DB.Dump() continued after a write error: sizes = %v",
+			writer.sizes)
+	}
+	if result, err := db.Query("status"); err != nil {
+		t.Fatalf("DB.Query() failed: %v", err)
+	} else if !strings.Contains(string(result), "uptime") {
+		t.Fatalf("DB.Query() returned a wrong result: %s", result)
+	}
 }
 
-func TestTableInsertRowWithUInt8Key(t *testing.T) {
-	testTableInsertRow(t, "UInt8")
+// recordingWriter is an io.Writer which records the size of each write.
+// If fail is true, every write fails after being recorded.
+type recordingWriter struct {
+	buf   bytes.Buffer
+	sizes []int
+	fail  bool
 }
 
-func TestTableInsertRowWithUInt16Key(t *testing.T) {
-	testTableInsertRow(t, "UInt16")
+func (w *recordingWriter) Write(p []byte) (int, error) {
+	w.sizes = append(w.sizes, len(p))
+	if w.fail {
+		return 0, errors.New("write failed")
+	}
+	return w.buf.Write(p)
 }
 
-func TestTableInsertRowWithUInt32Key(t *testing.T) {
-	testTableInsertRow(t, "UInt32")
+func TestParseDataType(t *testing.T) {
+	dataTypes := []DataType{Bool, Int8, Int16, Int32, Int64, UInt8,
+		UInt16, UInt32, UInt64, Float, Time, ShortText, Text, LongText,
+		TokyoGeoPoint, WGS84GeoPoint}
+	if Float32 >= 0 {
+		dataTypes = append(dataTypes, Float32)
+	}
+	for _, dataType := range dataTypes {
+		name := dataType.GroongaName()
+		if name == "" {
+			t.Fatalf("DataType.GroongaName() failed: dataType = %d", dataType)
+		}
+		if parsed, ok := ParseDataType(name); !ok || (parsed != dataType) {
+			t.Fatalf("ParseDataType() failed: name = %s, dataType = %v", name, parsed)
+		}
+	}
+	if name := DataType(12345).GroongaName(); name != "" {
+		t.Fatalf("DataType.GroongaName() failed: name = %s", name)
+	}
+	if _, ok := ParseDataType("NoSuchType"); ok {
+		t.Fatalf("ParseDataType() succeeded for an invalid name")
+	}
+	if _, ok := ParseDataType("Void"); ok {
+		t.Fatalf("ParseDataType() succeeded for Void")
+	}
 }
 
-func TestTableInsertRowWithUInt64Key(t *testing.T) {
-	testTableInsertRow(t, "UInt64")
-}
+func TestGroongaError(t *testing.T) {
+	dirPath, _, db, table := createTempTable(t, "Table", nil)
+	defer removeTempDB(t, dirPath, db)
 
-func TestTableInsertRowWithFloatKey(t *testing.T) {
-	testTableInsertRow(t, "Float")
-}
+	_, err := db.CreateTable("Table", nil)
+	if !IsAlreadyExists(err) || !errors.Is(err, ErrAlreadyExists) {
+		t.Fatalf("DB.CreateTable() failed: err = %v", err)
+	}
+	// The error reported by Groonga is kept.
+	var groongaError *GroongaError
+	if !errors.As(err, &groongaError) || (groongaError.Code() == 0) ||
+		(groongaError.Message == "") {
+		t.Fatalf("DB.CreateTable() failed: err = %#v", err)
+	}
+	if _, err := table.CreateColumn("Value", "Int32", nil); err != nil {
+		t.Fatalf("Table.CreateColumn() failed: %v", err)
+	}
+	_, err = table.CreateColumn("Value", "Int32", nil)
+	if !IsAlreadyExists(err) || !errors.As(err, &groongaError) {
+		t.Fatalf("Table.CreateColumn() failed: err = %v", err)
+	}
 
-func TestTableInsertRowWithTokyoGeoPointKey(t *testing.T) {
-	testTableInsertRow(t, "TokyoGeoPoint")
+	_, err = db.QueryEx("select", map[string]string{"table": "NoSuchTable"})
+	if !errors.As(err, &groongaError) {
+		t.Fatalf("DB.QueryEx() failed: err = %#v", err)
+	}
+	if groongaError.Code() == 0 || (groongaError.Message == "") {
+		t.Fatalf("DB.QueryEx() failed: err = %#v", groongaError)
+	}
+	if (groongaError.ErrFile == "") || (groongaError.ErrLine <= 0) ||
+		(groongaError.ErrFunc == "") {
+		t.Fatalf("DB.QueryEx() failed: err = %#v", groongaError)
+	}
+	if !strings.Contains(err.Error(), groongaError.ErrFile) {
+		t.Fatalf("GroongaError.Error() failed: err = %v", err)
+	}
+	if !IsInvalidArgument(err) || IsAlreadyExists(err) {
+		t.Fatalf("DB.QueryEx() failed: err = %v", err)
+	}
+	if !IsNotFound(table.RemoveRow(100)) {
+		t.Fatalf("IsNotFound() failed for ErrRowNotFound")
+	}
 }
 
-func TestTableInsertRowWithWGS84GeoPointKey(t *testing.T) {
-	testTableInsertRow(t, "WGS84GeoPoint")
+func TestConnectGQTPWithoutServer(t *testing.T) {
+	// Port 1 is reserved and no Groonga server is expected to listen on it.
+	if db, err := ConnectGQTP("127.0.0.1", 1); err == nil {
+		db.Close()
+		t.Fatalf("ConnectGQTP() succeeded without server")
+	}
 }
 
-func TestTableInsertRowWithTextKey(t *testing.T) {
-	testTableInsertRow(t, "ShortText")
+func testDBCreateTableWithKey(t *testing.T, keyType string) {
+	options := NewTableOptions()
+	options.TableType = PatTable
+	options.KeyType = keyType
+	dirPath, _, db, _ := createTempTable(t, "Table", options)
+	removeTempDB(t, dirPath, db)
 }
 
-func testTableCreateScalarColumn(t *testing.T, valueType string) {
-	dirPath, _, db, table, _ :=
-		createTempColumn(t, "Table", nil, "Value", valueType, nil)
-	defer removeTempDB(t, dirPath, db)
-
-	if column, err := table.FindColumn("_id"); err != nil {
-		t.Fatalf("Table.FindColumn() failed: %v", err)
-	} else {
-		t.Logf("_id: %+v", column)
-	}
-	if column, err := table.FindColumn("Value"); err != nil {
-		t.Fatalf("Table.FindColumn() failed: %v", err)
-	} else {
-		t.Logf("Value: %+v", column)
-	}
+func testDBCreateTableWithValue(t *testing.T, valueType string) {
+	options := NewTableOptions()
+	options.TableType = PatTable
+	options.ValueType = valueType
+	dirPath, _, db, _ := createTempTable(t, "Table", options)
+	removeTempDB(t, dirPath, db)
 }
 
-func testTableCreateVectorColumn(t *testing.T, valueType string) {
-	options := NewColumnOptions()
-	options.ColumnType = VectorColumn
-	dirPath, _, db, table, _ :=
-		createTempColumn(t, "Table", nil, "Value", valueType, options)
+func testDBCreateTableWithRefKey(t *testing.T, keyType string) {
+	options := NewTableOptions()
+	options.TableType = PatTable
+	options.KeyType = keyType
+	dirPath, _, db, _ := createTempTable(t, "To", options)
 	defer removeTempDB(t, dirPath, db)
 
-	if column, err := table.FindColumn("_id"); err != nil {
-		t.Fatalf("Table.FindColumn() failed: %v", err)
-	} else {
-		t.Logf("_id: %+v", column)
-	}
-	if column, err := table.FindColumn("Value"); err != nil {
-		t.Fatalf("Table.FindColumn() failed: %v", err)
-	} else {
-		t.Logf("Value: %+v", column)
+	options = NewTableOptions()
+	options.TableType = PatTable
+	options.KeyType = "To"
+	_, err := db.CreateTable("From", options)
+	if err != nil {
+		t.Fatalf("DB.CreateTable() failed: %v", err)
 	}
 }
 
-func testTableCreateScalarRefColumn(t *testing.T, keyType string) {
+func testDBCreateTableWithRefValue(t *testing.T, keyType string) {
 	options := NewTableOptions()
 	options.TableType = PatTable
 	options.KeyType = keyType
-	dirPath, _, db, table, _ :=
-		createTempColumn(t, "Table", options, "Value", "Table", nil)
+	dirPath, _, db, _ := createTempTable(t, "To", options)
 	defer removeTempDB(t, dirPath, db)
 
-	if column, err := table.FindColumn("Value"); err != nil {
-		t.Fatalf("Table.FindColumn() failed: %v", err)
-	} else {
-		t.Logf("Value: %+v", column)
-	}
-	if column, err := table.FindColumn("Value._id"); err != nil {
-		t.Fatalf("Table.FindColumn() failed: %v", err)
-	} else {
-		t.Logf("Value._id: %+v", column)
-	}
-	if column, err := table.FindColumn("Value._key"); err != nil {
-		t.Fatalf("Table.FindColumn() failed: %v", err)
-	} else {
-		t.Logf("Value._key: %+v", column)
+	options = NewTableOptions()
+	options.ValueType = ""
+	_, err := db.CreateTable("From", options)
+	if err != nil {
+		t.Fatalf("DB.CreateTable() failed: %v", err)
 	}
 }
 
-func testTableCreateVectorRefColumn(t *testing.T, keyType string) {
-	tableOptions := NewTableOptions()
-	tableOptions.TableType = PatTable
-	tableOptions.KeyType = keyType
-	columnOptions := NewColumnOptions()
-	columnOptions.ColumnType = VectorColumn
-	dirPath, _, db, table, _ :=
-		createTempColumn(t, "Table", tableOptions, "Value", "Table", columnOptions)
-	defer removeTempDB(t, dirPath, db)
+func TestDBCreateTableWithoutKeyValue(t *testing.T) {
+	dirPath, _, db, _ := createTempTable(t, "Table", nil)
+	removeTempDB(t, dirPath, db)
+}
 
-	if column, err := table.FindColumn("Value"); err != nil {
-		t.Fatalf("Table.FindColumn() failed: %v", err)
-	} else {
-		t.Logf("Value: %+v", column)
-	}
-	if column, err := table.FindColumn("Value._id"); err != nil {
-		t.Fatalf("Table.FindColumn() failed: %v", err)
-	} else {
-		t.Logf("Value._id: %+v", column)
-	}
-	if column, err := table.FindColumn("Value._key"); err != nil {
-		t.Fatalf("Table.FindColumn() failed: %v", err)
-	} else {
-		t.Logf("Value._key: %+v", column)
-	}
+func TestDBCreateTableWithBoolKey(t *testing.T) {
+	testDBCreateTableWithKey(t, "Bool")
 }
 
-func TestTableCreateColumnForBool(t *testing.T) {
-	testTableCreateScalarColumn(t, "Bool")
+func TestDBCreateTableWithIntKey(t *testing.T) {
+	testDBCreateTableWithKey(t, "Int64")
 }
 
-func TestTableCreateColumnForInt(t *testing.T) {
-	testTableCreateScalarColumn(t, "Int64")
+func TestDBCreateTableWithFloatKey(t *testing.T) {
+	testDBCreateTableWithKey(t, "Float")
 }
 
-func TestTableCreateColumnForFloat(t *testing.T) {
-	testTableCreateScalarColumn(t, "Float")
+func TestDBCreateTableWithTokyoGeoPointKey(t *testing.T) {
+	testDBCreateTableWithKey(t, "TokyoGeoPoint")
 }
 
-func TestTableCreateColumnForTokyoGeoPoint(t *testing.T) {
-	testTableCreateScalarColumn(t, "TokyoGeoPoint")
+func TestDBCreateTableWithWGS84GeoPointKey(t *testing.T) {
+	testDBCreateTableWithKey(t, "WGS84GeoPoint")
 }
 
-func TestTableCreateColumnForWGS84GeoPoint(t *testing.T) {
-	testTableCreateScalarColumn(t, "WGS84GeoPoint")
+func TestDBCreateTableWithTextKey(t *testing.T) {
+	testDBCreateTableWithKey(t, "ShortText")
 }
 
-func TestTableCreateColumnForText(t *testing.T) {
-	testTableCreateScalarColumn(t, "ShortText")
+func TestDBCreateTableWithBoolValue(t *testing.T) {
+	testDBCreateTableWithValue(t, "Bool")
 }
 
-func TestTableCreateColumnForBoolVector(t *testing.T) {
-	testTableCreateVectorColumn(t, "Bool")
+func TestDBCreateTableWithIntValue(t *testing.T) {
+	testDBCreateTableWithValue(t, "Int64")
 }
 
-func TestTableCreateColumnForIntVector(t *testing.T) {
-	testTableCreateVectorColumn(t, "Int64")
+func TestDBCreateTableWithFloatValue(t *testing.T) {
+	testDBCreateTableWithValue(t, "Float")
 }
 
-func TestTableCreateColumnForFloatVector(t *testing.T) {
-	testTableCreateVectorColumn(t, "Float")
+func TestDBCreateTableWithTokyoGeoPointValue(t *testing.T) {
+	testDBCreateTableWithValue(t, "TokyoGeoPoint")
 }
 
-func TestTableCreateColumnForTokyoGeoPointVector(t *testing.T) {
-	testTableCreateVectorColumn(t, "TokyoGeoPoint")
+func TestDBCreateTableWithWGS84GeoPointValue(t *testing.T) {
+	testDBCreateTableWithValue(t, "WGS84GeoPoint")
 }
 
-func TestTableCreateColumnForWGS84GeoPointVector(t *testing.T) {
-	testTableCreateVectorColumn(t, "WGS84GeoPoint")
+func TestDBCreateTableWithBoolRefKey(t *testing.T) {
+	testDBCreateTableWithRefKey(t, "Bool")
 }
 
-func TestTableCreateColumnForTextVector(t *testing.T) {
-	testTableCreateVectorColumn(t, "ShortText")
+func TestDBCreateTableWithIntRefKey(t *testing.T) {
+	testDBCreateTableWithRefKey(t, "Int64")
 }
 
-func TestTableCreateColumnForRefToBool(t *testing.T) {
-	testTableCreateScalarRefColumn(t, "Bool")
+func TestDBCreateTableWithFloatRefKey(t *testing.T) {
+	testDBCreateTableWithRefKey(t, "Float")
 }
 
-func TestTableCreateColumnForRefToInt(t *testing.T) {
-	testTableCreateScalarRefColumn(t, "Int64")
+func TestDBCreateTableWithTokyoGeoPointRefKey(t *testing.T) {
+	testDBCreateTableWithRefKey(t, "TokyoGeoPoint")
 }
 
-func TestTableCreateColumnForRefToFloat(t *testing.T) {
-	testTableCreateScalarRefColumn(t, "Float")
+func TestDBCreateTableWithWGS84GeoPointRefKey(t *testing.T) {
+	testDBCreateTableWithRefKey(t, "WGS84GeoPoint")
 }
 
-func TestTableCreateColumnForRefToTokyoGeoPoint(t *testing.T) {
-	testTableCreateScalarRefColumn(t, "TokyoGeoPoint")
+func TestDBCreateTableWithTextRefKey(t *testing.T) {
+	testDBCreateTableWithRefKey(t, "ShortText")
 }
 
-func TestTableCreateColumnForRefToWGS84GeoPoint(t *testing.T) {
-	testTableCreateScalarRefColumn(t, "WGS84GeoPoint")
+func TestDBCreateTableWithBoolRefValue(t *testing.T) {
+	testDBCreateTableWithRefValue(t, "Bool")
 }
 
-func TestTableCreateColumnForRefToText(t *testing.T) {
-	testTableCreateScalarRefColumn(t, "ShortText")
+func TestDBCreateTableWithIntRefValue(t *testing.T) {
+	testDBCreateTableWithRefValue(t, "Int64")
 }
 
-func TestTableCreateColumnForRefToBoolVector(t *testing.T) {
-	testTableCreateVectorRefColumn(t, "Bool")
+func TestDBCreateTableWithFloatRefValue(t *testing.T) {
+	testDBCreateTableWithRefValue(t, "Float")
 }
 
-func TestTableCreateColumnForRefToIntVector(t *testing.T) {
-	testTableCreateVectorRefColumn(t, "Int64")
+func TestDBCreateTableWithTokyoGeoPointRefValue(t *testing.T) {
+	testDBCreateTableWithRefValue(t, "TokyoGeoPoint")
 }
 
-func TestTableCreateColumnForRefToFloatVector(t *testing.T) {
-	testTableCreateVectorRefColumn(t, "Float")
+func TestDBCreateTableWithWGS84GeoPointRefValue(t *testing.T) {
+	testDBCreateTableWithRefValue(t, "WGS84GeoPoint")
 }
 
-func TestTableCreateColumnForRefToTokyoGeoPointVector(t *testing.T) {
-	testTableCreateVectorRefColumn(t, "TokyoGeoPoint")
+func TestDBCreateTableWithTextRefValue(t *testing.T) {
+	testDBCreateTableWithRefValue(t, "ShortText")
 }
 
-func TestTableCreateColumnForRefToWGS84GeoPointVector(t *testing.T) {
-	testTableCreateVectorRefColumn(t, "WGS84GeoPoint")
+func TestDBLock(t *testing.T) {
+	dirPath, dbPath, db, table := createTempTable(t, "Table", nil)
+	defer removeTempDB(t, dirPath, db)
+	db2, err := OpenDB(dbPath)
+	if err != nil {
+		t.Fatalf("OpenDB() failed: %v", err)
+	}
+	defer db2.Close()
+
+	if err := db.Lock(time.Second); err != nil {
+		t.Fatalf("DB.Lock() failed: %v", err)
+	}
+	if err := db2.Lock(10 * time.Millisecond); !errors.Is(err, ErrLockTimeout) {
+		t.Fatalf("DB.Lock() failed: err = %v", err)
+	}
+	if err := db.Unlock(); err != nil {
+		t.Fatalf("DB.Unlock() failed: %v", err)
+	}
+	if err := db2.Lock(0); err != nil {
+		t.Fatalf("DB.Lock() failed: %v", err)
+	}
+	if err := db2.Unlock(); err != nil {
+		t.Fatalf("DB.Unlock() failed: %v", err)
+	}
+
+	if err := table.Lock(0); err != nil {
+		t.Fatalf("Table.Lock() failed: %v", err)
+	}
+	if err := table.Lock(0); !errors.Is(err, ErrLockTimeout) {
+		t.Fatalf("Table.Lock() failed: err = %v", err)
+	}
+	if err := table.Unlock(); err != nil {
+		t.Fatalf("Table.Unlock() failed: %v", err)
+	}
 }
 
-func TestTableCreateColumnForRefToTextVector(t *testing.T) {
-	testTableCreateVectorRefColumn(t, "ShortText")
+func TestDBClearLock(t *testing.T) {
+	dirPath, _, db, table, _ :=
+		createTempColumn(t, "Table", nil, "Value", "Int32", nil)
+	defer removeTempDB(t, dirPath, db)
+
+	for _, target := range []string{"", "Table", "Table.Value"} {
+		if locked, err := db.IsLocked(target); err != nil {
+			t.Fatalf("DB.IsLocked() failed: %v", err)
+		} else if locked {
+			t.Fatalf("DB.IsLocked() failed: target = <%s>, locked = %v",
+				target, locked)
+		}
+	}
+	// Leave the lock of the table as if a writer crashed.
+	if err := table.Lock(0); err != nil {
+		t.Fatalf("Table.Lock() failed: %v", err)
+	}
+	if locked, err := db.IsLocked("Table"); err != nil {
+		t.Fatalf("DB.IsLocked() failed: %v", err)
+	} else if !locked {
+		t.Fatalf("DB.IsLocked() failed: locked = %v", locked)
+	}
+	if err := db.ClearLock(""); err != nil {
+		t.Fatalf("DB.ClearLock() failed: %v", err)
+	}
+	if locked, err := db.IsLocked("Table"); err != nil {
+		t.Fatalf("DB.IsLocked() failed: %v", err)
+	} else if locked {
+		t.Fatalf("DB.ClearLock() failed: locked = %v", locked)
+	}
+	if err := table.Lock(0); err != nil {
+		t.Fatalf("Table.Lock() failed: %v", err)
+	}
+	if err := db.ClearLock("Table"); err != nil {
+		t.Fatalf("DB.ClearLock() failed: %v", err)
+	}
+	if err := db.ClearLock("NoSuchTable"); err == nil {
+		t.Fatalf("DB.ClearLock() succeeded for a missing object")
+	}
+	if _, err := db.IsLocked("NoSuchTable"); err == nil {
+		t.Fatalf("DB.IsLocked() succeeded for a missing object")
+	}
 }
 
-func generateRandomValue(valueType string) interface{} {
-	switch valueType {
+func TestDBDefrag(t *testing.T) {
+	dirPath, _, db, table, column :=
+		createTempColumn(t, "Table", nil, "Value", "Text", nil)
+	defer removeTempDB(t, dirPath, db)
+
+	for i := 0; i < 100; i++ {
+		_, id, err := table.InsertRow(nil)
+		if err != nil {
+			t.Fatalf("Table.InsertRow() failed: %v", err)
+		}
+		for j := 0; j < 3; j++ {
+			value := []byte(strings.Repeat(strconv.Itoa(j), 100))
+			if err := column.SetValue(id, value); err != nil {
+				t.Fatalf("Column.SetValue() failed: %v", err)
+			}
+		}
+	}
+	if n, err := db.Defrag(nil); err != nil {
+		t.Fatalf("DB.Defrag() failed: %v", err)
+	} else if n < 0 {
+		t.Fatalf("DB.Defrag() failed: n = %d", n)
+	}
+	if _, err := table.Defrag(nil); err != nil {
+		t.Fatalf("Table.Defrag() failed: %v", err)
+	}
+	if _, err := column.Defrag(NewDefragOptions()); err != nil {
+		t.Fatalf("Column.Defrag() failed: %v", err)
+	}
+}
+
+func TestDBTableNames(t *testing.T) {
+	dirPath, _, db := createTempDB(t)
+	defer removeTempDB(t, dirPath, db)
+
+	if names, err := db.TableNames(); err != nil {
+		t.Fatalf("DB.TableNames() failed: %v", err)
+	} else if len(names) != 0 {
+		t.Fatalf("DB.TableNames() failed: names = %v", names)
+	}
+	for _, name := range []string{"B", "A", "C"} {
+		if _, err := db.CreateTable(name, nil); err != nil {
+			t.Fatalf("DB.CreateTable() failed: %v", err)
+		}
+	}
+	if names, err := db.TableNames(); err != nil {
+		t.Fatalf("DB.TableNames() failed: %v", err)
+	} else if !reflect.DeepEqual(names, []string{"A", "B", "C"}) {
+		t.Fatalf("DB.TableNames() failed: names = %v", names)
+	}
+}
+
+func TestDBRemoveTable(t *testing.T) {
+	options := NewTableOptions()
+	options.TableType = PatTable
+	options.KeyType = "ShortText"
+	dirPath, _, db, _ := createTempTable(t, "Terms", options)
+	defer removeTempDB(t, dirPath, db)
+
+	table, err := db.CreateTable("Table", nil)
+	if err != nil {
+		t.Fatalf("DB.CreateTable() failed: %v", err)
+	}
+	column, err := db.CreateColumn("Table", "Value", "ShortText", nil)
+	if err != nil {
+		t.Fatalf("DB.CreateColumn() failed: %v", err)
+	}
+	_, id, err := table.InsertRow(nil)
+	if err != nil {
+		t.Fatalf("Table.InsertRow() failed: %v", err)
+	}
+	indexOptions := NewColumnOptions()
+	indexOptions.ColumnType = IndexColumn
+	indexOptions.Source = "Value"
+	index, err := db.CreateColumn("Terms", "Index", "Table", indexOptions)
+	if err != nil {
+		t.Fatalf("DB.CreateColumn() failed: %v", err)
+	}
+	if err := db.RemoveTable("Table"); err != nil {
+		t.Fatalf("DB.RemoveTable() failed: %v", err)
+	}
+	if _, err := db.FindTable("Table"); err == nil {
+		t.Fatalf("DB.FindTable() succeeded for a removed table")
+	}
+	if _, err := db.FindColumn("Table", "Value"); err == nil {
+		t.Fatalf("DB.FindColumn() succeeded for a removed table")
+	}
+	if _, err := db.FindColumn("Terms", "Index"); err == nil {
+		t.Fatalf("DB.FindColumn() succeeded for a removed index")
+	}
+	if _, _, err := table.InsertRow(nil); err == nil {
+		t.Fatalf("Table.InsertRow() succeeded for a removed table")
+	}
+	if _, err := column.GetValue(id); err == nil {
+		t.Fatalf("Column.GetValue() succeeded for a removed column")
+	}
+	if _, err := index.IndexSources(); err == nil {
+		t.Fatalf("Column.IndexSources() succeeded for a removed index")
+	}
+	terms, err := db.FindTable("Terms")
+	if err != nil {
+		t.Fatalf("DB.FindTable() failed: %v", err)
+	}
+	if _, _, err := terms.InsertRow([]byte("Key")); err != nil {
+		t.Fatalf("Table.InsertRow() failed: %v", err)
+	}
+
+	tempOptions := NewTableOptions()
+	tempOptions.Temporary = true
+	temp, err := db.CreateTable("Temp", tempOptions)
+	if err != nil {
+		t.Fatalf("DB.CreateTable() failed: %v", err)
+	}
+	if err := db.RemoveTable("Temp"); err != nil {
+		t.Fatalf("DB.RemoveTable() failed: %v", err)
+	}
+	if _, _, err := temp.InsertRow(nil); err == nil {
+		t.Fatalf("Table.InsertRow() succeeded for a removed temporary table")
+	}
+	if err := db.removeTemporaryTable(temp); err == nil {
+		t.Fatalf("DB.removeTemporaryTable() succeeded for a removed table")
+	}
+	if err := db.RemoveTable("Temp"); err == nil {
+		t.Fatalf("DB.RemoveTable() succeeded for a removed temporary table")
+	}
+}
+
+func generateRandomKey(keyType string) interface{} {
+	switch keyType {
 	case "Bool":
-		return (rand.Int() & 1) == 1
+	  return (rand.Int() & 1) == 1
 	case "Int8":
 		return int64(int8(rand.Int()))
 	case "Int16":
@@ -549,6 +1272,8 @@ func generateRandomValue(valueType string) interface{} {
 		return rand.Int63()
 	case "Float":
 		return rand.Float64()
+	case "Time":
+		return time.Unix(rand.Int63n(1<<32), rand.Int63n(1000000)*1000)
 	case "TokyoGeoPoint", "WGS84GeoPoint":
 		const (
 			MinLatitude  = 73531000
@@ -566,391 +1291,4723 @@ func generateRandomValue(valueType string) interface{} {
 	}
 }
 
-func generateRandomVectorValue(valueType string) interface{} {
-	size := rand.Int() % 10
-	switch valueType {
-	case "Bool":
-		value := make([]bool, size)
-		for i := 0; i < size; i++ {
-			value[i] = (rand.Int() & 1) == 1
+func testTableInsertRow(t *testing.T, keyType string) {
+	options := NewTableOptions()
+	if keyType != "" {
+		options.TableType = PatTable
+	}
+	options.KeyType = keyType
+	dirPath, _, db, table := createTempTable(t, "Table", options)
+	defer removeTempDB(t, dirPath, db)
+
+	count := 0
+	for i := 0; i < 100; i++ {
+		inserted, _, err := table.InsertRow(generateRandomKey(keyType))
+		if err != nil {
+			t.Fatalf("Table.InsertRow() failed: %v", err)
 		}
-		return value
-	case "Int8":
-		value := make([]int64, size)
-		for i := 0; i < size; i++ {
-			value[i] = int64(int8(rand.Int()))
+		if inserted {
+			count++
 		}
-		return value
-	case "Int16":
-		value := make([]int64, size)
-		for i := 0; i < size; i++ {
-			value[i] = int64(int16(rand.Int()))
+	}
+	t.Logf("keyType = <%s>, count = %d", keyType, count)
+}
+
+func TestTableInsertRowWithoutKey(t *testing.T) {
+	testTableInsertRow(t, "")
+}
+
+func TestTableInsertRowWithBoolKey(t *testing.T) {
+	testTableInsertRow(t, "Bool")
+}
+
+func TestTableInsertRowWithInt8Key(t *testing.T) {
+	testTableInsertRow(t, "Int8")
+}
+
+func TestTableInsertRowWithInt16Key(t *testing.T) {
+	testTableInsertRow(t, "Int16")
+}
+
+func TestTableInsertRowWithInt32Key(t *testing.T) {
+	testTableInsertRow(t, "Int32")
+}
+
+func TestTableInsertRowWithInt64Key(t *testing.T) {
+	testTableInsertRow(t, "Int64")
+}
+
+func TestTableInsertRowWithUInt8Key(t *testing.T) {
+	testTableInsertRow(t, "UInt8")
+}
+
+func TestTableInsertRowWithUInt16Key(t *testing.T) {
+	testTableInsertRow(t, "UInt16")
+}
+
+func TestTableInsertRowWithUInt32Key(t *testing.T) {
+	testTableInsertRow(t, "UInt32")
+}
+
+func TestTableInsertRowWithUInt64Key(t *testing.T) {
+	testTableInsertRow(t, "UInt64")
+}
+
+func TestTableInsertRowWithFloatKey(t *testing.T) {
+	testTableInsertRow(t, "Float")
+}
+
+func TestTableInsertRowWithConvertedKey(t *testing.T) {
+	options := NewTableOptions()
+	options.TableType = PatTable
+	options.KeyType = "Int8"
+	dirPath, _, db, table := createTempTable(t, "Table", options)
+	defer removeTempDB(t, dirPath, db)
+
+	for _, key := range []interface{}{42, int8(-5), uint16(100), uint32(7)} {
+		if _, _, err := table.InsertRow(key); err != nil {
+			t.Fatalf("Table.InsertRow() failed: key = %v, err = %v", key, err)
 		}
-		return value
-	case "Int32":
+	}
+	if id, ok, err := table.GetIDByKey(int64(42)); err != nil || !ok || id != 1 {
+		t.Fatalf("Table.GetIDByKey() failed: id = %d, ok = %v, err = %v", id, ok, err)
+	}
+	for _, key := range []interface{}{200, int64(-129), uint64(1 << 63)} {
+		if _, _, err := table.InsertRow(key); err == nil {
+			t.Fatalf("Table.InsertRow() succeeded for an out-of-range key: key = %v", key)
+		}
+	}
+
+	options.KeyType = "Float"
+	floatTable, err := db.CreateTable("FloatTable", options)
+	if err != nil {
+		t.Fatalf("DB.CreateTable() failed: %v", err)
+	}
+	if _, _, err := floatTable.InsertRow(float32(1.5)); err != nil {
+		t.Fatalf("Table.InsertRow() failed: %v", err)
+	}
+	if _, ok, err := floatTable.GetIDByKey(1.5); err != nil || !ok {
+		t.Fatalf("Table.GetIDByKey() failed: ok = %v, err = %v", ok, err)
+	}
+}
+
+func TestColumnSetWeightedTextVector(t *testing.T) {
+	options := NewColumnOptions()
+	options.ColumnType = VectorColumn
+	options.WithWeight = true
+	dirPath, _, db, table, column :=
+		createTempColumn(t, "Table", nil, "Value", "ShortText", options)
+	defer removeTempDB(t, dirPath, db)
+
+	_, id, err := table.InsertRow(nil)
+	if err != nil {
+		t.Fatalf("Table.InsertRow() failed: %v", err)
+	}
+	values := [][]byte{[]byte("Apple"), []byte("Banana")}
+	if err := column.SetWeightedTextVector(id, values, []uint32{1}); err == nil {
+		t.Fatalf("Column.SetWeightedTextVector() succeeded for a length mismatch")
+	}
+	if err := column.SetWeightedTextVector(id, values, []uint32{1, 2}); err != nil {
+		t.Fatalf("Column.SetWeightedTextVector() failed: %v", err)
+	}
+	storedValue, err := column.GetValue(id)
+	if err != nil {
+		t.Fatalf("Column.GetValue() failed: %v", err)
+	}
+	if !reflect.DeepEqual(storedValue, values) {
+		t.Fatalf("Column.GetValue() failed: value = %v", storedValue)
+	}
+	result, err := db.Query("select Table --output_columns Value")
+	if err != nil {
+		t.Fatalf("DB.Query() failed: %v", err)
+	}
+	if !strings.Contains(string(result), `"Banana":2`) {
+		t.Fatalf("DB.Query() returned a wrong weight: result = %s", result)
+	}
+}
+
+func TestColumnGetWeightedTextVector(t *testing.T) {
+	options := NewColumnOptions()
+	options.ColumnType = VectorColumn
+	options.WithWeight = true
+	dirPath, _, db, table, column :=
+		createTempColumn(t, "Table", nil, "Value", "ShortText", options)
+	defer removeTempDB(t, dirPath, db)
+
+	_, id, err := table.InsertRow(nil)
+	if err != nil {
+		t.Fatalf("Table.InsertRow() failed: %v", err)
+	}
+	values := [][]byte{[]byte("Apple"), []byte(""), []byte("Cherry")}
+	weights := []uint32{10, 0, 30}
+	if err := column.SetWeightedTextVector(id, values, weights); err != nil {
+		t.Fatalf("Column.SetWeightedTextVector() failed: %v", err)
+	}
+	storedValues, storedWeights, err := column.GetWeightedTextVector(id)
+	if err != nil {
+		t.Fatalf("Column.GetWeightedTextVector() failed: %v", err)
+	}
+	if !reflect.DeepEqual(storedValues, [][]byte{[]byte("Apple"), nil,
+		[]byte("Cherry")}) {
+		t.Fatalf("Column.GetWeightedTextVector() failed: values = %q",
+			storedValues)
+	}
+	if !reflect.DeepEqual(storedWeights, weights) {
+		t.Fatalf("Column.GetWeightedTextVector() failed: weights = %v",
+			storedWeights)
+	}
+
+	columnOptions := NewColumnOptions()
+	columnOptions.ColumnType = VectorColumn
+	other, err := table.CreateColumn("Other", "ShortText", columnOptions)
+	if err != nil {
+		t.Fatalf("Table.CreateColumn() failed: %v", err)
+	}
+	if _, _, err := other.GetWeightedTextVector(id); err == nil {
+		t.Fatalf("Column.GetWeightedTextVector() succeeded without WITH_WEIGHT")
+	}
+}
+
+func TestColumnSetWeightedVectorWithoutWeight(t *testing.T) {
+	options := NewColumnOptions()
+	options.ColumnType = VectorColumn
+	dirPath, _, db, table, column :=
+		createTempColumn(t, "Table", nil, "Value", "Int32", options)
+	defer removeTempDB(t, dirPath, db)
+
+	_, id, err := table.InsertRow(nil)
+	if err != nil {
+		t.Fatalf("Table.InsertRow() failed: %v", err)
+	}
+	if err := column.SetWeightedIntVector(id, []int64{1}, []uint32{1}); err == nil {
+		t.Fatalf("Column.SetWeightedIntVector() succeeded without WITH_WEIGHT")
+	}
+}
+
+func TestColumnSetWeightedNumberVector(t *testing.T) {
+	dirPath, _, db, table := createTempTable(t, "Table", nil)
+	defer removeTempDB(t, dirPath, db)
+
+	_, id, err := table.InsertRow(nil)
+	if err != nil {
+		t.Fatalf("Table.InsertRow() failed: %v", err)
+	}
+	options := NewColumnOptions()
+	options.ColumnType = VectorColumn
+	options.WithWeight = true
+	values := []int64{1, 100, 3}
+	weights := []uint32{10, 20, 30}
+	for _, valueType := range []string{"Int8", "Int16", "Int32", "Int64",
+		"UInt8", "UInt16", "UInt32", "UInt64"} {
+		column, err := table.CreateColumn(valueType, valueType, options)
+		if err != nil {
+			t.Fatalf("Table.CreateColumn() failed: %v", err)
+		}
+		if err := column.SetWeightedIntVector(id, values, weights); err != nil {
+			t.Fatalf("Column.SetWeightedIntVector() failed: valueType = %s, err = %v",
+				valueType, err)
+		}
+		if value, err := column.GetValue(id); err != nil {
+			t.Fatalf("Column.GetValue() failed: %v", err)
+		} else if !reflect.DeepEqual(value, values) {
+			t.Fatalf("Column.GetValue() failed: valueType = %s, value = %v",
+				valueType, value)
+		}
+	}
+	column, _ := table.FindColumn("Int8")
+	if err := column.SetWeightedIntVector(id, []int64{128}, []uint32{1}); err == nil {
+		t.Fatalf("Column.SetWeightedIntVector() succeeded for an out-of-range value")
+	}
+
+	column, err = table.CreateColumn("Float", "Float", options)
+	if err != nil {
+		t.Fatalf("Table.CreateColumn() failed: %v", err)
+	}
+	floats := []float64{1.5, -2.25, 0}
+	if err := column.SetWeightedFloatVector(id, floats, weights); err != nil {
+		t.Fatalf("Column.SetWeightedFloatVector() failed: %v", err)
+	}
+	if value, err := column.GetValue(id); err != nil {
+		t.Fatalf("Column.GetValue() failed: %v", err)
+	} else if !reflect.DeepEqual(value, floats) {
+		t.Fatalf("Column.GetValue() failed: value = %v", value)
+	}
+	if err := column.SetWeightedFloatVector(id, floats, weights[:1]); err == nil {
+		t.Fatalf("Column.SetWeightedFloatVector() succeeded for a length mismatch")
+	}
+}
+
+func TestGetTyped(t *testing.T) {
+	dirPath, _, db, table, column :=
+		createTempColumn(t, "Table", nil, "Value", "Int32", nil)
+	defer removeTempDB(t, dirPath, db)
+
+	_, id, err := table.InsertRow(nil)
+	if err != nil {
+		t.Fatalf("Table.InsertRow() failed: %v", err)
+	}
+	if err := column.SetValue(id, int64(123)); err != nil {
+		t.Fatalf("Column.SetValue() failed: %v", err)
+	}
+	if value, err := GetTyped[int64](column, id); err != nil {
+		t.Fatalf("GetTyped() failed: %v", err)
+	} else if value != 123 {
+		t.Fatalf("GetTyped() failed: value = %d", value)
+	}
+	if value, err := GetTyped[[]byte](column, id); err == nil {
+		t.Fatalf("GetTyped() succeeded for a wrong type: value = %v", value)
+	} else if !strings.Contains(err.Error(), "int64") {
+		t.Fatalf("GetTyped() returned a wrong error: %v", err)
+	}
+	if _, err := GetTyped[int64](column, id+1); !errors.Is(err, ErrRowNotFound) {
+		t.Fatalf("GetTyped() returned a wrong error: %v", err)
+	}
+}
+
+func TestColumnAppendText(t *testing.T) {
+	options := NewColumnOptions()
+	options.ColumnType = VectorColumn
+	dirPath, _, db, table, column :=
+		createTempColumn(t, "Table", nil, "Tags", "ShortText", options)
+	defer removeTempDB(t, dirPath, db)
+
+	_, id, err := table.InsertRow(nil)
+	if err != nil {
+		t.Fatalf("Table.InsertRow() failed: %v", err)
+	}
+	tags := [][]byte{[]byte("Apple"), []byte("Banana"), []byte("Cherry")}
+	for _, tag := range tags {
+		if err := column.AppendText(id, tag); err != nil {
+			t.Fatalf("Column.AppendText() failed: %v", err)
+		}
+	}
+	if value, err := column.GetValue(id); err != nil {
+		t.Fatalf("Column.GetValue() failed: %v", err)
+	} else if !reflect.DeepEqual(value, tags) {
+		t.Fatalf("Column.GetValue() failed: value = %q", value)
+	}
+	if err := column.AppendInt(id, 1); err == nil {
+		t.Fatalf("Column.AppendInt() succeeded for a Text vector")
+	}
+}
+
+func TestColumnUpdate(t *testing.T) {
+	dirPath, _, db, table, column :=
+		createTempColumn(t, "Table", nil, "Hits", "Int32", nil)
+	defer removeTempDB(t, dirPath, db)
+	text, err := table.CreateColumn("Text", "Text", nil)
+	if err != nil {
+		t.Fatalf("Table.CreateColumn() failed: %v", err)
+	}
+	vectorOptions := NewColumnOptions()
+	vectorOptions.ColumnType = VectorColumn
+	values, err := table.CreateColumn("Values", "Int16", vectorOptions)
+	if err != nil {
+		t.Fatalf("Table.CreateColumn() failed: %v", err)
+	}
+
+	_, id, err := table.InsertRow(nil)
+	if err != nil {
+		t.Fatalf("Table.InsertRow() failed: %v", err)
+	}
+	for i := 0; i < 3; i++ {
+		if err := column.Update(id, 1, IncrUpdate); err != nil {
+			t.Fatalf("Column.Update() failed: %v", err)
+		}
+	}
+	if err := column.Update(id, 2, DecrUpdate); err != nil {
+		t.Fatalf("Column.Update() failed: %v", err)
+	}
+	if value, err := column.GetValue(id); err != nil {
+		t.Fatalf("Column.GetValue() failed: %v", err)
+	} else if value != int64(1) {
+		t.Fatalf("Column.GetValue() failed: value = %v", value)
+	}
+
+	if err := text.Update(id, []byte("Groonga"), SetUpdate); err != nil {
+		t.Fatalf("Column.Update() failed: %v", err)
+	}
+	if err := text.Update(id, []byte("!"), AppendUpdate); err != nil {
+		t.Fatalf("Column.Update() failed: %v", err)
+	}
+	if err := text.Update(id, []byte("Hello, "), PrependUpdate); err != nil {
+		t.Fatalf("Column.Update() failed: %v", err)
+	}
+	if value, err := text.GetValue(id); err != nil {
+		t.Fatalf("Column.GetValue() failed: %v", err)
+	} else if string(value.([]byte)) != "Hello, Groonga!" {
+		t.Fatalf("Column.GetValue() failed: value = %s", value)
+	}
+
+	if err := values.Update(id, []int64{1, 2}, AppendUpdate); err != nil {
+		t.Fatalf("Column.Update() failed: %v", err)
+	}
+	if err := values.Update(id, 3, AppendUpdate); err != nil {
+		t.Fatalf("Column.Update() failed: %v", err)
+	}
+	if value, err := values.GetValue(id); err != nil {
+		t.Fatalf("Column.GetValue() failed: %v", err)
+	} else if !reflect.DeepEqual(value, []int64{1, 2, 3}) {
+		t.Fatalf("Column.GetValue() failed: value = %v", value)
+	}
+
+	if err := column.Update(id, 1, AppendUpdate); err == nil {
+		t.Fatalf("Column.Update() succeeded to append to an Int32 scalar")
+	}
+	if err := text.Update(id, []byte("x"), IncrUpdate); err == nil {
+		t.Fatalf("Column.Update() succeeded to increment a Text value")
+	}
+	if err := values.Update(id, 1, IncrUpdate); err == nil {
+		t.Fatalf("Column.Update() succeeded to increment a vector")
+	}
+	if err := column.Update(id+1, 1, IncrUpdate); !errors.Is(err, ErrRowNotFound) {
+		t.Fatalf("Column.Update() failed: err = %v", err)
+	}
+}
+
+func TestColumnAppendInt(t *testing.T) {
+	options := NewColumnOptions()
+	options.ColumnType = VectorColumn
+	dirPath, _, db, table, column :=
+		createTempColumn(t, "Table", nil, "Values", "Int16", options)
+	defer removeTempDB(t, dirPath, db)
+
+	_, id, err := table.InsertRow(nil)
+	if err != nil {
+		t.Fatalf("Table.InsertRow() failed: %v", err)
+	}
+	if err := column.SetValue(id, []int64{1, 2}); err != nil {
+		t.Fatalf("Column.SetValue() failed: %v", err)
+	}
+	if err := column.AppendInt(id, -3); err != nil {
+		t.Fatalf("Column.AppendInt() failed: %v", err)
+	}
+	if value, err := column.GetValue(id); err != nil {
+		t.Fatalf("Column.GetValue() failed: %v", err)
+	} else if !reflect.DeepEqual(value, []int64{1, 2, -3}) {
+		t.Fatalf("Column.GetValue() failed: value = %v", value)
+	}
+
+	scalar, err := table.CreateColumn("Scalar", "Float", nil)
+	if err != nil {
+		t.Fatalf("Table.CreateColumn() failed: %v", err)
+	}
+	if err := scalar.AppendFloat(id, 1.5); err == nil {
+		t.Fatalf("Column.AppendFloat() succeeded for a scalar column")
+	}
+}
+
+func TestColumnTextStream(t *testing.T) {
+	dirPath, _, db, table, column :=
+		createTempColumn(t, "Table", nil, "Value", "LongText", nil)
+	defer removeTempDB(t, dirPath, db)
+
+	_, id, err := table.InsertRow(nil)
+	if err != nil {
+		t.Fatalf("Table.InsertRow() failed: %v", err)
+	}
+	value := bytes.Repeat([]byte("0123456789"), 20000)
+	if err := column.SetTextFrom(id, bytes.NewReader(value)); err != nil {
+		t.Fatalf("Column.SetTextFrom() failed: %v", err)
+	}
+	var buf bytes.Buffer
+	n, err := column.GetTextTo(id, &buf)
+	if err != nil {
+		t.Fatalf("Column.GetTextTo() failed: %v", err)
+	}
+	if (n != int64(len(value))) || !bytes.Equal(buf.Bytes(), value) {
+		t.Fatalf("Column.GetTextTo() failed: n = %d", n)
+	}
+	if err := column.SetTextFrom(id, strings.NewReader("")); err != nil {
+		t.Fatalf("Column.SetTextFrom() failed: %v", err)
+	}
+	buf.Reset()
+	if n, err := column.GetTextTo(id, &buf); (err != nil) || (n != 0) {
+		t.Fatalf("Column.GetTextTo() failed: n = %d, err = %v", n, err)
+	}
+
+	// Concurrent calls never interleave.
+	values := [][]byte{bytes.Repeat([]byte("a"), 300000),
+		bytes.Repeat([]byte("b"), 300000)}
+	var wg sync.WaitGroup
+	errs := make([]error, len(values))
+	for i := range values {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			errs[i] = column.SetTextFrom(id, bytes.NewReader(values[i]))
+		}(i)
+	}
+	wg.Wait()
+	for _, err := range errs {
+		if err != nil {
+			t.Fatalf("Column.SetTextFrom() failed: %v", err)
+		}
+	}
+	// The value is written in bounded chunks.
+	writer := &recordingWriter{}
+	if n, err := column.GetTextTo(id, writer); err != nil {
+		t.Fatalf("Column.GetTextTo() failed: %v", err)
+	} else if n != int64(writer.buf.Len()) {
+		t.Fatalf("Column.GetTextTo() failed: n = %d, len = %d", n, writer.buf.Len())
+	}
+	if !bytes.Equal(writer.buf.Bytes(), values[0]) &&
+		!bytes.Equal(writer.buf.Bytes(), values[1]) {
+		t.Fatalf("Column.SetTextFrom() interleaved: len = %d", writer.buf.Len())
+	}
+	if len(writer.sizes) < 2 {
+		t.Fatalf("Column.GetTextTo() wrote at once: sizes = %v", writer.sizes)
+	}
+	for _, size := range writer.sizes {
+		if size > textChunkSize {
+			t.Fatalf("Column.GetTextTo() wrote too much: size = %d", size)
+		}
+	}
+	writer = &recordingWriter{fail: true}
+	if _, err := column.GetTextTo(id, writer); err == nil {
+		t.Fatalf("Column.GetTextTo() succeeded despite a write error")
+	}
+	if len(writer.sizes) != 1 {
+		t.Fatalf("Column.GetTextTo() continued after a write error: sizes = %v",
+			writer.sizes)
+	}
+}
+
+func TestColumnGetRefID(t *testing.T) {
+	options := NewTableOptions()
+	options.TableType = HashTable
+	options.KeyType = "ShortText"
+	dirPath, _, db, refTable := createTempTable(t, "Ref", options)
+	defer removeTempDB(t, dirPath, db)
+
+	table, err := db.CreateTable("Table", nil)
+	if err != nil {
+		t.Fatalf("DB.CreateTable() failed: %v", err)
+	}
+	scalarColumn, err := table.CreateColumn("Scalar", "Ref", nil)
+	if err != nil {
+		t.Fatalf("Table.CreateColumn() failed: %v", err)
+	}
+	columnOptions := NewColumnOptions()
+	columnOptions.ColumnType = VectorColumn
+	vectorColumn, err := table.CreateColumn("Vector", "Ref", columnOptions)
+	if err != nil {
+		t.Fatalf("Table.CreateColumn() failed: %v", err)
+	}
+	textColumn, err := table.CreateColumn("Text", "ShortText", nil)
+	if err != nil {
+		t.Fatalf("Table.CreateColumn() failed: %v", err)
+	}
+
+	_, refID1, _ := refTable.InsertRow([]byte("a"))
+	_, refID2, _ := refTable.InsertRow([]byte("b"))
+	_, id, _ := table.InsertRow(nil)
+	if err := scalarColumn.SetValue(id, []byte("b")); err != nil {
+		t.Fatalf("Column.SetValue() failed: %v", err)
+	}
+	if err := vectorColumn.SetValue(id, [][]byte{[]byte("b"), []byte("a")}); err != nil {
+		t.Fatalf("Column.SetValue() failed: %v", err)
+	}
+	if refID, err := scalarColumn.GetRefID(id); err != nil {
+		t.Fatalf("Column.GetRefID() failed: %v", err)
+	} else if refID != refID2 {
+		t.Fatalf("Column.GetRefID() failed: refID = %d, want = %d", refID, refID2)
+	}
+	if refIDs, err := vectorColumn.GetRefIDs(id); err != nil {
+		t.Fatalf("Column.GetRefIDs() failed: %v", err)
+	} else if !reflect.DeepEqual(refIDs, []uint32{refID2, refID1}) {
+		t.Fatalf("Column.GetRefIDs() failed: refIDs = %v", refIDs)
+	}
+	if _, err := textColumn.GetRefID(id); err == nil {
+		t.Fatalf("Column.GetRefID() succeeded for a non-reference column")
+	}
+	if _, err := scalarColumn.GetRefIDs(id); err == nil {
+		t.Fatalf("Column.GetRefIDs() succeeded for a scalar column")
+	}
+}
+
+func TestColumnSetValueForFloat32(t *testing.T) {
+	dirPath, _, db, table := createTempTable(t, "Table", nil)
+	defer removeTempDB(t, dirPath, db)
+
+	column, err := table.CreateColumn("Value", "Float32", nil)
+	if err != nil {
+		t.Skipf("Float32 is not supported: %v", err)
+	}
+	columnOptions := NewColumnOptions()
+	columnOptions.ColumnType = VectorColumn
+	vectorColumn, err := table.CreateColumn("Vector", "Float32", columnOptions)
+	if err != nil {
+		t.Fatalf("Table.CreateColumn() failed: %v", err)
+	}
+	_, id, _ := table.InsertRow(nil)
+	if err := column.SetValue(id, float32(1.25)); err != nil {
+		t.Fatalf("Column.SetValue() failed: %v", err)
+	}
+	if value, err := column.GetValue(id); err != nil {
+		t.Fatalf("Column.GetValue() failed: %v", err)
+	} else if value != float32(1.25) {
+		t.Fatalf("Column.GetValue() failed: value = %v", value)
+	}
+	vector := []float32{0.5, -2.75, 3}
+	if err := vectorColumn.SetValue(id, vector); err != nil {
+		t.Fatalf("Column.SetValue() failed: %v", err)
+	}
+	if value, err := vectorColumn.GetValue(id); err != nil {
+		t.Fatalf("Column.GetValue() failed: %v", err)
+	} else if !reflect.DeepEqual(value, vector) {
+		t.Fatalf("Column.GetValue() failed: value = %v", value)
+	}
+	if err := column.SetValue(id, float64(1.25)); err == nil {
+		t.Fatalf("Column.SetValue() succeeded for float64")
+	}
+}
+
+func TestColumnSetValues(t *testing.T) {
+	dirPath, _, db, table := createTempTable(t, "Table", nil)
+	defer removeTempDB(t, dirPath, db)
+
+	intColumn, err := table.CreateColumn("Int", "Int16", nil)
+	if err != nil {
+		t.Fatalf("Table.CreateColumn() failed: %v", err)
+	}
+	textColumn, err := table.CreateColumn("Text", "ShortText", nil)
+	if err != nil {
+		t.Fatalf("Table.CreateColumn() failed: %v", err)
+	}
+	var ids []uint32
+	var ints []int64
+	var texts [][]byte
+	for i := 0; i < 100; i++ {
+		_, id, err := table.InsertRow(nil)
+		if err != nil {
+			t.Fatalf("Table.InsertRow() failed: %v", err)
+		}
+		ids = append(ids, id)
+		ints = append(ints, int64(i*10))
+		texts = append(texts, []byte(strconv.Itoa(i)))
+	}
+	if err := intColumn.SetValues(ids, ints); err != nil {
+		t.Fatalf("Column.SetValues() failed: %v", err)
+	}
+	if err := textColumn.SetValues(ids, texts); err != nil {
+		t.Fatalf("Column.SetValues() failed: %v", err)
+	}
+	for i, id := range ids {
+		if value, err := intColumn.GetValue(id); err != nil {
+			t.Fatalf("Column.GetValue() failed: %v", err)
+		} else if value != ints[i] {
+			t.Fatalf("Column.GetValue() failed: value = %v, want = %v",
+				value, ints[i])
+		}
+		if value, err := textColumn.GetValue(id); err != nil {
+			t.Fatalf("Column.GetValue() failed: %v", err)
+		} else if !reflect.DeepEqual(value, texts[i]) {
+			t.Fatalf("Column.GetValue() failed: value = %s, want = %s",
+				value, texts[i])
+		}
+	}
+
+	if err := intColumn.SetValues(ids, []int64{1, 2, 3}); err == nil {
+		t.Fatalf("Column.SetValues() succeeded for a length mismatch")
+	}
+	if value, _ := intColumn.GetValue(ids[0]); value != ints[0] {
+		t.Fatalf("Column.SetValues() assigned values for a length mismatch")
+	}
+	if err := intColumn.SetValues(ids, texts); err == nil {
+		t.Fatalf("Column.SetValues() succeeded for a type conflict")
+	}
+}
+
+func TestColumnGetValues(t *testing.T) {
+	dirPath, _, db, table := createTempTable(t, "Table", nil)
+	defer removeTempDB(t, dirPath, db)
+
+	floatColumn, err := table.CreateColumn("Float", "Float", nil)
+	if err != nil {
+		t.Fatalf("Table.CreateColumn() failed: %v", err)
+	}
+	textColumn, err := table.CreateColumn("Text", "Text", nil)
+	if err != nil {
+		t.Fatalf("Table.CreateColumn() failed: %v", err)
+	}
+	var ids []uint32
+	var floats []float64
+	var texts [][]byte
+	for i := 0; i < 100; i++ {
+		_, id, err := table.InsertRow(nil)
+		if err != nil {
+			t.Fatalf("Table.InsertRow() failed: %v", err)
+		}
+		ids = append(ids, id)
+		floats = append(floats, float64(i)/4)
+		texts = append(texts, []byte(strings.Repeat("x", i)))
+	}
+	if err := floatColumn.SetValues(ids, floats); err != nil {
+		t.Fatalf("Column.SetValues() failed: %v", err)
+	}
+	if err := textColumn.SetValues(ids, texts); err != nil {
+		t.Fatalf("Column.SetValues() failed: %v", err)
+	}
+
+	ids = append(ids, 1000)
+	floats = append(floats, 0)
+	texts = append(texts, []byte{})
+	if values, err := floatColumn.GetValues(ids); err != nil {
+		t.Fatalf("Column.GetValues() failed: %v", err)
+	} else if !reflect.DeepEqual(values, floats) {
+		t.Fatalf("Column.GetValues() failed: values = %v", values)
+	}
+	if values, err := textColumn.GetValues(ids); err != nil {
+		t.Fatalf("Column.GetValues() failed: %v", err)
+	} else if !reflect.DeepEqual(values, texts) {
+		t.Fatalf("Column.GetValues() failed: values = %v", values)
+	}
+	if values, err := floatColumn.GetValues(nil); err != nil {
+		t.Fatalf("Column.GetValues() failed: %v", err)
+	} else if len(values.([]float64)) != 0 {
+		t.Fatalf("Column.GetValues() failed: values = %v", values)
+	}
+	if Float32 >= 0 {
+		float32Column, err := table.CreateColumn("Float32", "Float32", nil)
+		if err != nil {
+			t.Fatalf("Table.CreateColumn() failed: %v", err)
+		}
+		float32s := make([]float32, len(ids))
+		for i, id := range ids[:len(ids)-1] {
+			float32s[i] = float32(i) / 4
+			if err := float32Column.SetValue(id, float32s[i]); err != nil {
+				t.Fatalf("Column.SetValue() failed: %v", err)
+			}
+		}
+		if values, err := float32Column.GetValues(ids); err != nil {
+			t.Fatalf("Column.GetValues() failed: %v", err)
+		} else if !reflect.DeepEqual(values, float32s) {
+			t.Fatalf("Column.GetValues() failed: values = %v", values)
+		}
+		if !float32Column.canGetValues() {
+			t.Fatalf("Column.canGetValues() failed for Float32")
+		}
+	}
+
+	// Stored zeros are returned like GetValue(), even after an error.
+	timeColumn, err := table.CreateColumn("Time", "Time", nil)
+	if err != nil {
+		t.Fatalf("Table.CreateColumn() failed: %v", err)
+	}
+	if err := timeColumn.SetValue(ids[1], time.Unix(123, 456000)); err != nil {
+		t.Fatalf("Column.SetValue() failed: %v", err)
+	}
+	if _, err := db.Query("no_such_command"); err == nil {
+		t.Fatalf("DB.Query() succeeded for an unknown command")
+	}
+	values, err := timeColumn.GetValues(ids[:3])
+	if err != nil {
+		t.Fatalf("Column.GetValues() failed: %v", err)
+	}
+	for i, value := range values.([]time.Time) {
+		expected, err := timeColumn.GetValue(ids[i])
+		if err != nil {
+			t.Fatalf("Column.GetValue() failed: %v", err)
+		}
+		if !value.Equal(expected.(time.Time)) {
+			t.Fatalf("Column.GetValues() failed: i = %d, value = %v, expected = %v",
+				i, value, expected)
+		}
+	}
+}
+
+func TestColumnScanAll(t *testing.T) {
+	dirPath, _, db, table, column :=
+		createTempColumn(t, "Table", nil, "Value", "Int32", nil)
+	defer removeTempDB(t, dirPath, db)
+	vectorOptions := NewColumnOptions()
+	vectorOptions.ColumnType = VectorColumn
+	tags, err := table.CreateColumn("Tags", "ShortText", vectorOptions)
+	if err != nil {
+		t.Fatalf("Table.CreateColumn() failed: %v", err)
+	}
+
+	const n = columnCursorBatchSize*2 + 10
+	for i := 0; i < n; i++ {
+		_, id, err := table.InsertRow(nil)
+		if err != nil {
+			t.Fatalf("Table.InsertRow() failed: %v", err)
+		}
+		if err := column.SetValue(id, int64(i)); err != nil {
+			t.Fatalf("Column.SetValue() failed: %v", err)
+		}
+		if err := tags.SetValue(id, [][]byte{[]byte(strconv.Itoa(i))}); err != nil {
+			t.Fatalf("Column.SetValue() failed: %v", err)
+		}
+	}
+	if err := table.RemoveRow(2); err != nil {
+		t.Fatalf("Table.RemoveRow() failed: %v", err)
+	}
+
+	cursor, err := column.ScanAll()
+	if err != nil {
+		t.Fatalf("Column.ScanAll() failed: %v", err)
+	}
+	count := 0
+	sum := int64(0)
+	for cursor.Next() {
+		if cursor.ID() == 2 {
+			t.Fatalf("ColumnCursor.Next() returned a removed row")
+		}
+		value, ok := cursor.Value().(int64)
+		if !ok || (value != int64(cursor.ID()-1)) {
+			t.Fatalf("ColumnCursor.Value() failed: id = %d, value = %v",
+				cursor.ID(), cursor.Value())
+		}
+		count++
+		sum += value
+	}
+	if err := cursor.Err(); err != nil {
+		t.Fatalf("ColumnCursor.Err() = %v", err)
+	}
+	if (count != n-1) || (sum != int64(n*(n-1)/2-1)) {
+		t.Fatalf("Column.ScanAll() failed: count = %d, sum = %d", count, sum)
+	}
+	if err := cursor.Close(); err != nil {
+		t.Fatalf("ColumnCursor.Close() failed: %v", err)
+	}
+	if cursor.Next() {
+		t.Fatalf("ColumnCursor.Next() succeeded after Close()")
+	}
+
+	cursor, err = tags.ScanAll()
+	if err != nil {
+		t.Fatalf("Column.ScanAll() failed: %v", err)
+	}
+	defer cursor.Close()
+	if !cursor.Next() {
+		t.Fatalf("ColumnCursor.Next() failed: %v", cursor.Err())
+	}
+	if value := cursor.Value(); !reflect.DeepEqual(value, [][]byte{[]byte("0")}) {
+		t.Fatalf("ColumnCursor.Value() failed: value = %v", value)
+	}
+}
+
+func TestTableInsertRowWithTimeKey(t *testing.T) {
+	testTableInsertRow(t, "Time")
+}
+
+func TestTableInsertRowWithDuplicateTimeKey(t *testing.T) {
+	options := NewTableOptions()
+	options.TableType = HashTable
+	options.KeyType = "Time"
+	dirPath, _, db, table := createTempTable(t, "Table", options)
+	defer removeTempDB(t, dirPath, db)
+
+	key := time.Now()
+	inserted, id, err := table.InsertRow(key)
+	if err != nil {
+		t.Fatalf("Table.InsertRow() failed: %v", err)
+	} else if !inserted {
+		t.Fatalf("Table.InsertRow() failed: inserted = %v", inserted)
+	}
+	inserted, id2, err := table.InsertRow(key)
+	if err != nil {
+		t.Fatalf("Table.InsertRow() failed: %v", err)
+	} else if inserted || (id2 != id) {
+		t.Fatalf("Table.InsertRow() failed: inserted = %v, id = %d, id2 = %d",
+			inserted, id, id2)
+	}
+}
+
+func TestTableInsertRowWithTokyoGeoPointKey(t *testing.T) {
+	testTableInsertRow(t, "TokyoGeoPoint")
+}
+
+func TestTableInsertRowWithWGS84GeoPointKey(t *testing.T) {
+	testTableInsertRow(t, "WGS84GeoPoint")
+}
+
+func TestTableInsertRowWithTextKey(t *testing.T) {
+	testTableInsertRow(t, "ShortText")
+}
+
+func TestTableRemoveRow(t *testing.T) {
+	dirPath, _, db, table, column :=
+		createTempColumn(t, "Table", nil, "Value", "Int64", nil)
+	defer removeTempDB(t, dirPath, db)
+
+	_, id, err := table.InsertRow(nil)
+	if err != nil {
+		t.Fatalf("Table.InsertRow() failed: %v", err)
+	}
+	if err := column.SetValue(id, int64(123)); err != nil {
+		t.Fatalf("Column.SetValue() failed: %v", err)
+	}
+	if err := table.RemoveRow(id); err != nil {
+		t.Fatalf("Table.RemoveRow() failed: %v", err)
+	}
+	if err := table.RemoveRow(id); err == nil {
+		t.Fatalf("Table.RemoveRow() succeeded for a removed row")
+	}
+	if _, err := column.GetValue(id); err == nil {
+		t.Fatalf("Column.GetValue() succeeded for a removed row")
+	}
+}
+
+func testTableLen(t *testing.T, keyType string) {
+	options := NewTableOptions()
+	if keyType != "" {
+		options.TableType = HashTable
+	}
+	options.KeyType = keyType
+	dirPath, _, db, table := createTempTable(t, "Table", options)
+	defer removeTempDB(t, dirPath, db)
+
+	var ids []uint32
+	for i := 0; i < 100; i++ {
+		inserted, id, err := table.InsertRow(generateRandomKey(keyType))
+		if err != nil {
+			t.Fatalf("Table.InsertRow() failed: %v", err)
+		}
+		if inserted {
+			ids = append(ids, id)
+		}
+	}
+	if n, err := table.Len(); err != nil {
+		t.Fatalf("Table.Len() failed: %v", err)
+	} else if int(n) != len(ids) {
+		t.Fatalf("Table.Len() failed: n = %d, want = %d", n, len(ids))
+	}
+	if err := table.RemoveRow(ids[0]); err != nil {
+		t.Fatalf("Table.RemoveRow() failed: %v", err)
+	}
+	if n, err := table.Len(); err != nil {
+		t.Fatalf("Table.Len() failed: %v", err)
+	} else if int(n) != len(ids)-1 {
+		t.Fatalf("Table.Len() failed: n = %d, want = %d", n, len(ids)-1)
+	}
+}
+
+func TestTableLenWithoutKey(t *testing.T) {
+	testTableLen(t, "")
+}
+
+func TestTableLenWithIntKey(t *testing.T) {
+	testTableLen(t, "Int64")
+}
+
+func TestTableLenWithTextKey(t *testing.T) {
+	testTableLen(t, "ShortText")
+}
+
+func TestTableRemoveRowByKey(t *testing.T) {
+	options := NewTableOptions()
+	options.TableType = PatTable
+	options.KeyType = "ShortText"
+	dirPath, _, db, table := createTempTable(t, "Table", options)
+	defer removeTempDB(t, dirPath, db)
+
+	key := []byte("Key")
+	if _, _, err := table.InsertRow(key); err != nil {
+		t.Fatalf("Table.InsertRow() failed: %v", err)
+	}
+	if err := table.RemoveRowByKey(key); err != nil {
+		t.Fatalf("Table.RemoveRowByKey() failed: %v", err)
+	}
+	if err := table.RemoveRowByKey(key); !errors.Is(err, ErrRowNotFound) {
+		t.Fatalf("Table.RemoveRowByKey() failed: err = %v", err)
+	}
+	if err := table.RemoveRowByKey(int64(1)); err == nil {
+		t.Fatalf("Table.RemoveRowByKey() succeeded for a wrong key type")
+	}
+}
+
+func TestTableRemoveRowByKeyNumber(t *testing.T) {
+	for _, keyType := range []string{"Int8", "Int16", "Int32", "Int64",
+		"UInt8", "UInt16", "UInt32", "UInt64", "Float", "Time"} {
+		options := NewTableOptions()
+		options.TableType = PatTable
+		options.KeyType = keyType
+		dirPath, _, db, table := createTempTable(t, "Table", options)
+		key := generateRandomKey(keyType)
+		if _, _, err := table.InsertRow(key); err != nil {
+			t.Fatalf("Table.InsertRow() failed: keyType = %s, err = %v",
+				keyType, err)
+		}
+		if _, _, err := table.InsertRow(generateRandomKey(keyType)); err != nil {
+			t.Fatalf("Table.InsertRow() failed: keyType = %s, err = %v",
+				keyType, err)
+		}
+		if err := table.RemoveRowByKey(key); err != nil {
+			t.Fatalf("Table.RemoveRowByKey() failed: keyType = %s, err = %v",
+				keyType, err)
+		}
+		if _, found, _ := table.GetIDByKey(key); found {
+			t.Fatalf("Table.RemoveRowByKey() did not remove the row: keyType = %s",
+				keyType)
+		}
+		if err := table.RemoveRowByKey(key); !errors.Is(err, ErrRowNotFound) {
+			t.Fatalf("Table.RemoveRowByKey() failed: keyType = %s, err = %v",
+				keyType, err)
+		}
+		removeTempDB(t, dirPath, db)
+	}
+}
+
+func TestTableRemoveRowByKeyWithoutKey(t *testing.T) {
+	dirPath, _, db, table := createTempTable(t, "Table", nil)
+	defer removeTempDB(t, dirPath, db)
+
+	if err := table.RemoveRowByKey(nil); err == nil {
+		t.Fatalf("Table.RemoveRowByKey() succeeded for a table without key")
+	}
+}
+
+func TestTableAccessors(t *testing.T) {
+	options := NewTableOptions()
+	options.TableType = PatTable
+	options.KeyType = "ShortText"
+	dirPath, _, db, table := createTempTable(t, "Table", options)
+	defer removeTempDB(t, dirPath, db)
+
+	if (table.KeyType() != ShortText) || (table.ValueType() != Void) ||
+		(table.KeyReference() != nil) || (table.ValueReference() != nil) {
+		t.Fatalf("Table accessors failed: table = %+v", table)
+	}
+	options = NewTableOptions()
+	options.TableType = HashTable
+	options.KeyType = "Table"
+	options.ValueType = "Int16"
+	refTable, err := db.CreateTable("RefTable", options)
+	if err != nil {
+		t.Fatalf("DB.CreateTable() failed: %v", err)
+	}
+	if (refTable.KeyType() != ShortText) || (refTable.ValueType() != Int16) ||
+		(refTable.KeyReference() != table) || (refTable.ValueReference() != nil) {
+		t.Fatalf("Table accessors failed: table = %+v", refTable)
+	}
+}
+
+func TestTableInsertRowWithRefID(t *testing.T) {
+	options := NewTableOptions()
+	options.TableType = HashTable
+	options.KeyType = "ShortText"
+	dirPath, _, db, table := createTempTable(t, "Table", options)
+	defer removeTempDB(t, dirPath, db)
+
+	_, refID, err := table.InsertRow([]byte("Apple"))
+	if err != nil {
+		t.Fatalf("Table.InsertRow() failed: %v", err)
+	}
+	options = NewTableOptions()
+	options.TableType = HashTable
+	options.KeyType = "Table"
+	joinTable, err := db.CreateTable("Join", options)
+	if err != nil {
+		t.Fatalf("DB.CreateTable() failed: %v", err)
+	}
+	inserted, id, err := joinTable.InsertRow(refID)
+	if err != nil {
+		t.Fatalf("Table.InsertRow() failed: %v", err)
+	}
+	if !inserted {
+		t.Fatalf("Table.InsertRow() failed: inserted = %v", inserted)
+	}
+	if foundID, found, err := joinTable.GetIDByKey(refID); err != nil {
+		t.Fatalf("Table.GetIDByKey() failed: %v", err)
+	} else if !found || (foundID != id) {
+		t.Fatalf("Table.GetIDByKey() failed: id = %d, found = %v", foundID, found)
+	}
+	if inserted, _, err := joinTable.InsertRow(refID); err != nil || inserted {
+		t.Fatalf("Table.InsertRow() failed: inserted = %v, err = %v", inserted, err)
+	}
+	if _, _, err := joinTable.InsertRow(refID + 1); !errors.Is(err, ErrRowNotFound) {
+		t.Fatalf("Table.InsertRow() returned a wrong error: %v", err)
+	}
+	if _, _, err := table.InsertRow(refID); err == nil {
+		t.Fatalf("Table.InsertRow() succeeded for a non-reference key")
+	}
+}
+
+func TestTableInsertRows(t *testing.T) {
+	options := NewTableOptions()
+	options.TableType = PatTable
+	options.KeyType = "ShortText"
+	dirPath, _, db, table := createTempTable(t, "Table", options)
+	defer removeTempDB(t, dirPath, db)
+
+	_, id, err := table.InsertRow([]byte("Apple"))
+	if err != nil {
+		t.Fatalf("Table.InsertRow() failed: %v", err)
+	}
+	keys := [][]byte{[]byte("Banana"), []byte("Apple"), []byte("Cherry")}
+	ids, inserted, err := table.InsertRows(keys)
+	if err != nil {
+		t.Fatalf("Table.InsertRows() failed: %v", err)
+	}
+	if !reflect.DeepEqual(inserted, []bool{true, false, true}) {
+		t.Fatalf("Table.InsertRows() failed: inserted = %v", inserted)
+	}
+	if (len(ids) != len(keys)) || (ids[1] != id) {
+		t.Fatalf("Table.InsertRows() failed: ids = %v", ids)
+	}
+	for i, key := range keys {
+		if foundID, _, _ := table.GetIDByKey(key); foundID != ids[i] {
+			t.Fatalf("Table.GetIDByKey() failed: key = %s, id = %d", key, foundID)
+		}
+	}
+	ids, inserted, err = table.InsertRows([][]byte{})
+	if err != nil {
+		t.Fatalf("Table.InsertRows() failed: %v", err)
+	}
+	if (len(ids) != 0) || (len(inserted) != 0) {
+		t.Fatalf("Table.InsertRows() failed: ids = %v, inserted = %v", ids, inserted)
+	}
+	if _, _, err := table.InsertRows([]int64{1}); err == nil {
+		t.Fatalf("Table.InsertRows() succeeded for a wrong key type")
+	}
+}
+
+func TestTableInsertRowsForInt(t *testing.T) {
+	options := NewTableOptions()
+	options.TableType = HashTable
+	options.KeyType = "Int16"
+	dirPath, _, db, table := createTempTable(t, "Table", options)
+	defer removeTempDB(t, dirPath, db)
+
+	ids, inserted, err := table.InsertRows([]int64{-1, 2, -1})
+	if err != nil {
+		t.Fatalf("Table.InsertRows() failed: %v", err)
+	}
+	if !reflect.DeepEqual(inserted, []bool{true, true, false}) ||
+		(ids[0] != ids[2]) {
+		t.Fatalf("Table.InsertRows() failed: ids = %v, inserted = %v",
+			ids, inserted)
+	}
+	if n, _ := table.Len(); n != 2 {
+		t.Fatalf("Table.Len() failed: n = %d", n)
+	}
+}
+
+func TestTableGetIDByKey(t *testing.T) {
+	options := NewTableOptions()
+	options.TableType = HashTable
+	options.KeyType = "Int32"
+	dirPath, _, db, table := createTempTable(t, "Table", options)
+	defer removeTempDB(t, dirPath, db)
+
+	_, id, err := table.InsertRow(int64(123))
+	if err != nil {
+		t.Fatalf("Table.InsertRow() failed: %v", err)
+	}
+	if foundID, found, err := table.GetIDByKey(int64(123)); err != nil {
+		t.Fatalf("Table.GetIDByKey() failed: %v", err)
+	} else if !found || (foundID != id) {
+		t.Fatalf("Table.GetIDByKey() failed: id = %d, found = %v", foundID, found)
+	}
+	if foundID, found, err := table.GetIDByKey(int64(456)); err != nil {
+		t.Fatalf("Table.GetIDByKey() failed: %v", err)
+	} else if found || (foundID != NilID) {
+		t.Fatalf("Table.GetIDByKey() failed: id = %d, found = %v", foundID, found)
+	}
+	if _, _, err := table.GetIDByKey([]byte("123")); err == nil {
+		t.Fatalf("Table.GetIDByKey() succeeded for a wrong key type")
+	}
+}
+
+func TestTableGetIDByKeyWithoutKey(t *testing.T) {
+	dirPath, _, db, table := createTempTable(t, "Table", nil)
+	defer removeTempDB(t, dirPath, db)
+
+	if _, _, err := table.GetIDByKey(int64(1)); err == nil {
+		t.Fatalf("Table.GetIDByKey() succeeded for a table without key")
+	}
+}
+
+func TestTableTruncate(t *testing.T) {
+	dirPath, _, db, table, column :=
+		createTempColumn(t, "Table", nil, "Value", "Int64", nil)
+	defer removeTempDB(t, dirPath, db)
+
+	for i := 0; i < 100; i++ {
+		_, id, err := table.InsertRow(nil)
+		if err != nil {
+			t.Fatalf("Table.InsertRow() failed: %v", err)
+		}
+		if err := column.SetValue(id, int64(i)); err != nil {
+			t.Fatalf("Column.SetValue() failed: %v", err)
+		}
+	}
+	if err := table.Truncate(); err != nil {
+		t.Fatalf("Table.Truncate() failed: %v", err)
+	}
+	if n, err := table.Len(); err != nil {
+		t.Fatalf("Table.Len() failed: %v", err)
+	} else if n != 0 {
+		t.Fatalf("Table.Len() failed: n = %d", n)
+	}
+	if err := table.RemoveRow(1); !errors.Is(err, ErrRowNotFound) {
+		t.Fatalf("Table.RemoveRow() failed: err = %v", err)
+	}
+	if _, err := table.FindColumn("Value"); err != nil {
+		t.Fatalf("Table.FindColumn() failed: %v", err)
+	}
+	_, id, err := table.InsertRow(nil)
+	if err != nil {
+		t.Fatalf("Table.InsertRow() failed: %v", err)
+	} else if id != 1 {
+		t.Fatalf("Table.InsertRow() failed: id = %d", id)
+	}
+	if err := column.SetValue(id, int64(123)); err != nil {
+		t.Fatalf("Column.SetValue() failed: %v", err)
+	}
+}
+
+func TestDBTruncateAll(t *testing.T) {
+	dirPath, _, db, table := createTempTable(t, "Table", nil)
+	defer removeTempDB(t, dirPath, db)
+	table2, err := db.CreateTable("Table2", nil)
+	if err != nil {
+		t.Fatalf("DB.CreateTable() failed: %v", err)
+	}
+
+	insertRows := func() {
+		for _, table := range []*Table{table, table2} {
+			for i := 0; i < 10; i++ {
+				if _, _, err := table.InsertRow(nil); err != nil {
+					t.Fatalf("Table.InsertRow() failed: %v", err)
+				}
+			}
+		}
+	}
+	insertRows()
+	if err := db.TruncateAll("Table"); err != nil {
+		t.Fatalf("DB.TruncateAll() failed: %v", err)
+	}
+	if n, _ := table.Len(); n != 0 {
+		t.Fatalf("Table.Len() failed: n = %d", n)
+	}
+	if n, _ := table2.Len(); n != 10 {
+		t.Fatalf("Table.Len() failed: n = %d", n)
+	}
+	insertRows()
+	if err := db.TruncateAll(); err != nil {
+		t.Fatalf("DB.TruncateAll() failed: %v", err)
+	}
+	for _, table := range []*Table{table, table2} {
+		if n, _ := table.Len(); n != 0 {
+			t.Fatalf("Table.Len() failed: name = %s, n = %d", table.name, n)
+		}
+	}
+
+	err = db.TruncateAll("Table", "NoSuchTable", "Table2")
+	if err == nil {
+		t.Fatalf("DB.TruncateAll() succeeded for an undefined table")
+	} else if !strings.Contains(err.Error(), "truncated = [Table]") {
+		t.Fatalf("DB.TruncateAll() failed: err = %v", err)
+	}
+}
+
+func testTableCreateScalarColumn(t *testing.T, valueType string) {
+	dirPath, _, db, table, _ :=
+		createTempColumn(t, "Table", nil, "Value", valueType, nil)
+	defer removeTempDB(t, dirPath, db)
+
+	if column, err := table.FindColumn("_id"); err != nil {
+		t.Fatalf("Table.FindColumn() failed: %v", err)
+	} else {
+		t.Logf("_id: %+v", column)
+	}
+	if column, err := table.FindColumn("Value"); err != nil {
+		t.Fatalf("Table.FindColumn() failed: %v", err)
+	} else {
+		t.Logf("Value: %+v", column)
+	}
+}
+
+func testTableCreateVectorColumn(t *testing.T, valueType string) {
+	options := NewColumnOptions()
+	options.ColumnType = VectorColumn
+	dirPath, _, db, table, _ :=
+		createTempColumn(t, "Table", nil, "Value", valueType, options)
+	defer removeTempDB(t, dirPath, db)
+
+	if column, err := table.FindColumn("_id"); err != nil {
+		t.Fatalf("Table.FindColumn() failed: %v", err)
+	} else {
+		t.Logf("_id: %+v", column)
+	}
+	if column, err := table.FindColumn("Value"); err != nil {
+		t.Fatalf("Table.FindColumn() failed: %v", err)
+	} else {
+		t.Logf("Value: %+v", column)
+	}
+}
+
+func testTableCreateScalarRefColumn(t *testing.T, keyType string) {
+	options := NewTableOptions()
+	options.TableType = PatTable
+	options.KeyType = keyType
+	dirPath, _, db, table, _ :=
+		createTempColumn(t, "Table", options, "Value", "Table", nil)
+	defer removeTempDB(t, dirPath, db)
+
+	if column, err := table.FindColumn("Value"); err != nil {
+		t.Fatalf("Table.FindColumn() failed: %v", err)
+	} else {
+		t.Logf("Value: %+v", column)
+	}
+	if column, err := table.FindColumn("Value._id"); err != nil {
+		t.Fatalf("Table.FindColumn() failed: %v", err)
+	} else {
+		t.Logf("Value._id: %+v", column)
+	}
+	if column, err := table.FindColumn("Value._key"); err != nil {
+		t.Fatalf("Table.FindColumn() failed: %v", err)
+	} else {
+		t.Logf("Value._key: %+v", column)
+	}
+}
+
+func testTableCreateVectorRefColumn(t *testing.T, keyType string) {
+	tableOptions := NewTableOptions()
+	tableOptions.TableType = PatTable
+	tableOptions.KeyType = keyType
+	columnOptions := NewColumnOptions()
+	columnOptions.ColumnType = VectorColumn
+	dirPath, _, db, table, _ :=
+		createTempColumn(t, "Table", tableOptions, "Value", "Table", columnOptions)
+	defer removeTempDB(t, dirPath, db)
+
+	if column, err := table.FindColumn("Value"); err != nil {
+		t.Fatalf("Table.FindColumn() failed: %v", err)
+	} else {
+		t.Logf("Value: %+v", column)
+	}
+	if column, err := table.FindColumn("Value._id"); err != nil {
+		t.Fatalf("Table.FindColumn() failed: %v", err)
+	} else {
+		t.Logf("Value._id: %+v", column)
+	}
+	if column, err := table.FindColumn("Value._key"); err != nil {
+		t.Fatalf("Table.FindColumn() failed: %v", err)
+	} else {
+		t.Logf("Value._key: %+v", column)
+	}
+}
+
+func TestTableFindColumnWithRefPath(t *testing.T) {
+	dirPath, _, db := createTempDB(t)
+	defer removeTempDB(t, dirPath, db)
+
+	options := NewTableOptions()
+	options.TableType = HashTable
+	options.KeyType = "ShortText"
+	if _, err := db.CreateTable("Category", options); err != nil {
+		t.Fatalf("DB.CreateTable() failed: %v", err)
+	}
+	options = NewTableOptions()
+	options.TableType = HashTable
+	options.KeyType = "ShortText"
+	table, err := db.CreateTable("Item", options)
+	if err != nil {
+		t.Fatalf("DB.CreateTable() failed: %v", err)
+	}
+	category, err := table.CreateColumn("category", "Category", nil)
+	if err != nil {
+		t.Fatalf("Table.CreateColumn() failed: %v", err)
+	}
+	_, id, err := table.InsertRow([]byte("apple"))
+	if err != nil {
+		t.Fatalf("Table.InsertRow() failed: %v", err)
+	}
+	if err := category.SetValue(id, []byte("fruit")); err != nil {
+		t.Fatalf("Column.SetValue() failed: %v", err)
+	}
+
+	column, err := table.FindColumn("category._key")
+	if err != nil {
+		t.Fatalf("Table.FindColumn() failed: %v", err)
+	}
+	value, err := column.GetValue(id)
+	if err != nil {
+		t.Fatalf("Column.GetValue() failed: %v", err)
+	}
+	if !reflect.DeepEqual(value, []byte("fruit")) {
+		t.Fatalf("Column.GetValue() failed: value = %v", value)
+	}
+	if _, err := table.FindColumn("category._value"); err == nil {
+		t.Fatalf("Table.FindColumn() succeeded for a table without value")
+	}
+}
+
+func TestTableCreateColumnForBool(t *testing.T) {
+	testTableCreateScalarColumn(t, "Bool")
+}
+
+func TestTableCreateColumnForInt(t *testing.T) {
+	testTableCreateScalarColumn(t, "Int64")
+}
+
+func TestTableCreateColumnForFloat(t *testing.T) {
+	testTableCreateScalarColumn(t, "Float")
+}
+
+func TestTableCreateColumnForTokyoGeoPoint(t *testing.T) {
+	testTableCreateScalarColumn(t, "TokyoGeoPoint")
+}
+
+func TestTableCreateColumnForWGS84GeoPoint(t *testing.T) {
+	testTableCreateScalarColumn(t, "WGS84GeoPoint")
+}
+
+func TestTableCreateColumnForText(t *testing.T) {
+	testTableCreateScalarColumn(t, "ShortText")
+}
+
+func TestTableCreateColumnForBoolVector(t *testing.T) {
+	testTableCreateVectorColumn(t, "Bool")
+}
+
+func TestTableCreateColumnForIntVector(t *testing.T) {
+	testTableCreateVectorColumn(t, "Int64")
+}
+
+func TestTableCreateColumnForFloatVector(t *testing.T) {
+	testTableCreateVectorColumn(t, "Float")
+}
+
+func TestTableCreateColumnForTokyoGeoPointVector(t *testing.T) {
+	testTableCreateVectorColumn(t, "TokyoGeoPoint")
+}
+
+func TestTableCreateColumnForWGS84GeoPointVector(t *testing.T) {
+	testTableCreateVectorColumn(t, "WGS84GeoPoint")
+}
+
+func TestTableCreateColumnForTextVector(t *testing.T) {
+	testTableCreateVectorColumn(t, "ShortText")
+}
+
+func TestTableCreateColumnForRefToBool(t *testing.T) {
+	testTableCreateScalarRefColumn(t, "Bool")
+}
+
+func TestTableCreateColumnForRefToInt(t *testing.T) {
+	testTableCreateScalarRefColumn(t, "Int64")
+}
+
+func TestTableCreateColumnForRefToFloat(t *testing.T) {
+	testTableCreateScalarRefColumn(t, "Float")
+}
+
+func TestTableCreateColumnForRefToTokyoGeoPoint(t *testing.T) {
+	testTableCreateScalarRefColumn(t, "TokyoGeoPoint")
+}
+
+func TestTableCreateColumnForRefToWGS84GeoPoint(t *testing.T) {
+	testTableCreateScalarRefColumn(t, "WGS84GeoPoint")
+}
+
+func TestTableCreateColumnForRefToText(t *testing.T) {
+	testTableCreateScalarRefColumn(t, "ShortText")
+}
+
+func TestTableCreateColumnForRefToBoolVector(t *testing.T) {
+	testTableCreateVectorRefColumn(t, "Bool")
+}
+
+func TestTableCreateColumnForRefToIntVector(t *testing.T) {
+	testTableCreateVectorRefColumn(t, "Int64")
+}
+
+func TestTableCreateColumnForRefToFloatVector(t *testing.T) {
+	testTableCreateVectorRefColumn(t, "Float")
+}
+
+func TestTableCreateColumnForRefToTokyoGeoPointVector(t *testing.T) {
+	testTableCreateVectorRefColumn(t, "TokyoGeoPoint")
+}
+
+func TestTableCreateColumnForRefToWGS84GeoPointVector(t *testing.T) {
+	testTableCreateVectorRefColumn(t, "WGS84GeoPoint")
+}
+
+func TestTableCreateColumnForRefToTextVector(t *testing.T) {
+	testTableCreateVectorRefColumn(t, "ShortText")
+}
+
+// testTableCreateCompressedColumn() tests that the flags of a column created
+// with options appear in the output of column_list and that
+// Column.Compression() returns the compression type.
+func testTableCreateCompressedColumn(t *testing.T, options *ColumnOptions,
+	flags ...string) {
+	dirPath, _, db, table := createTempTable(t, "Table", nil)
+	defer removeTempDB(t, dirPath, db)
+
+	column, err := table.CreateColumn("Value", "Text", options)
+	if err != nil {
+		t.Fatalf("Table.CreateColumn() failed: %v", err)
+	}
+	if compression, err := column.Compression(); err != nil {
+		t.Fatalf("Column.Compression() failed: %v", err)
+	} else if compression != options.CompressionType {
+		t.Fatalf("Column.Compression() failed: compression = %d", compression)
+	}
+	result, err := db.QueryEx("column_list", map[string]string{"table": "Table"})
+	if err != nil {
+		t.Fatalf("DB.QueryEx() failed: %v", err)
+	}
+	for _, flag := range flags {
+		if !strings.Contains(string(result), flag) {
+			t.Fatalf("column_list does not contain %s: result = %s", flag, result)
+		}
+	}
+}
+
+func TestTableCreateColumnWithZlibCompression(t *testing.T) {
+	options := NewColumnOptions()
+	options.CompressionType = ZlibCompression
+	testTableCreateCompressedColumn(t, options, "COLUMN_SCALAR", "COMPRESS_ZLIB")
+}
+
+func TestTableCreateVectorColumnWithZlibCompression(t *testing.T) {
+	options := NewColumnOptions()
+	options.ColumnType = VectorColumn
+	options.CompressionType = ZlibCompression
+	testTableCreateCompressedColumn(t, options, "COLUMN_VECTOR", "COMPRESS_ZLIB")
+}
+
+func TestTableCreateColumnWithLzoCompression(t *testing.T) {
+	options := NewColumnOptions()
+	options.CompressionType = LzoCompression
+	testTableCreateCompressedColumn(t, options, "COLUMN_SCALAR", "COMPRESS_LZO")
+}
+
+func TestColumnCompression(t *testing.T) {
+	dirPath, _, db, _, column :=
+		createTempColumn(t, "Table", nil, "Value", "Text", nil)
+	defer removeTempDB(t, dirPath, db)
+
+	if compression, err := column.Compression(); err != nil {
+		t.Fatalf("Column.Compression() failed: %v", err)
+	} else if compression != NoCompression {
+		t.Fatalf("Column.Compression() failed: compression = %d", compression)
+	}
+	options := NewColumnOptions()
+	options.CompressionType = UnknownCompression
+	if _, err := column.table.CreateColumn("Value2", "Text", options); err == nil {
+		t.Fatalf("Table.CreateColumn() succeeded for UnknownCompression")
+	}
+}
+
+func TestColumnRemove(t *testing.T) {
+	dirPath, _, db, table, column :=
+		createTempColumn(t, "Table", nil, "Value", "ShortText", nil)
+	defer removeTempDB(t, dirPath, db)
+
+	_, id, err := table.InsertRow(nil)
+	if err != nil {
+		t.Fatalf("Table.InsertRow() failed: %v", err)
+	}
+	if err := column.Remove(); err != nil {
+		t.Fatalf("Column.Remove() failed: %v", err)
+	}
+	if _, err := table.FindColumn("Value"); err == nil {
+		t.Fatalf("Table.FindColumn() succeeded for a removed column")
+	}
+	if _, err := column.GetValue(id); err == nil {
+		t.Fatalf("Column.GetValue() succeeded for a removed column")
+	}
+	if err := column.Remove(); err == nil {
+		t.Fatalf("Column.Remove() succeeded for a removed column")
+	}
+}
+
+func TestColumnRemoveEvictsAccessors(t *testing.T) {
+	options := NewTableOptions()
+	options.TableType = HashTable
+	options.KeyType = "ShortText"
+	dirPath, _, db, refTable := createTempTable(t, "Ref", options)
+	defer removeTempDB(t, dirPath, db)
+	table, err := db.CreateTable("Table", nil)
+	if err != nil {
+		t.Fatalf("DB.CreateTable() failed: %v", err)
+	}
+	column, err := table.CreateColumn("Value", "Ref", nil)
+	if err != nil {
+		t.Fatalf("Table.CreateColumn() failed: %v", err)
+	}
+	_, refID, err := refTable.InsertRow([]byte("Key"))
+	if err != nil {
+		t.Fatalf("Table.InsertRow() failed: %v", err)
+	}
+	_, id, err := table.InsertRow(nil)
+	if err != nil {
+		t.Fatalf("Table.InsertRow() failed: %v", err)
+	}
+	if err := column.SetValue(id, refID); err != nil {
+		t.Fatalf("Column.SetValue() failed: %v", err)
+	}
+	composite, err := table.FindColumn("Value._key")
+	if err != nil {
+		t.Fatalf("Table.FindColumn() failed: %v", err)
+	}
+	if value, err := composite.GetValue(id); err != nil {
+		t.Fatalf("Column.GetValue() failed: %v", err)
+	} else if !reflect.DeepEqual(value, []byte("Key")) {
+		t.Fatalf("Column.GetValue() failed: value = %v", value)
+	}
+
+	if err := column.Remove(); err != nil {
+		t.Fatalf("Column.Remove() failed: %v", err)
+	}
+	if _, err := composite.GetValue(id); err == nil {
+		t.Fatalf("Column.GetValue() succeeded for an evicted accessor")
+	}
+	if err := composite.SetValue(id, []byte("Key")); err == nil {
+		t.Fatalf("Column.SetValue() succeeded for an evicted accessor")
+	}
+}
+
+func TestDBCreateTableFromSchema(t *testing.T) {
+	dirPath, _, db := createTempDB(t)
+	defer removeTempDB(t, dirPath, db)
+
+	indexOptions := NewColumnOptions()
+	indexOptions.ColumnType = IndexColumn
+	indexOptions.Source = "Value"
+	schema := TableSchema{Name: "Table", Columns: []ColumnDefinition{
+		{"Index", "Table", indexOptions},
+		{"Value", "Int32", nil},
+	}}
+	schema.TableType = PatTable
+	schema.KeyType = "Int32"
+	table, err := db.CreateTableFromSchema(schema)
+	if err != nil {
+		t.Fatalf("DB.CreateTableFromSchema() failed: %v", err)
+	}
+	if names, err := table.ColumnNames(); err != nil {
+		t.Fatalf("Table.ColumnNames() failed: %v", err)
+	} else if !reflect.DeepEqual(names, []string{"Index", "Value"}) {
+		t.Fatalf("Table.ColumnNames() failed: names = %v", names)
+	}
+
+	schema = TableSchema{Name: "Broken", Columns: []ColumnDefinition{
+		{"Value", "Int32", nil},
+		{"Wrong", "NoSuchType", nil},
+	}}
+	if _, err := db.CreateTableFromSchema(schema); err == nil {
+		t.Fatalf("DB.CreateTableFromSchema() succeeded for a wrong type")
+	}
+	if _, err := db.FindTable("Broken"); err == nil {
+		t.Fatalf("DB.FindTable() succeeded for a rolled back table")
+	}
+}
+
+func TestTableColumnSchemas(t *testing.T) {
+	options := NewTableOptions()
+	options.TableType = PatTable
+	options.KeyType = "ShortText"
+	dirPath, _, db, table := createTempTable(t, "Table", options)
+	defer removeTempDB(t, dirPath, db)
+
+	if _, err := table.CreateColumn("Value", "Int32", nil); err != nil {
+		t.Fatalf("Table.CreateColumn() failed: %v", err)
+	}
+	columnOptions := NewColumnOptions()
+	columnOptions.ColumnType = VectorColumn
+	if _, err := table.CreateColumn("Refs", "Table", columnOptions); err != nil {
+		t.Fatalf("Table.CreateColumn() failed: %v", err)
+	}
+	optionsMap := map[string]string{"table": "Table", "name": "Index",
+		"flags": "COLUMN_INDEX", "type": "Table", "source": "Value"}
+	if _, err := db.QueryEx("column_create", optionsMap); err != nil {
+		t.Fatalf("DB.QueryEx() failed: %v", err)
+	}
+
+	if names, err := table.ColumnNames(); err != nil {
+		t.Fatalf("Table.ColumnNames() failed: %v", err)
+	} else if !reflect.DeepEqual(names, []string{"Index", "Refs", "Value"}) {
+		t.Fatalf("Table.ColumnNames() failed: names = %v", names)
+	}
+	schemas, err := table.ColumnSchemas()
+	if err != nil {
+		t.Fatalf("Table.ColumnSchemas() failed: %v", err)
+	}
+	expected := []ColumnSchema{
+		{"Index", Void, "Table", false, true, []string{"Table.Value"}, NoCompression},
+		{"Refs", ShortText, "Table", true, false, nil, NoCompression},
+		{"Value", Int32, "Int32", false, false, nil, NoCompression},
+	}
+	if !reflect.DeepEqual(schemas, expected) {
+		t.Fatalf("Table.ColumnSchemas() failed: schemas = %+v", schemas)
+	}
+}
+
+func TestColumnAccessors(t *testing.T) {
+	options := NewTableOptions()
+	options.TableType = HashTable
+	options.KeyType = "Int32"
+	dirPath, _, db, table := createTempTable(t, "Table", options)
+	defer removeTempDB(t, dirPath, db)
+
+	columnOptions := NewColumnOptions()
+	columnOptions.ColumnType = VectorColumn
+	column, err := table.CreateColumn("Values", "Float", columnOptions)
+	if err != nil {
+		t.Fatalf("Table.CreateColumn() failed: %v", err)
+	}
+	if (column.ValueType() != Float) || !column.IsVector() || column.IsReference() {
+		t.Fatalf("Column accessors failed: valueType = %v, isVector = %v, isReference = %v",
+			column.ValueType(), column.IsVector(), column.IsReference())
+	}
+	column, err = table.CreateColumn("Ref", "Table", nil)
+	if err != nil {
+		t.Fatalf("Table.CreateColumn() failed: %v", err)
+	}
+	if (column.ValueType() != Int32) || column.IsVector() || !column.IsReference() {
+		t.Fatalf("Column accessors failed: valueType = %v, isVector = %v, isReference = %v",
+			column.ValueType(), column.IsVector(), column.IsReference())
+	}
+}
+
+func TestTableRowValue(t *testing.T) {
+	options := NewTableOptions()
+	options.TableType = HashTable
+	options.KeyType = "ShortText"
+	options.ValueType = "Int32"
+	dirPath, _, db, table := createTempTable(t, "Table", options)
+	defer removeTempDB(t, dirPath, db)
+
+	_, id, err := table.InsertRow([]byte("key"))
+	if err != nil {
+		t.Fatalf("Table.InsertRow() failed: %v", err)
+	}
+	if err := table.SetRowValue(id, int64(123)); err != nil {
+		t.Fatalf("Table.SetRowValue() failed: %v", err)
+	}
+	if value, err := table.GetRowValue(id); err != nil || value.(int64) != 123 {
+		t.Fatalf("Table.GetRowValue() failed: value = %v, err = %v", value, err)
+	}
+	if _, err := table.GetRowValue(id + 1); !errors.Is(err, ErrRowNotFound) {
+		t.Fatalf("Table.GetRowValue() failed: err = %v", err)
+	}
+
+	options.ValueType = ""
+	table2, err := db.CreateTable("Table2", options)
+	if err != nil {
+		t.Fatalf("DB.CreateTable() failed: %v", err)
+	}
+	if _, id, err = table2.InsertRow([]byte("key")); err != nil {
+		t.Fatalf("Table.InsertRow() failed: %v", err)
+	}
+	if _, err := table2.GetRowValue(id); err == nil {
+		t.Fatalf("Table.GetRowValue() succeeded for a table without value")
+	}
+	if err := table2.SetRowValue(id, int64(1)); err == nil {
+		t.Fatalf("Table.SetRowValue() succeeded for a table without value")
+	}
+}
+
+func TestTableTokenizer(t *testing.T) {
+	options := NewTableOptions()
+	options.TableType = PatTable
+	options.KeyType = "ShortText"
+	options.DefaultTokenizer = "TokenBigram"
+	options.Normalizer = "NormalizerAuto"
+	dirPath, _, db, table := createTempTable(t, "Terms", options)
+	defer removeTempDB(t, dirPath, db)
+
+	if tokenizer, err := table.Tokenizer(); err != nil || tokenizer != "TokenBigram" {
+		t.Fatalf("Table.Tokenizer() failed: tokenizer = %s, err = %v", tokenizer, err)
+	}
+	if normalizer, err := table.Normalizer(); err != nil || normalizer != "NormalizerAuto" {
+		t.Fatalf("Table.Normalizer() failed: normalizer = %s, err = %v", normalizer, err)
+	}
+	if filters, err := table.TokenFilters(); err != nil || len(filters) != 0 {
+		t.Fatalf("Table.TokenFilters() failed: filters = %v, err = %v", filters, err)
+	}
+
+	options = NewTableOptions()
+	options.TableType = HashTable
+	options.KeyType = "ShortText"
+	table2, err := db.CreateTable("Table2", options)
+	if err != nil {
+		t.Fatalf("DB.CreateTable() failed: %v", err)
+	}
+	if tokenizer, err := table2.Tokenizer(); err != nil || tokenizer != "" {
+		t.Fatalf("Table.Tokenizer() failed: tokenizer = %s, err = %v", tokenizer, err)
+	}
+	if normalizer, err := table2.Normalizer(); err != nil || normalizer != "" {
+		t.Fatalf("Table.Normalizer() failed: normalizer = %s, err = %v", normalizer, err)
+	}
+}
+
+func TestDBRegisterPlugin(t *testing.T) {
+	dirPath, _, db := createTempDB(t)
+	defer removeTempDB(t, dirPath, db)
+
+	if err := db.RegisterPlugin("token_filters/stop_word"); err != nil {
+		t.Skipf("DB.RegisterPlugin() failed: %v", err)
+	}
+	options := NewTableOptions()
+	options.TableType = PatTable
+	options.KeyType = "ShortText"
+	options.DefaultTokenizer = "TokenBigram"
+	options.TokenFilters = []string{"TokenFilterStopWord"}
+	table, err := db.CreateTable("Terms", options)
+	if err != nil {
+		t.Fatalf("DB.CreateTable() failed: %v", err)
+	}
+	if filters, err := table.TokenFilters(); err != nil ||
+		!reflect.DeepEqual(filters, []string{"TokenFilterStopWord"}) {
+		t.Fatalf("Table.TokenFilters() failed: filters = %v, err = %v", filters, err)
+	}
+
+	err = db.RegisterPlugin("no_such_plugin")
+	var groongaError *GroongaError
+	if !errors.As(err, &groongaError) || (groongaError.Message == "") {
+		t.Fatalf("DB.RegisterPlugin() failed: err = %v", err)
+	}
+}
+
+func TestDBSchema(t *testing.T) {
+	dirPath, _, db, table, _ :=
+		createTempColumn(t, "Table", nil, "Value", "ShortText", nil)
+	defer removeTempDB(t, dirPath, db)
+
+	columnOptions := NewColumnOptions()
+	columnOptions.CompressionType = ZlibCompression
+	if _, err := table.CreateColumn("Body", "Text", columnOptions); err != nil {
+		t.Fatalf("Table.CreateColumn() failed: %v", err)
+	}
+	options := NewTableOptions()
+	options.TableType = PatTable
+	options.KeyType = "ShortText"
+	options.DefaultTokenizer = "TokenBigram"
+	options.Normalizer = "NormalizerAuto"
+	terms, err := db.CreateTable("Terms", options)
+	if err != nil {
+		t.Fatalf("DB.CreateTable() failed: %v", err)
+	}
+	columnOptions = NewColumnOptions()
+	columnOptions.ColumnType = IndexColumn
+	columnOptions.Source = "Value"
+	if _, err := terms.CreateColumn("Index", "Table", columnOptions); err != nil {
+		t.Fatalf("Table.CreateColumn() failed: %v", err)
+	}
+
+	schema, err := db.Schema()
+	if err != nil {
+		t.Fatalf("DB.Schema() failed: %v", err)
+	}
+	if len(schema.Tables) != 2 {
+		t.Fatalf("DB.Schema() failed: tables = %+v", schema.Tables)
+	}
+	expected := SchemaTable{
+		Name:         "Table",
+		TableType:    ArrayTable,
+		TokenFilters: []string{},
+		Columns: []ColumnSchema{
+			{"Body", Text, "Text", false, false, nil, ZlibCompression},
+			{"Value", ShortText, "ShortText", false, false, nil, NoCompression},
+		},
+	}
+	if info := schema.Table("Table"); (info == nil) || !reflect.DeepEqual(*info, expected) {
+		t.Fatalf("DB.Schema() failed: table = %+v", info)
+	}
+	expected = SchemaTable{
+		Name:         "Terms",
+		TableType:    PatTable,
+		KeyType:      ShortText,
+		Tokenizer:    "TokenBigram",
+		Normalizer:   "NormalizerAuto",
+		TokenFilters: []string{},
+		Columns: []ColumnSchema{
+			{"Index", Void, "Table", false, true, []string{"Table.Value"}, NoCompression},
+		},
+	}
+	if info := schema.Table("Terms"); (info == nil) || !reflect.DeepEqual(*info, expected) {
+		t.Fatalf("DB.Schema() failed: table = %+v", info)
+	}
+	if schema.Table("Unknown") != nil {
+		t.Fatalf("Schema.Table() succeeded for an unknown table")
+	}
+}
+
+func TestColumnIndexSources(t *testing.T) {
+	dirPath, _, db, table, column :=
+		createTempColumn(t, "Table", nil, "Value", "ShortText", nil)
+	defer removeTempDB(t, dirPath, db)
+
+	options := NewTableOptions()
+	options.TableType = PatTable
+	options.KeyType = "ShortText"
+	lexicon, err := db.CreateTable("Lexicon", options)
+	if err != nil {
+		t.Fatalf("DB.CreateTable() failed: %v", err)
+	}
+	columnOptions := NewColumnOptions()
+	columnOptions.ColumnType = IndexColumn
+	columnOptions.Source = "Value"
+	index, err := lexicon.CreateColumn("Index", "Table", columnOptions)
+	if err != nil {
+		t.Fatalf("Table.CreateColumn() failed: %v", err)
+	}
+	if !index.IsIndex() || column.IsIndex() {
+		t.Fatalf("Column.IsIndex() failed")
+	}
+	sources, err := index.IndexSources()
+	if err != nil {
+		t.Fatalf("Column.IndexSources() failed: %v", err)
+	}
+	if !reflect.DeepEqual(sources, []string{"Table.Value"}) {
+		t.Fatalf("Column.IndexSources() failed: sources = %v", sources)
+	}
+	if _, err := column.IndexSources(); err == nil {
+		t.Fatalf("Column.IndexSources() succeeded for a data column")
+	}
+	if _, err := table.FindColumn("Value"); err != nil {
+		t.Fatalf("Table.FindColumn() failed: %v", err)
+	}
+}
+
+func TestColumnRebuild(t *testing.T) {
+	dirPath, _, db, table, column :=
+		createTempColumn(t, "Table", nil, "Value", "ShortText", nil)
+	defer removeTempDB(t, dirPath, db)
+
+	options := NewTableOptions()
+	options.TableType = PatTable
+	options.KeyType = "ShortText"
+	lexicon, err := db.CreateTable("Lexicon", options)
+	if err != nil {
+		t.Fatalf("DB.CreateTable() failed: %v", err)
+	}
+	columnOptions := NewColumnOptions()
+	columnOptions.ColumnType = IndexColumn
+	columnOptions.Source = "Value"
+	index, err := lexicon.CreateColumn("Index", "Table", columnOptions)
+	if err != nil {
+		t.Fatalf("Table.CreateColumn() failed: %v", err)
+	}
+	for _, value := range []string{"abc", "def", "abc"} {
+		_, id, err := table.InsertRow(nil)
+		if err != nil {
+			t.Fatalf("Table.InsertRow() failed: %v", err)
+		}
+		if err := column.SetValue(id, []byte(value)); err != nil {
+			t.Fatalf("Column.SetValue() failed: %v", err)
+		}
+	}
+
+	if err := index.Rebuild(); err != nil {
+		t.Fatalf("Column.Rebuild() failed: %v", err)
+	}
+	selectOptions := NewSelectOptions()
+	selectOptions.Filter = "Value == \"abc\""
+	records, err := table.Select(selectOptions)
+	if err != nil {
+		t.Fatalf("Table.Select() failed: %v", err)
+	}
+	if records.NHits != 2 {
+		t.Fatalf("Table.Select() failed: NHits = %d", records.NHits)
+	}
+	if err := column.Rebuild(); err == nil {
+		t.Fatalf("Column.Rebuild() succeeded for a data column")
+	}
+}
+
+func TestTableFlags(t *testing.T) {
+	options := NewTableOptions()
+	options.TableType = PatTable
+	options.KeyType = "ShortText"
+	options.WithSIS = true
+	dirPath, _, db, table := createTempTable(t, "Table", options)
+	defer removeTempDB(t, dirPath, db)
+
+	flags, err := table.Flags()
+	if err != nil {
+		t.Fatalf("Table.Flags() failed: %v", err)
+	}
+	if (flags & TableTypeMaskFlag) != TablePatKeyFlag {
+		t.Fatalf("Table.Flags() failed: flags = %#x", flags)
+	}
+	if ((flags & KeyWithSISFlag) == 0) || ((flags & PersistentFlag) == 0) {
+		t.Fatalf("Table.Flags() failed: flags = %#x", flags)
+	}
+
+	columnOptions := NewColumnOptions()
+	columnOptions.ColumnType = VectorColumn
+	columnOptions.WithWeight = true
+	column, err := table.CreateColumn("Tags", "ShortText", columnOptions)
+	if err != nil {
+		t.Fatalf("Table.CreateColumn() failed: %v", err)
+	}
+	if flags, err = column.Flags(); err != nil {
+		t.Fatalf("Column.Flags() failed: %v", err)
+	}
+	if ((flags & ColumnTypeMaskFlag) != ColumnVectorFlag) ||
+		((flags & WithWeightFlag) == 0) || ((flags & WithPositionFlag) != 0) {
+		t.Fatalf("Column.Flags() failed: flags = %#x", flags)
+	}
+
+	terms, err := db.CreateTable("Terms", options)
+	if err != nil {
+		t.Fatalf("DB.CreateTable() failed: %v", err)
+	}
+	columnOptions = NewColumnOptions()
+	columnOptions.ColumnType = IndexColumn
+	columnOptions.WithPosition = true
+	columnOptions.Source = "_key"
+	index, err := terms.CreateColumn("Table_key", "Table", columnOptions)
+	if err != nil {
+		t.Fatalf("Table.CreateColumn() failed: %v", err)
+	}
+	if flags, err = index.Flags(); err != nil {
+		t.Fatalf("Column.Flags() failed: %v", err)
+	}
+	if ((flags & ColumnTypeMaskFlag) != ColumnIndexFlag) ||
+		((flags & WithPositionFlag) == 0) {
+		t.Fatalf("Column.Flags() failed: flags = %#x", flags)
+	}
+}
+
+func TestDBCreateTemporaryTable(t *testing.T) {
+	dirPath, _, db := createTempDB(t)
+	defer removeTempDB(t, dirPath, db)
+
+	options := NewTableOptions()
+	options.TableType = HashTable
+	options.KeyType = "ShortText"
+	options.Temporary = true
+	table, err := db.CreateTable("Temp", options)
+	if err != nil {
+		t.Fatalf("DB.CreateTable() failed: %v", err)
+	}
+	if !table.IsTemporary() {
+		t.Fatalf("Table.IsTemporary() failed")
+	}
+	flags, err := table.Flags()
+	if err != nil {
+		t.Fatalf("Table.Flags() failed: %v", err)
+	}
+	if (flags & PersistentFlag) != 0 {
+		t.Fatalf("Table.Flags() failed: flags = %#x", flags)
+	}
+	if _, _, err := table.InsertRow("Key"); err != nil {
+		t.Fatalf("Table.InsertRow() failed: %v", err)
+	}
+	found, err := db.FindTable("Temp")
+	if err != nil {
+		t.Fatalf("DB.FindTable() failed: %v", err)
+	}
+	if found != table {
+		t.Fatalf("DB.FindTable() returned another table")
+	}
+	names, err := db.TableNames()
+	if err != nil {
+		t.Fatalf("DB.TableNames() failed: %v", err)
+	}
+	if len(names) != 0 {
+		t.Fatalf("DB.TableNames() failed: names = %v", names)
+	}
+	if _, err := table.CreateColumn("Value", "Int32", nil); err == nil {
+		t.Fatalf("Table.CreateColumn() succeeded for temporary table")
+	}
+	if _, err := db.CreateTable("Temp", options); err == nil {
+		t.Fatalf("DB.CreateTable() succeeded for existing name")
+	}
+
+	if err := db.RemoveTable("Temp"); err != nil {
+		t.Fatalf("DB.RemoveTable() failed: %v", err)
+	}
+	if _, err := db.FindTable("Temp"); err == nil {
+		t.Fatalf("DB.FindTable() found removed temporary table")
+	}
+}
+
+func TestTableDiskUsage(t *testing.T) {
+	dirPath, _, db, table, column :=
+		createTempColumn(t, "Table", nil, "Value", "ShortText", nil)
+	defer removeTempDB(t, dirPath, db)
+
+	options := NewTableOptions()
+	options.TableType = PatTable
+	options.KeyType = "ShortText"
+	lexicon, err := db.CreateTable("Lexicon", options)
+	if err != nil {
+		t.Fatalf("DB.CreateTable() failed: %v", err)
+	}
+	columnOptions := NewColumnOptions()
+	columnOptions.ColumnType = IndexColumn
+	columnOptions.Source = "Value"
+	index, err := lexicon.CreateColumn("Index", "Table", columnOptions)
+	if err != nil {
+		t.Fatalf("Table.CreateColumn() failed: %v", err)
+	}
+	for i := 0; i < 100; i++ {
+		_, id, err := table.InsertRow(nil)
+		if err != nil {
+			t.Fatalf("Table.InsertRow() failed: %v", err)
+		}
+		if err := column.SetValue(id, []byte(strconv.Itoa(i))); err != nil {
+			t.Fatalf("Column.SetValue() failed: %v", err)
+		}
+	}
+
+	columnUsage, err := column.DiskUsage()
+	if err != nil {
+		t.Fatalf("Column.DiskUsage() failed: %v", err)
+	}
+	tableUsage, err := table.DiskUsage()
+	if err != nil {
+		t.Fatalf("Table.DiskUsage() failed: %v", err)
+	}
+	if (columnUsage == 0) || (tableUsage <= columnUsage) {
+		t.Fatalf("Table.DiskUsage() failed: table = %d, column = %d",
+			tableUsage, columnUsage)
+	}
+	if indexUsage, err := table.IndexDiskUsage(); err != nil {
+		t.Fatalf("Table.IndexDiskUsage() failed: %v", err)
+	} else if indexUsage != 0 {
+		t.Fatalf("Table.IndexDiskUsage() failed: indexUsage = %d", indexUsage)
+	}
+	indexColumnUsage, err := index.DiskUsage()
+	if err != nil {
+		t.Fatalf("Column.DiskUsage() failed: %v", err)
+	}
+	if indexUsage, err := lexicon.IndexDiskUsage(); err != nil {
+		t.Fatalf("Table.IndexDiskUsage() failed: %v", err)
+	} else if (indexUsage == 0) || (indexUsage != indexColumnUsage) {
+		t.Fatalf("Table.IndexDiskUsage() failed: indexUsage = %d, column = %d",
+			indexUsage, indexColumnUsage)
+	}
+}
+
+func TestTableRelease(t *testing.T) {
+	dirPath, _, db, table, column :=
+		createTempColumn(t, "Table", nil, "Value", "Int32", nil)
+	defer removeTempDB(t, dirPath, db)
+
+	_, id, err := table.InsertRow(nil)
+	if err != nil {
+		t.Fatalf("Table.InsertRow() failed: %v", err)
+	}
+	if err := column.SetValue(id, int64(123)); err != nil {
+		t.Fatalf("Column.SetValue() failed: %v", err)
+	}
+	column.Release()
+	if _, err := column.GetValue(id); err == nil {
+		t.Fatalf("Column.GetValue() succeeded for a released column")
+	}
+	newColumn, err := table.FindColumn("Value")
+	if err != nil {
+		t.Fatalf("Table.FindColumn() failed: %v", err)
+	}
+	if newColumn == column {
+		t.Fatalf("Table.FindColumn() returned a released column")
+	}
+	if value, err := newColumn.GetValue(id); err != nil {
+		t.Fatalf("Column.GetValue() failed: %v", err)
+	} else if value != int64(123) {
+		t.Fatalf("Column.GetValue() failed: value = %v", value)
+	}
+
+	table.Release()
+	if _, err := table.Len(); err == nil {
+		t.Fatalf("Table.Len() succeeded for a released table")
+	}
+	if _, err := newColumn.GetValue(id); err == nil {
+		t.Fatalf("Column.GetValue() succeeded for a released column")
+	}
+	newTable, err := db.FindTable("Table")
+	if err != nil {
+		t.Fatalf("DB.FindTable() failed: %v", err)
+	}
+	if n, err := newTable.Len(); err != nil || n != 1 {
+		t.Fatalf("Table.Len() failed: n = %d, err = %v", n, err)
+	}
+}
+
+func TestTableReleaseReferenced(t *testing.T) {
+	options := NewTableOptions()
+	options.TableType = HashTable
+	options.KeyType = "ShortText"
+	dirPath, _, db, refTable := createTempTable(t, "Ref", options)
+	defer removeTempDB(t, dirPath, db)
+
+	options = NewTableOptions()
+	options.TableType = HashTable
+	options.KeyType = "Ref"
+	table, err := db.CreateTable("Table", options)
+	if err != nil {
+		t.Fatalf("DB.CreateTable() failed: %v", err)
+	}
+	column, err := table.CreateColumn("Value", "Ref", nil)
+	if err != nil {
+		t.Fatalf("Table.CreateColumn() failed: %v", err)
+	}
+	_, refID, err := refTable.InsertRow([]byte("Key"))
+	if err != nil {
+		t.Fatalf("Table.InsertRow() failed: %v", err)
+	}
+	_, id, err := table.InsertRow(refID)
+	if err != nil {
+		t.Fatalf("Table.InsertRow() failed: %v", err)
+	}
+	if err := column.SetValue(id, refID); err != nil {
+		t.Fatalf("Column.SetValue() failed: %v", err)
+	}
+
+	refTable.Release()
+	_, _, err = table.InsertRow(refID)
+	if err == nil {
+		t.Fatalf("Table.InsertRow() succeeded with a released key table")
+	}
+	if errors.Is(err, ErrRowNotFound) ||
+		!strings.Contains(err.Error(), "referenced table released") {
+		t.Fatalf("Table.InsertRow() failed: err = %v", err)
+	}
+	if _, err := table.FindColumn("Value._key"); err == nil {
+		t.Fatalf("Table.FindColumn() succeeded with a released value table")
+	}
+	if _, err := column.GetRefID(id); err != nil {
+		t.Fatalf("Column.GetRefID() failed: %v", err)
+	}
+	if n, err := table.Len(); err != nil || n != 1 {
+		t.Fatalf("Table.Len() failed: n = %d, err = %v", n, err)
+	}
+}
+
+func TestDBHasTable(t *testing.T) {
+	dirPath, _, db := createTempDB(t)
+	defer removeTempDB(t, dirPath, db)
+
+	if db.HasTable("Table") {
+		t.Fatalf("DB.HasTable() returned true for a missing table")
+	}
+	table, err := db.CreateTable("Table", nil)
+	if err != nil {
+		t.Fatalf("DB.CreateTable() failed: %v", err)
+	}
+	if !db.HasTable("Table") {
+		t.Fatalf("DB.HasTable() returned false for an existing table")
+	}
+
+	if table.HasColumn("Value") {
+		t.Fatalf("Table.HasColumn() returned true for a missing column")
+	}
+	if _, err := table.CreateColumn("Value", "Int32", nil); err != nil {
+		t.Fatalf("Table.CreateColumn() failed: %v", err)
+	}
+	if !table.HasColumn("Value") || !table.HasColumn("_id") {
+		t.Fatalf("Table.HasColumn() returned false for an existing column")
+	}
+}
+
+func TestTableRemoveColumn(t *testing.T) {
+	dirPath, _, db, table, _ :=
+		createTempColumn(t, "Table", nil, "Value", "Int32", nil)
+	defer removeTempDB(t, dirPath, db)
+
+	if err := table.RemoveColumn("Value"); err != nil {
+		t.Fatalf("Table.RemoveColumn() failed: %v", err)
+	}
+	if _, err := table.FindColumn("Value"); err == nil {
+		t.Fatalf("Table.FindColumn() succeeded for a removed column")
+	}
+	if err := table.RemoveColumn("_id"); err == nil {
+		t.Fatalf("Table.RemoveColumn() succeeded for _id")
+	}
+}
+
+func generateRandomValue(valueType string) interface{} {
+	switch valueType {
+	case "Bool":
+		return (rand.Int() & 1) == 1
+	case "Int8":
+		return int64(int8(rand.Int()))
+	case "Int16":
+		return int64(int16(rand.Int()))
+	case "Int32":
+		return int64(int32(rand.Int()))
+	case "Int64":
+		return rand.Int63()
+	case "UInt8":
+		return int64(uint8(rand.Int()))
+	case "UInt16":
+		return int64(uint16(rand.Int()))
+	case "UInt32":
+		return int64(rand.Uint32())
+	case "UInt64":
+		return rand.Int63()
+	case "Float":
+		return rand.Float64()
+	case "Time":
+		return time.Unix(rand.Int63n(1<<32), rand.Int63n(1000000)*1000)
+	case "TokyoGeoPoint", "WGS84GeoPoint":
+		const (
+			MinLatitude  = 73531000
+			MaxLatitude  = 164006000
+			MinLongitude = 439451000
+			MaxLongitude = 554351000
+		)
+		latitude := MinLatitude + rand.Intn(MaxLatitude-MinLatitude+1)
+		longitude := MinLongitude + rand.Intn(MaxLongitude-MinLongitude+1)
+		return GeoPoint{int32(latitude), int32(longitude)}
+	case "ShortText":
+		return []byte(strconv.Itoa(rand.Int()))
+	default:
+		return nil
+	}
+}
+
+func generateRandomVectorValue(valueType string) interface{} {
+	size := rand.Int() % 10
+	switch valueType {
+	case "Bool":
+		value := make([]bool, size)
+		for i := 0; i < size; i++ {
+			value[i] = (rand.Int() & 1) == 1
+		}
+		return value
+	case "Int8":
+		value := make([]int64, size)
+		for i := 0; i < size; i++ {
+			value[i] = int64(int8(rand.Int()))
+		}
+		return value
+	case "Int16":
+		value := make([]int64, size)
+		for i := 0; i < size; i++ {
+			value[i] = int64(int16(rand.Int()))
+		}
+		return value
+	case "Int32":
 		value := make([]int64, size)
 		for i := 0; i < size; i++ {
 			value[i] = int64(int32(rand.Int()))
 		}
-		return value
-	case "Int64":
-		value := make([]int64, size)
-		for i := 0; i < size; i++ {
-			value[i] = rand.Int63()
+		return value
+	case "Int64":
+		value := make([]int64, size)
+		for i := 0; i < size; i++ {
+			value[i] = rand.Int63()
+		}
+		return value
+	case "UInt8":
+		value := make([]int64, size)
+		for i := 0; i < size; i++ {
+			value[i] = int64(uint8(rand.Int()))
+		}
+		return value
+	case "UInt16":
+		value := make([]int64, size)
+		for i := 0; i < size; i++ {
+			value[i] = int64(uint16(rand.Int()))
+		}
+		return value
+	case "UInt32":
+		value := make([]int64, size)
+		for i := 0; i < size; i++ {
+			value[i] = int64(rand.Uint32())
+		}
+		return value
+	case "UInt64":
+		value := make([]int64, size)
+		for i := 0; i < size; i++ {
+			value[i] = rand.Int63()
+		}
+		return value
+	case "Float":
+		value := make([]float64, size)
+		for i := 0; i < size; i++ {
+			value[i] = rand.Float64()
+		}
+		return value
+	case "Time":
+		value := make([]time.Time, size)
+		for i := 0; i < size; i++ {
+			value[i] = time.Unix(rand.Int63n(1<<32), rand.Int63n(1000000)*1000)
+		}
+		return value
+	case "TokyoGeoPoint", "WGS84GeoPoint":
+		const (
+			MinLatitude  = 73531000
+			MaxLatitude  = 164006000
+			MinLongitude = 439451000
+			MaxLongitude = 554351000
+		)
+		value := make([]GeoPoint, size)
+		for i := 0; i < size; i++ {
+			latitude := MinLatitude + rand.Intn(MaxLatitude-MinLatitude+1)
+			longitude := MinLongitude + rand.Intn(MaxLongitude-MinLongitude+1)
+			value[i] = GeoPoint{int32(latitude), int32(longitude)}
+		}
+		return value
+	case "ShortText":
+		value := make([][]byte, size)
+		for i := 0; i < size; i++ {
+			value[i] = []byte(strconv.Itoa(rand.Int()))
+		}
+		return value
+	default:
+		return nil
+	}
+}
+
+func testColumnSetValueForScalar(t *testing.T, valueType string) {
+	dirPath, _, db, table, column :=
+		createTempColumn(t, "Table", nil, "Value", valueType, nil)
+	defer removeTempDB(t, dirPath, db)
+
+	for i := 0; i < 100; i++ {
+		_, id, err := table.InsertRow(nil)
+		if err != nil {
+			t.Fatalf("Table.InsertRow() failed: %v", err)
+		}
+		if err := column.SetValue(id, generateRandomValue(valueType)); err != nil {
+			t.Fatalf("Column.SetValue() failed: %v", err)
+		}
+	}
+
+	bytes, _ := db.Query("select Table --limit 3")
+	t.Logf("valueType = <%s>, result = %s", valueType, string(bytes))
+}
+
+func testColumnSetValueForVector(t *testing.T, valueType string) {
+	options := NewColumnOptions()
+	options.ColumnType = VectorColumn
+	dirPath, _, db, table, column :=
+		createTempColumn(t, "Table", nil, "Value", valueType, options)
+	defer removeTempDB(t, dirPath, db)
+
+	for i := 0; i < 100; i++ {
+		_, id, err := table.InsertRow(nil)
+		if err != nil {
+			t.Fatalf("Table.InsertRow() failed: %v", err)
+		}
+		if err := column.SetValue(id, generateRandomVectorValue(valueType)); err != nil {
+			t.Fatalf("Column.SetValue() failed: %v", err)
+		}
+	}
+
+	bytes, _ := db.Query("select Table --limit 3")
+	t.Logf("valueType = <%s>, result = %s", valueType, string(bytes))
+}
+
+func TestColumnSetValueForBool(t *testing.T) {
+	testColumnSetValueForScalar(t, "Bool")
+}
+
+func TestColumnSetValueForInt8(t *testing.T) {
+	testColumnSetValueForScalar(t, "Int8")
+}
+
+func TestColumnSetValueForInt16(t *testing.T) {
+	testColumnSetValueForScalar(t, "Int16")
+}
+
+func TestColumnSetValueForInt32(t *testing.T) {
+	testColumnSetValueForScalar(t, "Int32")
+}
+
+func TestColumnSetValueForInt64(t *testing.T) {
+	testColumnSetValueForScalar(t, "Int64")
+}
+
+func TestColumnSetValueForUInt8(t *testing.T) {
+	testColumnSetValueForScalar(t, "UInt8")
+}
+
+func TestColumnSetValueForUInt16(t *testing.T) {
+	testColumnSetValueForScalar(t, "UInt16")
+}
+
+func TestColumnSetValueForUInt32(t *testing.T) {
+	testColumnSetValueForScalar(t, "UInt32")
+}
+
+func TestColumnSetValueForUInt64(t *testing.T) {
+	testColumnSetValueForScalar(t, "UInt64")
+}
+
+func TestColumnSetValueForFloat(t *testing.T) {
+	testColumnSetValueForScalar(t, "Float")
+}
+
+func TestColumnSetValueForTime(t *testing.T) {
+	testColumnSetValueForScalar(t, "Time")
+}
+
+func TestColumnSetValueForTokyoGeoPoint(t *testing.T) {
+	testColumnSetValueForScalar(t, "TokyoGeoPoint")
+}
+
+func TestColumnSetValueForWGS84GeoPoint(t *testing.T) {
+	testColumnSetValueForScalar(t, "WGS84GeoPoint")
+}
+
+func TestColumnSetValueForText(t *testing.T) {
+	testColumnSetValueForScalar(t, "ShortText")
+}
+
+func TestColumnSetValueForBoolVector(t *testing.T) {
+	testColumnSetValueForVector(t, "Bool")
+}
+
+func TestColumnSetValueForInt8Vector(t *testing.T) {
+	testColumnSetValueForVector(t, "Int8")
+}
+
+func TestColumnSetValueForInt16Vector(t *testing.T) {
+	testColumnSetValueForVector(t, "Int16")
+}
+
+func TestColumnSetValueForInt32Vector(t *testing.T) {
+	testColumnSetValueForVector(t, "Int32")
+}
+
+func TestColumnSetValueForInt64Vector(t *testing.T) {
+	testColumnSetValueForVector(t, "Int64")
+}
+
+func TestColumnSetValueForUInt8Vector(t *testing.T) {
+	testColumnSetValueForVector(t, "UInt8")
+}
+
+func TestColumnSetValueForUInt16Vector(t *testing.T) {
+	testColumnSetValueForVector(t, "UInt16")
+}
+
+func TestColumnSetValueForUInt32Vector(t *testing.T) {
+	testColumnSetValueForVector(t, "UInt32")
+}
+
+func TestColumnSetValueForUInt64Vector(t *testing.T) {
+	testColumnSetValueForVector(t, "UInt64")
+}
+
+func TestColumnSetValueForFloatVector(t *testing.T) {
+	testColumnSetValueForVector(t, "Float")
+}
+
+func TestColumnSetValueForTimeVector(t *testing.T) {
+	testColumnSetValueForVector(t, "Time")
+}
+
+func TestColumnSetValueForTokyoGeoPointVector(t *testing.T) {
+	testColumnSetValueForVector(t, "TokyoGeoPoint")
+}
+
+func TestColumnSetValueForWGS84GeoPointVector(t *testing.T) {
+	testColumnSetValueForVector(t, "WGS84GeoPoint")
+}
+
+func TestColumnSetValueForTextVector(t *testing.T) {
+	testColumnSetValueForVector(t, "ShortText")
+}
+
+func testColumnGetValueForScalar(t *testing.T, valueType string) {
+	dirPath, _, db, table, column :=
+		createTempColumn(t, "Table", nil, "Value", valueType, nil)
+	defer removeTempDB(t, dirPath, db)
+
+	for i := 0; i < 100; i++ {
+		_, id, err := table.InsertRow(nil)
+		if err != nil {
+			t.Fatalf("Table.InsertRow() failed: %v", err)
+		}
+		value := generateRandomValue(valueType)
+		if err := column.SetValue(id, value); err != nil {
+			t.Fatalf("Column.SetValue() failed: %v", err)
+		}
+		if storedValue, err := column.GetValue(id); err != nil {
+			t.Fatalf("Column.GetValue() failed: %v", err)
+		} else if !reflect.DeepEqual(value, storedValue) {
+			t.Fatalf("Column.GetValue() failed: value = %v, storedValue = %v",
+				value, storedValue)
+		}
+	}
+}
+
+func testColumnGetValueForVector(t *testing.T, valueType string) {
+	options := NewColumnOptions()
+	options.ColumnType = VectorColumn
+	dirPath, _, db, table, column :=
+		createTempColumn(t, "Table", nil, "Value", valueType, options)
+	defer removeTempDB(t, dirPath, db)
+
+	for i := 0; i < 100; i++ {
+		_, id, err := table.InsertRow(nil)
+		if err != nil {
+			t.Fatalf("Table.InsertRow() failed: %v", err)
+		}
+		value := generateRandomVectorValue(valueType)
+		if err := column.SetValue(id, value); err != nil {
+			t.Fatalf("Column.SetValue() failed: %v", err)
+		}
+		if storedValue, err := column.GetValue(id); err != nil {
+			t.Fatalf("Column.GetValue() failed: %v", err)
+		} else if !reflect.DeepEqual(value, storedValue) {
+			t.Fatalf("Column.GetValue() failed: value = %v, storedValue = %v",
+				value, storedValue)
+		}
+	}
+
+	bytes, _ := db.Query("select Table --limit 3")
+	t.Logf("valueType = <%s>, result = %s", valueType, string(bytes))
+}
+
+func TestColumnGetValueForBool(t *testing.T) {
+	testColumnGetValueForScalar(t, "Bool")
+}
+
+func TestColumnGetValueForInt8(t *testing.T) {
+	testColumnGetValueForScalar(t, "Int8")
+}
+
+func TestColumnGetValueForInt16(t *testing.T) {
+	testColumnGetValueForScalar(t, "Int16")
+}
+
+func TestColumnGetValueForInt32(t *testing.T) {
+	testColumnGetValueForScalar(t, "Int32")
+}
+
+func TestColumnGetValueForInt64(t *testing.T) {
+	testColumnGetValueForScalar(t, "Int64")
+}
+
+func TestColumnGetValueForUInt8(t *testing.T) {
+	testColumnGetValueForScalar(t, "UInt8")
+}
+
+func TestColumnGetValueForUInt16(t *testing.T) {
+	testColumnGetValueForScalar(t, "UInt16")
+}
+
+func TestColumnGetValueForUInt32(t *testing.T) {
+	testColumnGetValueForScalar(t, "UInt32")
+}
+
+func TestColumnGetValueForUInt64(t *testing.T) {
+	testColumnGetValueForScalar(t, "UInt64")
+}
+
+func TestColumnGetValueForFloat(t *testing.T) {
+	testColumnGetValueForScalar(t, "Float")
+}
+
+func TestColumnGetValueForTime(t *testing.T) {
+	testColumnGetValueForScalar(t, "Time")
+}
+
+func TestColumnGetValueForCurrentTime(t *testing.T) {
+	dirPath, _, db, table, column :=
+		createTempColumn(t, "Table", nil, "Value", "Time", nil)
+	defer removeTempDB(t, dirPath, db)
+
+	_, id, err := table.InsertRow(nil)
+	if err != nil {
+		t.Fatalf("Table.InsertRow() failed: %v", err)
+	}
+	value := time.Now()
+	if err := column.SetValue(id, value); err != nil {
+		t.Fatalf("Column.SetValue() failed: %v", err)
+	}
+	storedValue, err := column.GetValue(id)
+	if err != nil {
+		t.Fatalf("Column.GetValue() failed: %v", err)
+	}
+	diff := value.Sub(storedValue.(time.Time))
+	if (diff < 0) || (diff >= time.Microsecond) {
+		t.Fatalf("Column.GetValue() failed: value = %v, storedValue = %v",
+			value, storedValue)
+	}
+
+	if err := column.SetValue(id, time.Time{}); err != nil {
+		t.Fatalf("Column.SetValue() failed: %v", err)
+	}
+	if storedValue, err := column.GetValue(id); err != nil {
+		t.Fatalf("Column.GetValue() failed: %v", err)
+	} else if storedValue.(time.Time).UnixNano() != 0 {
+		t.Fatalf("Column.GetValue() failed: storedValue = %v", storedValue)
+	}
+}
+
+func TestColumnGetValueForTokyoGeoPoint(t *testing.T) {
+	testColumnGetValueForScalar(t, "TokyoGeoPoint")
+}
+
+func TestColumnGetValueForWGS84GeoPoint(t *testing.T) {
+	testColumnGetValueForScalar(t, "WGS84GeoPoint")
+}
+
+func TestColumnGetValueForText(t *testing.T) {
+	testColumnGetValueForScalar(t, "ShortText")
+}
+
+func TestColumnGetValueForBoolVector(t *testing.T) {
+	testColumnGetValueForVector(t, "Bool")
+}
+
+func TestColumnGetValueForInt8Vector(t *testing.T) {
+	testColumnGetValueForVector(t, "Int8")
+}
+
+func TestColumnGetValueForInt16Vector(t *testing.T) {
+	testColumnGetValueForVector(t, "Int16")
+}
+
+func TestColumnGetValueForInt32Vector(t *testing.T) {
+	testColumnGetValueForVector(t, "Int32")
+}
+
+func TestColumnGetValueForInt64Vector(t *testing.T) {
+	testColumnGetValueForVector(t, "Int64")
+}
+
+func TestColumnGetValueForUInt8Vector(t *testing.T) {
+	testColumnGetValueForVector(t, "UInt8")
+}
+
+func TestColumnGetValueForUInt16Vector(t *testing.T) {
+	testColumnGetValueForVector(t, "UInt16")
+}
+
+func TestColumnGetValueForUInt32Vector(t *testing.T) {
+	testColumnGetValueForVector(t, "UInt32")
+}
+
+func TestColumnGetValueForUInt64Vector(t *testing.T) {
+	testColumnGetValueForVector(t, "UInt64")
+}
+
+func TestColumnGetValueForFloatVector(t *testing.T) {
+	testColumnGetValueForVector(t, "Float")
+}
+
+func TestColumnGetValueForTimeVector(t *testing.T) {
+	testColumnGetValueForVector(t, "Time")
+}
+
+func TestColumnGetValueForGeoPointVectorLayout(t *testing.T) {
+	dirPath, _, db, table := createTempTable(t, "Table", nil)
+	defer removeTempDB(t, dirPath, db)
+
+	columnOptions := NewColumnOptions()
+	columnOptions.ColumnType = VectorColumn
+	column, err := table.CreateColumn("Value", "WGS84GeoPoint", columnOptions)
+	if err != nil {
+		t.Fatalf("Table.CreateColumn() failed: %v", err)
+	}
+	_, id, _ := table.InsertRow(nil)
+	value := []GeoPoint{{1, -2}, {2147483647, -2147483648}, {123456789, 987654321}}
+	if err := column.SetValue(id, value); err != nil {
+		t.Fatalf("Column.SetValue() failed: %v", err)
+	}
+	storedValue, err := column.GetValue(id)
+	if err != nil {
+		t.Fatalf("Column.GetValue() failed: %v", err)
+	}
+	if !reflect.DeepEqual(storedValue, value) {
+		t.Fatalf("Column.GetValue() failed: value = %v, storedValue = %v",
+			value, storedValue)
+	}
+}
+
+func TestColumnGetValueForTokyoGeoPointVector(t *testing.T) {
+	testColumnGetValueForVector(t, "TokyoGeoPoint")
+}
+
+func TestColumnGetValueForWGS84GeoPointVector(t *testing.T) {
+	testColumnGetValueForVector(t, "WGS84GeoPoint")
+}
+
+func TestColumnGetValueForTextVector(t *testing.T) {
+	testColumnGetValueForVector(t, "ShortText")
+}
+
+func TestTableNewBatch(t *testing.T) {
+	options := NewTableOptions()
+	options.TableType = PatTable
+	options.KeyType = "ShortText"
+	dirPath, _, db, table, column :=
+		createTempColumn(t, "Table", options, "Value", "Int32", nil)
+	defer removeTempDB(t, dirPath, db)
+
+	batch := table.NewBatch(nil)
+	for i := 0; i < 10; i++ {
+		j, err := batch.InsertRow([]byte(strconv.Itoa(i % 5)))
+		if err != nil {
+			t.Fatalf("WriteBatch.InsertRow() failed: %v", err)
+		}
+		if err := batch.SetValue(j, "Value", int64(i)); err != nil {
+			t.Fatalf("WriteBatch.SetValue() failed: %v", err)
+		}
+	}
+	if batch.Len() != 5 {
+		t.Fatalf("WriteBatch.Len() failed: len = %d", batch.Len())
+	}
+	if n, err := table.Len(); err != nil || n != 0 {
+		t.Fatalf("Table.Len() failed: n = %d, err = %v", n, err)
+	}
+	if n, err := batch.Commit(); err != nil || n != 5 {
+		t.Fatalf("WriteBatch.Commit() failed: n = %d, err = %v", n, err)
+	}
+	if batch.Len() != 0 {
+		t.Fatalf("WriteBatch.Commit() failed: len = %d", batch.Len())
+	}
+	id, ok, err := table.GetIDByKey([]byte("3"))
+	if err != nil || !ok {
+		t.Fatalf("Table.GetIDByKey() failed: ok = %v, err = %v", ok, err)
+	}
+	if value, err := column.GetValue(id); err != nil || value.(int64) != 8 {
+		t.Fatalf("Column.GetValue() failed: value = %v, err = %v", value, err)
+	}
+
+	if _, err := batch.InsertRow([]byte("discarded")); err != nil {
+		t.Fatalf("WriteBatch.InsertRow() failed: %v", err)
+	}
+	batch.Rollback()
+	if n, err := batch.Commit(); err != nil || n != 0 {
+		t.Fatalf("WriteBatch.Commit() failed: n = %d, err = %v", n, err)
+	}
+	if _, ok, _ := table.GetIDByKey([]byte("discarded")); ok {
+		t.Fatalf("WriteBatch.Rollback() failed")
+	}
+	if err := batch.SetValue(0, "Value", int64(1)); err == nil {
+		t.Fatalf("WriteBatch.SetValue() succeeded for an invalid index")
+	}
+	if _, err := batch.InsertRow(nil); err == nil {
+		t.Fatalf("WriteBatch.InsertRow() succeeded without key")
+	}
+}
+
+func TestTableSelect(t *testing.T) {
+	dirPath, _, db, table, column :=
+		createTempColumn(t, "Table", nil, "Value", "Int32", nil)
+	defer removeTempDB(t, dirPath, db)
+
+	for i := 0; i < 100; i++ {
+		_, id, err := table.InsertRow(nil)
+		if err != nil {
+			t.Fatalf("Table.InsertRow() failed: %v", err)
+		}
+		if err := column.SetValue(id, int64(i)); err != nil {
+			t.Fatalf("Column.SetValue() failed: %v", err)
+		}
+	}
+
+	options := NewSelectOptions()
+	options.Filter = "Value >= 50"
+	options.SortBy = "-Value"
+	options.Limit = 5
+	options.OutputColumns = []string{"_id", "Value"}
+	records, err := table.Select(options)
+	if err != nil {
+		t.Fatalf("Table.Select() failed: %v", err)
+	}
+	if records.NHits != 50 {
+		t.Fatalf("Table.Select() failed: NHits = %d", records.NHits)
+	}
+	if records.Len() != 5 {
+		t.Fatalf("Table.Select() failed: Len = %d", records.Len())
+	}
+	if names := records.ColumnNames(); !reflect.DeepEqual(names, []string{"_id", "Value"}) {
+		t.Fatalf("Table.Select() failed: names = %v", names)
+	}
+	for i := 0; i < records.Len(); i++ {
+		value, err := records.GetInt(i, "Value")
+		if err != nil {
+			t.Fatalf("Records.GetInt() failed: %v", err)
+		}
+		if value != int64(99-i) {
+			t.Fatalf("Records.GetInt() failed: i = %d, value = %d", i, value)
+		}
+	}
+	if _, err := records.GetText(0, "Value"); err == nil {
+		t.Fatalf("Records.GetText() succeeded for an Int32 column")
+	}
+	if _, err := records.Get(0, "Unknown"); err == nil {
+		t.Fatalf("Records.Get() succeeded for an unknown column")
+	}
+}
+
+func TestTablePrepareFilter(t *testing.T) {
+	dirPath, _, db, table, column :=
+		createTempColumn(t, "Table", nil, "Value", "Int32", nil)
+	defer removeTempDB(t, dirPath, db)
+
+	for i := 0; i < 100; i++ {
+		_, id, err := table.InsertRow(nil)
+		if err != nil {
+			t.Fatalf("Table.InsertRow() failed: %v", err)
+		}
+		if err := column.SetValue(id, int64(i)); err != nil {
+			t.Fatalf("Column.SetValue() failed: %v", err)
+		}
+	}
+
+	filter, err := table.PrepareFilter("Value >= $min && Value < $max")
+	if err != nil {
+		t.Fatalf("Table.PrepareFilter() failed: %v", err)
+	}
+	defer filter.Close()
+	if names := filter.VarNames(); !reflect.DeepEqual(names, []string{"$min", "$max"}) {
+		t.Fatalf("Filter.VarNames() failed: names = %v", names)
+	}
+	options := NewSelectOptions()
+	options.SortBy = "-Value"
+	options.Limit = 5
+	options.OutputColumns = []string{"_id", "Value"}
+	for _, min := range []int64{10, 50, 90} {
+		args := map[string]interface{}{"$min": min, "$max": min + 20}
+		records, err := filter.Select(args, options)
+		if err != nil {
+			t.Fatalf("Filter.Select() failed: %v", err)
+		}
+		expected := 20
+		if min+20 > 100 {
+			expected = int(100 - min)
+		}
+		if records.NHits != expected {
+			t.Fatalf("Filter.Select() failed: min = %d, NHits = %d", min, records.NHits)
+		}
+		if records.Len() != 5 {
+			t.Fatalf("Filter.Select() failed: min = %d, Len = %d", min, records.Len())
+		}
+		for i := 0; i < records.Len(); i++ {
+			value, err := records.GetInt(i, "Value")
+			if err != nil {
+				t.Fatalf("Records.GetInt() failed: %v", err)
+			}
+			if value != min+int64(expected-1-i) {
+				t.Fatalf("Records.GetInt() failed: i = %d, value = %d", i, value)
+			}
+		}
+	}
+	for _, args := range []map[string]interface{}{
+		{"$min": 10, "$max": 30},
+		{"$min": int8(10), "$max": uint16(30)},
+		{"$min": uint64(10), "$max": int32(30)},
+		{"$min": float32(9.5), "$max": 29.5},
+	} {
+		records, err := filter.Select(args, nil)
+		if err != nil {
+			t.Fatalf("Filter.Select() failed: args = %v, err = %v", args, err)
+		}
+		if records.NHits != 20 {
+			t.Fatalf("Filter.Select() failed: args = %v, NHits = %d",
+				args, records.NHits)
+		}
+	}
+	if _, err := filter.Select(map[string]interface{}{"$min": int64(0)}, nil); err == nil {
+		t.Fatalf("Filter.Select() succeeded with an unbound variable")
+	}
+	args := map[string]interface{}{"$min": int64(0), "$max": int64(1)}
+	for _, name := range []string{"_score", "_nsubrecs"} {
+		options.OutputColumns = []string{"_id", name}
+		if _, err := filter.Select(args, options); err == nil {
+			t.Fatalf("Filter.Select() succeeded with %s", name)
+		}
+	}
+	if err := filter.Close(); err != nil {
+		t.Fatalf("Filter.Close() failed: %v", err)
+	}
+	if _, err := filter.Select(map[string]interface{}{"$min": int64(0), "$max": int64(1)}, nil); err == nil {
+		t.Fatalf("Filter.Select() succeeded after Filter.Close()")
+	}
+}
+
+func TestFilterExpr(t *testing.T) {
+	cases := []struct {
+		expr     FilterExpr
+		expected string
+	}{
+		{Equal("Count", 10), `Count == 10`},
+		{NotEqual("_key", "a\"b\\c"), `_key != "a\"b\\c"`},
+		{GreaterThan("Price", 1.5), `Price > 1.5`},
+		{LessEqual("Date", time.Unix(1234567890, 500000000)), `Date <= 1234567890.500000`},
+		{Equal("Location", GeoPoint{Latitude: 100, Longitude: 200}), `Location == "100x200"`},
+		{Match("Body", []byte("Go")), `Body @ "Go"`},
+		{Prefix("category.name", "Gro"), `category.name @^ "Gro"`},
+		{Suffix("Name", "nga"), `Name @$ "nga"`},
+		{And(Equal("A", true), Or(LessThan("B", -1), GreaterEqual("B", uint8(1)))),
+			`(A == true && (B < -1 || B >= 1))`},
+		{Or(Equal("A", 1)), `A == 1`},
+	}
+	for _, c := range cases {
+		filter, err := c.expr.Build()
+		if err != nil {
+			t.Fatalf("FilterExpr.Build() failed: %v", err)
+		}
+		if filter != c.expected {
+			t.Fatalf("FilterExpr.Build() failed: filter = <%s>, expected = <%s>",
+				filter, c.expected)
+		}
+	}
+
+	for _, expr := range []FilterExpr{
+		Equal("", 1),
+		Equal("A || true", 1),
+		Equal("A", []int{1}),
+		Equal("A", math.NaN()),
+		And(),
+		Or(Equal("A", 1), Equal("1B", 1)),
+	} {
+		if filter, err := expr.Build(); err == nil {
+			t.Fatalf("FilterExpr.Build() succeeded: filter = <%s>", filter)
+		}
+	}
+}
+
+func TestTableSelectWithFilterExpr(t *testing.T) {
+	dirPath, _, db, table, column :=
+		createTempColumn(t, "Table", nil, "Value", "ShortText", nil)
+	defer removeTempDB(t, dirPath, db)
+
+	for _, value := range []string{"Groonga", "Mroonga", "Go \"lang\""} {
+		_, id, err := table.InsertRow(nil)
+		if err != nil {
+			t.Fatalf("Table.InsertRow() failed: %v", err)
+		}
+		if err := column.SetValue(id, []byte(value)); err != nil {
+			t.Fatalf("Column.SetValue() failed: %v", err)
+		}
+	}
+	filter, err := Or(Prefix("Value", "Gr"), Equal("Value", "Go \"lang\"")).Build()
+	if err != nil {
+		t.Fatalf("FilterExpr.Build() failed: %v", err)
+	}
+	options := NewSelectOptions()
+	options.Filter = filter
+	records, err := table.Select(options)
+	if err != nil {
+		t.Fatalf("Table.Select() failed: %v", err)
+	}
+	if records.NHits != 2 {
+		t.Fatalf("Table.Select() failed: NHits = %d", records.NHits)
+	}
+}
+
+func TestTableSelectMatch(t *testing.T) {
+	dirPath, _, db, table, column :=
+		createTempColumn(t, "Table", nil, "Title", "ShortText", nil)
+	defer removeTempDB(t, dirPath, db)
+	body, err := table.CreateColumn("Body", "Text", nil)
+	if err != nil {
+		t.Fatalf("Table.CreateColumn() failed: %v", err)
+	}
+
+	values := [][2]string{
+		{"Groonga", "Groonga is a full-text search engine"},
+		{"Mroonga", "Mroonga is a storage engine based on Groonga"},
+		{"Rroonga", "Rroonga is a Ruby binding"},
+	}
+	for _, value := range values {
+		_, id, err := table.InsertRow(nil)
+		if err != nil {
+			t.Fatalf("Table.InsertRow() failed: %v", err)
+		}
+		if err := column.SetValue(id, []byte(value[0])); err != nil {
+			t.Fatalf("Column.SetValue() failed: %v", err)
+		}
+		if err := body.SetValue(id, []byte(value[1])); err != nil {
+			t.Fatalf("Column.SetValue() failed: %v", err)
+		}
+	}
+
+	options := NewTableOptions()
+	options.TableType = PatTable
+	options.KeyType = "ShortText"
+	options.DefaultTokenizer = "TokenBigram"
+	options.Normalizer = "NormalizerAuto"
+	terms, err := db.CreateTable("Terms", options)
+	if err != nil {
+		t.Fatalf("DB.CreateTable() failed: %v", err)
+	}
+	for _, name := range []string{"Title", "Body"} {
+		columnOptions := NewColumnOptions()
+		columnOptions.ColumnType = IndexColumn
+		columnOptions.Source = name
+		columnOptions.WithPosition = true
+		if _, err := terms.CreateColumn("Table_"+name, "Table", columnOptions); err != nil {
+			t.Fatalf("Table.CreateColumn() failed: %v", err)
+		}
+	}
+	if !column.HasMatchIndex() {
+		t.Fatalf("Column.HasMatchIndex() failed")
+	}
+
+	selectOptions := NewSelectOptions()
+	selectOptions.SortBy = "-_score,_id"
+	selectOptions.OutputColumns = []string{"Title", "_score"}
+	records, err := table.SelectMatch("Groonga", "Title * 10 || Body", selectOptions)
+	if err != nil {
+		t.Fatalf("Table.SelectMatch() failed: %v", err)
+	}
+	if records.NHits != 2 {
+		t.Fatalf("Table.SelectMatch() failed: NHits = %d", records.NHits)
+	}
+	if title, err := records.GetText(0, "Title"); err != nil || string(title) != "Groonga" {
+		t.Fatalf("Records.GetText() failed: title = %s, err = %v", title, err)
+	}
+	score0, err := records.Score(0)
+	if err != nil {
+		t.Fatalf("Records.Score() failed: %v", err)
+	}
+	score1, err := records.Score(1)
+	if err != nil {
+		t.Fatalf("Records.Score() failed: %v", err)
+	}
+	if score0 <= score1 {
+		t.Fatalf("Records.Score() failed: score0 = %v, score1 = %v", score0, score1)
+	}
+	records, err = table.SelectMatch("\"search engine\" -Ruby", "Body", nil)
+	if err != nil {
+		t.Fatalf("Table.SelectMatch() failed: %v", err)
+	}
+	if records.NHits != 1 {
+		t.Fatalf("Table.SelectMatch() failed: NHits = %d", records.NHits)
+	}
+	if score, err := records.Score(0); err != nil || score <= 0.0 {
+		t.Fatalf("Records.Score() failed: score = %v, err = %v", score, err)
+	}
+	if title, err := records.GetText(0, "Title"); err != nil || string(title) != "Groonga" {
+		t.Fatalf("Records.GetText() failed: title = %s, err = %v", title, err)
+	}
+	records, err = table.Select(nil)
+	if err != nil {
+		t.Fatalf("Table.Select() failed: %v", err)
+	}
+	if score, err := records.Score(0); err != nil || score != 0.0 {
+		t.Fatalf("Records.Score() failed: score = %v, err = %v", score, err)
+	}
+	if _, err := records.Score(records.Len()); err == nil {
+		t.Fatalf("Records.Score() succeeded for an out-of-range index")
+	}
+	records, err = table.SelectMatch("Groonga", "scorer_tf_idf(Body)", nil)
+	if err != nil {
+		t.Fatalf("Table.SelectMatch() failed: %v", err)
+	}
+	if records.NHits != 2 {
+		t.Fatalf("Table.SelectMatch() failed: NHits = %d", records.NHits)
+	}
+	var groongaErr *GroongaError
+	if _, err := table.SelectMatch("Groonga", "Unknown", nil); !errors.As(err, &groongaErr) {
+		t.Fatalf("Table.SelectMatch() failed for an unknown column: err = %v", err)
+	}
+	if _, err := table.SelectMatch("Groonga", "Title * x", nil); err == nil {
+		t.Fatalf("Table.SelectMatch() succeeded for an invalid weight")
+	}
+}
+
+func TestTableApply(t *testing.T) {
+	dirPath, _, db, table, column :=
+		createTempColumn(t, "Table", nil, "popularity", "Int32", nil)
+	defer removeTempDB(t, dirPath, db)
+	score, err := table.CreateColumn("score", "Float", nil)
+	if err != nil {
+		t.Fatalf("Table.CreateColumn() failed: %v", err)
+	}
+
+	var ids []uint32
+	for _, value := range []int64{1, 2, 3} {
+		_, id, err := table.InsertRow(nil)
+		if err != nil {
+			t.Fatalf("Table.InsertRow() failed: %v", err)
+		}
+		if err := column.SetValue(id, value); err != nil {
+			t.Fatalf("Column.SetValue() failed: %v", err)
+		}
+		ids = append(ids, id)
+	}
+
+	n, err := table.Apply("score", "popularity * 2")
+	if err != nil {
+		t.Fatalf("Table.Apply() failed: %v", err)
+	}
+	if n != len(ids) {
+		t.Fatalf("Table.Apply() failed: n = %d", n)
+	}
+	for i, id := range ids {
+		value, err := score.GetValue(id)
+		if err != nil {
+			t.Fatalf("Column.GetValue() failed: %v", err)
+		}
+		if value != float64(2*(i+1)) {
+			t.Fatalf("Table.Apply() failed: id = %d, value = %v", id, value)
+		}
+	}
+	if _, err := table.Apply("no_such_column", "popularity"); err == nil {
+		t.Fatalf("Table.Apply() succeeded for a missing column")
+	}
+	if _, err := table.Apply("score", "popularity +"); err == nil {
+		t.Fatalf("Table.Apply() succeeded for an invalid expression")
+	}
+}
+
+func TestTableSelectCount(t *testing.T) {
+	dirPath, _, db, table, column :=
+		createTempColumn(t, "Table", nil, "Value", "Int32", nil)
+	defer removeTempDB(t, dirPath, db)
+
+	for i := 0; i < 20; i++ {
+		_, id, err := table.InsertRow(nil)
+		if err != nil {
+			t.Fatalf("Table.InsertRow() failed: %v", err)
+		}
+		if err := column.SetValue(id, int64(i)); err != nil {
+			t.Fatalf("Column.SetValue() failed: %v", err)
+		}
+	}
+	if n, err := table.SelectCount(nil); err != nil {
+		t.Fatalf("Table.SelectCount() failed: %v", err)
+	} else if n != 20 {
+		t.Fatalf("Table.SelectCount() failed: n = %d", n)
+	}
+	options := NewSelectOptions()
+	options.Filter = "Value >= 5"
+	options.Limit = 1
+	options.Drilldowns = []string{"Value"}
+	if n, err := table.SelectCount(options); err != nil {
+		t.Fatalf("Table.SelectCount() failed: %v", err)
+	} else if n != 15 {
+		t.Fatalf("Table.SelectCount() failed: n = %d", n)
+	}
+	options.Filter = "Value >"
+	if _, err := table.SelectCount(options); err == nil {
+		t.Fatalf("Table.SelectCount() succeeded for an invalid filter")
+	}
+}
+
+func TestTableSelectWithCacheMode(t *testing.T) {
+	dirPath, _, db, table := createTempTable(t, "Table", nil)
+	defer removeTempDB(t, dirPath, db)
+
+	if _, _, err := table.InsertRow(nil); err != nil {
+		t.Fatalf("Table.InsertRow() failed: %v", err)
+	}
+	options := NewSelectOptions()
+	options.CacheMode = ReadCache
+	if records, err := table.Select(options); err != nil {
+		t.Fatalf("Table.Select() failed: %v", err)
+	} else if records.NHits != 1 {
+		t.Fatalf("Table.Select() failed: NHits = %d", records.NHits)
+	}
+	if _, _, err := table.InsertRow(nil); err != nil {
+		t.Fatalf("Table.InsertRow() failed: %v", err)
+	}
+	options.CacheMode = NoCache
+	if records, err := table.Select(options); err != nil {
+		t.Fatalf("Table.Select() failed: %v", err)
+	} else if records.NHits != 2 {
+		t.Fatalf("Table.Select() failed: NHits = %d", records.NHits)
+	}
+	options.CacheMode = CacheMode(100)
+	if _, err := table.Select(options); err == nil {
+		t.Fatalf("Table.Select() succeeded for an undefined cache mode")
+	}
+
+	if err := db.SetCacheLimit(0); err != nil {
+		t.Fatalf("DB.SetCacheLimit() failed: %v", err)
+	}
+	if err := db.SetCacheLimit(100); err != nil {
+		t.Fatalf("DB.SetCacheLimit() failed: %v", err)
+	}
+	if err := db.SetCacheLimit(-1); err == nil {
+		t.Fatalf("DB.SetCacheLimit() succeeded for a negative limit")
+	}
+}
+
+func TestTableSelectWithDrilldowns(t *testing.T) {
+	dirPath, _, db, table, column :=
+		createTempColumn(t, "Table", nil, "Category", "ShortText", nil)
+	defer removeTempDB(t, dirPath, db)
+	rank, err := table.CreateColumn("Rank", "Int32", nil)
+	if err != nil {
+		t.Fatalf("Table.CreateColumn() failed: %v", err)
+	}
+
+	categories := []string{"A", "B", "A", "C", "A", "B"}
+	for i, category := range categories {
+		_, id, err := table.InsertRow(nil)
+		if err != nil {
+			t.Fatalf("Table.InsertRow() failed: %v", err)
+		}
+		if err := column.SetValue(id, []byte(category)); err != nil {
+			t.Fatalf("Column.SetValue() failed: %v", err)
 		}
-		return value
-	case "UInt8":
-		value := make([]int64, size)
-		for i := 0; i < size; i++ {
-			value[i] = int64(uint8(rand.Int()))
+		if err := rank.SetValue(id, int64(i%2)); err != nil {
+			t.Fatalf("Column.SetValue() failed: %v", err)
 		}
-		return value
-	case "UInt16":
-		value := make([]int64, size)
-		for i := 0; i < size; i++ {
-			value[i] = int64(uint16(rand.Int()))
+	}
+
+	options := NewSelectOptions()
+	options.Drilldowns = []string{"Category", "Rank"}
+	records, err := table.Select(options)
+	if err != nil {
+		t.Fatalf("Table.Select() failed: %v", err)
+	}
+	if records.NHits != len(categories) {
+		t.Fatalf("Table.Select() failed: NHits = %d", records.NHits)
+	}
+	drilldowns := records.Drilldowns()
+	expected := []DrilldownEntry{
+		{[]byte("A"), 3, nil}, {[]byte("B"), 2, nil}, {[]byte("C"), 1, nil},
+	}
+	if !reflect.DeepEqual(drilldowns["Category"], expected) {
+		t.Fatalf("Records.Drilldowns() failed: Category = %v", drilldowns["Category"])
+	}
+	expected = []DrilldownEntry{{int64(0), 3, nil}, {int64(1), 3, nil}}
+	if !reflect.DeepEqual(drilldowns["Rank"], expected) {
+		t.Fatalf("Records.Drilldowns() failed: Rank = %v", drilldowns["Rank"])
+	}
+
+	options.Filter = "Rank > 1"
+	records, err = table.Select(options)
+	if err != nil {
+		t.Fatalf("Table.Select() failed: %v", err)
+	}
+	if (records.NHits != 0) || (records.Len() != 0) {
+		t.Fatalf("Table.Select() failed: NHits = %d, Len = %d",
+			records.NHits, records.Len())
+	}
+	for _, key := range options.Drilldowns {
+		if entries, ok := records.Drilldowns()[key]; !ok || (len(entries) != 0) {
+			t.Fatalf("Records.Drilldowns() failed: key = %s, entries = %v", key, entries)
 		}
-		return value
-	case "UInt32":
-		value := make([]int64, size)
-		for i := 0; i < size; i++ {
-			value[i] = int64(rand.Uint32())
+	}
+}
+
+func TestTableSelectWithDrilldownSpecs(t *testing.T) {
+	dirPath, _, db, table, column :=
+		createTempColumn(t, "Table", nil, "Category", "ShortText", nil)
+	defer removeTempDB(t, dirPath, db)
+	price, err := table.CreateColumn("Price", "Int32", nil)
+	if err != nil {
+		t.Fatalf("Table.CreateColumn() failed: %v", err)
+	}
+
+	categories := []string{"A", "B", "A", "C", "A", "B"}
+	prices := []int64{100, 200, 300, 400, 500, 600}
+	for i, category := range categories {
+		_, id, err := table.InsertRow(nil)
+		if err != nil {
+			t.Fatalf("Table.InsertRow() failed: %v", err)
 		}
-		return value
-	case "UInt64":
-		value := make([]int64, size)
-		for i := 0; i < size; i++ {
-			value[i] = rand.Int63()
+		if err := column.SetValue(id, []byte(category)); err != nil {
+			t.Fatalf("Column.SetValue() failed: %v", err)
 		}
-		return value
-	case "Float":
-		value := make([]float64, size)
-		for i := 0; i < size; i++ {
-			value[i] = rand.Float64()
+		if err := price.SetValue(id, prices[i]); err != nil {
+			t.Fatalf("Column.SetValue() failed: %v", err)
 		}
-		return value
-	case "TokyoGeoPoint", "WGS84GeoPoint":
-		const (
-			MinLatitude  = 73531000
-			MaxLatitude  = 164006000
-			MinLongitude = 439451000
-			MaxLongitude = 554351000
-		)
-		value := make([]GeoPoint, size)
-		for i := 0; i < size; i++ {
-			latitude := MinLatitude + rand.Intn(MaxLatitude-MinLatitude+1)
-			longitude := MinLongitude + rand.Intn(MaxLongitude-MinLongitude+1)
-			value[i] = GeoPoint{int32(latitude), int32(longitude)}
+	}
+
+	options := NewSelectOptions()
+	options.DrilldownSpecs = []DrilldownSpec{{
+		Key:        "Category",
+		CalcTarget: "Price",
+		CalcTypes:  []CalcType{SumCalc, AvgCalc, MaxCalc, MinCalc},
+	}}
+	records, err := table.Select(options)
+	if err != nil {
+		t.Fatalf("Table.Select() failed: %v", err)
+	}
+	expected := []DrilldownEntry{
+		{[]byte("A"), 3, map[string]float64{
+			"_sum": 900, "_avg": 300, "_max": 500, "_min": 100}},
+		{[]byte("B"), 2, map[string]float64{
+			"_sum": 800, "_avg": 400, "_max": 600, "_min": 200}},
+		{[]byte("C"), 1, map[string]float64{
+			"_sum": 400, "_avg": 400, "_max": 400, "_min": 400}},
+	}
+	drilldowns := records.Drilldowns()
+	if !reflect.DeepEqual(drilldowns["Category"], expected) {
+		t.Fatalf("Records.Drilldowns() failed: Category = %v", drilldowns["Category"])
+	}
+
+	options.DrilldownSpecs = []DrilldownSpec{
+		{Label: "by_category", Key: "Category"},
+		{Label: "by_price", Key: "Price", CalcTarget: "Price",
+			CalcTypes: []CalcType{SumCalc}},
+	}
+	if records, err = table.Select(options); err != nil {
+		t.Fatalf("Table.Select() failed: %v", err)
+	}
+	drilldowns = records.Drilldowns()
+	expected = []DrilldownEntry{
+		{[]byte("A"), 3, nil},
+		{[]byte("B"), 2, nil},
+		{[]byte("C"), 1, nil},
+	}
+	if !reflect.DeepEqual(drilldowns["by_category"], expected) {
+		t.Fatalf("Records.Drilldowns() failed: by_category = %v",
+			drilldowns["by_category"])
+	}
+	if len(drilldowns["by_price"]) != len(prices) {
+		t.Fatalf("Records.Drilldowns() failed: by_price = %v",
+			drilldowns["by_price"])
+	}
+	for i, entry := range drilldowns["by_price"] {
+		if (entry.Count != 1) ||
+			(entry.Aggregates["_sum"] != float64(prices[i])) {
+			t.Fatalf("Records.Drilldowns() failed: by_price = %v",
+				drilldowns["by_price"])
 		}
-		return value
-	case "ShortText":
-		value := make([][]byte, size)
-		for i := 0; i < size; i++ {
-			value[i] = []byte(strconv.Itoa(rand.Int()))
+	}
+
+	options.DrilldownSpecs = options.DrilldownSpecs[:1]
+	for _, label := range []string{"a]b", "a].keys --load_table X", "a b"} {
+		options.DrilldownSpecs[0].Label = label
+		if _, err := table.Select(options); err == nil {
+			t.Fatalf("Table.Select() succeeded with label = <%s>", label)
+		}
+	}
+	options.DrilldownSpecs[0].Label = "Category"
+	options.Drilldowns = []string{"Category"}
+	if _, err := table.Select(options); err == nil {
+		t.Fatalf("Table.Select() succeeded with a duplicate label")
+	}
+	options.Drilldowns = nil
+	options.DrilldownSpecs[0].CalcTarget = ""
+	options.DrilldownSpecs[0].CalcTypes = []CalcType{SumCalc}
+	if _, err := table.Select(options); err == nil {
+		t.Fatalf("Table.Select() succeeded without CalcTarget")
+	}
+}
+
+func TestColumnGetTextInto(t *testing.T) {
+	dirPath, _, db, table, column :=
+		createTempColumn(t, "Table", nil, "Value", "Text", nil)
+	defer removeTempDB(t, dirPath, db)
+
+	values := []string{"abc", "", strings.Repeat("x", 100), "de"}
+	ids := make([]uint32, len(values))
+	for i, value := range values {
+		_, id, err := table.InsertRow(nil)
+		if err != nil {
+			t.Fatalf("Table.InsertRow() failed: %v", err)
+		}
+		if err := column.SetValue(id, []byte(value)); err != nil {
+			t.Fatalf("Column.SetValue() failed: %v", err)
+		}
+		ids[i] = id
+	}
+	buf := make([]byte, 0, 8)
+	for i, id := range ids {
+		value, err := column.GetTextInto(id, buf)
+		if err != nil {
+			t.Fatalf("Column.GetTextInto() failed: %v", err)
+		}
+		if string(value) != values[i] {
+			t.Fatalf("Column.GetTextInto() failed: value = %s", value)
+		}
+		if (len(values[i]) <= 8) && (cap(value) != 8) {
+			t.Fatalf("Column.GetTextInto() did not reuse the buffer: cap = %d",
+				cap(value))
+		}
+	}
+	if value, err := column.GetTextInto(ids[0], nil); err != nil {
+		t.Fatalf("Column.GetTextInto() failed: %v", err)
+	} else if string(value) != values[0] {
+		t.Fatalf("Column.GetTextInto() failed: value = %s", value)
+	}
+
+	intColumn, err := table.CreateColumn("Int", "Int32", nil)
+	if err != nil {
+		t.Fatalf("Table.CreateColumn() failed: %v", err)
+	}
+	if _, err := intColumn.GetTextInto(ids[0], buf); err == nil {
+		t.Fatalf("Column.GetTextInto() succeeded for an Int32 column")
+	}
+}
+
+func TestColumnGetUint(t *testing.T) {
+	dirPath, _, db, table, column :=
+		createTempColumn(t, "Table", nil, "Value", "UInt64", nil)
+	defer removeTempDB(t, dirPath, db)
+
+	_, id, err := table.InsertRow(nil)
+	if err != nil {
+		t.Fatalf("Table.InsertRow() failed: %v", err)
+	}
+	if err := column.SetValue(id, uint64(0xFFFFFFFFFFFFFFFF)); err != nil {
+		t.Fatalf("Column.SetValue() failed: %v", err)
+	}
+	value, err := column.GetUint(id)
+	if err != nil {
+		t.Fatalf("Column.GetUint() failed: %v", err)
+	}
+	if value != 0xFFFFFFFFFFFFFFFF {
+		t.Fatalf("Column.GetUint() failed: value = %d", value)
+	}
+
+	signed, err := table.CreateColumn("Signed", "Int64", nil)
+	if err != nil {
+		t.Fatalf("Table.CreateColumn() failed: %v", err)
+	}
+	if _, err := signed.GetUint(id); err == nil {
+		t.Fatalf("Column.GetUint() succeeded for an Int64 column")
+	}
+}
+
+func TestColumnGetGeoPointDegrees(t *testing.T) {
+	dirPath, _, db, table, column :=
+		createTempColumn(t, "Table", nil, "Location", "WGS84GeoPoint", nil)
+	defer removeTempDB(t, dirPath, db)
+	vectorOptions := NewColumnOptions()
+	vectorOptions.ColumnType = VectorColumn
+	locations, err := table.CreateColumn("Locations", "WGS84GeoPoint", vectorOptions)
+	if err != nil {
+		t.Fatalf("Table.CreateColumn() failed: %v", err)
+	}
+
+	_, id, err := table.InsertRow(nil)
+	if err != nil {
+		t.Fatalf("Table.InsertRow() failed: %v", err)
+	}
+	// Tokyo Station: 35.681382, 139.766084.
+	tokyo := GeoPoint{128452975, 503157902}
+	if err := column.SetValue(id, tokyo); err != nil {
+		t.Fatalf("Column.SetValue() failed: %v", err)
+	}
+	lat, lng, err := column.GetGeoPointDegrees(id)
+	if err != nil {
+		t.Fatalf("Column.GetGeoPointDegrees() failed: %v", err)
+	}
+	if (math.Abs(lat-35.681381944) > 1e-9) || (math.Abs(lng-139.766083889) > 1e-9) {
+		t.Fatalf("Column.GetGeoPointDegrees() failed: lat = %v, lng = %v", lat, lng)
+	}
+
+	if err := locations.SetValue(id, []GeoPoint{tokyo, {-180000, 360000}}); err != nil {
+		t.Fatalf("Column.SetValue() failed: %v", err)
+	}
+	lats, lngs, err := locations.GetGeoPointDegreesVector(id)
+	if err != nil {
+		t.Fatalf("Column.GetGeoPointDegreesVector() failed: %v", err)
+	}
+	if (len(lats) != 2) || (len(lngs) != 2) || (lats[0] != lat) || (lngs[0] != lng) ||
+		(lats[1] != -0.05) || (lngs[1] != 0.1) {
+		t.Fatalf("Column.GetGeoPointDegreesVector() failed: lats = %v, lngs = %v",
+			lats, lngs)
+	}
+
+	if _, _, err := locations.GetGeoPointDegrees(id); err == nil {
+		t.Fatalf("Column.GetGeoPointDegrees() succeeded for a vector column")
+	}
+	if _, _, err := column.GetGeoPointDegreesVector(id); err == nil {
+		t.Fatalf("Column.GetGeoPointDegreesVector() succeeded for a scalar column")
+	}
+	if _, _, err := column.GetGeoPointDegrees(id + 1); !errors.Is(err, ErrRowNotFound) {
+		t.Fatalf("Column.GetGeoPointDegrees() failed: err = %v", err)
+	}
+}
+
+func TestColumnGetUintVector(t *testing.T) {
+	options := NewColumnOptions()
+	options.ColumnType = VectorColumn
+	dirPath, _, db, table, column :=
+		createTempColumn(t, "Table", nil, "Value", "UInt64", options)
+	defer removeTempDB(t, dirPath, db)
+
+	_, id, err := table.InsertRow(nil)
+	if err != nil {
+		t.Fatalf("Table.InsertRow() failed: %v", err)
+	}
+	values := []uint64{0, 1 << 63, 0xFFFFFFFFFFFFFFFE, 0xFFFFFFFFFFFFFFFF}
+	if err := column.SetValue(id, values); err != nil {
+		t.Fatalf("Column.SetValue() failed: %v", err)
+	}
+	stored, err := column.GetUintVector(id)
+	if err != nil {
+		t.Fatalf("Column.GetUintVector() failed: %v", err)
+	}
+	if !reflect.DeepEqual(stored, values) {
+		t.Fatalf("Column.GetUintVector() failed: values = %v", stored)
+	}
+	if _, err := column.GetUint(id); err == nil {
+		t.Fatalf("Column.GetUint() succeeded for a vector column")
+	}
+}
+
+func TestColumnSetValueWithConvertedValue(t *testing.T) {
+	dirPath, _, db, table, column :=
+		createTempColumn(t, "Table", nil, "Value", "Int32", nil)
+	defer removeTempDB(t, dirPath, db)
+	floatColumn, err := table.CreateColumn("Float", "Float", nil)
+	if err != nil {
+		t.Fatalf("Table.CreateColumn() failed: %v", err)
+	}
+
+	_, id, err := table.InsertRow(nil)
+	if err != nil {
+		t.Fatalf("Table.InsertRow() failed: %v", err)
+	}
+	for _, value := range []interface{}{42, int16(-7), uint(100), int32(math.MaxInt32)} {
+		if err := column.SetValue(id, value); err != nil {
+			t.Fatalf("Column.SetValue() failed: value = %v, err = %v", value, err)
+		}
+	}
+	if value, err := column.GetValue(id); err != nil || value.(int64) != math.MaxInt32 {
+		t.Fatalf("Column.GetValue() failed: value = %v, err = %v", value, err)
+	}
+	for _, value := range []interface{}{math.MaxInt32 + 1, int64(math.MinInt32 - 1), uint64(1 << 63)} {
+		if err := column.SetValue(id, value); err == nil {
+			t.Fatalf("Column.SetValue() succeeded for an out-of-range value: value = %v", value)
+		}
+	}
+	if err := floatColumn.SetValue(id, float32(1.5)); err != nil {
+		t.Fatalf("Column.SetValue() failed: %v", err)
+	}
+	if value, err := floatColumn.GetValue(id); err != nil || value.(float64) != 1.5 {
+		t.Fatalf("Column.GetValue() failed: value = %v, err = %v", value, err)
+	}
+}
+
+func TestColumnHasValue(t *testing.T) {
+	dirPath, _, db, table, column :=
+		createTempColumn(t, "Table", nil, "Value", "ShortText", nil)
+	defer removeTempDB(t, dirPath, db)
+	options := NewColumnOptions()
+	options.ColumnType = VectorColumn
+	vector, err := table.CreateColumn("Vector", "Int32", options)
+	if err != nil {
+		t.Fatalf("Table.CreateColumn() failed: %v", err)
+	}
+
+	_, id, err := table.InsertRow(nil)
+	if err != nil {
+		t.Fatalf("Table.InsertRow() failed: %v", err)
+	}
+	for _, c := range []*Column{column, vector} {
+		if hasValue, err := c.HasValue(id); err != nil {
+			t.Fatalf("Column.HasValue() failed: %v", err)
+		} else if hasValue {
+			t.Fatalf("Column.HasValue() failed: name = %s, hasValue = %v",
+				c.name, hasValue)
+		}
+	}
+	if err := column.SetValue(id, []byte("abc")); err != nil {
+		t.Fatalf("Column.SetValue() failed: %v", err)
+	}
+	if err := vector.SetValue(id, []int64{0}); err != nil {
+		t.Fatalf("Column.SetValue() failed: %v", err)
+	}
+	for _, c := range []*Column{column, vector} {
+		if hasValue, err := c.HasValue(id); err != nil {
+			t.Fatalf("Column.HasValue() failed: %v", err)
+		} else if !hasValue {
+			t.Fatalf("Column.HasValue() failed: name = %s, hasValue = %v",
+				c.name, hasValue)
+		}
+	}
+	if _, err := column.HasValue(id + 1); err == nil {
+		t.Fatalf("Column.HasValue() succeeded for a missing row")
+	}
+}
+
+func TestColumnClearValue(t *testing.T) {
+	dirPath, _, db, table, column :=
+		createTempColumn(t, "Table", nil, "Value", "Int32", nil)
+	defer removeTempDB(t, dirPath, db)
+	textColumn, err := table.CreateColumn("Text", "ShortText", nil)
+	if err != nil {
+		t.Fatalf("Table.CreateColumn() failed: %v", err)
+	}
+	columnOptions := NewColumnOptions()
+	columnOptions.ColumnType = VectorColumn
+	vectorColumn, err := table.CreateColumn("Vector", "ShortText", columnOptions)
+	if err != nil {
+		t.Fatalf("Table.CreateColumn() failed: %v", err)
+	}
+
+	_, id, err := table.InsertRow(nil)
+	if err != nil {
+		t.Fatalf("Table.InsertRow() failed: %v", err)
+	}
+	if err := column.SetValue(id, int64(123)); err != nil {
+		t.Fatalf("Column.SetValue() failed: %v", err)
+	}
+	if err := textColumn.SetValue(id, []byte("abc")); err != nil {
+		t.Fatalf("Column.SetValue() failed: %v", err)
+	}
+	if err := vectorColumn.SetValue(id, [][]byte{[]byte("abc")}); err != nil {
+		t.Fatalf("Column.SetValue() failed: %v", err)
+	}
+
+	if err := column.ClearValue(id); err != nil {
+		t.Fatalf("Column.ClearValue() failed: %v", err)
+	}
+	if err := textColumn.SetValue(id, nil); err != nil {
+		t.Fatalf("Column.SetValue() failed: %v", err)
+	}
+	if err := vectorColumn.ClearValue(id); err != nil {
+		t.Fatalf("Column.ClearValue() failed: %v", err)
+	}
+	if value, err := column.GetValue(id); err != nil || value.(int64) != 0 {
+		t.Fatalf("Column.GetValue() failed: value = %v, err = %v", value, err)
+	}
+	if value, err := textColumn.GetValue(id); err != nil || len(value.([]byte)) != 0 {
+		t.Fatalf("Column.GetValue() failed: value = %v, err = %v", value, err)
+	}
+	if value, err := vectorColumn.GetValue(id); err != nil || len(value.([][]byte)) != 0 {
+		t.Fatalf("Column.GetValue() failed: value = %v, err = %v", value, err)
+	}
+}
+
+func TestTableSelectWithSortKeys(t *testing.T) {
+	dirPath, _, db, table, column :=
+		createTempColumn(t, "Table", nil, "Group", "Int32", nil)
+	defer removeTempDB(t, dirPath, db)
+	timeColumn, err := table.CreateColumn("Time", "Time", nil)
+	if err != nil {
+		t.Fatalf("Table.CreateColumn() failed: %v", err)
+	}
+
+	for i := 0; i < 6; i++ {
+		_, id, err := table.InsertRow(nil)
+		if err != nil {
+			t.Fatalf("Table.InsertRow() failed: %v", err)
+		}
+		if err := column.SetValue(id, int64(i%2)); err != nil {
+			t.Fatalf("Column.SetValue() failed: %v", err)
+		}
+		if err := timeColumn.SetValue(id, time.Unix(int64(i), 0)); err != nil {
+			t.Fatalf("Column.SetValue() failed: %v", err)
+		}
+	}
+
+	options := NewSelectOptions()
+	options.SortKeys = []SortKey{{"Group", false}, {"Time", true}}
+	options.OutputColumns = []string{"_id"}
+	records, err := table.Select(options)
+	if err != nil {
+		t.Fatalf("Table.Select() failed: %v", err)
+	}
+	expected := []int64{5, 3, 1, 6, 4, 2}
+	for i, id := range expected {
+		if value, err := records.GetInt(i, "_id"); err != nil || value != id {
+			t.Fatalf("Records.GetInt() failed: i = %d, value = %d, err = %v", i, value, err)
+		}
+	}
+	options.SortKeys = []SortKey{{"Group", true}, {"_id", true}}
+	if records, err = table.Select(options); err != nil {
+		t.Fatalf("Table.Select() failed: %v", err)
+	}
+	expected = []int64{6, 4, 2, 5, 3, 1}
+	for i, id := range expected {
+		if value, err := records.GetInt(i, "_id"); err != nil || value != id {
+			t.Fatalf("Records.GetInt() failed: i = %d, value = %d, err = %v", i, value, err)
 		}
-		return value
-	default:
-		return nil
+	}
+	options.SortKeys = []SortKey{{"Unknown", false}}
+	if _, err := table.Select(options); err == nil {
+		t.Fatalf("Table.Select() succeeded for an unknown sort key")
+	}
+	options.SortKeys = []SortKey{{"Group,Time", false}}
+	if _, err := table.Select(options); err == nil {
+		t.Fatalf("Table.Select() succeeded for an invalid sort key")
+	}
+	options.SortKeys = []SortKey{{"Group", false}}
+	options.SortBy = "Group"
+	if _, err := table.Select(options); err == nil {
+		t.Fatalf("Table.Select() succeeded with both SortBy and SortKeys")
 	}
 }
 
-func testColumnSetValueForScalar(t *testing.T, valueType string) {
-	dirPath, _, db, table, column :=
-		createTempColumn(t, "Table", nil, "Value", valueType, nil)
+func TestTableSelectWithSortKeysByKey(t *testing.T) {
+	options := NewTableOptions()
+	options.TableType = PatTable
+	options.KeyType = "ShortText"
+	dirPath, _, db, table := createTempTable(t, "Table", options)
 	defer removeTempDB(t, dirPath, db)
 
-	for i := 0; i < 100; i++ {
-		_, id, err := table.InsertRow(nil)
-		if err != nil {
+	keys := []string{"b", "c", "a"}
+	for _, key := range keys {
+		if _, _, err := table.InsertRow([]byte(key)); err != nil {
 			t.Fatalf("Table.InsertRow() failed: %v", err)
 		}
-		if err := column.SetValue(id, generateRandomValue(valueType)); err != nil {
-			t.Fatalf("Column.SetValue() failed: %v", err)
+	}
+	selectOptions := NewSelectOptions()
+	selectOptions.SortKeys = []SortKey{{"_key", true}}
+	selectOptions.OutputColumns = []string{"_key"}
+	records, err := table.Select(selectOptions)
+	if err != nil {
+		t.Fatalf("Table.Select() failed: %v", err)
+	}
+	for i, expected := range []string{"c", "b", "a"} {
+		if key, err := records.GetText(i, "_key"); err != nil || string(key) != expected {
+			t.Fatalf("Records.GetText() failed: i = %d, key = %s, err = %v", i, key, err)
 		}
 	}
-
-	bytes, _ := db.Query("select Table --limit 3")
-	t.Logf("valueType = <%s>, result = %s", valueType, string(bytes))
 }
 
-func testColumnSetValueForVector(t *testing.T, valueType string) {
-	options := NewColumnOptions()
-	options.ColumnType = VectorColumn
+func TestTableSelectCursor(t *testing.T) {
 	dirPath, _, db, table, column :=
-		createTempColumn(t, "Table", nil, "Value", valueType, options)
+		createTempColumn(t, "Table", nil, "Value", "Int32", nil)
 	defer removeTempDB(t, dirPath, db)
 
-	for i := 0; i < 100; i++ {
+	const numRows = selectCursorPageSize*2 + 500
+	for i := 0; i < numRows; i++ {
 		_, id, err := table.InsertRow(nil)
 		if err != nil {
 			t.Fatalf("Table.InsertRow() failed: %v", err)
 		}
-		if err := column.SetValue(id, generateRandomVectorValue(valueType)); err != nil {
+		if err := column.SetValue(id, int64(i)); err != nil {
 			t.Fatalf("Column.SetValue() failed: %v", err)
 		}
 	}
 
-	bytes, _ := db.Query("select Table --limit 3")
-	t.Logf("valueType = <%s>, result = %s", valueType, string(bytes))
-}
-
-func TestColumnSetValueForBool(t *testing.T) {
-	testColumnSetValueForScalar(t, "Bool")
-}
-
-func TestColumnSetValueForInt8(t *testing.T) {
-	testColumnSetValueForScalar(t, "Int8")
-}
-
-func TestColumnSetValueForInt16(t *testing.T) {
-	testColumnSetValueForScalar(t, "Int16")
-}
-
-func TestColumnSetValueForInt32(t *testing.T) {
-	testColumnSetValueForScalar(t, "Int32")
-}
-
-func TestColumnSetValueForInt64(t *testing.T) {
-	testColumnSetValueForScalar(t, "Int64")
-}
-
-func TestColumnSetValueForUInt8(t *testing.T) {
-	testColumnSetValueForScalar(t, "UInt8")
-}
-
-func TestColumnSetValueForUInt16(t *testing.T) {
-	testColumnSetValueForScalar(t, "UInt16")
-}
-
-func TestColumnSetValueForUInt32(t *testing.T) {
-	testColumnSetValueForScalar(t, "UInt32")
-}
+	options := NewSelectOptions()
+	options.SortBy = "Value"
+	options.Offset = 10
+	options.Limit = -1
+	options.OutputColumns = []string{"_id", "Value"}
+	cursor, err := table.SelectCursor(options)
+	if err != nil {
+		t.Fatalf("Table.SelectCursor() failed: %v", err)
+	}
+	defer cursor.Close()
+	if cursor.NHits() != numRows {
+		t.Fatalf("SelectCursor.NHits() failed: NHits = %d", cursor.NHits())
+	}
+	count := 0
+	for cursor.Next() {
+		var id uint32
+		var value int64
+		if err := cursor.Scan(&id, &value); err != nil {
+			t.Fatalf("SelectCursor.Scan() failed: %v", err)
+		}
+		if value != int64(count+10) {
+			t.Fatalf("SelectCursor.Scan() failed: count = %d, value = %d", count, value)
+		}
+		count++
+	}
+	if err := cursor.Err(); err != nil {
+		t.Fatalf("SelectCursor.Err() failed: %v", err)
+	}
+	if count != numRows-10 {
+		t.Fatalf("SelectCursor.Next() failed: count = %d", count)
+	}
 
-func TestColumnSetValueForUInt64(t *testing.T) {
-	testColumnSetValueForScalar(t, "UInt64")
+	options.Limit = selectCursorPageSize + 1
+	cursor, err = table.SelectCursor(options)
+	if err != nil {
+		t.Fatalf("Table.SelectCursor() failed: %v", err)
+	}
+	for count = 0; cursor.Next(); count++ {
+		if count == 5 {
+			cursor.Close()
+		}
+	}
+	if count != 6 {
+		t.Fatalf("SelectCursor.Close() failed: count = %d", count)
+	}
 }
 
-func TestColumnSetValueForFloat(t *testing.T) {
-	testColumnSetValueForScalar(t, "Float")
-}
+func TestRecordsColumns(t *testing.T) {
+	options := NewTableOptions()
+	options.TableType = PatTable
+	options.KeyType = "ShortText"
+	dirPath, _, db, table := createTempTable(t, "Table", options)
+	defer removeTempDB(t, dirPath, db)
 
-func TestColumnSetValueForTokyoGeoPoint(t *testing.T) {
-	testColumnSetValueForScalar(t, "TokyoGeoPoint")
+	columnOptions := NewColumnOptions()
+	columnOptions.ColumnType = VectorColumn
+	if _, err := table.CreateColumn("Point", "WGS84GeoPoint", nil); err != nil {
+		t.Fatalf("Table.CreateColumn() failed: %v", err)
+	}
+	if _, err := table.CreateColumn("Refs", "Table", columnOptions); err != nil {
+		t.Fatalf("Table.CreateColumn() failed: %v", err)
+	}
+	if _, _, err := table.InsertRow([]byte("Key")); err != nil {
+		t.Fatalf("Table.InsertRow() failed: %v", err)
+	}
+	selectOptions := NewSelectOptions()
+	selectOptions.OutputColumns = []string{"_key", "Point", "Refs"}
+	records, err := table.Select(selectOptions)
+	if err != nil {
+		t.Fatalf("Table.Select() failed: %v", err)
+	}
+	columns := []ColumnInfo{
+		{"_key", ShortText}, {"Point", WGS84GeoPoint}, {"Refs", Void}}
+	if !reflect.DeepEqual(records.Columns(), columns) {
+		t.Fatalf("Records.Columns() failed: columns = %v", records.Columns())
+	}
 }
 
-func TestColumnSetValueForWGS84GeoPoint(t *testing.T) {
-	testColumnSetValueForScalar(t, "WGS84GeoPoint")
-}
+func TestRecordsScan(t *testing.T) {
+	options := NewTableOptions()
+	options.TableType = PatTable
+	options.KeyType = "ShortText"
+	dirPath, _, db, table := createTempTable(t, "Table", options)
+	defer removeTempDB(t, dirPath, db)
 
-func TestColumnSetValueForText(t *testing.T) {
-	testColumnSetValueForScalar(t, "ShortText")
-}
+	columns := map[string]string{
+		"Count": "Int32", "Point": "WGS84GeoPoint", "Time": "Time"}
+	for name, valueType := range columns {
+		if _, err := table.CreateColumn(name, valueType, nil); err != nil {
+			t.Fatalf("Table.CreateColumn() failed: %v", err)
+		}
+	}
+	_, id, err := table.InsertRow([]byte("Key"))
+	if err != nil {
+		t.Fatalf("Table.InsertRow() failed: %v", err)
+	}
+	values := map[string]interface{}{
+		"Count": int64(123),
+		"Point": GeoPoint{130000000, 500000000},
+		"Time":  time.Unix(1435312000, 123456000),
+	}
+	for name, value := range values {
+		column, err := table.FindColumn(name)
+		if err != nil {
+			t.Fatalf("Table.FindColumn() failed: %v", err)
+		}
+		if err := column.SetValue(id, value); err != nil {
+			t.Fatalf("Column.SetValue() failed: %v", err)
+		}
+	}
 
-func TestColumnSetValueForBoolVector(t *testing.T) {
-	testColumnSetValueForVector(t, "Bool")
-}
+	type Record struct {
+		Key     string    `grngo:"_key"`
+		Count   int       `grngo:"Count"`
+		Point   GeoPoint  `grngo:"Point"`
+		Time    time.Time `grngo:"Time"`
+		Ignored string    `grngo:"-"`
+		Unknown float64
+		private int
+	}
+	selectOptions := NewSelectOptions()
+	selectOptions.OutputColumns = []string{"_key", "Count", "Point", "Time"}
+	records, err := table.Select(selectOptions)
+	if err != nil {
+		t.Fatalf("Table.Select() failed: %v", err)
+	}
+	var result []Record
+	if err := records.Scan(&result); err != nil {
+		t.Fatalf("Records.Scan() failed: %v", err)
+	}
+	if len(result) != 1 {
+		t.Fatalf("Records.Scan() failed: len = %d", len(result))
+	}
+	record := result[0]
+	if (record.Key != "Key") || (record.Count != 123) ||
+		(record.Point != values["Point"]) ||
+		!record.Time.Equal(values["Time"].(time.Time)) {
+		t.Fatalf("Records.Scan() failed: record = %+v", record)
+	}
 
-func TestColumnSetValueForInt8Vector(t *testing.T) {
-	testColumnSetValueForVector(t, "Int8")
+	var mismatch []struct {
+		Key bool `grngo:"_key"`
+	}
+	if err := records.Scan(&mismatch); err == nil {
+		t.Fatalf("Records.Scan() succeeded for a type mismatch")
+	}
 }
 
-func TestColumnSetValueForInt16Vector(t *testing.T) {
-	testColumnSetValueForVector(t, "Int16")
+func TestNewGeoPointFromDegrees(t *testing.T) {
+	point, err := NewGeoPointFromDegrees(35.681, 139.767)
+	if err != nil {
+		t.Fatalf("NewGeoPointFromDegrees() failed: %v", err)
+	}
+	if (point.Latitude != 128451600) || (point.Longitude != 503161200) {
+		t.Fatalf("NewGeoPointFromDegrees() failed: point = %+v", point)
+	}
+	lat, lng := point.Degrees()
+	const resolution = 1.0 / (60 * 60 * 1000)
+	if (math.Abs(lat-35.681) > resolution) || (math.Abs(lng-139.767) > resolution) {
+		t.Fatalf("GeoPoint.Degrees() failed: lat = %v, lng = %v", lat, lng)
+	}
+	for _, degrees := range [][2]float64{
+		{90.5, 0}, {-90.5, 0}, {0, 180.5}, {0, -180.5}, {math.NaN(), 0},
+	} {
+		if _, err := NewGeoPointFromDegrees(degrees[0], degrees[1]); err == nil {
+			t.Fatalf("NewGeoPointFromDegrees() succeeded for out of range: %v",
+				degrees)
+		}
+	}
 }
 
-func TestColumnSetValueForInt32Vector(t *testing.T) {
-	testColumnSetValueForVector(t, "Int32")
+func TestGeoPointDistanceTo(t *testing.T) {
+	// 1 degree of latitude.
+	a := GeoPoint{35 * 3600 * 1000, 139 * 3600 * 1000}
+	b := GeoPoint{36 * 3600 * 1000, 139 * 3600 * 1000}
+	if d := a.DistanceTo(b); math.Abs(d-110955.87) > 0.01 {
+		t.Fatalf("GeoPoint.DistanceTo() failed: d = %v", d)
+	}
+	if d := a.DistanceTo(a); d != 0 {
+		t.Fatalf("GeoPoint.DistanceTo() failed: d = %v", d)
+	}
+	if d1, d2 := a.DistanceTo(b), b.DistanceTo(a); d1 != d2 {
+		t.Fatalf("GeoPoint.DistanceTo() failed: d1 = %v, d2 = %v", d1, d2)
+	}
 }
 
-func TestColumnSetValueForInt64Vector(t *testing.T) {
-	testColumnSetValueForVector(t, "Int64")
+func TestParseGeoPoint(t *testing.T) {
+	for _, pair := range []struct {
+		s     string
+		point GeoPoint
+	}{
+		{"128452975x503157902", GeoPoint{128452975, 503157902}},
+		{"128452975,503157902", GeoPoint{128452975, 503157902}},
+		{"-1x-2", GeoPoint{-1, -2}},
+		{"35.681,139.767", GeoPoint{128451600, 503161200}},
+		{"35.681x139.767", GeoPoint{128451600, 503161200}},
+	} {
+		point, err := ParseGeoPoint(pair.s)
+		if err != nil {
+			t.Fatalf("ParseGeoPoint() failed: s = %s, err = %v", pair.s, err)
+		}
+		if point != pair.point {
+			t.Fatalf("ParseGeoPoint() failed: s = %s, point = %+v", pair.s, point)
+		}
+	}
+	for _, s := range []string{"", "128452975", "ax1", "1x", "91.0,0.0"} {
+		if _, err := ParseGeoPoint(s); err == nil {
+			t.Fatalf("ParseGeoPoint() succeeded for invalid input: s = %s", s)
+		}
+	}
+	point := GeoPoint{128452975, 503157902}
+	if s := point.String(); s != "128452975x503157902" {
+		t.Fatalf("GeoPoint.String() failed: s = %s", s)
+	}
+	if parsed, err := ParseGeoPoint(point.String()); err != nil || parsed != point {
+		t.Fatalf("ParseGeoPoint() failed: point = %+v, err = %v", parsed, err)
+	}
 }
 
-func TestColumnSetValueForUInt8Vector(t *testing.T) {
-	testColumnSetValueForVector(t, "UInt8")
-}
+func TestTableSelectNearby(t *testing.T) {
+	dirPath, _, db, table := createTempTable(t, "Table", nil)
+	defer removeTempDB(t, dirPath, db)
 
-func TestColumnSetValueForUInt16Vector(t *testing.T) {
-	testColumnSetValueForVector(t, "UInt16")
+	column, err := table.CreateColumn("Point", "WGS84GeoPoint", nil)
+	if err != nil {
+		t.Fatalf("Table.CreateColumn() failed: %v", err)
+	}
+	if _, err := table.CreateColumn("Text", "ShortText", nil); err != nil {
+		t.Fatalf("Table.CreateColumn() failed: %v", err)
+	}
+	center := GeoPoint{128452975, 503157902}
+	points := []GeoPoint{
+		center,
+		{center.Latitude + 10000, center.Longitude},  // About 300m away.
+		{center.Latitude + 100000, center.Longitude}, // About 3km away.
+	}
+	for _, point := range points {
+		_, id, _ := table.InsertRow(nil)
+		if err := column.SetValue(id, point); err != nil {
+			t.Fatalf("Column.SetValue() failed: %v", err)
+		}
+	}
+	selectOptions := NewSelectOptions()
+	selectOptions.OutputColumns = []string{"_id"}
+	records, err := table.SelectNearby("Point", center, 1000, selectOptions)
+	if err != nil {
+		t.Fatalf("Table.SelectNearby() failed: %v", err)
+	}
+	if records.NHits != 2 {
+		t.Fatalf("Table.SelectNearby() failed: NHits = %d", records.NHits)
+	}
+	selectOptions.Filter = "_id == 2"
+	records, err = table.SelectNearby("Point", center, 1000, selectOptions)
+	if err != nil {
+		t.Fatalf("Table.SelectNearby() failed: %v", err)
+	}
+	if records.NHits != 1 {
+		t.Fatalf("Table.SelectNearby() failed: NHits = %d", records.NHits)
+	}
+	if _, err := table.SelectNearby("Text", center, 1000, nil); err == nil {
+		t.Fatalf("Table.SelectNearby() succeeded for a non-GeoPoint column")
+	}
 }
 
-func TestColumnSetValueForUInt32Vector(t *testing.T) {
-	testColumnSetValueForVector(t, "UInt32")
-}
+func TestTableSelectInRectangle(t *testing.T) {
+	dirPath, _, db, table := createTempTable(t, "Table", nil)
+	defer removeTempDB(t, dirPath, db)
 
-func TestColumnSetValueForUInt64Vector(t *testing.T) {
-	testColumnSetValueForVector(t, "UInt64")
+	column, err := table.CreateColumn("Point", "WGS84GeoPoint", nil)
+	if err != nil {
+		t.Fatalf("Table.CreateColumn() failed: %v", err)
+	}
+	if _, err := table.CreateColumn("Text", "ShortText", nil); err != nil {
+		t.Fatalf("Table.CreateColumn() failed: %v", err)
+	}
+	degrees := [][2]float64{
+		{35.68, 139.76}, // Tokyo
+		{34.69, 135.50}, // Osaka
+		{-36.85, 174.76},
+		{21.31, -157.86},
+		{-17.71, 178.06},
+		{-13.83, -171.76},
+	}
+	for _, d := range degrees {
+		point, err := NewGeoPointFromDegrees(d[0], d[1])
+		if err != nil {
+			t.Fatalf("NewGeoPointFromDegrees() failed: %v", err)
+		}
+		_, id, _ := table.InsertRow(nil)
+		if err := column.SetValue(id, point); err != nil {
+			t.Fatalf("Column.SetValue() failed: %v", err)
+		}
+	}
+	selectOptions := NewSelectOptions()
+	selectOptions.OutputColumns = []string{"_id"}
+	topLeft, _ := NewGeoPointFromDegrees(36, 139)
+	bottomRight, _ := NewGeoPointFromDegrees(34, 140)
+	records, err := table.SelectInRectangle("Point", topLeft, bottomRight,
+		selectOptions)
+	if err != nil {
+		t.Fatalf("Table.SelectInRectangle() failed: %v", err)
+	}
+	if records.NHits != 1 {
+		t.Fatalf("Table.SelectInRectangle() failed: NHits = %d", records.NHits)
+	}
+	// Across the 180th meridian.
+	topLeft, _ = NewGeoPointFromDegrees(0, 170)
+	bottomRight, _ = NewGeoPointFromDegrees(-20, -170)
+	records, err = table.SelectInRectangle("Point", topLeft, bottomRight,
+		selectOptions)
+	if err != nil {
+		t.Fatalf("Table.SelectInRectangle() failed: %v", err)
+	}
+	if records.NHits != 2 {
+		t.Fatalf("Table.SelectInRectangle() failed: NHits = %d", records.NHits)
+	}
+	selectOptions.Filter = "_id == 5"
+	records, err = table.SelectInRectangle("Point", topLeft, bottomRight,
+		selectOptions)
+	if err != nil {
+		t.Fatalf("Table.SelectInRectangle() failed: %v", err)
+	}
+	if records.NHits != 1 {
+		t.Fatalf("Table.SelectInRectangle() failed: NHits = %d", records.NHits)
+	}
+	if _, err := table.SelectInRectangle("Point", bottomRight, topLeft, nil); err == nil {
+		t.Fatalf("Table.SelectInRectangle() succeeded for an upside-down rectangle")
+	}
+	if _, err := table.SelectInRectangle("Text", topLeft, bottomRight, nil); err == nil {
+		t.Fatalf("Table.SelectInRectangle() succeeded for a non-GeoPoint column")
+	}
 }
 
-func TestColumnSetValueForFloatVector(t *testing.T) {
-	testColumnSetValueForVector(t, "Float")
-}
+func TestTableInsertStruct(t *testing.T) {
+	options := NewTableOptions()
+	options.TableType = HashTable
+	options.KeyType = "ShortText"
+	dirPath, _, db, table := createTempTable(t, "Table", options)
+	defer removeTempDB(t, dirPath, db)
 
-func TestColumnSetValueForTokyoGeoPointVector(t *testing.T) {
-	testColumnSetValueForVector(t, "TokyoGeoPoint")
-}
+	vectorOptions := NewColumnOptions()
+	vectorOptions.ColumnType = VectorColumn
+	if _, err := table.CreateColumn("Count", "Int32", nil); err != nil {
+		t.Fatalf("Table.CreateColumn() failed: %v", err)
+	}
+	if _, err := table.CreateColumn("tags", "ShortText", vectorOptions); err != nil {
+		t.Fatalf("Table.CreateColumn() failed: %v", err)
+	}
 
-func TestColumnSetValueForWGS84GeoPointVector(t *testing.T) {
-	testColumnSetValueForVector(t, "WGS84GeoPoint")
-}
+	type Record struct {
+		Key   string   `grngo:"_key"`
+		Count int      `grngo:"Count"`
+		Tags  []string // Associated with "tags".
+		Other float64  // Skipped because there is no such column.
+	}
+	id, err := table.InsertStruct([]byte("Key"), &Record{"Key", 123, []string{"a", "b"}, 1.5})
+	if err != nil {
+		t.Fatalf("Table.InsertStruct() failed: %v", err)
+	}
+	column, _ := table.FindColumn("Count")
+	if value, err := column.GetValue(id); err != nil {
+		t.Fatalf("Column.GetValue() failed: %v", err)
+	} else if value != int64(123) {
+		t.Fatalf("Column.GetValue() failed: value = %v", value)
+	}
+	column, _ = table.FindColumn("tags")
+	if value, err := column.GetValue(id); err != nil {
+		t.Fatalf("Column.GetValue() failed: %v", err)
+	} else if !reflect.DeepEqual(value, [][]byte{[]byte("a"), []byte("b")}) {
+		t.Fatalf("Column.GetValue() failed: value = %v", value)
+	}
 
-func TestColumnSetValueForTextVector(t *testing.T) {
-	testColumnSetValueForVector(t, "ShortText")
+	type BadRecord struct {
+		Count   string `grngo:"Count"`
+		Missing int    `grngo:"Missing"`
+	}
+	if _, err := table.InsertStruct([]byte("Bad"), BadRecord{}); err == nil {
+		t.Fatalf("Table.InsertStruct() succeeded for invalid fields")
+	}
+	if n, _ := table.Len(); n != 1 {
+		t.Fatalf("Table.InsertStruct() inserted a row for invalid fields")
+	}
 }
 
-func testColumnGetValueForScalar(t *testing.T, valueType string) {
-	dirPath, _, db, table, column :=
-		createTempColumn(t, "Table", nil, "Value", valueType, nil)
+func TestTableInsertAndSet(t *testing.T) {
+	dirPath, _, db, table := createTempTable(t, "Table", nil)
 	defer removeTempDB(t, dirPath, db)
 
-	for i := 0; i < 100; i++ {
-		_, id, err := table.InsertRow(nil)
-		if err != nil {
-			t.Fatalf("Table.InsertRow() failed: %v", err)
-		}
-		value := generateRandomValue(valueType)
-		if err := column.SetValue(id, value); err != nil {
-			t.Fatalf("Column.SetValue() failed: %v", err)
-		}
-		if storedValue, err := column.GetValue(id); err != nil {
-			t.Fatalf("Column.GetValue() failed: %v", err)
-		} else if !reflect.DeepEqual(value, storedValue) {
-			t.Fatalf("Column.GetValue() failed: value = %v, storedValue = %v",
-				value, storedValue)
-		}
+	if _, err := table.CreateColumn("Serial", "Int32", nil); err != nil {
+		t.Fatalf("Table.CreateColumn() failed: %v", err)
+	}
+	if _, err := table.CreateColumn("Name", "ShortText", nil); err != nil {
+		t.Fatalf("Table.CreateColumn() failed: %v", err)
+	}
+	id, err := table.InsertAndSet(nil, map[string]interface{}{
+		"Serial": int64(1),
+		"Name":   []byte("Alice"),
+	})
+	if err != nil {
+		t.Fatalf("Table.InsertAndSet() failed: %v", err)
+	}
+	column, _ := table.FindColumn("Serial")
+	if value, err := column.GetValue(id); err != nil {
+		t.Fatalf("Column.GetValue() failed: %v", err)
+	} else if value != int64(1) {
+		t.Fatalf("Column.GetValue() failed: value = %v", value)
+	}
+	column, _ = table.FindColumn("Name")
+	if value, err := column.GetValue(id); err != nil {
+		t.Fatalf("Column.GetValue() failed: %v", err)
+	} else if !reflect.DeepEqual(value, []byte("Alice")) {
+		t.Fatalf("Column.GetValue() failed: value = %v", value)
+	}
+
+	if _, err := table.InsertAndSet(nil, map[string]interface{}{
+		"Missing": int64(1),
+	}); err == nil {
+		t.Fatalf("Table.InsertAndSet() succeeded for a missing column")
+	}
+	if n, _ := table.Len(); n != 1 {
+		t.Fatalf("Table.InsertAndSet() inserted a row for a missing column")
+	}
+	id, err = table.InsertAndSet(nil, map[string]interface{}{
+		"Serial": "invalid",
+	})
+	if err == nil {
+		t.Fatalf("Table.InsertAndSet() succeeded for an invalid value")
+	} else if !strings.Contains(err.Error(), "Serial") {
+		t.Fatalf("Table.InsertAndSet() failed: err = %v", err)
+	}
+	if id == NilID {
+		t.Fatalf("Table.InsertAndSet() failed: id = %d", id)
+	}
+	if err := table.RemoveRow(id); err != nil {
+		t.Fatalf("Table.RemoveRow() failed: %v", err)
 	}
-}
 
-func testColumnGetValueForVector(t *testing.T, valueType string) {
-	options := NewColumnOptions()
-	options.ColumnType = VectorColumn
-	dirPath, _, db, table, column :=
-		createTempColumn(t, "Table", nil, "Value", valueType, options)
-	defer removeTempDB(t, dirPath, db)
+	options := NewTableOptions()
+	options.TableType = HashTable
+	options.KeyType = "ShortText"
+	keyed, err := db.CreateTable("Keyed", options)
+	if err != nil {
+		t.Fatalf("DB.CreateTable() failed: %v", err)
+	}
+	if _, err := keyed.CreateColumn("Serial", "Int32", nil); err != nil {
+		t.Fatalf("Table.CreateColumn() failed: %v", err)
+	}
+	id, err = keyed.InsertAndSet([]byte("Key"), map[string]interface{}{"Serial": 1})
+	if err != nil {
+		t.Fatalf("Table.InsertAndSet() failed: %v", err)
+	}
+	existingID, err := keyed.InsertAndSet([]byte("Key"), map[string]interface{}{"Serial": 2})
+	if !errors.Is(err, ErrAlreadyExists) || (existingID != id) {
+		t.Fatalf("Table.InsertAndSet() failed: id = %d, err = %v", existingID, err)
+	}
+	var groongaError *GroongaError
+	if errors.As(err, &groongaError) {
+		t.Fatalf("Table.InsertAndSet() returned a GroongaError: err = %v", err)
+	}
+	column, _ = keyed.FindColumn("Serial")
+	if value, err := column.GetValue(id); err != nil || value != int64(1) {
+		t.Fatalf("Column.GetValue() failed: value = %v, err = %v", value, err)
+	}
 
-	for i := 0; i < 100; i++ {
-		_, id, err := table.InsertRow(nil)
-		if err != nil {
-			t.Fatalf("Table.InsertRow() failed: %v", err)
-		}
-		value := generateRandomVectorValue(valueType)
-		if err := column.SetValue(id, value); err != nil {
-			t.Fatalf("Column.SetValue() failed: %v", err)
+	// Concurrent readers never see a row without its values.
+	var wg sync.WaitGroup
+	done := make(chan struct{})
+	errs := make(chan error, 1)
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for {
+			select {
+			case <-done:
+				return
+			default:
+			}
+			cursor, err := keyed.OpenCursor(nil)
+			if err != nil {
+				errs <- err
+				return
+			}
+			for cursor.Next() {
+				if value, _ := column.GetValue(cursor.ID()); value == int64(0) {
+					cursor.Close()
+					errs <- fmt.Errorf("row without value: id = %d", cursor.ID())
+					return
+				}
+			}
+			cursor.Close()
 		}
-		if storedValue, err := column.GetValue(id); err != nil {
-			t.Fatalf("Column.GetValue() failed: %v", err)
-		} else if !reflect.DeepEqual(value, storedValue) {
-			t.Fatalf("Column.GetValue() failed: value = %v, storedValue = %v",
-				value, storedValue)
+	}()
+	for i := 0; i < 100; i++ {
+		key := []byte(strconv.Itoa(i))
+		if _, err := keyed.InsertAndSet(key, map[string]interface{}{"Serial": i + 1}); err != nil {
+			t.Fatalf("Table.InsertAndSet() failed: %v", err)
 		}
 	}
-
-	bytes, _ := db.Query("select Table --limit 3")
-	t.Logf("valueType = <%s>, result = %s", valueType, string(bytes))
+	close(done)
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Fatalf("Table.InsertAndSet() exposed a partial row: %v", err)
+	}
 }
 
-func TestColumnGetValueForBool(t *testing.T) {
-	testColumnGetValueForScalar(t, "Bool")
-}
+func TestTableSetRow(t *testing.T) {
+	options := NewTableOptions()
+	options.TableType = HashTable
+	options.KeyType = "ShortText"
+	dirPath, _, db, table := createTempTable(t, "Table", options)
+	defer removeTempDB(t, dirPath, db)
 
-func TestColumnGetValueForInt8(t *testing.T) {
-	testColumnGetValueForScalar(t, "Int8")
-}
+	count, err := table.CreateColumn("Count", "Int32", nil)
+	if err != nil {
+		t.Fatalf("Table.CreateColumn() failed: %v", err)
+	}
+	name, err := table.CreateColumn("Name", "ShortText", nil)
+	if err != nil {
+		t.Fatalf("Table.CreateColumn() failed: %v", err)
+	}
+	vectorOptions := NewColumnOptions()
+	vectorOptions.ColumnType = VectorColumn
+	tags, err := table.CreateColumn("Tags", "ShortText", vectorOptions)
+	if err != nil {
+		t.Fatalf("Table.CreateColumn() failed: %v", err)
+	}
+	_, id, err := table.InsertRow([]byte("Key"))
+	if err != nil {
+		t.Fatalf("Table.InsertRow() failed: %v", err)
+	}
 
-func TestColumnGetValueForInt16(t *testing.T) {
-	testColumnGetValueForScalar(t, "Int16")
-}
+	if err := table.SetRow(id, map[string]interface{}{
+		"Count": 123,
+		"Name":  "Groonga",
+		"Tags":  []string{"a", "b"},
+	}); err != nil {
+		t.Fatalf("Table.SetRow() failed: %v", err)
+	}
+	if value, err := count.GetValue(id); err != nil || value != int64(123) {
+		t.Fatalf("Column.GetValue() failed: value = %v, err = %v", value, err)
+	}
+	if value, err := name.GetValue(id); err != nil ||
+		!reflect.DeepEqual(value, []byte("Groonga")) {
+		t.Fatalf("Column.GetValue() failed: value = %v, err = %v", value, err)
+	}
+	if value, err := tags.GetValue(id); err != nil ||
+		!reflect.DeepEqual(value, [][]byte{[]byte("a"), []byte("b")}) {
+		t.Fatalf("Column.GetValue() failed: value = %v, err = %v", value, err)
+	}
 
-func TestColumnGetValueForInt32(t *testing.T) {
-	testColumnGetValueForScalar(t, "Int32")
+	err = table.SetRow(id, map[string]interface{}{
+		"Count": "invalid",
+		"Name":  "Mroonga",
+	})
+	if err == nil {
+		t.Fatalf("Table.SetRow() succeeded for an invalid value")
+	} else if !strings.Contains(err.Error(), "Count") {
+		t.Fatalf("Table.SetRow() failed: err = %v", err)
+	}
+	if value, err := name.GetValue(id); err != nil ||
+		!reflect.DeepEqual(value, []byte("Groonga")) {
+		t.Fatalf("Table.SetRow() assigned a value on failure: value = %v", value)
+	}
+	if err := table.SetRow(id, map[string]interface{}{"Missing": 1}); err == nil {
+		t.Fatalf("Table.SetRow() succeeded for a missing column")
+	}
+	if err := table.SetRow(id, map[string]interface{}{"_key": "Other"}); err == nil {
+		t.Fatalf("Table.SetRow() succeeded for _key")
+	}
+	err = table.SetRow(id+1, map[string]interface{}{"Count": 1})
+	if !errors.Is(err, ErrRowNotFound) {
+		t.Fatalf("Table.SetRow() failed: err = %v", err)
+	}
+	if n, _ := table.Len(); n != 1 {
+		t.Fatalf("Table.SetRow() inserted a row: n = %d", n)
+	}
 }
 
-func TestColumnGetValueForInt64(t *testing.T) {
-	testColumnGetValueForScalar(t, "Int64")
+func TestValueToJSONTime(t *testing.T) {
+	cases := []struct {
+		value    interface{}
+		expected string
+	}{
+		{time.Unix(1435312000, 123457000), `1435312000.123457`},
+		{time.Unix(1234567890, 500000000), `1234567890.500000`},
+		{time.Unix(-2, 250000000), `-1.750000`},
+		{[]time.Time{time.Unix(0, 1000), time.Unix(1, 999999000)},
+			`[0.000001,1.999999]`},
+	}
+	for _, c := range cases {
+		bytes, err := json.Marshal(valueToJSON(c.value))
+		if err != nil {
+			t.Fatalf("json.Marshal() failed: %v", err)
+		}
+		if string(bytes) != c.expected {
+			t.Fatalf("valueToJSON() failed: actual = %s, expected = %s",
+				bytes, c.expected)
+		}
+	}
 }
 
-func TestColumnGetValueForUInt8(t *testing.T) {
-	testColumnGetValueForScalar(t, "UInt8")
-}
+func TestTableLoad(t *testing.T) {
+	options := NewTableOptions()
+	options.TableType = HashTable
+	options.KeyType = "ShortText"
+	dirPath, _, db, table := createTempTable(t, "Table", options)
+	defer removeTempDB(t, dirPath, db)
 
-func TestColumnGetValueForUInt16(t *testing.T) {
-	testColumnGetValueForScalar(t, "UInt16")
-}
+	if _, err := table.CreateColumn("Value", "Text", nil); err != nil {
+		t.Fatalf("Table.CreateColumn() failed: %v", err)
+	}
+	if _, err := table.CreateColumn("Time", "Time", nil); err != nil {
+		t.Fatalf("Table.CreateColumn() failed: %v", err)
+	}
+	now := time.Unix(1234567890, 500000000)
+	var records []map[string]interface{}
+	for i := 0; i < 100; i++ {
+		records = append(records, map[string]interface{}{
+			"_key":  []byte(strconv.Itoa(i)),
+			"Value": []byte(fmt.Sprintf("'quoted' \\ \"%d\"", i)),
+			"Time":  now,
+		})
+	}
+	loadOptions := NewLoadOptions()
+	loadOptions.ChunkSize = 256
+	if n, err := table.Load(records, loadOptions); err != nil {
+		t.Fatalf("Table.Load() failed: %v", err)
+	} else if n != 100 {
+		t.Fatalf("Table.Load() failed: n = %d", n)
+	}
+	if n, _ := table.Len(); n != 100 {
+		t.Fatalf("Table.Len() failed: n = %d", n)
+	}
+	id, _, _ := table.GetIDByKey([]byte("12"))
+	column, _ := table.FindColumn("Value")
+	if value, err := column.GetValue(id); err != nil {
+		t.Fatalf("Column.GetValue() failed: %v", err)
+	} else if string(value.([]byte)) != "'quoted' \\ \"12\"" {
+		t.Fatalf("Column.GetValue() failed: value = %s", value)
+	}
+	column, _ = table.FindColumn("Time")
+	if value, err := column.GetValue(id); err != nil {
+		t.Fatalf("Column.GetValue() failed: %v", err)
+	} else if !value.(time.Time).Equal(now) {
+		t.Fatalf("Column.GetValue() failed: value = %v", value)
+	}
 
-func TestColumnGetValueForUInt32(t *testing.T) {
-	testColumnGetValueForScalar(t, "UInt32")
-}
+	loadOptions.IfExists = "false"
+	records = records[:1]
+	records[0]["Value"] = []byte("Updated")
+	if _, err := table.Load(records, loadOptions); err != nil {
+		t.Fatalf("Table.Load() failed: %v", err)
+	}
+	id, _, _ = table.GetIDByKey([]byte("0"))
+	column, _ = table.FindColumn("Value")
+	if value, _ := column.GetValue(id); string(value.([]byte)) == "Updated" {
+		t.Fatalf("Table.Load() updated a row despite ifexists")
+	}
 
-func TestColumnGetValueForUInt64(t *testing.T) {
-	testColumnGetValueForScalar(t, "UInt64")
+	records = []map[string]interface{}{{"Value": []byte("NoKey")}}
+	if _, err := table.Load(records, nil); err == nil {
+		t.Fatalf("Table.Load() succeeded for a record without key")
+	}
 }
 
-func TestColumnGetValueForFloat(t *testing.T) {
-	testColumnGetValueForScalar(t, "Float")
-}
+func TestTableOpenCursor(t *testing.T) {
+	dirPath, _, db, table := createTempTable(t, "Table", nil)
+	defer removeTempDB(t, dirPath, db)
 
-func TestColumnGetValueForTokyoGeoPoint(t *testing.T) {
-	testColumnGetValueForScalar(t, "TokyoGeoPoint")
-}
+	for i := 0; i < 10; i++ {
+		if _, _, err := table.InsertRow(nil); err != nil {
+			t.Fatalf("Table.InsertRow() failed: %v", err)
+		}
+	}
 
-func TestColumnGetValueForWGS84GeoPoint(t *testing.T) {
-	testColumnGetValueForScalar(t, "WGS84GeoPoint")
-}
+	cursor, err := table.OpenCursor(nil)
+	if err != nil {
+		t.Fatalf("Table.OpenCursor() failed: %v", err)
+	}
+	var ids []uint32
+	for cursor.Next() {
+		ids = append(ids, cursor.ID())
+	}
+	if err := cursor.Close(); err != nil {
+		t.Fatalf("TableCursor.Close() failed: %v", err)
+	}
+	if len(ids) != 10 {
+		t.Fatalf("TableCursor.Next() failed: ids = %v", ids)
+	}
+	for i, id := range ids {
+		if id != uint32(i+1) {
+			t.Fatalf("TableCursor.ID() failed: ids = %v", ids)
+		}
+	}
 
-func TestColumnGetValueForText(t *testing.T) {
-	testColumnGetValueForScalar(t, "ShortText")
-}
+	options := NewCursorOptions()
+	options.CursorOrder = Descending
+	options.Offset = 2
+	options.Limit = 3
+	cursor, err = table.OpenCursor(options)
+	if err != nil {
+		t.Fatalf("Table.OpenCursor() failed: %v", err)
+	}
+	ids = nil
+	for cursor.Next() {
+		ids = append(ids, cursor.ID())
+	}
+	if !reflect.DeepEqual(ids, []uint32{8, 7, 6}) {
+		t.Fatalf("TableCursor.Next() failed: ids = %v", ids)
+	}
+	if err := cursor.Close(); err != nil {
+		t.Fatalf("TableCursor.Close() failed: %v", err)
+	}
 
-func TestColumnGetValueForBoolVector(t *testing.T) {
-	testColumnGetValueForVector(t, "Bool")
+	cursor, err = table.OpenCursor(nil)
+	if err != nil {
+		t.Fatalf("Table.OpenCursor() failed: %v", err)
+	}
+	if !cursor.Next() {
+		t.Fatalf("TableCursor.Next() failed")
+	}
+	if err := cursor.Close(); err != nil {
+		t.Fatalf("TableCursor.Close() failed: %v", err)
+	}
+	if cursor.Next() {
+		t.Fatalf("TableCursor.Next() succeeded after TableCursor.Close()")
+	}
 }
 
-func TestColumnGetValueForInt8Vector(t *testing.T) {
-	testColumnGetValueForVector(t, "Int8")
-}
+func TestTableOpenCursorWithKeyRange(t *testing.T) {
+	options := NewTableOptions()
+	options.TableType = PatTable
+	options.KeyType = "ShortText"
+	dirPath, _, db, table := createTempTable(t, "Table", options)
+	defer removeTempDB(t, dirPath, db)
 
-func TestColumnGetValueForInt16Vector(t *testing.T) {
-	testColumnGetValueForVector(t, "Int16")
-}
+	for _, key := range []string{"f", "a", "d", "c", "g", "b", "e"} {
+		if _, _, err := table.InsertRow([]byte(key)); err != nil {
+			t.Fatalf("Table.InsertRow() failed: %v", err)
+		}
+	}
+	keyColumn, err := table.FindColumn("_key")
+	if err != nil {
+		t.Fatalf("Table.FindColumn() failed: %v", err)
+	}
+	scan := func(options *CursorOptions) []string {
+		cursor, err := table.OpenCursor(options)
+		if err != nil {
+			t.Fatalf("Table.OpenCursor() failed: %v", err)
+		}
+		defer cursor.Close()
+		var keys []string
+		for cursor.Next() {
+			key, err := keyColumn.GetValue(cursor.ID())
+			if err != nil {
+				t.Fatalf("Column.GetValue() failed: %v", err)
+			}
+			keys = append(keys, string(key.([]byte)))
+		}
+		return keys
+	}
 
-func TestColumnGetValueForInt32Vector(t *testing.T) {
-	testColumnGetValueForVector(t, "Int32")
+	cursorOptions := NewCursorOptions()
+	cursorOptions.MinKey = []byte("c")
+	cursorOptions.MaxKey = []byte("f")
+	cursorOptions.MaxExclusive = true
+	if keys := scan(cursorOptions); !reflect.DeepEqual(keys, []string{"c", "d", "e"}) {
+		t.Fatalf("Table.OpenCursor() failed: keys = %v", keys)
+	}
+	cursorOptions.CursorOrder = Descending
+	if keys := scan(cursorOptions); !reflect.DeepEqual(keys, []string{"e", "d", "c"}) {
+		t.Fatalf("Table.OpenCursor() failed: keys = %v", keys)
+	}
+	cursorOptions = NewCursorOptions()
+	cursorOptions.MinKey = []byte("e")
+	cursorOptions.MinExclusive = true
+	if keys := scan(cursorOptions); !reflect.DeepEqual(keys, []string{"f", "g"}) {
+		t.Fatalf("Table.OpenCursor() failed: keys = %v", keys)
+	}
+	cursorOptions.MinKey = int64(1)
+	if _, err := table.OpenCursor(cursorOptions); err == nil {
+		t.Fatalf("Table.OpenCursor() succeeded for a wrong key type")
+	}
 }
 
-func TestColumnGetValueForInt64Vector(t *testing.T) {
-	testColumnGetValueForVector(t, "Int64")
-}
+func TestTableCursorKey(t *testing.T) {
+	options := NewTableOptions()
+	options.TableType = PatTable
+	options.KeyType = "Int32"
+	dirPath, _, db, table := createTempTable(t, "Table", options)
+	defer removeTempDB(t, dirPath, db)
 
-func TestColumnGetValueForUInt8Vector(t *testing.T) {
-	testColumnGetValueForVector(t, "UInt8")
-}
+	for _, key := range []int64{3, -1, 2} {
+		if _, _, err := table.InsertRow(key); err != nil {
+			t.Fatalf("Table.InsertRow() failed: %v", err)
+		}
+	}
+	cursorOptions := NewCursorOptions()
+	cursorOptions.CursorOrder = Ascending
+	cursor, err := table.OpenCursor(cursorOptions)
+	if err != nil {
+		t.Fatalf("Table.OpenCursor() failed: %v", err)
+	}
+	defer cursor.Close()
+	if _, err := cursor.Key(); err == nil {
+		t.Fatalf("TableCursor.Key() succeeded before TableCursor.Next()")
+	}
+	var keys []int64
+	for cursor.Next() {
+		key, err := cursor.Key()
+		if err != nil {
+			t.Fatalf("TableCursor.Key() failed: %v", err)
+		}
+		keys = append(keys, key.(int64))
+	}
+	if !reflect.DeepEqual(keys, []int64{-1, 2, 3}) {
+		t.Fatalf("TableCursor.Key() failed: keys = %v", keys)
+	}
 
-func TestColumnGetValueForUInt16Vector(t *testing.T) {
-	testColumnGetValueForVector(t, "UInt16")
-}
+	dirPath2, _, db2, table2 := createTempTable(t, "Table", nil)
+	defer removeTempDB(t, dirPath2, db2)
+	if _, _, err := table2.InsertRow(nil); err != nil {
+		t.Fatalf("Table.InsertRow() failed: %v", err)
+	}
+	cursor2, err := table2.OpenCursor(nil)
+	if err != nil {
+		t.Fatalf("Table.OpenCursor() failed: %v", err)
+	}
+	defer cursor2.Close()
+	if !cursor2.Next() {
+		t.Fatalf("TableCursor.Next() failed")
+	}
+	if _, err := cursor2.Key(); err == nil {
+		t.Fatalf("TableCursor.Key() succeeded for a table without key")
+	}
 
-func TestColumnGetValueForUInt32Vector(t *testing.T) {
-	testColumnGetValueForVector(t, "UInt32")
+	options.KeyType = "ShortText"
+	table3, err := db.CreateTable("Text", options)
+	if err != nil {
+		t.Fatalf("DB.CreateTable() failed: %v", err)
+	}
+	for _, key := range []string{"abc", "de"} {
+		if _, _, err := table3.InsertRow([]byte(key)); err != nil {
+			t.Fatalf("Table.InsertRow() failed: %v", err)
+		}
+	}
+	cursor3, err := table3.OpenCursor(cursorOptions)
+	if err != nil {
+		t.Fatalf("Table.OpenCursor() failed: %v", err)
+	}
+	defer cursor3.Close()
+	var textKeys [][]byte
+	for cursor3.Next() {
+		key, err := cursor3.Key()
+		if err != nil {
+			t.Fatalf("TableCursor.Key() failed: %v", err)
+		}
+		// The key must not keep the buffer of the cursor.
+		if cap(key.([]byte)) != len(key.([]byte)) {
+			t.Fatalf("TableCursor.Key() failed: len = %d, cap = %d",
+				len(key.([]byte)), cap(key.([]byte)))
+		}
+		textKeys = append(textKeys, key.([]byte))
+	}
+	if !reflect.DeepEqual(textKeys, [][]byte{[]byte("abc"), []byte("de")}) {
+		t.Fatalf("TableCursor.Key() failed: keys = %q", textKeys)
+	}
 }
 
-func TestColumnGetValueForUInt64Vector(t *testing.T) {
-	testColumnGetValueForVector(t, "UInt64")
-}
+func TestDBParseSelectResult(t *testing.T) {
+	dirPath, _, db := createTempDB(t)
+	defer removeTempDB(t, dirPath, db)
 
-func TestColumnGetValueForFloatVector(t *testing.T) {
-	testColumnGetValueForVector(t, "Float")
+	result := `[[[2],[["_id","UInt32"],["Value","ShortText"],["Time","Time"]],` +
+		`[1,"abc",1435312000.123456],[2,null,null]]]`
+	records, err := db.parseSelectResult([]byte(result), nil, nil)
+	if err != nil {
+		t.Fatalf("DB.parseSelectResult() failed: %v", err)
+	}
+	if (records.NHits != 2) || (records.Len() != 2) {
+		t.Fatalf("DB.parseSelectResult() failed: NHits = %d, Len = %d",
+			records.NHits, records.Len())
+	}
+	columns := []ColumnInfo{{"_id", UInt32}, {"Value", ShortText}, {"Time", Time}}
+	if !reflect.DeepEqual(records.Columns(), columns) {
+		t.Fatalf("Records.Columns() failed: columns = %v", records.Columns())
+	}
+	if value, err := records.GetText(0, "Value"); err != nil {
+		t.Fatalf("Records.GetText() failed: %v", err)
+	} else if string(value) != "abc" {
+		t.Fatalf("Records.GetText() failed: value = %s", value)
+	}
+	if value, err := records.GetTime(0, "Time"); err != nil {
+		t.Fatalf("Records.GetTime() failed: %v", err)
+	} else if !value.Equal(time.Unix(1435312000, 123456000)) {
+		t.Fatalf("Records.GetTime() failed: value = %v", value)
+	}
+	if value, err := records.Get(1, "Value"); err != nil {
+		t.Fatalf("Records.Get() failed: %v", err)
+	} else if value != nil {
+		t.Fatalf("Records.Get() failed: value = %v", value)
+	}
+	if _, err := records.GetText(1, "Value"); err == nil {
+		t.Fatalf("Records.GetText() succeeded for null")
+	}
 }
 
-func TestColumnGetValueForTokyoGeoPointVector(t *testing.T) {
-	testColumnGetValueForVector(t, "TokyoGeoPoint")
-}
+func TestRecordsToMaps(t *testing.T) {
+	dirPath, _, db := createTempDB(t)
+	defer removeTempDB(t, dirPath, db)
 
-func TestColumnGetValueForWGS84GeoPointVector(t *testing.T) {
-	testColumnGetValueForVector(t, "WGS84GeoPoint")
-}
+	result := `[[[2],[["_id","UInt32"],["Value","ShortText"],["Tags","ShortText"],` +
+		`["Time","Time"],["Location","WGS84GeoPoint"]],` +
+		`[1,"abc",["a","b"],1435312000.5,"126000000x504000000"],[2,null,[],null,null]]]`
+	records, err := db.parseSelectResult([]byte(result), nil, nil)
+	if err != nil {
+		t.Fatalf("DB.parseSelectResult() failed: %v", err)
+	}
+	maps := records.ToMaps()
+	expected := []map[string]interface{}{{
+		"_id":      int64(1),
+		"Value":    "abc",
+		"Tags":     []string{"a", "b"},
+		"Time":     time.Unix(1435312000, 500000000),
+		"Location": GeoPoint{126000000, 504000000},
+	}, {
+		"_id":      int64(2),
+		"Value":    nil,
+		"Tags":     []string{},
+		"Time":     nil,
+		"Location": nil,
+	}}
+	if !reflect.DeepEqual(maps, expected) {
+		t.Fatalf("Records.ToMaps() failed: maps = %v", maps)
+	}
 
-func TestColumnGetValueForTextVector(t *testing.T) {
-	testColumnGetValueForVector(t, "ShortText")
+	bytes, err := json.Marshal(records)
+	if err != nil {
+		t.Fatalf("json.Marshal() failed: %v", err)
+	}
+	expectedJSON := `[{"Location":{"lat":35,"lng":140},"Tags":["a","b"],"Time":"` +
+		time.Unix(1435312000, 500000000).Format(time.RFC3339Nano) +
+		`","Value":"abc","_id":1},` +
+		`{"Location":null,"Tags":[],"Time":null,"Value":null,"_id":2}]`
+	if string(bytes) != expectedJSON {
+		t.Fatalf("Records.MarshalJSON() failed: json = %s", bytes)
+	}
 }
 
 var numTestRows = 100000
@@ -1273,3 +6330,41 @@ func BenchmarkDBSelectForWGS84GeoPointVector(b *testing.B) {
 func BenchmarkDBSelectForTextVector(b *testing.B) {
 	benchmarkDBSelectForVector(b, "ShortText")
 }
+
+func benchmarkPoolSelect(b *testing.B, size int) {
+	b.StopTimer()
+	dirPath, dbPath, db, table := createTempTable(b, "Table", nil)
+	defer removeTempDB(b, dirPath, db)
+	for i := 0; i < numTestRows; i++ {
+		if _, _, err := table.InsertRow(nil); err != nil {
+			b.Fatalf("Table.InsertRow() failed: %s", err)
+		}
+	}
+	pool, err := OpenPool(dbPath, size)
+	if err != nil {
+		b.Fatalf("OpenPool() failed: %s", err)
+	}
+	defer pool.Close()
+
+	b.StartTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			pooledDB := pool.Get()
+			_, err := pooledDB.Query("select Table --output_columns _id --limit -1")
+			pool.Put(pooledDB)
+			if err != nil {
+				b.Errorf("DB.Query() failed: %s", err)
+				return
+			}
+		}
+	})
+	b.StopTimer()
+}
+
+func BenchmarkPoolSelectWithSize1(b *testing.B) {
+	benchmarkPoolSelect(b, 1)
+}
+
+func BenchmarkPoolSelectWithSize4(b *testing.B) {
+	benchmarkPoolSelect(b, 4)
+}